@@ -4,14 +4,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/api/middlewares"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/config"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/controllers"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/migrations"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/drivers"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/routes"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/minio/minio-go/v7"
@@ -30,6 +34,14 @@ func main() {
 	}
 	time.Local = loc
 
+	// go run ./api migrate <up|down|status|seed|create>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Cargar las variables del archivo .env
 	err = godotenv.Load(".env")
 	if err != nil {
@@ -48,6 +60,32 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// No se atiende con un esquema distinto al que espera el binario. Con MIGRATE_ON_START=true se migra al arrancar.
+	// Las migraciones versionadas son SQL de Postgres: en MySQL y SQLite las tablas se crean desde las entities, sin
+	// control de versión ni migraciones de datos (ver `migrate` sin argumentos).
+	if db.Dialector.Name() == config.DriverPostgres {
+		migrator, err := migrations.New(db)
+		if err != nil {
+			log.Fatal("Error loading migrations:", err)
+		}
+		if os.Getenv("MIGRATE_ON_START") == "true" {
+			if err := migrateUp(db, migrator, 0); err != nil {
+				log.Fatal("Error migrating database:", err)
+			}
+		}
+		if err := migrator.Check(); err != nil {
+			log.Fatalf("%v. Correr `go run ./api migrate up` o arrancar con MIGRATE_ON_START=true", err)
+		}
+	} else if err := migrateUp(db, nil, 0); err != nil {
+		log.Fatal("Error migrating database:", err)
+	}
+
+	// Repositorios del motor configurado en DB_DRIVER
+	repos, err := drivers.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Instanciar el cliente de MinIO
 	minioClient, err := minio.New(os.Getenv("MINIO_ENDPOINT"), &minio.Options{
 		Creds: credentials.NewStaticV4(os.Getenv("MINIO_ACCESS_KEY"), os.Getenv("MINIO_SECRET_KEY"), ""),
@@ -71,55 +109,96 @@ func main() {
 		go TelegramService.RunTelegramGoRoutine(&InstanceTelegram)
 	}
 
-	// Instancio api de OPEN AI
-	OpenAIAssistantClient := services.NewOpenAIAssistantService(os.Getenv("OPENAI_API_KEY"))
+	// Instancio api de OPEN AI. Un único cliente (timeouts, reintentos y circuit breaker en OPENAI_*) para la
+	// Assistants API y Chat Completions
+	OpenAIClient := clients.NewOpenAIClient(clients.OpenAIConfigFromEnv())
+	OpenAIAssistantClient := services.NewOpenAIAssistantService(OpenAIClient)
+	// Cliente de la Cloud API de WhatsApp compartido por todos los números (cada uno usa su token)
+	WhatsappClient := clients.NewWhatsappClient(os.Getenv("WHATSAPP_URL"), os.Getenv("WHATSAPP_VERSION"))
 
 	// Inicialización de repositorios y servicios
-	RolesRepository := postgres_client.NewRolesRepository(db)
+	RolesRepository := repos.Roles
 	RolesService := services.NewRolesService(RolesRepository)
 	UtilService := services.NewUtilService()
-	UsersRepository := postgres_client.NewUsersRepository(db)
+	UsersRepository := repos.Users
 	UsersService := services.NewUsersService(UsersRepository, RolesService)
 	UsersController := controllers.NewUsersController(UsersService)
-	LogsRepository := postgres_client.NewLogsRepository(db)
+	LogsRepository := repos.Logs
 	LogsService := services.NewLogsService(LogsRepository)
 	LogsController := controllers.NewLogsController(LogsService)
-	Password_resetsRepository := postgres_client.NewPasswordResetsRepository(db)
+	Password_resetsRepository := repos.PasswordResets
 	Password_resetsService := services.NewPassword_resetsService(Password_resetsRepository)
 	Password_resetsController := controllers.NewPassword_resetsController(Password_resetsService)
-	MessageRepository := postgres_client.NewMessagesRepository(db)
-	MessageService := services.NewMessagesService(MessageRepository)
+	MessageRepository := repos.Messages
+	MessageStatusesRepository := repos.MessageStatuses
+	NumberPhonesRepository := repos.NumberPhones
+	// Eventos en tiempo real para el dashboard (mensajes, estados y handoff)
+	ConversationStream := services.NewConversationStream(MessageRepository, NumberPhonesRepository)
+	StreamController := controllers.NewStreamController(ConversationStream)
+	MessageService := services.NewMessagesService(MessageRepository, MessageStatusesRepository, ConversationStream)
 	MessageController := controllers.NewMessagesController(MessageService)
 
-	ContactRepository := postgres_client.NewContactsRepository(db)
+	ContactRepository := repos.Contacts
 	ContactService := services.NewContactsService(ContactRepository)
 	ContactController := controllers.NewContactsController(ContactService)
 
 	RolesController := controllers.NewRolesController(RolesService)
-	PermissionsRepository := postgres_client.NewPermissionsRepository(db)
+	PermissionsRepository := repos.Permissions
 	PermissionsService := services.NewPermissionsService(PermissionsRepository)
 	PermissionsController := controllers.NewPermissionsController(PermissionsService)
-	NumberPhonesRepository := postgres_client.NewNumberPhonesRepository(db)
 	NumberPhonesService := services.NewNumberPhonesService(NumberPhonesRepository)
 	NumberPhonesController := controllers.NewNumberPhonesController(NumberPhonesService)
-	FileRepository := postgres_client.NewFileRepository(db)
+	FileRepository := repos.Files
 	FileService := services.NewFileService(FileRepository, minioClient)
 	FileController := controllers.NewFileController(FileService)
-	ConfigurationRepository := postgres_client.NewConfigurationsRepository(db)
+	ConfigurationRepository := repos.Configurations
 	ConfigurationService := services.NewConfigurationsService(ConfigurationRepository)
-	AssistantRepository := postgres_client.NewAssistantRepository(db)
-	AssistantService := services.NewAssistantService(AssistantRepository, FileService, OpenAIAssistantClient)
+	AssistantRepository := repos.Assistants
+	// Tools que los assistants pueden ejecutar; cada servicio registra las suyas
+	ToolRegistry := services.NewToolRegistry()
+	AssistantService := services.NewAssistantService(AssistantRepository, FileService, OpenAIAssistantClient, ToolRegistry)
 	AssistantController := controllers.NewAssistantController(AssistantService)
-	EventsRepository := postgres_client.NewEventsRepository(db)
+	EventsRepository := repos.Events
 	EventsService := services.NewEventsService(EventsRepository, *UtilService)
 	EventsController := controllers.NewEventsController(EventsService)
-	GoogleCalendarRepository := postgres_client.NewGoogleCalendarConfigsRepository(db)
+	GoogleCalendarRepository := repos.GoogleCalendarCredentials
 	GoogleCalendarService := services.NewGoogleCalendarService(GoogleCalendarRepository, *AssistantService, EventsService)
-	ThreadRepository := postgres_client.NewThreadRepository(db)
+	ClosuresRepository := repos.AssistantClosures
+	ClosuresService := services.NewClosuresService(ClosuresRepository, AssistantService)
+	ClosuresController := controllers.NewClosuresController(ClosuresService)
+	AvailabilityService := services.NewAvailabilityService(AssistantService, ClosuresService, EventsRepository, GoogleCalendarService, OauthConfig)
+	AvailabilityService.RegisterTools(ToolRegistry)
+	AvailabilityController := controllers.NewAvailabilityController(AvailabilityService)
+	ThreadRepository := repos.Threads
 	ThreadService := services.NewThreadService(ThreadRepository, OpenAIAssistantClient)
-	WhatsappService := services.NewWhatsappService(UsersService, LogsService, OpenAIAssistantClient, UtilService, NumberPhonesService, MessageRepository, AssistantService, ConfigurationService, GoogleCalendarService, OauthConfig, EventsService, ThreadService)
-	WhatsappController := controllers.NewWhatsappController(WhatsappService)
-	BussinessRepository := postgres_client.NewBussinessRepository(db)
+	// Proveedores de modelos que puede usar cada assistant
+	LLMProviders := services.NewLLMProviders(map[string]services.LLMProvider{
+		dtos.LLMProviderOpenAIAssistants: services.NewOpenAIAssistantsProvider(OpenAIAssistantClient, ThreadService, FileService),
+		dtos.LLMProviderOpenAIChat:       services.NewOpenAIChatProvider(OpenAIClient),
+		dtos.LLMProviderOllama:           services.NewOllamaProvider(os.Getenv("OLLAMA_URL"), os.Getenv("OLLAMA_MODEL"), strings.Split(os.Getenv("OLLAMA_ALLOWED_URLS"), ",")),
+	})
+	EventRemindersRepository := repos.EventReminders
+	HandoffService := services.NewHandoffService(ContactRepository, MessageRepository, ConversationStream)
+	// Templates de WhatsApp sincronizados con Meta y los que usa cada assistant
+	MessageTemplatesRepository := repos.MessageTemplates
+	AssistantTemplatesRepository := repos.AssistantTemplates
+	TemplatesService := services.NewTemplatesService(MessageTemplatesRepository, AssistantTemplatesRepository, NumberPhonesRepository, AssistantService, WhatsappClient)
+	TemplatesController := controllers.NewTemplatesController(TemplatesService)
+	// Consumo de tokens del modelo por contacto, assistant, número y bussiness, con la cuota mensual de cada bussiness
+	TokenUsagesRepository := repos.TokenUsages
+	BussinessRepository := repos.Bussiness
+	UsageService := services.NewUsageService(TokenUsagesRepository, BussinessRepository, ContactRepository, services.LoadLLMPrices())
+	UsageController := controllers.NewUsageController(UsageService)
+	WhatsappService := services.NewWhatsappService(UsersService, LogsService, OpenAIAssistantClient, UtilService, NumberPhonesService, MessageRepository, AssistantService, ConfigurationService, GoogleCalendarService, OauthConfig, EventsService, ThreadService, FileService, MessageService, LLMProviders, ToolRegistry, AvailabilityService, EventRemindersRepository, HandoffService, TemplatesService, UsageService, ContactRepository, WhatsappClient)
+	InboundJobsRepository := repos.InboundJobs
+	InboundJobsService := services.NewInboundJobsService(InboundJobsRepository, WhatsappService)
+	InboundJobsController := controllers.NewInboundJobsController(InboundJobsService)
+	WhatsappController := controllers.NewWhatsappController(WhatsappService, InboundJobsService)
+	HandoffController := controllers.NewHandoffController(HandoffService, WhatsappService)
+	// Campañas: envíos masivos de templates a segmentos de contactos
+	CampaignsRepository := repos.Campaigns
+	CampaignsService := services.NewCampaignsService(CampaignsRepository, ContactRepository, NumberPhonesRepository, MessageTemplatesRepository, EventsRepository, WhatsappService)
+	CampaignsController := controllers.NewCampaignsController(CampaignsService)
 	BussinessService := services.NewBussinessService(BussinessRepository)
 	BussinessController := controllers.NewBussinessController(BussinessService)
 
@@ -130,11 +209,20 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Workers que procesan las notificaciones del webhook
+	InboundJobsService.Start()
+	// Workers que envían las campañas programadas
+	CampaignsService.Start()
+
 	// AUTH
 	AuthService := services.NewAuthService(UsersService, Password_resetsRepository)
 	AuthController := controllers.NewAuthController(AuthService)
 
-	meddlewares := middlewares.MiddlewareManager{}
+	// TENANTS: cada request ve solo los bussiness del usuario (bussiness_has_users)
+	TenantRepository := repos.Tenant
+	TenantService := services.NewTenantService(TenantRepository)
+
+	meddlewares := middlewares.MiddlewareManager{TenantResolver: TenantService.ResolveScope}
 
 	// Registrar el middleware para registrar las rutas consultadas
 	// Configuración del middleware logger con formato personalizado
@@ -153,7 +241,7 @@ func main() {
 	app.Use(meddlewares.SecureHeadersMiddleware())
 
 	// Configuración de TODAS las rutas
-	routes.Setup(app, &meddlewares, AuthController, FileController, AssistantController, BussinessController, UsersController, LogsController, Password_resetsController, RolesController, PermissionsController, WhatsappController, NumberPhonesController, TelegramController, OauthConfig, GoogleCalendarService, MessageController, ContactController, ContactService, EventsController)
+	routes.Setup(app, &meddlewares, AuthController, FileController, AssistantController, BussinessController, UsersController, LogsController, Password_resetsController, RolesController, PermissionsController, WhatsappController, NumberPhonesController, TelegramController, OauthConfig, GoogleCalendarService, MessageController, ContactController, ContactService, EventsController, NumberPhonesService, InboundJobsController, AvailabilityController, ClosuresController, HandoffController, StreamController, TemplatesController, CampaignsController, UsageController)
 
 	log.Fatal(app.Listen(":" + os.Getenv("APP_PORT")))
 }
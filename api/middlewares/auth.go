@@ -8,12 +8,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// TenantResolver devuelve los bussiness a los que puede acceder el usuario del token
+type TenantResolver func(userID int64, role string) (dtos.TenantScope, error)
+
 type MiddlewareManager struct {
-	HTTPClient *http.Client
+	HTTPClient     *http.Client
+	TenantResolver TenantResolver
 }
 
 // SecureHeadersMiddleware agrega cabeceras de seguridad a todas las respuestas
@@ -43,6 +48,19 @@ func (m *MiddlewareManager) ValidarApikey() func(c *fiber.Ctx) error {
 	}
 }
 
+// TokenFromQuery toma el token del parámetro access_token cuando el request no trae el header Authorization.
+// Es para las conexiones que no pueden mandar headers (EventSource del navegador); después se valida con ValidarPermiso.
+func (m *MiddlewareManager) TokenFromQuery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
+
 // ValidarPermiso verifica que el token incluya el rol y permisos adecuados
 func (m *MiddlewareManager) ValidarPermiso(scope string) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
@@ -120,10 +138,24 @@ func (m *MiddlewareManager) ValidarPermiso(scope string) func(c *fiber.Ctx) erro
 		}
 
 		// Pasar los claims al contexto para usarlos en el handler si es necesario
-		c.Locals("user_id", claims["id"])
+		userID, _ := claims["userId"].(float64)
+		c.Locals("user_id", int64(userID))
 		c.Locals("role", rol)
 		c.Locals("permissions", permissions)
 
+		// Bussiness del usuario: los controllers filtran con ellos todas las consultas (ver dtos.TenantScope)
+		if m.TenantResolver != nil {
+			tenant, err := m.TenantResolver(int64(userID), rol)
+			if err != nil {
+				log.Printf("Error obteniendo los bussiness del usuario %d: %v", int64(userID), err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"status":  false,
+					"message": "No se pudieron obtener los permisos del usuario",
+				})
+			}
+			c.Locals(dtos.TenantLocalsKey, tenant)
+		}
+
 		return c.Next()
 	}
 }
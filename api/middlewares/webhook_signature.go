@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header con el que Meta firma el cuerpo de cada notificación del webhook
+const HeaderHubSignature256 = "X-Hub-Signature-256"
+
+// AppSecretResolver devuelve el app secret de Meta que corresponde al phone_number_id de la notificación
+type AppSecretResolver func(whatsappNumberPhoneID string) (string, error)
+
+// ValidarFirmaWebhook verifica la firma HMAC-SHA256 (X-Hub-Signature-256) que Meta envía en el webhook de WhatsApp.
+// Rechaza las notificaciones sin firma o con una firma que no corresponde al app secret del número.
+func (m *MiddlewareManager) ValidarFirmaWebhook(resolver AppSecretResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		signature := c.Get(HeaderHubSignature256)
+		if signature == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"status":  false,
+				"message": "Firma del webhook no enviada",
+			})
+		}
+
+		body := c.Body()
+
+		// El app secret depende del número que recibió el mensaje, por eso se lee el phone_number_id antes de validar
+		appSecret, err := resolver(phoneNumberIDFromPayload(body))
+		if err != nil {
+			log.Printf("Error obteniendo el app secret del webhook: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  false,
+				"message": "No se pudo validar la firma del webhook",
+			})
+		}
+		if appSecret == "" {
+			log.Println("No hay app secret configurado para validar el webhook de WhatsApp")
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"status":  false,
+				"message": "Firma del webhook inválida",
+			})
+		}
+
+		if !ValidWebhookSignature(body, signature, appSecret) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"status":  false,
+				"message": "Firma del webhook inválida",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// ValidWebhookSignature compara en tiempo constante la firma "sha256=<hex>" con el HMAC-SHA256 del cuerpo
+func ValidWebhookSignature(body []byte, signature, appSecret string) bool {
+	signatureHex, found := strings.CutPrefix(signature, "sha256=")
+	if !found {
+		return false
+	}
+
+	received, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// phoneNumberIDFromPayload obtiene el primer phone_number_id de la notificación (todas las entradas son de la misma app)
+func phoneNumberIDFromPayload(body []byte) string {
+	var payload whatsapp.ResponseComplet
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Value.Metadata.PhoneNumberID != "" {
+				return change.Value.Metadata.PhoneNumberID
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	testAppSecret      = "4f9c1e2d7b3a8c6e5d0f1a2b3c4d5e6f"
+	testOtherAppSecret = "0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+	testPhoneNumberID  = "106540352242922"
+	testOtherNumberID  = "209876543210987"
+)
+
+func loadPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading payload %s: %v", name, err)
+	}
+	return body
+}
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// secretsByNumber simula la búsqueda del app secret por phone_number_id
+func secretsByNumber(secrets map[string]string) AppSecretResolver {
+	return func(whatsappNumberPhoneID string) (string, error) {
+		return secrets[whatsappNumberPhoneID], nil
+	}
+}
+
+func newWebhookApp(resolver AppSecretResolver) *fiber.App {
+	m := MiddlewareManager{}
+	app := fiber.New()
+	app.Post("/api/webhook", m.ValidarFirmaWebhook(resolver), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func postWebhook(t *testing.T, app *fiber.App, body []byte, signature string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/webhook", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(HeaderHubSignature256, signature)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestValidarFirmaWebhook(t *testing.T) {
+	resolver := secretsByNumber(map[string]string{
+		testPhoneNumberID: testAppSecret,
+		testOtherNumberID: testOtherAppSecret,
+	})
+	textMessage := loadPayload(t, "webhook_text_message.json")
+	statusDelivered := loadPayload(t, "webhook_status_delivered.json")
+	otherNumber := loadPayload(t, "webhook_other_number.json")
+
+	tampered := bytes.Replace(textMessage, []byte("jueves"), []byte("viernes"), 1)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		want      int
+	}{
+		{"mensaje firmado", textMessage, sign(textMessage, testAppSecret), fiber.StatusOK},
+		{"estado firmado", statusDelivered, sign(statusDelivered, testAppSecret), fiber.StatusOK},
+		{"secret propio de otro número", otherNumber, sign(otherNumber, testOtherAppSecret), fiber.StatusOK},
+		{"sin firma", textMessage, "", fiber.StatusUnauthorized},
+		{"cuerpo modificado", tampered, sign(textMessage, testAppSecret), fiber.StatusUnauthorized},
+		{"firmado con el secret de otro número", otherNumber, sign(otherNumber, testAppSecret), fiber.StatusUnauthorized},
+		{"firma sin prefijo sha256", textMessage, hex.EncodeToString([]byte("x")), fiber.StatusUnauthorized},
+		{"firma que no es hex", textMessage, "sha256=zzzz", fiber.StatusUnauthorized},
+	}
+
+	app := newWebhookApp(resolver)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postWebhook(t, app, tt.body, tt.signature); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidarFirmaWebhookSinSecretConfigurado(t *testing.T) {
+	body := loadPayload(t, "webhook_text_message.json")
+	app := newWebhookApp(secretsByNumber(map[string]string{}))
+
+	if got := postWebhook(t, app, body, sign(body, "")); got != fiber.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", got, fiber.StatusUnauthorized)
+	}
+}
+
+func TestValidarFirmaWebhookErrorResolver(t *testing.T) {
+	body := loadPayload(t, "webhook_text_message.json")
+	app := newWebhookApp(func(string) (string, error) {
+		return "", errors.New("database down")
+	})
+
+	if got := postWebhook(t, app, body, sign(body, testAppSecret)); got != fiber.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", got, fiber.StatusInternalServerError)
+	}
+}
+
+func TestPhoneNumberIDFromPayload(t *testing.T) {
+	if got := phoneNumberIDFromPayload(loadPayload(t, "webhook_status_delivered.json")); got != testPhoneNumberID {
+		t.Errorf("phone_number_id = %q, want %q", got, testPhoneNumberID)
+	}
+	if got := phoneNumberIDFromPayload([]byte("no es json")); got != "" {
+		t.Errorf("phone_number_id = %q, want empty", got)
+	}
+}
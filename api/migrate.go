@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/config"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/migrations"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `uso: api migrate <comando>
+
+  up [N]         aplica las migraciones pendientes (o las N siguientes) y carga roles y permisos
+  down [N]       revierte la última migración aplicada (o las N últimas)
+  status         lista las migraciones y si están aplicadas
+  seed           carga los roles y el catálogo de permisos que falten
+  create <name>  crea los archivos up y down de una migración nueva en MIGRATIONS_DIR (internal/migrations/sql)
+
+Las migraciones versionadas son SQL de Postgres y son el único lugar donde cambia el esquema. Con DB_DRIVER mysql
+o sqlite solo están up y seed: up crea y amplía las tablas desde las entities (AutoMigrate), sin control de versión
+y sin las migraciones de datos (por ejemplo la conversión de los números a E.164 de 0004), así que esos motores
+son para instalaciones nuevas.`
+
+// runMigrate atiende el subcomando migrate: `go run ./api migrate up`
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Las variables pueden venir del entorno (en el deploy no siempre hay .env)
+	_ = godotenv.Load(".env")
+
+	if args[0] == "create" {
+		dir := os.Getenv("MIGRATIONS_DIR")
+		if dir == "" {
+			dir = "internal/migrations/sql"
+		}
+		paths, err := migrations.Create(dir, strings.Join(args[1:], "_"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Println("creada", path)
+		}
+		return nil
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("cantidad de migraciones inválida: %s", args[1])
+		}
+		steps = n
+	}
+
+	db, err := config.InitDatabase()
+	if err != nil {
+		return err
+	}
+
+	// En MySQL y SQLite no hay migraciones versionadas: up crea las tablas desde las entities
+	if name := db.Dialector.Name(); name != config.DriverPostgres {
+		switch args[0] {
+		case "up":
+			return migrateUp(db, nil, 0)
+		case "seed":
+			return migrations.Seed(db, adminRoleName())
+		}
+		return fmt.Errorf("migrate %s solo está disponible en Postgres (DB_DRIVER=%s)", args[0], name)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrateUp(db, migrator, steps)
+	case "down":
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("revertida %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no hay migraciones aplicadas")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pendiente"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+		if err := migrator.Check(); err != nil {
+			fmt.Println(err)
+		}
+		return nil
+	case "seed":
+		return migrations.Seed(db, adminRoleName())
+	}
+	return errors.New(migrateUsage)
+}
+
+// migrateUp aplica las migraciones pendientes y después los seeds. Sin migrator (MySQL o SQLite) las tablas se crean
+// con migrations.AutoMigrate.
+func migrateUp(db *gorm.DB, migrator *migrations.Migrator, steps int) error {
+	if migrator == nil {
+		if err := migrations.AutoMigrate(db); err != nil {
+			return err
+		}
+		return migrations.Seed(db, adminRoleName())
+	}
+	if err := config.EnsureSchema(db); err != nil {
+		return err
+	}
+	applied, err := migrator.Up(steps)
+	for _, migration := range applied {
+		fmt.Printf("aplicada %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("el esquema ya está actualizado")
+	}
+	return migrations.Seed(db, adminRoleName())
+}
+
+// adminRoleName es el rol de administrador que se crea en los seeds, el mismo que reconoce TenantService
+func adminRoleName() string {
+	if role := os.Getenv("ROL_ADMIN"); role != "" {
+		return role
+	}
+	return "admin"
+}
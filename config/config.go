@@ -5,23 +5,59 @@ import (
 	"os"
 	"time"
 
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// DatabaseSchema es el schema de Postgres donde viven las tablas de la API
+const DatabaseSchema = "chatbot_whatsapp"
+
+// Drivers de base soportados (DB_DRIVER). Postgres es el de producción y el default.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseDriver devuelve el driver configurado en DB_DRIVER
+func DatabaseDriver() string {
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		return driver
+	}
+	return DriverPostgres
+}
 
 func InitDatabase() (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=America/Argentina/Buenos_Aires  search_path=chatbot_whatsapp",
-		os.Getenv("POSTGRES_HOST"),
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_DB"),
-		os.Getenv("POSTGRES_PORT"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	var dialector gorm.Dialector
+	switch driver := DatabaseDriver(); driver {
+	case DriverPostgres:
+		dialector = postgres.Open(fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=America/Argentina/Buenos_Aires  search_path=%s",
+			os.Getenv("POSTGRES_HOST"),
+			os.Getenv("POSTGRES_USER"),
+			os.Getenv("POSTGRES_PASSWORD"),
+			os.Getenv("POSTGRES_DB"),
+			os.Getenv("POSTGRES_PORT"),
+			DatabaseSchema,
+		))
+	case DriverMySQL:
+		dialector = mysql.Open(fmt.Sprintf(
+			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=America%%2FArgentina%%2FBuenos_Aires",
+			os.Getenv("MYSQL_USER"),
+			os.Getenv("MYSQL_PASSWORD"),
+			os.Getenv("MYSQL_HOST"),
+			os.Getenv("MYSQL_PORT"),
+			os.Getenv("MYSQL_DB"),
+		))
+	case DriverSQLite:
+		dialector = sqlite.Open(os.Getenv("SQLITE_PATH"))
+	default:
+		return nil, fmt.Errorf("DB_DRIVER inválido: %s (postgres, mysql o sqlite)", driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +73,18 @@ func InitDatabase() (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)                  // Máximo de conexiones en espera
 	sqlDB.SetConnMaxLifetime(10 * time.Minute) // Reiniciar conexiones cada 10 minutos
 	sqlDB.SetConnMaxIdleTime(5 * time.Minute)  // Tiempo máximo de inactividad antes de cerrar una conexión
+	if db.Dialector.Name() == DriverSQLite {
+		// SQLite no admite escrituras concurrentes
+		sqlDB.SetMaxOpenConns(1)
+	}
 
-	DB = db
 	return db, nil
 }
+
+// EnsureSchema crea el schema si no existe, para poder migrar una base vacía (solo Postgres usa schemas)
+func EnsureSchema(db *gorm.DB) error {
+	if db.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+	return db.Exec("CREATE SCHEMA IF NOT EXISTS " + DatabaseSchema).Error
+}
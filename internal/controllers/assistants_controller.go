@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type AssistantController struct {
@@ -58,10 +60,15 @@ func (controller *AssistantController) AddAssistant(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	service := controller.service.WithTenant(tenantScope(c))
+
 	// Obtener el archivo de la solicitud
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
-		assistantDto, err = controller.service.CreateAssistant(assistantDto)
+		assistantDto, err = service.CreateAssistant(assistantDto)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Bussiness not found"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error creating assistant. " + err.Error()})
 		}
@@ -73,7 +80,10 @@ func (controller *AssistantController) AddAssistant(c *fiber.Ctx) error {
 		}
 
 		// Llamar al servicio AssistantService para crear el asistente, pasando el fileHeader
-		assistantDto, err = controller.service.CreateAssistantWithFile(assistantDto, fileHeader)
+		assistantDto, err = service.CreateAssistantWithFile(assistantDto, fileHeader)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Bussiness not found"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error creating assistant. " + err.Error()})
 		}
@@ -105,14 +115,14 @@ func (controller *AssistantController) EditAssistant(c *fiber.Ctx) error {
 	fileContent, err := c.FormFile("file")
 	if err == nil {
 		// Manejo del archivo si está presente
-		updatedAssistant, err = controller.service.UpdateAssistantWithFile(int64(id), assistantDto, fileContent)
+		updatedAssistant, err = controller.service.WithTenant(tenantScope(c)).UpdateAssistantWithFile(int64(id), assistantDto, fileContent)
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 		}
 	} else {
 
 		fmt.Println("No file uploaded, proceeding without file.")
-		updatedAssistant, err = controller.service.UpdateAssistant(int64(id), assistantDto)
+		updatedAssistant, err = controller.service.WithTenant(tenantScope(c)).UpdateAssistant(int64(id), assistantDto)
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -138,7 +148,7 @@ func (controller *AssistantController) UpdateAssistant(c *fiber.Ctx) error {
 	assistantDto.ID = int64(id)
 
 	// Enviamos los datos al servicio para actualizar solo los campos proporcionados
-	updatedAssistant, err := controller.service.PartialUpdateAssistant(int64(id), assistantDto)
+	updatedAssistant, err := controller.service.WithTenant(tenantScope(c)).PartialUpdateAssistant(int64(id), assistantDto)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -148,7 +158,7 @@ func (controller *AssistantController) UpdateAssistant(c *fiber.Ctx) error {
 
 // Obtener todos los asistentes
 func (controller *AssistantController) GetAllAssistants(c *fiber.Ctx) error {
-	assistants, err := controller.service.FindAllAssistants()
+	assistants, err := controller.service.WithTenant(tenantScope(c)).FindAllAssistants()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error retrieving assistants"})
 	}
@@ -162,7 +172,7 @@ func (controller *AssistantController) GetAssistant(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	assistant, err := controller.service.FindAssistantById(int64(id))
+	assistant, err := controller.service.WithTenant(tenantScope(c)).FindAssistantById(int64(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
 	}
@@ -176,7 +186,7 @@ func (controller *AssistantController) DeleteAssistant(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	err = controller.service.DeleteAssistant(int64(id))
+	err = controller.service.WithTenant(tenantScope(c)).DeleteAssistant(int64(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -190,7 +200,12 @@ func (controller *AssistantController) GetAllAssistantsByBussinessId(c *fiber.Ct
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Bussiness ID"})
 	}
 
-	assistants, err := controller.service.GetAllAssistantsByBussinessId(int64(id))
+	tenant := tenantScope(c)
+	if !tenant.Allows(int64(id)) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Bussiness not found"})
+	}
+
+	assistants, err := controller.service.WithTenant(tenant).GetAllAssistantsByBussinessId(int64(id))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error retrieving assistants"})
 	}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type AvailabilityController struct {
+	service *services.AvailabilityService
+}
+
+func NewAvailabilityController(service *services.AvailabilityService) *AvailabilityController {
+	return &AvailabilityController{service: service}
+}
+
+// GetAvailability - Horarios libres de un assistant para una fecha (?date=YYYY-MM-DD, por defecto hoy)
+func (controller *AvailabilityController) GetAvailability(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	date := c.Query("date", time.Now().Format("2006-01-02"))
+
+	availability, err := controller.service.WithTenant(tenantScope(c)).GetAvailability(id, date)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Assistant no encontrado",
+			})
+		case errors.Is(err, services.ErrInvalidAvailabilityDate):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "La fecha debe tener el formato YYYY-MM-DD",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    availability,
+		"message": "Disponibilidad obtenida exitosamente",
+	})
+}
@@ -27,7 +27,7 @@ func (controller *BussinessController) CreateBussiness(c *fiber.Ctx) error {
 		})
 	}
 
-	bussiness, err := controller.service.CreateBussiness(bussinessDto)
+	bussiness, err := controller.service.WithTenant(tenantScope(c)).CreateBussiness(bussinessDto)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  false,
@@ -45,7 +45,7 @@ func (controller *BussinessController) CreateBussiness(c *fiber.Ctx) error {
 
 // Obtener todos los negocios
 func (controller *BussinessController) GetAllBussinesses(c *fiber.Ctx) error {
-	bussinesses, err := controller.service.GetAllBussinesses()
+	bussinesses, err := controller.service.WithTenant(tenantScope(c)).GetAllBussinesses()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  false,
@@ -71,7 +71,7 @@ func (controller *BussinessController) GetBussinessById(c *fiber.Ctx) error {
 		})
 	}
 
-	bussiness, err := controller.service.GetBussinessById(int64(id))
+	bussiness, err := controller.service.WithTenant(tenantScope(c)).GetBussinessById(int64(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  false,
@@ -106,7 +106,7 @@ func (controller *BussinessController) UpdateBussiness(c *fiber.Ctx) error {
 		})
 	}
 
-	updatedBussiness, err := controller.service.UpdateBussiness(int64(id), bussinessDto)
+	updatedBussiness, err := controller.service.WithTenant(tenantScope(c)).UpdateBussiness(int64(id), bussinessDto)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  false,
@@ -132,7 +132,7 @@ func (controller *BussinessController) DeleteBussiness(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := controller.service.DeleteBussiness(int64(id)); err != nil {
+	if err := controller.service.WithTenant(tenantScope(c)).DeleteBussiness(int64(id)); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  false,
 			"message": "Negocio no encontrado",
@@ -156,7 +156,7 @@ func (controller *BussinessController) GetBussinessUser(c *fiber.Ctx) error {
 		})
 	}
 
-	bussinesses, err := controller.service.FindByUserId(int64(userId))
+	bussinesses, err := controller.service.WithTenant(tenantScope(c)).FindByUserId(int64(userId))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  false,
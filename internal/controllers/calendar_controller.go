@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
+	"gorm.io/gorm"
 )
 
 // GetRequestDetails obtiene todos los parámetros enviados en la solicitud.
@@ -76,6 +77,10 @@ func GetCalendarEventsByDate(service *services.GoogleCalendarService, config *oa
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid end_date format.(sended dd-mm-aaaa)"})
 		}
 
+		if _, err := service.AssistantService.WithTenant(tenantScope(c)).FindAssistantById(int64(assistantID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
+		}
+
 		token, err := service.GetOrRefreshToken(assistantID, config, c.Context())
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
@@ -121,9 +126,13 @@ func AddCalendarEvent(service *services.GoogleCalendarService, config *oauth2.Co
 		}
 
 		// Configurar el asistente
-		assistantWihtGoogleConfig, err := service.AssistantService.FindAssistantById(int64(assistantID))
+		assistantWihtGoogleConfig, err := service.AssistantService.WithTenant(tenantScope(c)).FindAssistantById(int64(assistantID))
 		if err != nil {
-			return fmt.Errorf("assistant not found: %v", err)
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
+		}
+		// El contacto se verifica antes de crear el evento en Google
+		if _, err := contactService.WithTenant(tenantScope(c)).GetById(int64(eventRequest.ContactsID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Contact not found"})
 		}
 
 		var createdEvent *calendar.Event
@@ -194,7 +203,10 @@ func AddCalendarEvent(service *services.GoogleCalendarService, config *oauth2.Co
 		eventDto.CodeEvent = codeUnique
 
 		// Guardar en la base de datos usando EventsService
-		err = service.EventsService.Create(eventDto)
+		err = service.EventsService.WithTenant(tenantScope(c)).Create(eventDto)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Contact not found"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -223,6 +235,10 @@ func InsertCalendarEvent(service *services.GoogleCalendarService, config *oauth2
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid event_date format"})
 		}
 
+		if _, err := service.AssistantService.WithTenant(tenantScope(c)).FindAssistantById(int64(assistantID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
+		}
+
 		token, err := service.GetOrRefreshToken(assistantID, config, c.Context())
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
@@ -264,6 +280,10 @@ func DeleteCalendarEvent(service *services.GoogleCalendarService, config *oauth2
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El event_id es requerido"})
 		}
 
+		if _, err := service.AssistantService.WithTenant(tenantScope(c)).FindAssistantById(int64(assistantID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
+		}
+
 		token, err := service.GetOrRefreshToken(assistantID, config, c.Context())
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
@@ -305,6 +325,10 @@ func UpdateCalendarEvent(service *services.GoogleCalendarService, config *oauth2
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
 
+		if _, err := service.AssistantService.WithTenant(tenantScope(c)).FindAssistantById(int64(assistantID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Assistant not found"})
+		}
+
 		token, err := service.GetOrRefreshToken(assistantID, config, c.Context())
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CampaignsController struct {
+	service *services.CampaignsService
+}
+
+func NewCampaignsController(service *services.CampaignsService) *CampaignsController {
+	return &CampaignsController{service: service}
+}
+
+// GetCampaigns - Lista las campañas del número (?number_phone_id=)
+func (controller *CampaignsController) GetCampaigns(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Query("number_phone_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "number_phone_id es obligatorio",
+		})
+	}
+
+	campaigns, err := controller.service.WithTenant(tenantScope(c)).GetByNumberPhone(numberPhoneID)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaigns,
+		"message": "Campañas obtenidas exitosamente",
+	})
+}
+
+// GetCampaign - Obtiene la campaña con el resumen del envío
+func (controller *CampaignsController) GetCampaign(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	campaign, err := controller.service.WithTenant(tenantScope(c)).GetByID(id)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaign,
+		"message": "Campaña obtenida exitosamente",
+	})
+}
+
+// CreateCampaign - Crea una campaña en borrador
+func (controller *CampaignsController) CreateCampaign(c *fiber.Ctx) error {
+	var request dtos.CampaignRequestDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	tenant := tenantScope(c)
+	campaign, err := controller.service.WithTenant(tenant).Create(request, tenant.UserID)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaign,
+		"message": "Campaña creada exitosamente",
+	})
+}
+
+// UpdateCampaign - Edita una campaña que todavía es borrador
+func (controller *CampaignsController) UpdateCampaign(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	var request dtos.CampaignRequestDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	campaign, err := controller.service.WithTenant(tenantScope(c)).Update(id, request)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaign,
+		"message": "Campaña actualizada exitosamente",
+	})
+}
+
+// DeleteCampaign - Borra una campaña que no se está enviando
+func (controller *CampaignsController) DeleteCampaign(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	if err := controller.service.WithTenant(tenantScope(c)).Delete(id); err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Campaña eliminada exitosamente",
+	})
+}
+
+// GetAudience - Cuántos contactos recibirían la campaña con el segmento actual
+func (controller *CampaignsController) GetAudience(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	total, err := controller.service.WithTenant(tenantScope(c)).Audience(id)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    fiber.Map{"contacts": total},
+		"message": "Audiencia calculada exitosamente",
+	})
+}
+
+// ScheduleCampaign - Programa la campaña (sin scheduled_at se envía enseguida)
+func (controller *CampaignsController) ScheduleCampaign(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	var request dtos.ScheduleCampaignDto
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Datos inválidos",
+			})
+		}
+	}
+
+	campaign, err := controller.service.WithTenant(tenantScope(c)).Schedule(id, request.ScheduledAt)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaign,
+		"message": "Campaña programada exitosamente",
+	})
+}
+
+// PauseCampaign - Frena el envío de la campaña
+func (controller *CampaignsController) PauseCampaign(c *fiber.Ctx) error {
+	return controller.changeStatus(c, (*services.CampaignsService).Pause, "Campaña pausada")
+}
+
+// ResumeCampaign - Retoma el envío de una campaña pausada
+func (controller *CampaignsController) ResumeCampaign(c *fiber.Ctx) error {
+	return controller.changeStatus(c, (*services.CampaignsService).Resume, "Campaña reanudada")
+}
+
+// CancelCampaign - Cancela la campaña; a los pendientes ya no se les envía
+func (controller *CampaignsController) CancelCampaign(c *fiber.Ctx) error {
+	return controller.changeStatus(c, (*services.CampaignsService).Cancel, "Campaña cancelada")
+}
+
+// GetRecipients - Resultado del envío a cada destinatario (?status=&page=&limit=)
+func (controller *CampaignsController) GetRecipients(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	recipients, total, err := controller.service.WithTenant(tenantScope(c)).GetRecipients(id, c.Query("status"), page, limit)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Destinatarios obtenidos exitosamente",
+		"data": fiber.Map{
+			"recipients": recipients,
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+		},
+	})
+}
+
+func (controller *CampaignsController) changeStatus(c *fiber.Ctx, action func(*services.CampaignsService, int64) (dtos.CampaignDto, error), message string) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidCampaignID(c)
+	}
+
+	campaign, err := action(controller.service.WithTenant(tenantScope(c)), id)
+	if err != nil {
+		return campaignsErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    campaign,
+		"message": message,
+	})
+}
+
+func invalidCampaignID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"status":  "error",
+		"message": "ID de campaña inválido",
+	})
+}
+
+func campaignsErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Campaña o número de teléfono no encontrado",
+		})
+	case errors.Is(err, services.ErrInvalidCampaign):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrCampaignState):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Tamaño máximo del archivo iCal que se puede importar
+const maxICalFileSize = 2 << 20
+
+type ClosuresController struct {
+	service *services.ClosuresService
+}
+
+func NewClosuresController(service *services.ClosuresService) *ClosuresController {
+	return &ClosuresController{service: service}
+}
+
+// GetClosures - Lista los feriados y cierres del assistant (?from=YYYY-MM-DD para omitir los que ya pasaron)
+func (controller *ClosuresController) GetClosures(c *fiber.Ctx) error {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	closures, err := controller.service.WithTenant(tenantScope(c)).GetByAssistant(assistantID, c.Query("from"))
+	if err != nil {
+		return closuresErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    closures,
+		"message": "Cierres obtenidos exitosamente",
+	})
+}
+
+// GetClosure - Obtiene un cierre del assistant
+func (controller *ClosuresController) GetClosure(c *fiber.Ctx) error {
+	assistantID, closureID, err := closureParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	closure, err := controller.service.WithTenant(tenantScope(c)).GetByID(assistantID, closureID)
+	if err != nil {
+		return closuresErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    closure,
+		"message": "Cierre obtenido exitosamente",
+	})
+}
+
+// CreateClosure - Agrega un feriado o cierre al assistant
+func (controller *ClosuresController) CreateClosure(c *fiber.Ctx) error {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	var closureDto dtos.AssistantClosureDto
+	if err := c.BodyParser(&closureDto); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Cuerpo de la solicitud inválido",
+		})
+	}
+	if err := closureDto.ValidateAssistantClosureDto(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	closure, err := controller.service.WithTenant(tenantScope(c)).Create(assistantID, closureDto)
+	if err != nil {
+		return closuresErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"data":    closure,
+		"message": "Cierre creado exitosamente",
+	})
+}
+
+// UpdateClosure - Modifica un cierre del assistant
+func (controller *ClosuresController) UpdateClosure(c *fiber.Ctx) error {
+	assistantID, closureID, err := closureParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	var closureDto dtos.AssistantClosureDto
+	if err := c.BodyParser(&closureDto); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Cuerpo de la solicitud inválido",
+		})
+	}
+	if err := closureDto.ValidateAssistantClosureDto(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	closure, err := controller.service.WithTenant(tenantScope(c)).Update(assistantID, closureID, closureDto)
+	if err != nil {
+		return closuresErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    closure,
+		"message": "Cierre actualizado exitosamente",
+	})
+}
+
+// DeleteClosure - Elimina un cierre del assistant
+func (controller *ClosuresController) DeleteClosure(c *fiber.Ctx) error {
+	assistantID, closureID, err := closureParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	if err := controller.service.WithTenant(tenantScope(c)).Delete(assistantID, closureID); err != nil {
+		return closuresErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Cierre eliminado exitosamente",
+	})
+}
+
+// ImportICal - Importa los eventos de un archivo .ics (campo "file") como cierres del assistant
+func (controller *ClosuresController) ImportICal(c *fiber.Ctx) error {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El archivo .ics es obligatorio",
+		})
+	}
+	if file.Size > maxICalFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El archivo no debe superar los 2MB",
+		})
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "No se pudo abrir el archivo",
+		})
+	}
+	defer content.Close()
+
+	result, err := controller.service.WithTenant(tenantScope(c)).ImportICal(assistantID, content)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return closuresErrorResponse(c, err)
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    result,
+		"message": "Calendario importado exitosamente",
+	})
+}
+
+func closureParams(c *fiber.Ctx) (int64, int64, error) {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("ID de assistant inválido")
+	}
+	closureID, err := strconv.ParseInt(c.Params("closure_id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("ID de cierre inválido")
+	}
+	return assistantID, closureID, nil
+}
+
+func closuresErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Assistant o cierre no encontrado",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": err.Error(),
+	})
+}
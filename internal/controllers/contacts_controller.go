@@ -1,12 +1,21 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// Tamaño máximo del archivo de contactos a importar (el límite del body de Fiber es 4MB)
+const maxContactsFileSize = 4 << 20
+
 type ContactsController struct {
 	service *services.ContactsService
 }
@@ -27,7 +36,23 @@ func (controller *ContactsController) GetMessagesByNumberPhone(c *fiber.Ctx) err
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
-	contacts, total, err := controller.service.GetContactsByNumberPhone(numberPhoneID, page, limit)
+	service := controller.service.WithTenant(tenantScope(c))
+
+	exists, err := service.DoesNumberPhoneExist(numberPhoneID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Error al verificar el número de teléfono",
+		})
+	}
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+
+	contacts, total, err := service.GetContactsByNumberPhone(numberPhoneID, contactFilter(c), page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -53,6 +78,213 @@ func (controller *ContactsController) GetMessagesByNumberPhone(c *fiber.Ctx) err
 	})
 }
 
+// contactFilter arma el filtro del listado: ?search= (nombre, email o número), ?tag=, ?opted_out=true|false, ?blocked=true|false
+func contactFilter(c *fiber.Ctx) dtos.ContactFilterDto {
+	filter := dtos.ContactFilterDto{
+		Search: c.Query("search"),
+		Tag:    strings.ToLower(strings.TrimSpace(c.Query("tag"))),
+	}
+	if c.Query("opted_out") != "" {
+		optedOut := c.QueryBool("opted_out")
+		filter.OptedOut = &optedOut
+	}
+	if c.Query("blocked") != "" {
+		blocked := c.QueryBool("blocked")
+		filter.Blocked = &blocked
+	}
+	return filter
+}
+
+// ImportContacts - Importa contactos al número desde un archivo CSV o vCard (campo "file").
+// Query: ?format=csv|vcard (si no viene se usa la extensión), ?default_country_code=54, ?update_existing=true
+func (controller *ContactsController) ImportContacts(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("number_phone_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El archivo .csv o .vcf es obligatorio",
+		})
+	}
+	if file.Size > maxContactsFileSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El archivo no debe superar los 4MB",
+		})
+	}
+
+	options := dtos.ContactImportOptionsDto{
+		Format:             strings.ToLower(c.Query("format")),
+		DefaultCountryCode: strings.TrimPrefix(strings.TrimSpace(c.Query("default_country_code")), "+"),
+		UpdateExisting:     c.QueryBool("update_existing"),
+	}
+	if options.Format == "" {
+		options.Format = dtos.ContactImportCSV
+		if extension := strings.ToLower(filepath.Ext(file.Filename)); extension == ".vcf" || extension == ".vcard" {
+			options.Format = dtos.ContactImportVCard
+		}
+	}
+	if options.Format != dtos.ContactImportCSV && options.Format != dtos.ContactImportVCard {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "format debe ser csv o vcard",
+		})
+	}
+	if _, err := strconv.Atoi(options.DefaultCountryCode); options.DefaultCountryCode != "" && (err != nil || len(options.DefaultCountryCode) > 3) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "default_country_code inválido",
+		})
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "No se pudo abrir el archivo",
+		})
+	}
+	defer content.Close()
+
+	result, err := controller.service.WithTenant(tenantScope(c)).ImportContacts(numberPhoneID, content, options)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+	if errors.Is(err, services.ErrInvalidContactImport) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Contactos importados",
+		"data":    result,
+	})
+}
+
+// ExportContacts - Descarga en CSV los contactos del número (acepta los mismos filtros que el listado)
+func (controller *ContactsController) ExportContacts(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("number_phone_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	content, err := controller.service.WithTenant(tenantScope(c)).ExportContacts(numberPhoneID, contactFilter(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="contactos-%d.csv"`, numberPhoneID))
+	return c.Status(fiber.StatusOK).Send(content)
+}
+
+// GetContact obtiene el perfil del contacto
+func (controller *ContactsController) GetContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	contact, err := controller.service.WithTenant(tenantScope(c)).GetById(contactID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Contacto obtenido exitosamente",
+		"data":    contact,
+	})
+}
+
+// UpdateContact edita el perfil del contacto (nombre, email, notas, etiquetas, campos personalizados y baja)
+func (controller *ContactsController) UpdateContact(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	var request dtos.ContactProfileDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	contact, err := controller.service.WithTenant(tenantScope(c)).UpdateProfile(contactID, request)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Contacto actualizado exitosamente",
+		"data":    contact,
+	})
+}
+
 func (controller *ContactsController) UpdateIsBlocked(c *fiber.Ctx) error {
 	// Obtener el ID del número de teléfono desde los parámetros
 	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -75,7 +307,13 @@ func (controller *ContactsController) UpdateIsBlocked(c *fiber.Ctx) error {
 	block := c.QueryBool("block", false)
 
 	// Llamar al servicio para actualizar el campo IsBlocked
-	err = controller.service.UpdateIsBlocked(contactID, numberPhoneID, block)
+	err = controller.service.WithTenant(tenantScope(c)).UpdateIsBlocked(contactID, numberPhoneID, block)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"status":  "error",
@@ -89,3 +327,87 @@ func (controller *ContactsController) UpdateIsBlocked(c *fiber.Ctx) error {
 		"data":    nil,
 	})
 }
+
+// UpdateTags reemplaza las etiquetas del contacto (se usan para segmentar campañas)
+func (controller *ContactsController) UpdateTags(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	var request dtos.ContactTagsDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	contact, err := controller.service.WithTenant(tenantScope(c)).SetTags(contactID, request)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Etiquetas actualizadas",
+		"data":    contact,
+	})
+}
+
+// UpdateOptOut da de baja al contacto de las campañas o lo vuelve a suscribir
+func (controller *ContactsController) UpdateOptOut(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	var request dtos.ContactOptOutDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	contact, err := controller.service.WithTenant(tenantScope(c)).SetOptOut(contactID, request.OptedOut)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Suscripción del contacto actualizada",
+		"data":    contact,
+	})
+}
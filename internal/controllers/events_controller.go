@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // EventsController maneja las rutas relacionadas con los eventos
@@ -26,7 +28,10 @@ func (ec *EventsController) CreateEvent(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if err := ec.eventsService.Create(eventDTO); err != nil {
+	if err := ec.eventsService.WithTenant(tenantScope(c)).Create(eventDTO); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -40,7 +45,7 @@ func (ec *EventsController) GetEventByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid event ID"})
 	}
 
-	event, err := ec.eventsService.GetByID(id)
+	event, err := ec.eventsService.WithTenant(tenantScope(c)).GetByID(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
 	}
@@ -79,7 +84,7 @@ func (ec *EventsController) GetAllEvents(c *fiber.Ctx) error {
 
 	pagination.SetDefaults()
 
-	events, paginacion, err := ec.eventsService.GetAll(request, pagination)
+	events, paginacion, err := ec.eventsService.WithTenant(tenantScope(c)).GetAll(request, pagination)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  false,
@@ -107,7 +112,10 @@ func (ec *EventsController) UpdateEvent(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if err := ec.eventsService.Update(eventDTO); err != nil {
+	if err := ec.eventsService.WithTenant(tenantScope(c)).Update(eventDTO); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -121,7 +129,10 @@ func (ec *EventsController) DeleteEvent(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid event ID"})
 	}
 
-	if err := ec.eventsService.Delete(id); err != nil {
+	if err := ec.eventsService.WithTenant(tenantScope(c)).Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -132,7 +143,10 @@ func (ec *EventsController) DeleteEvent(c *fiber.Ctx) error {
 func (ec *EventsController) CancelEvent(c *fiber.Ctx) error {
 	codeEvent := c.Params("codeEvent")
 
-	if err := ec.eventsService.Cancel(codeEvent); err != nil {
+	if err := ec.eventsService.WithTenant(tenantScope(c)).Cancel(codeEvent); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -149,7 +163,7 @@ func (ec *EventsController) GetEventsByContactAndDate(c *fiber.Ctx) error {
 	date := c.Params("date")
 	currentTime := c.Params("currentTime")
 
-	events, err := ec.eventsService.GetEventByContactAndDate(contactID, date, currentTime)
+	events, err := ec.eventsService.WithTenant(tenantScope(c)).GetEventByContactAndDate(contactID, date, currentTime)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type FileController struct {
@@ -31,7 +33,10 @@ func (controller *FileController) CreateFile(c *fiber.Ctx) error {
 	}
 
 	// Llamar a CreateFile en el servicio, pasando fileHeader, assistantsID y purpose
-	newFile, err := controller.service.CreateFile(fileHeader, assistantsID, purpose, "", "")
+	newFile, err := controller.service.WithTenant(tenantScope(c)).CreateFile(fileHeader, assistantsID, purpose, "", "")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Assistant not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Error creating file"})
 	}
@@ -40,7 +45,7 @@ func (controller *FileController) CreateFile(c *fiber.Ctx) error {
 }
 
 func (controller *FileController) GetAllFiles(c *fiber.Ctx) error {
-	files, err := controller.service.GetAllFiles()
+	files, err := controller.service.WithTenant(tenantScope(c)).GetAllFiles()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Error fetching files"})
 	}
@@ -50,7 +55,7 @@ func (controller *FileController) GetAllFiles(c *fiber.Ctx) error {
 
 func (controller *FileController) GetFileById(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
-	file, err := controller.service.GetFileById(id)
+	file, err := controller.service.WithTenant(tenantScope(c)).GetFileById(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "File not found"})
 	}
@@ -63,7 +68,7 @@ func (controller *FileController) UpdateFile(c *fiber.Ctx) error {
 	assistantsID, _ := strconv.ParseInt(c.FormValue("assistants_id"), 10, 64)
 	purpose := c.FormValue("purpose")
 
-	updatedFile, err := controller.service.UpdateFile(id, assistantsID, purpose)
+	updatedFile, err := controller.service.WithTenant(tenantScope(c)).UpdateFile(id, assistantsID, purpose)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "File not found"})
 	}
@@ -73,7 +78,7 @@ func (controller *FileController) UpdateFile(c *fiber.Ctx) error {
 
 func (controller *FileController) DeleteFile(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
-	err := controller.service.DeleteFile(id)
+	err := controller.service.WithTenant(tenantScope(c)).DeleteFile(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "File not found"})
 	}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type HandoffController struct {
+	service         *services.HandoffService
+	whatsappService *services.WhatsappService
+}
+
+func NewHandoffController(service *services.HandoffService, whatsappService *services.WhatsappService) *HandoffController {
+	return &HandoffController{service: service, whatsappService: whatsappService}
+}
+
+// UpdateHandoff cambia quién atiende la conversación del contacto (bot, human o paused)
+func (controller *HandoffController) UpdateHandoff(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	var request dtos.HandoffRequestDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	contact, err := controller.service.WithTenant(tenantScope(c)).SetMode(contactID, request)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Contacto no encontrado",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Modo de atención actualizado",
+		"data":    contact,
+	})
+}
+
+// SendMessage envía al contacto un mensaje escrito por el operador y deja la conversación a su cargo
+func (controller *HandoffController) SendMessage(c *fiber.Ctx) error {
+	contactID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de contacto inválido",
+		})
+	}
+
+	var request dtos.OperatorMessageDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	tenant := tenantScope(c)
+	if _, err := controller.service.WithTenant(tenant).GetContact(contactID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Contacto no encontrado",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	message, err := controller.whatsappService.SendOperatorMessage(contactID, tenant.UserID, request.Text)
+	if errors.Is(err, dtos.ErrOutsideServiceWindow) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"status":  "error",
+			"message": "No se pudo enviar el mensaje: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  true,
+		"message": "Mensaje enviado",
+		"data":    message,
+	})
+}
+
+// GetInbox lista las conversaciones del número que atiende un operador o están pausadas
+func (controller *HandoffController) GetInbox(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("number_phone_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	inbox, err := controller.service.WithTenant(tenantScope(c)).GetInbox(numberPhoneID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  true,
+		"message": "Bandeja obtenida exitosamente",
+		"data":    inbox,
+	})
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type InboundJobsController struct {
+	service *services.InboundJobsService
+}
+
+func NewInboundJobsController(service *services.InboundJobsService) *InboundJobsController {
+	return &InboundJobsController{service: service}
+}
+
+// GetJobs - Lista los jobs del webhook filtrando por estado (?status=dead) con paginación
+func (controller *InboundJobsController) GetJobs(c *fiber.Ctx) error {
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	jobs, total, err := controller.service.GetJobs(status, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	// Calcular total de páginas
+	totalPages := (total + limit - 1) / limit
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    jobs,
+		"message": "Jobs obtenidos exitosamente",
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// ReplayJob - Vuelve a encolar un job que agotó sus reintentos
+func (controller *InboundJobsController) ReplayJob(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de job inválido",
+		})
+	}
+
+	job, err := controller.service.Replay(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Job no encontrado",
+			})
+		case errors.Is(err, services.ErrInboundJobNotReplayable):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"status":  "error",
+				"message": "Solo se pueden reencolar jobs en estado dead",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    job,
+		"message": "Job reencolado exitosamente",
+	})
+}
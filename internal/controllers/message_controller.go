@@ -35,8 +35,10 @@ func (controller *MessagesController) GetMessagesByNumberPhone(c *fiber.Ctx) err
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
-	// Verificar si el numberPhoneID existe en la base de datos
-	exists, err := controller.service.DoesNumberPhoneExist(numberPhoneID)
+	service := controller.service.WithTenant(tenantScope(c))
+
+	// Verificar si el numberPhoneID existe en la base de datos (y es del usuario)
+	exists, err := service.DoesNumberPhoneExist(numberPhoneID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -50,7 +52,7 @@ func (controller *MessagesController) GetMessagesByNumberPhone(c *fiber.Ctx) err
 		})
 	}
 
-	messages, total, err := controller.service.GetMessagesByNumberPhoneAndContact(numberPhoneID, int64(contactID), page, limit)
+	messages, total, err := service.GetMessagesByNumberPhoneAndContact(numberPhoneID, int64(contactID), page, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -75,3 +77,83 @@ func (controller *MessagesController) GetMessagesByNumberPhone(c *fiber.Ctx) err
 		},
 	})
 }
+
+// GetFailedMessagesByNumberPhone - Devuelve los mensajes que WhatsApp informó como fallidos para un número de teléfono
+func (controller *MessagesController) GetFailedMessagesByNumberPhone(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("number_phone_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	service := controller.service.WithTenant(tenantScope(c))
+
+	exists, err := service.DoesNumberPhoneExist(numberPhoneID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Error al verificar el número de teléfono",
+		})
+	}
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+
+	messages, total, err := service.GetFailedMessagesByNumberPhone(numberPhoneID, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	// Calcular total de páginas
+	totalPages := (total + limit - 1) / limit
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    messages,
+		"message": "Mensajes fallidos obtenidos exitosamente",
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// GetMessageStatuses - Devuelve el historial de estados informados por WhatsApp para un mensaje
+func (controller *MessagesController) GetMessageStatuses(c *fiber.Ctx) error {
+	messageID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de mensaje inválido",
+		})
+	}
+
+	history, err := controller.service.WithTenant(tenantScope(c)).GetStatusHistory(messageID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Mensaje no encontrado",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    history,
+		"message": "Estados del mensaje obtenidos exitosamente",
+	})
+}
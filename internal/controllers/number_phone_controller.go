@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"errors"
+
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type NumberPhonesController struct {
@@ -20,7 +23,7 @@ func (controller *NumberPhonesController) GetAll(c *fiber.Ctx) error {
 	id := c.QueryInt("assistant_id")
 
 	if id > 0 {
-		item, err := controller.service.GetAllByAssistantID(int64(id))
+		item, err := controller.service.WithTenant(tenantScope(c)).GetAllByAssistantID(int64(id))
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"status":  "error",
@@ -34,7 +37,7 @@ func (controller *NumberPhonesController) GetAll(c *fiber.Ctx) error {
 		})
 	}
 
-	items, err := controller.service.GetAll()
+	items, err := controller.service.WithTenant(tenantScope(c)).GetAll()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -57,7 +60,7 @@ func (controller *NumberPhonesController) GetAllByAssistantID(c *fiber.Ctx) erro
 		})
 	}
 
-	item, err := controller.service.GetAllByAssistantID(int64(id))
+	item, err := controller.service.WithTenant(tenantScope(c)).GetAllByAssistantID(int64(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
@@ -73,7 +76,7 @@ func (controller *NumberPhonesController) GetAllByAssistantID(c *fiber.Ctx) erro
 
 func (controller *NumberPhonesController) GetById(c *fiber.Ctx) error {
 	id := c.Params("id")
-	item, err := controller.service.GetById(id)
+	item, err := controller.service.WithTenant(tenantScope(c)).GetById(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"status":  "error",
@@ -122,7 +125,19 @@ func (controller *NumberPhonesController) Create(c *fiber.Ctx) error {
 	dto.UUID = uniqueUUID
 
 	// Llamada al servicio para crear el número de teléfono
-	err := controller.service.Create(dto)
+	err := controller.service.WithTenant(tenantScope(c)).Create(dto)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Assistant not found",
+		})
+	}
+	if errors.Is(err, services.ErrInvalidNumberPhone) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -144,7 +159,19 @@ func (controller *NumberPhonesController) Update(c *fiber.Ctx) error {
 			"message": err.Error(),
 		})
 	}
-	err := controller.service.Update(id, dto)
+	err := controller.service.WithTenant(tenantScope(c)).Update(id, dto)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Item not found",
+		})
+	}
+	if errors.Is(err, services.ErrInvalidNumberPhone) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
@@ -159,7 +186,13 @@ func (controller *NumberPhonesController) Update(c *fiber.Ctx) error {
 
 func (controller *NumberPhonesController) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
-	err := controller.service.Delete(id)
+	err := controller.service.WithTenant(tenantScope(c)).Delete(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Item not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"status":  "error",
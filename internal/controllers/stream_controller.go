@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// Cada cuánto se manda un comentario para mantener viva la conexión y detectar si el cliente se fue
+const streamHeartbeat = 25 * time.Second
+
+type StreamController struct {
+	stream *services.ConversationStream
+}
+
+func NewStreamController(stream *services.ConversationStream) *StreamController {
+	return &StreamController{stream: stream}
+}
+
+// Stream abre un stream de Server-Sent Events con los mensajes nuevos, estados y cambios de handoff de los
+// números del usuario (o solo de ?number_phone_id=). Al reconectar reenvía los mensajes posteriores al
+// header Last-Event-ID o a ?last_message_id=.
+func (controller *StreamController) Stream(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Query("number_phone_id", "0"), 10, 64)
+	if err != nil || numberPhoneID < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	lastSeen := c.Get("Last-Event-ID")
+	if lastSeen == "" {
+		lastSeen = c.Query("last_message_id", "0")
+	}
+	lastMessageID, err := strconv.ParseInt(lastSeen, 10, 64)
+	if err != nil || lastMessageID < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "last_message_id inválido",
+		})
+	}
+
+	tenant := tenantScope(c)
+	subscription, err := controller.stream.Subscribe(tenant, numberPhoneID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "El ID de número de teléfono no es válido",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	// La suscripción se abre antes del backfill para no perder los mensajes que lleguen mientras tanto
+	backlog, err := controller.stream.Backfill(tenant, numberPhoneID, lastMessageID)
+	if err != nil {
+		controller.stream.Unsubscribe(subscription)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Que nginx no acumule la respuesta
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer controller.stream.Unsubscribe(subscription)
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		sent := lastMessageID
+		for _, event := range backlog {
+			writeStreamEvent(w, event)
+			if event.MessageID > sent {
+				sent = event.MessageID
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					// La conexión no consumía a tiempo; el cliente reconecta con Last-Event-ID
+					return
+				}
+				// Los mensajes que llegaron durante el backfill ya se enviaron
+				if event.MessageID > 0 && event.MessageID <= sent {
+					continue
+				}
+				writeStreamEvent(w, event)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+	return nil
+}
+
+// writeStreamEvent escribe el evento con el formato de Server-Sent Events. Solo los mensajes llevan id, así
+// el Last-Event-ID del navegador siempre es el último mensaje recibido.
+func writeStreamEvent(w *bufio.Writer, event dtos.StreamEventDto) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if event.MessageID > 0 {
+		fmt.Fprintf(w, "id: %d\n", event.MessageID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TemplatesController struct {
+	service *services.TemplatesService
+}
+
+func NewTemplatesController(service *services.TemplatesService) *TemplatesController {
+	return &TemplatesController{service: service}
+}
+
+// GetTemplates - Lista los templates del número (?refresh=true para sincronizarlos antes con Meta)
+func (controller *TemplatesController) GetTemplates(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	templates, err := controller.service.WithTenant(tenantScope(c)).GetByNumberPhone(numberPhoneID, c.QueryBool("refresh"))
+	if err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    templates,
+		"message": "Templates obtenidos exitosamente",
+	})
+}
+
+// SyncTemplates - Sincroniza los templates del número con su WhatsApp Business Account
+func (controller *TemplatesController) SyncTemplates(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	service := controller.service.WithTenant(tenantScope(c))
+	if err := service.Sync(numberPhoneID); err != nil {
+		return templatesErrorResponse(c, err)
+	}
+	templates, err := service.GetByNumberPhone(numberPhoneID, false)
+	if err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    templates,
+		"message": "Templates sincronizados exitosamente",
+	})
+}
+
+// CreateTemplate - Envía un template nuevo a aprobación de Meta
+func (controller *TemplatesController) CreateTemplate(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	var request dtos.CreateMessageTemplateDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	template, err := controller.service.WithTenant(tenantScope(c)).Create(numberPhoneID, request)
+	if err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"data":    template,
+		"message": "Template enviado a aprobación",
+	})
+}
+
+// DeleteTemplate - Borra el template (todos sus idiomas) del WhatsApp Business Account
+func (controller *TemplatesController) DeleteTemplate(c *fiber.Ctx) error {
+	numberPhoneID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono inválido",
+		})
+	}
+
+	if err := controller.service.WithTenant(tenantScope(c)).Delete(numberPhoneID, c.Params("name")); err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Template eliminado exitosamente",
+	})
+}
+
+// GetAssistantTemplates - Templates que usa el assistant al crear, modificar, cancelar y recordar eventos
+func (controller *TemplatesController) GetAssistantTemplates(c *fiber.Ctx) error {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	templates, err := controller.service.WithTenant(tenantScope(c)).GetAssistantTemplates(assistantID)
+	if err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    templates,
+		"message": "Templates del assistant obtenidos exitosamente",
+	})
+}
+
+// UpdateAssistantTemplates - Reemplaza los templates elegidos por el assistant (los que no se envían vuelven al por defecto)
+func (controller *TemplatesController) UpdateAssistantTemplates(c *fiber.Ctx) error {
+	assistantID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "ID de assistant inválido",
+		})
+	}
+
+	var request []dtos.AssistantTemplateDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	templates, err := controller.service.WithTenant(tenantScope(c)).SetAssistantTemplates(assistantID, request)
+	if err != nil {
+		return templatesErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    templates,
+		"message": "Templates del assistant actualizados exitosamente",
+	})
+}
+
+func templatesErrorResponse(c *fiber.Ctx, err error) error {
+	var metaErr *services.MetaAPIError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono o assistant no encontrado",
+		})
+	case errors.Is(err, services.ErrMissingBusinessAccount):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidTemplate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	case errors.As(err, &metaErr):
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": err.Error(),
+	})
+}
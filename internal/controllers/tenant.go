@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/gofiber/fiber/v2"
+)
+
+// tenantScope devuelve los bussiness a los que puede acceder el usuario del request (los deja ValidarPermiso).
+// Si la ruta no pasó por el middleware no se le da acceso a ningún bussiness.
+func tenantScope(c *fiber.Ctx) dtos.TenantScope {
+	if tenant, ok := c.Locals(dtos.TenantLocalsKey).(dtos.TenantScope); ok {
+		return tenant
+	}
+	return dtos.NewTenantScope(0, nil)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type UsageController struct {
+	service *services.UsageService
+}
+
+func NewUsageController(service *services.UsageService) *UsageController {
+	return &UsageController{service: service}
+}
+
+// GetReport - Consumo de tokens y costo del período, agrupado por día, bussiness, assistant, número, contacto o modelo
+func (controller *UsageController) GetReport(c *fiber.Ctx) error {
+	filter := dtos.UsageFilterDto{
+		BussinessID:   int64(c.QueryInt("bussiness_id")),
+		AssistantID:   int64(c.QueryInt("assistant_id")),
+		NumberPhoneID: int64(c.QueryInt("number_phone_id")),
+		ContactID:     int64(c.QueryInt("contact_id")),
+		From:          c.Query("from"),
+		To:            c.Query("to"),
+	}
+	if groupBy := strings.TrimSpace(c.Query("group_by")); groupBy != "" {
+		filter.GroupBy = strings.Split(groupBy, ",")
+	}
+
+	report, err := controller.service.WithTenant(tenantScope(c)).Report(filter)
+	if err != nil {
+		return usageErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    report,
+		"message": "Consumo obtenido exitosamente",
+	})
+}
+
+// GetQuota - Cuota mensual del bussiness y lo que lleva consumido en el mes
+func (controller *UsageController) GetQuota(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidBussinessID(c)
+	}
+
+	quota, err := controller.service.WithTenant(tenantScope(c)).GetQuota(id)
+	if err != nil {
+		return usageErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    quota,
+		"message": "Cuota obtenida exitosamente",
+	})
+}
+
+// UpdateQuota - Cambia la cuota mensual del bussiness (0 = sin límite) y el mensaje que responde el bot al superarla
+func (controller *UsageController) UpdateQuota(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return invalidBussinessID(c)
+	}
+
+	var request dtos.BussinessQuotaRequestDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+
+	quota, err := controller.service.WithTenant(tenantScope(c)).UpdateQuota(id, request)
+	if err != nil {
+		return usageErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"data":    quota,
+		"message": "Cuota actualizada exitosamente",
+	})
+}
+
+func invalidBussinessID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"status":  "error",
+		"message": "ID de bussiness inválido",
+	})
+}
+
+func usageErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Bussiness no encontrado",
+		})
+	case errors.Is(err, services.ErrInvalidUsageFilter):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": err.Error(),
+	})
+}
@@ -1,38 +1,25 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type WhatsappController struct {
-	service *services.WhatsappService
+	service     *services.WhatsappService
+	jobsService *services.InboundJobsService
 }
 
-func NewWhatsappController(service *services.WhatsappService) *WhatsappController {
-	return &WhatsappController{service: service}
-}
-
-// Demo para probar funciones
-func (controller *WhatsappController) DemoFunctionWhatsappController(c *fiber.Ctx) error {
-
-	templateName := c.Query("template_name")
-	numberPhoneIDWhatsapp := c.Query("number_phone_id_whatsapp")
-	tokenWhatsapp := c.Query("token_whatsapp")
-
-	// Lógica principal delegada al servicio de WhatsApp
-	err := controller.service.DemoFunctionWhatsappService(templateName, numberPhoneIDWhatsapp, tokenWhatsapp)
-	if err != nil {
-		fmt.Println(err.Error())
-	}
-
-	// Respuesta de éxito
-	return c.Status(fiber.StatusOK).SendString("Message processed successfully")
-
+func NewWhatsappController(service *services.WhatsappService, jobsService *services.InboundJobsService) *WhatsappController {
+	return &WhatsappController{service: service, jobsService: jobsService}
 }
 
 // Se encarga de toda la logica de recepcion y envio de mensajes
@@ -44,30 +31,72 @@ func (controller *WhatsappController) PostWhatsapp(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid request body")
 	}
 
-	go func() {
-		// Lógica principal delegada al servicio de WhatsApp
-		err := controller.service.HandleIncomingMessageWithAssistant(responseComplet)
-		if err != nil {
-			fmt.Println("ERROR PROCESANDING THE MESSAGE")
-			fmt.Println(err.Error())
-		}
-	}()
+	// Se persiste la notificación y los workers la procesan con reintentos.
+	// Si no se pudo guardar se responde con error para que Meta la vuelva a enviar.
+	if err := controller.jobsService.Enqueue(c.Body()); err != nil {
+		fmt.Println(err.Error())
+		return c.Status(fiber.StatusInternalServerError).SendString("Error enqueuing message")
+	}
 
 	// Respuesta de éxito
 	return c.Status(fiber.StatusOK).SendString("Message processed successfully")
 
 }
 
-// Envia un mensaje de whatsapp a un numero específico.
+// Envia un mensaje de whatsapp (texto, template, media o interactivo) desde un número del usuario a un contacto
 func (controller *WhatsappController) PostSendMessageWhatsapp(c *fiber.Ctx) error {
+	var request dtos.OutboundMessageDto
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Datos inválidos",
+		})
+	}
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
 
-	go func() {
-		// Lógica principal delegada al servicio de WhatsApp
-
-	}()
+	message, err := controller.service.SendOutboundMessage(tenantScope(c), request)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Número de teléfono o contacto no encontrado",
+		})
+	case errors.Is(err, dtos.ErrOutsideServiceWindow):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	case message.ID > 0 && (errors.Is(err, clients.ErrWhatsappReengagement) || errors.Is(err, clients.ErrWhatsappInvalidRecipient)):
+		// El contacto no puede recibir el mensaje (pasaron las 24 horas o el número no tiene WhatsApp); quedó guardado como failed
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"status":  "error",
+			"message": "No se pudo enviar el mensaje: " + err.Error(),
+			"data":    message,
+		})
+	case err != nil && message.ID > 0:
+		// WhatsApp rechazó el envío; el mensaje quedó guardado como failed
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"status":  "error",
+			"message": "No se pudo enviar el mensaje: " + err.Error(),
+			"data":    message,
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"message": err.Error(),
+		})
+	}
 
-	// Respuesta de éxito
-	return c.Status(fiber.StatusOK).SendString("response")
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  true,
+		"message": "Mensaje enviado",
+		"data":    message,
+	})
 }
 
 // Se usa para vincular el webhook de la API Whatsapp META
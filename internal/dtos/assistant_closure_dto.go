@@ -0,0 +1,70 @@
+package dtos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Origen de un cierre
+const (
+	ClosureSourceManual = "manual"
+	ClosureSourceICal   = "ical"
+)
+
+// AssistantClosureDto es un día (o rango de días) en que el assistant no atiende: feriados, vacaciones, cierres puntuales.
+// Sin start_time/end_time el cierre es por el día completo; con ellos solo se cierra ese horario.
+type AssistantClosureDto struct {
+	ID              int64     `json:"id"`
+	AssistantsID    int64     `json:"assistants_id"`
+	StartDate       string    `json:"start_date"`             // YYYY-MM-DD
+	EndDate         string    `json:"end_date,omitempty"`     // YYYY-MM-DD, si no se envía es igual a start_date
+	StartTime       string    `json:"start_time,omitempty"`   // HH:MM, solo para cierres parciales
+	EndTime         string    `json:"end_time,omitempty"`     // HH:MM, solo para cierres parciales
+	RecurringYearly bool      `json:"recurring_yearly"`       // Se repite todos los años (feriados de fecha fija)
+	Reason          string    `json:"reason,omitempty"`       // Ej: "Día de la Independencia"
+	Source          string    `json:"source,omitempty"`       // manual o ical
+	ExternalUID     string    `json:"external_uid,omitempty"` // UID del evento en el archivo iCal importado
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// ClosuresImportResultDto es el resultado de importar un archivo iCal
+type ClosuresImportResultDto struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped []string `json:"skipped,omitempty"` // Eventos que no se pudieron interpretar
+}
+
+func (dto *AssistantClosureDto) ValidateAssistantClosureDto() error {
+	start, err := time.Parse("2006-01-02", dto.StartDate)
+	if err != nil {
+		return errors.New("start_date es obligatorio y debe tener el formato YYYY-MM-DD")
+	}
+
+	if strings.TrimSpace(dto.EndDate) == "" {
+		dto.EndDate = dto.StartDate
+	}
+	end, err := time.Parse("2006-01-02", dto.EndDate)
+	if err != nil {
+		return errors.New("end_date debe tener el formato YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return errors.New("end_date no puede ser anterior a start_date")
+	}
+
+	if (dto.StartTime == "") != (dto.EndTime == "") {
+		return errors.New("start_time y end_time se deben enviar juntos")
+	}
+	if dto.StartTime != "" {
+		if _, err := ParseTimeRanges(dto.StartTime + "-" + dto.EndTime); err != nil {
+			return errors.New("start_time y end_time deben tener el formato HH:MM y end_time debe ser posterior")
+		}
+	}
+
+	if len(dto.Reason) > 255 {
+		return errors.New("reason no debe exceder los 255 caracteres")
+	}
+
+	return nil
+}
@@ -2,6 +2,10 @@ package dtos
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +27,53 @@ type AssistantDto struct {
 	Events             []EventsDto      `json:"events,omitempty"`               //
 	OpeningDays        uint8            `json:"opening_days"`                   // Días de apertura representados en un entero de 7 bits
 	WorkingHours       string           `json:"working_hours"`                  // Horarios de trabajo en formato "HH:MM-HH:MM,HH:MM-HH:MM"
+	BreakHours         string           `json:"break_hours,omitempty"`          // Pausas dentro del horario de trabajo, mismo formato que working_hours
+	SlotCapacity       int16            `json:"slot_capacity"`                  // Cantidad de eventos que se pueden agendar en un mismo horario (por defecto 1)
+	ReminderOffsets    string           `json:"reminder_offsets,omitempty"`     // Horas antes del evento en que se envía un recordatorio, ej: "24,2". Vacío desactiva los recordatorios
+	ReminderTemplate   string           `json:"reminder_template,omitempty"`    // Template de Meta para el recordatorio, por defecto recordatorio_evento
+	LLMProvider        string           `json:"llm_provider"`                   // Proveedor del modelo: openai_assistants (por defecto), openai_chat u ollama
+	LLMBaseURL         string           `json:"llm_base_url,omitempty"`         // URL del endpoint compatible con OpenAI (solo ollama y de OLLAMA_ALLOWED_URLS)
+}
+
+// Proveedores de modelos que puede usar un assistant
+const (
+	LLMProviderOpenAIAssistants = "openai_assistants"
+	LLMProviderOpenAIChat       = "openai_chat"
+	LLMProviderOllama           = "ollama"
+)
+
+// DefaultLLMUnavailableMessage es lo que responde el bot cuando el modelo no está disponible (OpenAI caído o el
+// circuit breaker abierto). Se puede cambiar con LLM_UNAVAILABLE_MESSAGE.
+const DefaultLLMUnavailableMessage = "En este momento no podemos responder tu consulta. Volvé a escribirnos en unos minutos."
+
+// Máximo de horas de anticipación de un recordatorio (una semana)
+const MaxReminderOffsetHours = 168
+
+// ParseReminderOffsets interpreta las horas de anticipación de los recordatorios ("24,2") ordenadas de mayor a menor
+func ParseReminderOffsets(value string) ([]int, error) {
+	var offsets []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		hours, err := strconv.Atoi(part)
+		if err != nil || hours <= 0 || hours > MaxReminderOffsetHours {
+			return nil, fmt.Errorf("%q debe ser una cantidad de horas entre 1 y %d", part, MaxReminderOffsetHours)
+		}
+		if !seen[hours] {
+			seen[hours] = true
+			offsets = append(offsets, hours)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	return offsets, nil
+}
+
+// UsesOpenAIAssistants indica si el assistant corre sobre la Assistants API (threads, runs y vector stores de OpenAI)
+func (dto *AssistantDto) UsesOpenAIAssistants() bool {
+	return dto.LLMProvider == "" || dto.LLMProvider == LLMProviderOpenAIAssistants
 }
 
 func (dto *AssistantDto) ValidateAssistantDto(isCreate bool) error {
@@ -55,10 +106,45 @@ func (dto *AssistantDto) ValidateAssistantDto(isCreate bool) error {
 		return errors.New("la descripción debe tener por lo menos 10 caracteres")
 	}
 
-	if strings.TrimSpace(dto.OpenaiAssistantsID) == "" {
+	switch dto.LLMProvider {
+	case "", LLMProviderOpenAIAssistants, LLMProviderOpenAIChat, LLMProviderOllama:
+	default:
+		return errors.New("llm_provider debe ser openai_assistants, openai_chat u ollama")
+	}
+
+	if dto.UsesOpenAIAssistants() && strings.TrimSpace(dto.OpenaiAssistantsID) == "" {
 		return errors.New("openai_assistants_id es obligatorio")
 	}
 
+	// Solo Ollama usa un endpoint propio: los proveedores de OpenAI mandan la API key de la plataforma
+	if dto.LLMBaseURL != "" {
+		if dto.LLMProvider != LLMProviderOllama {
+			return errors.New("llm_base_url solo se puede usar con llm_provider ollama")
+		}
+		if len(dto.LLMBaseURL) > 255 {
+			return errors.New("llm_base_url no debe exceder los 255 caracteres")
+		}
+		if parsed, err := url.Parse(dto.LLMBaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("llm_base_url debe ser una URL http o https")
+		}
+	}
+
+	if _, err := ParseTimeRanges(dto.WorkingHours); err != nil {
+		return fmt.Errorf("working_hours inválido: %v", err)
+	}
+
+	if _, err := ParseTimeRanges(dto.BreakHours); err != nil {
+		return fmt.Errorf("break_hours inválido: %v", err)
+	}
+
+	if _, err := ParseReminderOffsets(dto.ReminderOffsets); err != nil {
+		return fmt.Errorf("reminder_offsets inválido: %v", err)
+	}
+
+	if dto.SlotCapacity < 0 {
+		return errors.New("slot_capacity no puede ser negativo")
+	}
+
 	if len(dto.Model) < 2 {
 		return errors.New("el modelo debe tener por lo menos 2 caracteres")
 	}
@@ -0,0 +1,62 @@
+package dtos
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AvailabilityDto son los horarios libres de un assistant para un día
+type AvailabilityDto struct {
+	AssistantID  int64     `json:"assistant_id"`
+	Date         string    `json:"date"`                    // YYYY-MM-DD
+	Open         bool      `json:"open"`                    // false si el assistant no atiende ese día
+	ClosedReason string    `json:"closed_reason,omitempty"` // Motivo del feriado o cierre que afecta el día
+	Duration     int64     `json:"duration"`                // Duración de cada turno en minutos
+	Slots        []SlotDto `json:"slots"`                   // Solo los horarios con lugar disponible
+	GoogleBusy   bool      `json:"google_busy"`             // Se descontaron los horarios ocupados del Google Calendar del assistant
+	Timezone     string    `json:"timezone"`                // Zona horaria de start y end
+}
+
+// SlotDto es un horario en el que se puede agendar un evento
+type SlotDto struct {
+	Start     string `json:"start"`     // YYYY-MM-DDTHH:MM:SS
+	End       string `json:"end"`       // YYYY-MM-DDTHH:MM:SS
+	Available int    `json:"available"` // Lugares libres según slot_capacity
+}
+
+// TimeRange es un rango horario dentro del día, medido desde la medianoche
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseTimeRanges interpreta rangos "HH:MM-HH:MM" separados por coma (ej: "09:00-13:00,14:00-18:00").
+// Un string vacío devuelve una lista vacía.
+func ParseTimeRanges(value string) ([]TimeRange, error) {
+	var ranges []TimeRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var openHour, openMin, closeHour, closeMin int
+		if _, err := fmt.Sscanf(part, "%d:%d-%d:%d", &openHour, &openMin, &closeHour, &closeMin); err != nil {
+			return nil, fmt.Errorf("el rango %q no tiene el formato HH:MM-HH:MM", part)
+		}
+		if openHour < 0 || openHour > 24 || closeHour < 0 || closeHour > 24 || openMin < 0 || openMin > 59 || closeMin < 0 || closeMin > 59 {
+			return nil, fmt.Errorf("el rango %q tiene una hora inválida", part)
+		}
+
+		timeRange := TimeRange{
+			Start: time.Duration(openHour)*time.Hour + time.Duration(openMin)*time.Minute,
+			End:   time.Duration(closeHour)*time.Hour + time.Duration(closeMin)*time.Minute,
+		}
+		if timeRange.End <= timeRange.Start || timeRange.End > 24*time.Hour {
+			return nil, fmt.Errorf("en el rango %q la hora de fin debe ser posterior a la de inicio", part)
+		}
+		ranges = append(ranges, timeRange)
+	}
+	return ranges, nil
+}
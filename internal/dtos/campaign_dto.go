@@ -0,0 +1,197 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+)
+
+// Estados de una campaña
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusRunning   = "running"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// Estados del envío a cada destinatario. El estado de entrega (delivered, read) es el del mensaje.
+const (
+	CampaignRecipientPending = "pending"
+	CampaignRecipientSent    = "sent"
+	CampaignRecipientFailed  = "failed"
+	CampaignRecipientSkipped = "skipped" // Se dio de baja, fue bloqueado o le faltan variables
+)
+
+// Tiers de Meta: cuántos contactos distintos puede iniciar el número en 24 horas
+var MessagingLimitTiers = map[string]int{
+	"TIER_250":       250,
+	"TIER_1K":        1000,
+	"TIER_10K":       10000,
+	"TIER_100K":      100000,
+	"TIER_UNLIMITED": 0,
+}
+
+// Variables que se pueden usar en los parámetros del template: {{contact.name}}, {{event.start}}, {{var.nombre}},
+// {{field.nombre}} (campo personalizado del contacto)...
+var CampaignPlaceholderPattern = regexp.MustCompile(`\{\{\s*(contact|event|var|field)\.([a-z0-9_]+)\s*\}\}`)
+
+// Variables de contact y event que completa la campaña con los datos del contacto
+var campaignBuiltinVariables = map[string]bool{
+	"contact.id": true, "contact.phone": true, "contact.name": true, "contact.email": true,
+	"event.summary": true, "event.start": true, "event.code": true,
+}
+
+// CampaignSegmentDto filtra los contactos del número que reciben la campaña. Los filtros se combinan (AND);
+// los contactos bloqueados o que se dieron de baja nunca se incluyen.
+type CampaignSegmentDto struct {
+	ContactIDs            []int64    `json:"contact_ids,omitempty"`
+	Tags                  []string   `json:"tags,omitempty"`         // Tiene alguna de las etiquetas
+	ExcludeTags           []string   `json:"exclude_tags,omitempty"` // No tiene ninguna de las etiquetas
+	LastInteractionAfter  *time.Time `json:"last_interaction_after,omitempty"`
+	LastInteractionBefore *time.Time `json:"last_interaction_before,omitempty"`
+	HasUpcomingEvent      *bool      `json:"has_upcoming_event,omitempty"`
+}
+
+// CampaignDto es una campaña de envío masivo de un template a un segmento de contactos del número
+type CampaignDto struct {
+	ID             int64                        `json:"id"`
+	NumberPhonesID int64                        `json:"number_phones_id"`
+	Name           string                       `json:"name"`
+	Template       OutboundTemplateDto          `json:"template"`
+	Segment        CampaignSegmentDto           `json:"segment"`
+	Variables      map[string]map[string]string `json:"variables,omitempty"`
+	Status         string                       `json:"status"`
+	ScheduledAt    *time.Time                   `json:"scheduled_at"`
+	StartedAt      *time.Time                   `json:"started_at"`
+	FinishedAt     *time.Time                   `json:"finished_at"`
+	LastError      string                       `json:"last_error,omitempty"`
+	Stats          *CampaignStatsDto            `json:"stats,omitempty"`
+	CreatedAt      time.Time                    `json:"created_at"`
+}
+
+// CampaignStatsDto resume el resultado de la campaña por destinatario. Delivered incluye a los que ya lo leyeron.
+type CampaignStatsDto struct {
+	Total     int64 `json:"total"`
+	Pending   int64 `json:"pending"`
+	Sent      int64 `json:"sent"`
+	Delivered int64 `json:"delivered"`
+	Read      int64 `json:"read"`
+	Failed    int64 `json:"failed"`
+	Skipped   int64 `json:"skipped"`
+}
+
+// CampaignRecipientDto es el resultado del envío a un contacto
+type CampaignRecipientDto struct {
+	ID             int64      `json:"id"`
+	ContactsID     int64      `json:"contacts_id"`
+	ContactNumber  string     `json:"contact_number"`
+	Status         string     `json:"status"`
+	DeliveryStatus string     `json:"delivery_status,omitempty"` // Último estado del mensaje en WhatsApp (sent, delivered, read, failed)
+	Error          string     `json:"error,omitempty"`
+	MessagesID     *int64     `json:"messages_id,omitempty"`
+	SentAt         *time.Time `json:"sent_at"`
+}
+
+// CampaignRequestDto crea o edita una campaña. Variables tiene las variables de cada contacto por ID ({"15": {"nombre": "Ana"}}).
+type CampaignRequestDto struct {
+	NumberPhoneID int64                        `json:"number_phone_id"`
+	Name          string                       `json:"name"`
+	Template      OutboundTemplateDto          `json:"template"`
+	Segment       CampaignSegmentDto           `json:"segment"`
+	Variables     map[string]map[string]string `json:"variables"`
+}
+
+// ScheduleCampaignDto programa la campaña. Sin scheduled_at empieza a enviarse enseguida.
+type ScheduleCampaignDto struct {
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// ContactTagsDto reemplaza las etiquetas del contacto
+type ContactTagsDto struct {
+	Tags []string `json:"tags"`
+}
+
+func (dto *CampaignRequestDto) Validate() error {
+	dto.Name = strings.TrimSpace(dto.Name)
+	dto.Template.Name = strings.TrimSpace(dto.Template.Name)
+
+	if dto.NumberPhoneID <= 0 {
+		return errors.New("number_phone_id es obligatorio")
+	}
+	if dto.Name == "" || utf8.RuneCountInString(dto.Name) > 255 {
+		return errors.New("name es obligatorio y no debe exceder los 255 caracteres")
+	}
+	if dto.Template.Name == "" {
+		return errors.New("template.name es obligatorio")
+	}
+	if dto.Template.Language == "" {
+		dto.Template.Language = "es"
+	}
+	if dto.Segment.LastInteractionAfter != nil && dto.Segment.LastInteractionBefore != nil &&
+		!dto.Segment.LastInteractionAfter.Before(*dto.Segment.LastInteractionBefore) {
+		return errors.New("segment.last_interaction_after debe ser anterior a segment.last_interaction_before")
+	}
+
+	var err error
+	if dto.Segment.Tags, err = NormalizeTags(dto.Segment.Tags); err != nil {
+		return fmt.Errorf("segment.tags: %v", err)
+	}
+	if dto.Segment.ExcludeTags, err = NormalizeTags(dto.Segment.ExcludeTags); err != nil {
+		return fmt.Errorf("segment.exclude_tags: %v", err)
+	}
+
+	for _, name := range CampaignTemplateVariables(dto.Template.Components) {
+		if strings.HasPrefix(name, "var.") || strings.HasPrefix(name, "field.") || campaignBuiltinVariables[name] {
+			continue
+		}
+		return fmt.Errorf("la variable {{%s}} no existe", name)
+	}
+	return nil
+}
+
+func (dto *ContactTagsDto) Validate() error {
+	tags, err := NormalizeTags(dto.Tags)
+	if err != nil {
+		return err
+	}
+	dto.Tags = tags
+	return nil
+}
+
+// NormalizeTags pasa las etiquetas a minúsculas y saca las vacías y repetidas
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > 50 {
+			return nil, fmt.Errorf("la etiqueta %q excede los 50 caracteres", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// CampaignTemplateVariables devuelve las variables ({{contact.phone}} -> contact.phone) usadas en los parámetros de texto
+func CampaignTemplateVariables(components []metaapi.Component) []string {
+	var names []string
+	for _, component := range components {
+		for _, parameter := range component.Parameters {
+			for _, match := range CampaignPlaceholderPattern.FindAllStringSubmatch(parameter.Text+" "+parameter.Payload, -1) {
+				names = append(names, match[1]+"."+match[2])
+			}
+		}
+	}
+	return names
+}
@@ -1,11 +1,151 @@
 package dtos
 
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
 type ContactDto struct {
-	ID              int64       `json:"id"`
-	NumberPhonesID  int64       `json:"number_phones_id"`
-	ContactNumber   int64       `json:"contact_number"`
-	OpenaiThreadsID string      `json:"openai_threads_id"`
-	CountTokens     string      `json:"count_tokens"`
-	IsBlocked       bool        `json:"is_blocked"`
-	Events          []EventsDto `json:"events,omitempty"`
+	ID              int64             `json:"id"`
+	NumberPhonesID  int64             `json:"number_phones_id"`
+	ContactNumber   string            `json:"contact_number"`
+	DisplayName     string            `json:"display_name"`
+	ProfileName     string            `json:"profile_name"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	CustomFields    map[string]string `json:"custom_fields"`
+	Notes           string            `json:"notes"`
+	OpenaiThreadsID string            `json:"openai_threads_id"`
+	CountTokens     string            `json:"count_tokens"`
+	IsBlocked       bool              `json:"is_blocked"`
+	OptedInAt       *time.Time        `json:"opted_in_at"`
+	OptedOutAt      *time.Time        `json:"opted_out_at"`
+	Tags            []string          `json:"tags"`
+	Handoff         HandoffStateDto   `json:"handoff"`
+	Events          []EventsDto       `json:"events,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// ContactOptOutDto da de baja (o vuelve a suscribir) al contacto de las campañas
+type ContactOptOutDto struct {
+	OptedOut bool `json:"opted_out"`
+}
+
+// Los nombres de los campos personalizados se usan como variables de las campañas ({{field.nombre}})
+var contactCustomFieldPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+const maxContactCustomFields = 50
+
+// ContactProfileDto edita el perfil del contacto. Solo se modifican los campos que vienen en el body;
+// custom_fields reemplaza todos los campos personalizados.
+type ContactProfileDto struct {
+	Name         *string            `json:"name"`
+	Email        *string            `json:"email"`
+	Notes        *string            `json:"notes"`
+	Tags         *[]string          `json:"tags"`
+	CustomFields *map[string]string `json:"custom_fields"`
+	OptedOut     *bool              `json:"opted_out"`
+}
+
+func (dto *ContactProfileDto) Validate() error {
+	if dto.Name != nil {
+		name := strings.TrimSpace(*dto.Name)
+		if utf8.RuneCountInString(name) > 255 {
+			return errors.New("name no debe exceder los 255 caracteres")
+		}
+		dto.Name = &name
+	}
+	if dto.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*dto.Email))
+		if email != "" && !IsValidEmail(email) {
+			return errors.New("email inválido")
+		}
+		dto.Email = &email
+	}
+	if dto.Tags != nil {
+		tags, err := NormalizeTags(*dto.Tags)
+		if err != nil {
+			return err
+		}
+		dto.Tags = &tags
+	}
+	if dto.CustomFields != nil {
+		fields, err := NormalizeCustomFields(*dto.CustomFields)
+		if err != nil {
+			return err
+		}
+		dto.CustomFields = &fields
+	}
+	return nil
+}
+
+// IsValidEmail verifica que sea una dirección sola, sin nombre ("juan@mail.com", no "Juan <juan@mail.com>")
+func IsValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && utf8.RuneCountInString(email) <= 255
+}
+
+// NormalizeCustomFields pasa los nombres de los campos a minúsculas y saca los que no tienen valor
+func NormalizeCustomFields(fields map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(fields))
+	for key, value := range fields {
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !contactCustomFieldPattern.MatchString(key) {
+			return nil, fmt.Errorf("el campo %q solo puede tener letras minúsculas, números y _ (hasta 50 caracteres)", key)
+		}
+		if utf8.RuneCountInString(value) > 500 {
+			return nil, fmt.Errorf("el valor del campo %q excede los 500 caracteres", key)
+		}
+		normalized[key] = value
+	}
+	if len(normalized) > maxContactCustomFields {
+		return nil, fmt.Errorf("el contacto no puede tener más de %d campos personalizados", maxContactCustomFields)
+	}
+	return normalized, nil
+}
+
+// ContactFilterDto filtra el listado de contactos del número
+type ContactFilterDto struct {
+	Search   string // Busca en el nombre, el nombre de WhatsApp, el email y el número
+	Tag      string
+	OptedOut *bool
+	Blocked  *bool
+}
+
+// Formatos de archivo para importar contactos
+const (
+	ContactImportCSV   = "csv"
+	ContactImportVCard = "vcard"
+)
+
+// ContactImportOptionsDto configura la importación de contactos
+type ContactImportOptionsDto struct {
+	Format             string // csv o vcard
+	DefaultCountryCode string // Se agrega a los números que vienen sin código de país (por defecto 54)
+	UpdateExisting     bool   // Completa el perfil de los contactos que ya existían en vez de saltearlos
+}
+
+// ContactImportResultDto es el resultado de importar un archivo de contactos
+type ContactImportResultDto struct {
+	Total      int                         `json:"total"`
+	Created    int                         `json:"created"`
+	Updated    int                         `json:"updated"`
+	Duplicates int                         `json:"duplicates"` // Ya existían (o estaban repetidos en el archivo)
+	Rejected   []ContactImportRejectionDto `json:"rejected,omitempty"`
+}
+
+// ContactImportRejectionDto es una fila del archivo que no se importó
+type ContactImportRejectionDto struct {
+	Line   int    `json:"line"`
+	Phone  string `json:"phone"`
+	Reason string `json:"reason"`
 }
@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"google.golang.org/api/calendar/v3"
@@ -9,17 +10,18 @@ import (
 
 // EventsDto representa la estructura de un evento con validaciones
 type EventsDto struct {
-	ID                    int    `json:"id"`
-	Summary               string `json:"summary" validate:"required,min=3,max=255"`
-	Description           string `json:"description" validate:"required,min=5,max=500"`
-	StartDate             string `json:"start_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
-	EndDate               string `json:"end_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00,gtfield=StartDate"`
-	EventGoogleCalendarID string `json:"event_google_calendar_id" validate:"omitempty"`
-	AssistantsID          int64  `json:"assistants_id" validate:"required,gt=0"`
-	ContactsID            int64  `json:"contacts_id" validate:"required,gt=0"`
-	CodeEvent             string `json:"code_event" validate:"omitempty"`
-	CreatedAt             string `json:"created_at"`
-	MonthYear             string `json:"month_year" validate:"required,len=7,datetime=2006-01"`
+	ID                    int        `json:"id"`
+	Summary               string     `json:"summary" validate:"required,min=3,max=255"`
+	Description           string     `json:"description" validate:"required,min=5,max=500"`
+	StartDate             string     `json:"start_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndDate               string     `json:"end_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00,gtfield=StartDate"`
+	EventGoogleCalendarID string     `json:"event_google_calendar_id" validate:"omitempty"`
+	AssistantsID          int64      `json:"assistants_id" validate:"required,gt=0"`
+	ContactsID            int64      `json:"contacts_id" validate:"required,gt=0"`
+	CodeEvent             string     `json:"code_event" validate:"omitempty"`
+	ConfirmedAt           *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt             string     `json:"created_at"`
+	MonthYear             string     `json:"month_year" validate:"required,len=7,datetime=2006-01"`
 }
 
 // Validador de datos del DTO
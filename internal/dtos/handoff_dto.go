@@ -0,0 +1,69 @@
+package dtos
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Quién atiende la conversación con un contacto
+const (
+	HandoffModeBot    = "bot"    // Responde el assistant
+	HandoffModeHuman  = "human"  // La atiende un operador desde la bandeja, el assistant no responde
+	HandoffModePaused = "paused" // Nadie responde automáticamente; los mensajes quedan en la bandeja
+)
+
+// HandoffStateDto es el modo actual de la conversación del contacto
+type HandoffStateDto struct {
+	Mode      string     `json:"mode"`
+	Reason    string     `json:"reason,omitempty"`
+	Since     *time.Time `json:"since,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Cuándo vuelve a responder el bot
+}
+
+// HandoffRequestDto cambia quién atiende la conversación. Con timeout_minutes en 0 el modo human usa el
+// vencimiento por defecto (HANDOFF_TIMEOUT_MINUTES) y el modo paused no vence.
+type HandoffRequestDto struct {
+	Mode           string `json:"mode"`
+	Reason         string `json:"reason"`
+	TimeoutMinutes int    `json:"timeout_minutes"`
+}
+
+// OperatorMessageDto es un mensaje que un operador le envía al contacto desde la bandeja
+type OperatorMessageDto struct {
+	Text string `json:"text"`
+}
+
+// InboxConversationDto es una conversación de la bandeja: el contacto y su último mensaje
+type InboxConversationDto struct {
+	Contact     ContactDto  `json:"contact"`
+	LastMessage *MessageDto `json:"last_message,omitempty"`
+}
+
+func (dto *HandoffRequestDto) Validate() error {
+	dto.Mode = strings.ToLower(strings.TrimSpace(dto.Mode))
+	switch dto.Mode {
+	case HandoffModeBot, HandoffModeHuman, HandoffModePaused:
+	default:
+		return errors.New("mode debe ser bot, human o paused")
+	}
+	if dto.TimeoutMinutes < 0 {
+		return errors.New("timeout_minutes no puede ser negativo")
+	}
+	if len(dto.Reason) > 255 {
+		return errors.New("reason no debe exceder los 255 caracteres")
+	}
+	return nil
+}
+
+func (dto *OperatorMessageDto) Validate() error {
+	dto.Text = strings.TrimSpace(dto.Text)
+	if dto.Text == "" {
+		return errors.New("text es obligatorio")
+	}
+	// Límite de WhatsApp para el cuerpo de un mensaje de texto
+	if len([]rune(dto.Text)) > 4096 {
+		return errors.New("text no debe exceder los 4096 caracteres")
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package dtos
+
+import "time"
+
+type InboundJobDto struct {
+	ID          int64      `json:"id"`
+	Payload     string     `json:"payload"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -3,11 +3,20 @@ package dtos
 import "time"
 
 type MessageDto struct {
-	ID             int64     `json:"id"`
-	NumberPhonesID int64     `json:"number_phones_id"`
-	ContactsID     int64     `json:"contacts_id"`
-	MessageText    string    `json:"message_text"`
-	IsFromBot      bool      `json:"is_from_bot"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                int64      `json:"id"`
+	NumberPhonesID    int64      `json:"number_phones_id"`
+	ContactsID        int64      `json:"contacts_id"`
+	MessageText       string     `json:"message_text"`
+	IsFromBot         bool       `json:"is_from_bot"`
+	MessageType       string     `json:"message_type"`
+	MediaPath         string     `json:"media_path,omitempty"`
+	MediaMimeType     string     `json:"media_mime_type,omitempty"`
+	MessageIdWhatsapp string     `json:"message_id_whatsapp"`
+	Status            string     `json:"status,omitempty"`
+	StatusUpdatedAt   *time.Time `json:"status_updated_at,omitempty"`
+	ErrorCode         int        `json:"error_code,omitempty"`
+	ErrorTitle        string     `json:"error_title,omitempty"`
+	UsersID           *int64     `json:"users_id,omitempty"` // Operador que lo envió desde la bandeja
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,20 @@
+package dtos
+
+import "time"
+
+type MessageStatusDto struct {
+	ID                 int64     `json:"id"`
+	MessagesID         int64     `json:"messages_id,omitempty"`
+	MessageIdWhatsapp  string    `json:"message_id_whatsapp"`
+	Status             string    `json:"status"`
+	StatusTimestamp    time.Time `json:"status_timestamp"`
+	RecipientID        string    `json:"recipient_id"`
+	ConversationID     string    `json:"conversation_id,omitempty"`
+	ConversationOrigin string    `json:"conversation_origin,omitempty"`
+	PricingCategory    string    `json:"pricing_category,omitempty"`
+	Billable           bool      `json:"billable"`
+	ErrorCode          int       `json:"error_code,omitempty"`
+	ErrorTitle         string    `json:"error_title,omitempty"`
+	ErrorDetails       string    `json:"error_details,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
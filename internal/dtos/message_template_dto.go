@@ -0,0 +1,129 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Estados de aprobación de un template en Meta
+const (
+	TemplateStatusApproved = "APPROVED"
+	TemplateStatusPending  = "PENDING"
+	TemplateStatusRejected = "REJECTED"
+	TemplateStatusPaused   = "PAUSED"
+	TemplateStatusDisabled = "DISABLED"
+)
+
+// Momentos del ciclo de vida de un evento en los que se envía un template
+const (
+	TemplateEventCreated   = "created"
+	TemplateEventUpdated   = "updated"
+	TemplateEventCancelled = "cancelled"
+	TemplateEventReminder  = "reminder"
+)
+
+// TemplateEventBodyParameters son los parámetros del body que se envían en cada momento: resumen, inicio, fin,
+// contacto y código para los avisos al dueño del número; resumen, inicio y código para el recordatorio al contacto.
+var TemplateEventBodyParameters = map[string]int{
+	TemplateEventCreated:   5,
+	TemplateEventUpdated:   5,
+	TemplateEventCancelled: 5,
+	TemplateEventReminder:  3,
+}
+
+// Nombres válidos para Meta: minúsculas, números y guion bajo
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9_]{1,512}$`)
+
+// Variables de un texto de template: {{1}}, {{2}} o con nombre ({{nombre}})
+var templatePlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-z0-9_]+)\s*\}\}`)
+
+// MessageTemplateDto es un template del WhatsApp Business Account del número, tal como se sincronizó de Meta
+type MessageTemplateDto struct {
+	ID              int64                      `json:"id"`
+	NumberPhonesID  int64                      `json:"number_phones_id"`
+	MetaTemplateID  string                     `json:"meta_template_id"`
+	Name            string                     `json:"name"`
+	Language        string                     `json:"language"`
+	Category        string                     `json:"category"`
+	Status          string                     `json:"status"`
+	RejectedReason  string                     `json:"rejected_reason,omitempty"`
+	Components      interface{}                `json:"components"`
+	ParameterSchema TemplateParameterSchemaDto `json:"parameter_schema"`
+	SyncedAt        *time.Time                 `json:"synced_at"`
+}
+
+// TemplateParameterSchemaDto indica cuántos parámetros espera cada componente del template
+type TemplateParameterSchemaDto struct {
+	HeaderFormat     string                    `json:"header_format,omitempty"` // TEXT, IMAGE, VIDEO, DOCUMENT o LOCATION
+	HeaderParameters int                       `json:"header_parameters"`
+	BodyParameters   int                       `json:"body_parameters"`
+	Buttons          []TemplateButtonSchemaDto `json:"buttons,omitempty"`
+}
+
+type TemplateButtonSchemaDto struct {
+	Index      int    `json:"index"`
+	Type       string `json:"type"`
+	Parameters int    `json:"parameters"`
+}
+
+// CreateMessageTemplateDto es un template nuevo para enviar a aprobación. Components va tal cual a la Graph API.
+type CreateMessageTemplateDto struct {
+	Name       string                   `json:"name"`
+	Language   string                   `json:"language"`
+	Category   string                   `json:"category"` // MARKETING, UTILITY o AUTHENTICATION
+	Components []map[string]interface{} `json:"components"`
+}
+
+// AssistantTemplateDto asigna el template (y su idioma) que usa el assistant en un momento del ciclo de vida de los eventos
+type AssistantTemplateDto struct {
+	Event        string `json:"event"`
+	TemplateName string `json:"template_name"`
+	Language     string `json:"language"`
+}
+
+// CountTemplatePlaceholders cuenta las variables distintas del texto de un componente
+func CountTemplatePlaceholders(text string) int {
+	seen := map[string]bool{}
+	for _, match := range templatePlaceholderPattern.FindAllStringSubmatch(text, -1) {
+		seen[match[1]] = true
+	}
+	return len(seen)
+}
+
+func (dto *CreateMessageTemplateDto) Validate() error {
+	dto.Name = strings.TrimSpace(dto.Name)
+	dto.Category = strings.ToUpper(strings.TrimSpace(dto.Category))
+	if !templateNamePattern.MatchString(dto.Name) {
+		return errors.New("name solo puede tener minúsculas, números y guion bajo")
+	}
+	if dto.Language == "" {
+		dto.Language = "es"
+	}
+	switch dto.Category {
+	case "MARKETING", "UTILITY", "AUTHENTICATION":
+	default:
+		return errors.New("category debe ser MARKETING, UTILITY o AUTHENTICATION")
+	}
+	if len(dto.Components) == 0 {
+		return errors.New("components es obligatorio")
+	}
+	return nil
+}
+
+func (dto *AssistantTemplateDto) Validate() error {
+	dto.Event = strings.ToLower(strings.TrimSpace(dto.Event))
+	dto.TemplateName = strings.TrimSpace(dto.TemplateName)
+	if _, ok := TemplateEventBodyParameters[dto.Event]; !ok {
+		return fmt.Errorf("event debe ser %s, %s, %s o %s", TemplateEventCreated, TemplateEventUpdated, TemplateEventCancelled, TemplateEventReminder)
+	}
+	if !templateNamePattern.MatchString(dto.TemplateName) {
+		return errors.New("template_name no es un nombre de template válido")
+	}
+	if dto.Language == "" {
+		dto.Language = "es"
+	}
+	return nil
+}
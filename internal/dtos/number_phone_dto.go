@@ -1,12 +1,45 @@
 package dtos
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 type NumberPhoneDto struct {
-	ID                    int64  `json:"id"`
-	AssistantsID          int64  `json:"assistants_id"`
-	NumberPhone           int64  `json:"number_phone"`
-	UUID                  string `json:"uuid"`
-	NumberPhoneToNotify   int64  `json:"number_phone_to_notify"`
-	TokenPermanent        string `json:"token_permanent"`
-	WhatsappNumberPhoneId int64  `json:"whatsapp_number_phone_id"`
-	Active                bool   `json:"active"`
+	ID                    int64       `json:"id"`
+	AssistantsID          int64       `json:"assistants_id"`
+	NumberPhone           PhoneNumber `json:"number_phone"` // E.164 ("+5493515551234")
+	UUID                  string      `json:"uuid"`
+	NumberPhoneToNotify   PhoneNumber `json:"number_phone_to_notify"` // E.164, vacío si no se notifica a nadie
+	TokenPermanent        string      `json:"token_permanent"`
+	WhatsappNumberPhoneId int64       `json:"whatsapp_number_phone_id"`
+	WhatsappBusinessID    int64       `json:"whatsapp_business_id"` // WABA del número, necesario para administrar sus templates
+	MessagingLimitTier    string      `json:"messaging_limit_tier"` // Tier de Meta (TIER_250, TIER_1K, TIER_10K, TIER_100K o TIER_UNLIMITED)
+	AppSecret             string      `json:"app_secret,omitempty"` // Solo se recibe al crear o editar el número, nunca se devuelve
+	Active                bool        `json:"active"`
+}
+
+// PhoneNumber es un número de teléfono en el JSON. Se acepta como string ("+54 9 351 555-1234") o, como se
+// mandaba antes de guardarlos en E.164, como número (5493515551234). Siempre se devuelve como string.
+type PhoneNumber string
+
+func (p *PhoneNumber) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil {
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+			if number.String() == "0" {
+				*p = ""
+			} else {
+				*p = PhoneNumber(number.String())
+			}
+			return nil
+		}
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*p = PhoneNumber(value)
+	return nil
 }
@@ -79,3 +79,12 @@ type DeleteMessageResponse struct {
 }
 
 // ==============================================
+
+// ListMessagesResponse es la respuesta de GET /threads/{thread_id}/messages, del mensaje más nuevo al más viejo
+type ListMessagesResponse struct {
+	Object  string               `json:"object"`
+	Data    []GetMessageResponse `json:"data"`
+	FirstID string               `json:"first_id"`
+	LastID  string               `json:"last_id"`
+	HasMore bool                 `json:"has_more"`
+}
@@ -6,6 +6,15 @@ type OpenAIRunResponse struct {
 	ThreadID       string         `json:"thread_id"`
 	Status         string         `json:"status"`
 	RequiredAction RequiredAction `json:"required_action"`
+	Model          string         `json:"model"`
+	Usage          *RunUsage      `json:"usage"` // Solo viene cuando el run terminó
+}
+
+// RunUsage son los tokens que consumió un run (sumando todos sus pasos)
+type RunUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // RequiredAction representa la acción requerida cuando el estado es "requires_action"
@@ -0,0 +1,172 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+)
+
+// Tipos de mensajes que se pueden enviar con la API de salida
+const (
+	OutboundTypeText        = "text"
+	OutboundTypeTemplate    = "template"
+	OutboundTypeImage       = "image"
+	OutboundTypeDocument    = "document"
+	OutboundTypeAudio       = "audio"
+	OutboundTypeVideo       = "video"
+	OutboundTypeSticker     = "sticker"
+	OutboundTypeInteractive = "interactive"
+)
+
+// ErrOutsideServiceWindow es el error de enviar un mensaje que no es template cuando pasaron más de 24 horas
+// desde el último mensaje del contacto (ventana de atención de WhatsApp)
+var ErrOutsideServiceWindow = errors.New("pasaron más de 24 horas desde el último mensaje del contacto, solo se pueden enviar templates")
+
+// OutboundMessageDto es un mensaje que se envía desde un número a un contacto. El contacto se indica con
+// contact_id o, para escribirle a alguien que todavía no es contacto del número, con to (solo templates).
+type OutboundMessageDto struct {
+	NumberPhoneID int64                `json:"number_phone_id"`
+	ContactID     int64                `json:"contact_id"`
+	To            string               `json:"to"`
+	Type          string               `json:"type"`
+	Text          string               `json:"text"`
+	Template      *OutboundTemplateDto `json:"template"`
+	Media         *metaapi.Media       `json:"media"`
+	Interactive   *metaapi.Interactive `json:"interactive"`
+}
+
+// OutboundTemplateDto es un template aprobado en Meta con los parámetros de cada componente
+type OutboundTemplateDto struct {
+	Name       string              `json:"name"`
+	Language   string              `json:"language"` // Por defecto es
+	Components []metaapi.Component `json:"components"`
+}
+
+func (dto *OutboundMessageDto) Validate() error {
+	dto.Type = strings.ToLower(strings.TrimSpace(dto.Type))
+	dto.To = strings.TrimSpace(dto.To)
+
+	if dto.NumberPhoneID <= 0 {
+		return errors.New("number_phone_id es obligatorio")
+	}
+	if dto.ContactID <= 0 && dto.To == "" {
+		return errors.New("contact_id o to es obligatorio")
+	}
+	if dto.ContactID <= 0 {
+		// Se guarda en E.164 como el resto de los contactos (siempre con código de país, tenga o no el +)
+		number, err := phone.NormalizeMobile("+"+strings.TrimPrefix(dto.To, "+"), "")
+		if err != nil {
+			return errors.New("to debe ser un número de teléfono con código de país")
+		}
+		dto.To = number
+	}
+	if dto.ContactID <= 0 && dto.Type != OutboundTypeTemplate {
+		return errors.New("a un número que no es contacto solo se le pueden enviar templates")
+	}
+
+	switch dto.Type {
+	case OutboundTypeText:
+		dto.Text = strings.TrimSpace(dto.Text)
+		if dto.Text == "" {
+			return errors.New("text es obligatorio")
+		}
+		if utf8.RuneCountInString(dto.Text) > 4096 {
+			return errors.New("text no debe exceder los 4096 caracteres")
+		}
+	case OutboundTypeTemplate:
+		if dto.Template == nil || strings.TrimSpace(dto.Template.Name) == "" {
+			return errors.New("template.name es obligatorio")
+		}
+		if dto.Template.Language == "" {
+			dto.Template.Language = "es"
+		}
+	case OutboundTypeImage, OutboundTypeDocument, OutboundTypeAudio, OutboundTypeVideo, OutboundTypeSticker:
+		return dto.validateMedia()
+	case OutboundTypeInteractive:
+		return dto.validateInteractive()
+	default:
+		return fmt.Errorf("type debe ser text, template, image, document, audio, video, sticker o interactive")
+	}
+	return nil
+}
+
+func (dto *OutboundMessageDto) validateMedia() error {
+	if dto.Media == nil || (dto.Media.ID == "") == (dto.Media.Link == "") {
+		return errors.New("media debe tener id o link")
+	}
+	if dto.Media.Caption != "" && dto.Type != OutboundTypeImage && dto.Type != OutboundTypeVideo && dto.Type != OutboundTypeDocument {
+		return errors.New("media.caption solo se puede usar con image, video o document")
+	}
+	if dto.Media.Filename != "" && dto.Type != OutboundTypeDocument {
+		return errors.New("media.filename solo se puede usar con document")
+	}
+	return nil
+}
+
+// validateInteractive controla los límites de WhatsApp para botones y listas
+func (dto *OutboundMessageDto) validateInteractive() error {
+	interactive := dto.Interactive
+	if interactive == nil {
+		return errors.New("interactive es obligatorio")
+	}
+	if strings.TrimSpace(interactive.Body.Text) == "" || utf8.RuneCountInString(interactive.Body.Text) > 1024 {
+		return errors.New("interactive.body.text es obligatorio y no debe exceder los 1024 caracteres")
+	}
+	if interactive.Header != nil && interactive.Header.Type == "" {
+		interactive.Header.Type = "text"
+	}
+
+	switch interactive.Type {
+	case "button":
+		buttons := interactive.Action.Buttons
+		if len(buttons) == 0 || len(buttons) > 3 {
+			return errors.New("interactive.action.buttons debe tener entre 1 y 3 botones")
+		}
+		for _, button := range buttons {
+			if button.Reply.ID == "" || button.Reply.Title == "" || utf8.RuneCountInString(button.Reply.Title) > 20 {
+				return errors.New("cada botón debe tener id y un title de hasta 20 caracteres")
+			}
+		}
+	case "list":
+		action := interactive.Action
+		if action.Button == "" || utf8.RuneCountInString(action.Button) > 20 {
+			return errors.New("interactive.action.button es obligatorio y no debe exceder los 20 caracteres")
+		}
+		rows := 0
+		for _, section := range action.Sections {
+			for _, row := range section.Rows {
+				if row.ID == "" || row.Title == "" || utf8.RuneCountInString(row.Title) > 24 {
+					return errors.New("cada opción debe tener id y un title de hasta 24 caracteres")
+				}
+			}
+			rows += len(section.Rows)
+		}
+		if len(action.Sections) == 0 || rows == 0 || rows > 10 {
+			return errors.New("interactive.action.sections debe tener entre 1 y 10 opciones")
+		}
+	default:
+		return errors.New("interactive.type debe ser button o list")
+	}
+	return nil
+}
+
+// StoredText es el texto con el que se guarda el mensaje en messages
+func (dto OutboundMessageDto) StoredText() string {
+	switch dto.Type {
+	case OutboundTypeText:
+		return dto.Text
+	case OutboundTypeTemplate:
+		return "[template] " + dto.Template.Name
+	case OutboundTypeInteractive:
+		return dto.Interactive.Body.Text
+	default:
+		if dto.Media != nil {
+			return dto.Media.Caption
+		}
+		return ""
+	}
+}
@@ -0,0 +1,28 @@
+package dtos
+
+// Tipos de eventos que se envían por el stream de conversaciones del dashboard
+const (
+	StreamEventMessage = "message"        // Mensaje nuevo: del contacto, del bot o de un operador
+	StreamEventStatus  = "message_status" // Callback de estado de WhatsApp para un mensaje saliente
+	StreamEventHandoff = "handoff"        // Cambió quién atiende la conversación del contacto
+	StreamEventReset   = "reset"          // Se perdieron demasiados mensajes para el backfill; hay que recargar por la API
+)
+
+// StreamEventDto es un evento del stream. Los de tipo message llevan MessageID, que se usa como id del evento
+// para que al reconectar el cliente reciba los mensajes que se perdió (Last-Event-ID o last_message_id).
+type StreamEventDto struct {
+	Type          string      `json:"type"`
+	NumberPhoneID int64       `json:"number_phone_id"`
+	ContactID     int64       `json:"contact_id"`
+	MessageID     int64       `json:"message_id,omitempty"`
+	Data          interface{} `json:"data"`
+}
+
+// MessageStatusEventDto es el estado informado por WhatsApp para un mensaje
+type MessageStatusEventDto struct {
+	MessageID         int64  `json:"message_id"`
+	MessageIdWhatsapp string `json:"message_id_whatsapp"`
+	Status            string `json:"status"`
+	ErrorCode         int    `json:"error_code,omitempty"`
+	ErrorTitle        string `json:"error_title,omitempty"`
+}
@@ -0,0 +1,31 @@
+package dtos
+
+// TenantLocalsKey es la clave con la que el middleware deja el TenantScope del usuario en c.Locals
+const TenantLocalsKey = "tenant"
+
+// TenantScope son los bussiness (vía bussiness_has_users) a los que puede acceder quien hace el request.
+// El valor cero no restringe nada: es el que usan los procesos internos (webhook, cron, tools del assistant)
+// y los usuarios con el rol de administrador (ROL_ADMIN).
+type TenantScope struct {
+	Restricted   bool
+	UserID       int64
+	BussinessIDs []int64
+}
+
+// NewTenantScope arma el scope de un usuario que solo puede ver sus bussiness
+func NewTenantScope(userID int64, bussinessIDs []int64) TenantScope {
+	return TenantScope{Restricted: true, UserID: userID, BussinessIDs: bussinessIDs}
+}
+
+// Allows indica si el scope incluye el bussiness
+func (s TenantScope) Allows(bussinessID int64) bool {
+	if !s.Restricted {
+		return true
+	}
+	for _, id := range s.BussinessIDs {
+		if id == bussinessID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// UsageDayLayout es el formato de los días de los reportes de consumo (y de los parámetros from y to)
+const UsageDayLayout = "2006-01-02"
+
+// Máxima cantidad de días que abarca un reporte de consumo
+const maxUsageReportDays = 366
+
+// DefaultQuotaFallbackMessage es lo que responde el bot cuando el bussiness superó su cuota mensual y no
+// configuró un mensaje propio
+const DefaultQuotaFallbackMessage = "En este momento no podemos responder tu consulta. Te vamos a contactar a la brevedad."
+
+// Dimensiones por las que se puede agrupar el reporte de consumo
+const (
+	UsageGroupDay         = "day"
+	UsageGroupBussiness   = "bussiness"
+	UsageGroupAssistant   = "assistant"
+	UsageGroupNumberPhone = "number_phone"
+	UsageGroupContact     = "contact"
+	UsageGroupModel       = "model"
+)
+
+var usageGroups = map[string]bool{
+	UsageGroupDay: true, UsageGroupBussiness: true, UsageGroupAssistant: true,
+	UsageGroupNumberPhone: true, UsageGroupContact: true, UsageGroupModel: true,
+}
+
+// UsageFilterDto filtra y agrupa el reporte de consumo de tokens. Los IDs en cero no filtran.
+type UsageFilterDto struct {
+	BussinessID   int64
+	AssistantID   int64
+	NumberPhoneID int64
+	ContactID     int64
+	From          string // YYYY-MM-DD, inclusive. Por defecto el primer día del mes
+	To            string // YYYY-MM-DD, inclusive. Por defecto hoy
+	GroupBy       []string
+}
+
+// Validate completa los valores por defecto (con now en la zona horaria de los reportes) y valida el rango
+func (dto *UsageFilterDto) Validate(now time.Time) error {
+	if dto.From == "" {
+		dto.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format(UsageDayLayout)
+	}
+	if dto.To == "" {
+		dto.To = now.Format(UsageDayLayout)
+	}
+	from, err := time.Parse(UsageDayLayout, dto.From)
+	if err != nil {
+		return errors.New("from debe tener el formato YYYY-MM-DD")
+	}
+	to, err := time.Parse(UsageDayLayout, dto.To)
+	if err != nil {
+		return errors.New("to debe tener el formato YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return errors.New("to debe ser posterior a from")
+	}
+	if to.Sub(from) > maxUsageReportDays*24*time.Hour {
+		return fmt.Errorf("el reporte no puede abarcar más de %d días", maxUsageReportDays)
+	}
+
+	if len(dto.GroupBy) == 0 {
+		dto.GroupBy = []string{UsageGroupDay}
+	}
+	seen := map[string]bool{}
+	groups := make([]string, 0, len(dto.GroupBy))
+	for _, group := range dto.GroupBy {
+		group = strings.ToLower(strings.TrimSpace(group))
+		if !usageGroups[group] {
+			return fmt.Errorf("group_by inválido: %q (day, bussiness, assistant, number_phone, contact o model)", group)
+		}
+		if !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+	dto.GroupBy = groups
+	return nil
+}
+
+// UsageReportRowDto es el consumo de un grupo del reporte. Solo vienen los campos por los que se agrupó.
+type UsageReportRowDto struct {
+	Day              string  `json:"day,omitempty"`
+	BussinessID      int64   `json:"bussiness_id,omitempty"`
+	AssistantsID     int64   `json:"assistants_id,omitempty"`
+	NumberPhonesID   int64   `json:"number_phones_id,omitempty"`
+	ContactsID       int64   `json:"contacts_id,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Runs             int64   `json:"runs"`
+	Cost             float64 `json:"cost"` // USD
+}
+
+// UsageReportDto es el reporte de consumo de tokens del período
+type UsageReportDto struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	GroupBy []string            `json:"group_by"`
+	Rows    []UsageReportRowDto `json:"rows"`
+	Totals  UsageReportRowDto   `json:"totals"`
+}
+
+// BussinessQuotaDto es la cuota mensual del bussiness y lo que lleva consumido en el mes
+type BussinessQuotaDto struct {
+	BussinessID       int64   `json:"bussiness_id"`
+	MonthlyTokenQuota int64   `json:"monthly_token_quota"` // 0 = sin límite
+	MonthlyCostQuota  float64 `json:"monthly_cost_quota"`  // USD, 0 = sin límite
+	FallbackMessage   string  `json:"fallback_message"`
+	Month             string  `json:"month"` // YYYY-MM
+	UsedTokens        int64   `json:"used_tokens"`
+	UsedCost          float64 `json:"used_cost"`
+	Exceeded          bool    `json:"exceeded"`
+}
+
+// BussinessQuotaRequestDto cambia la cuota del bussiness. Los campos que no vienen no se modifican.
+type BussinessQuotaRequestDto struct {
+	MonthlyTokenQuota *int64   `json:"monthly_token_quota"`
+	MonthlyCostQuota  *float64 `json:"monthly_cost_quota"`
+	FallbackMessage   *string  `json:"fallback_message"`
+}
+
+func (dto *BussinessQuotaRequestDto) Validate() error {
+	if dto.MonthlyTokenQuota == nil && dto.MonthlyCostQuota == nil && dto.FallbackMessage == nil {
+		return errors.New("no hay cambios para aplicar")
+	}
+	if dto.MonthlyTokenQuota != nil && *dto.MonthlyTokenQuota < 0 {
+		return errors.New("monthly_token_quota no puede ser negativa")
+	}
+	if dto.MonthlyCostQuota != nil && *dto.MonthlyCostQuota < 0 {
+		return errors.New("monthly_cost_quota no puede ser negativa")
+	}
+	if dto.FallbackMessage != nil {
+		message := strings.TrimSpace(*dto.FallbackMessage)
+		if utf8.RuneCountInString(message) > 1024 {
+			return errors.New("fallback_message no debe exceder los 1024 caracteres")
+		}
+		dto.FallbackMessage = &message
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package metaapi
+
+// MessageTemplate es un template del WhatsApp Business Account como lo devuelve la Graph API
+type MessageTemplate struct {
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Language       string                `json:"language"`
+	Status         string                `json:"status"`
+	Category       string                `json:"category"`
+	RejectedReason string                `json:"rejected_reason"`
+	Components     []TemplateDefinedPart `json:"components"`
+}
+
+// TemplateDefinedPart es un componente de la definición del template (HEADER, BODY, FOOTER o BUTTONS)
+type TemplateDefinedPart struct {
+	Type    string                  `json:"type"`
+	Format  string                  `json:"format,omitempty"`
+	Text    string                  `json:"text,omitempty"`
+	Buttons []TemplateDefinedButton `json:"buttons,omitempty"`
+}
+
+type TemplateDefinedButton struct {
+	Type        string `json:"type"` // QUICK_REPLY, URL, PHONE_NUMBER, COPY_CODE
+	Text        string `json:"text"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// MessageTemplatesPage es una página del listado de templates
+type MessageTemplatesPage struct {
+	Data   []MessageTemplate `json:"data"`
+	Paging struct {
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+// CreateMessageTemplateResponse es la respuesta al crear un template
+type CreateMessageTemplateResponse struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Category string `json:"category"`
+}
+
+// GraphError es el error que devuelve la Graph API
+type GraphError struct {
+	Error struct {
+		Message      string `json:"message"`
+		Type         string `json:"type"`
+		Code         int    `json:"code"`
+		ErrorSubcode int    `json:"error_subcode"`
+		UserMessage  string `json:"error_user_msg"`
+		ErrorData    struct {
+			Details string `json:"details"`
+		} `json:"error_data"`
+		FBTraceID string `json:"fbtrace_id"`
+	} `json:"error"`
+}
@@ -0,0 +1,22 @@
+package metaapi
+
+// OutgoingMessage es cualquier body que se envía a POST /{phone-number-id}/messages: SendMessageBasic,
+// SendMessageTemplate, SendMessageMedia o SendMessageInteractive
+type OutgoingMessage interface {
+	// MessageType es el type del mensaje (text, template, image, interactive, ...)
+	MessageType() string
+	// Recipient es el número de destino como lo espera la API (ver FormatRecipient)
+	Recipient() string
+}
+
+func (m SendMessageBasic) MessageType() string { return m.Type }
+func (m SendMessageBasic) Recipient() string   { return m.To }
+
+func (m SendMessageTemplate) MessageType() string { return m.Type }
+func (m SendMessageTemplate) Recipient() string   { return m.To }
+
+func (m SendMessageMedia) MessageType() string { return m.Type }
+func (m SendMessageMedia) Recipient() string   { return m.To }
+
+func (m SendMessageInteractive) MessageType() string { return m.Type }
+func (m SendMessageInteractive) Recipient() string   { return m.To }
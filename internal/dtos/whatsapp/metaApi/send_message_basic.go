@@ -1,5 +1,7 @@
 package metaapi
 
+import "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+
 type SendMessageBasic struct {
 	MessagingProduct string `json:"messaging_product"`
 	RecipientType    string `json:"recipient_type"`
@@ -12,16 +14,10 @@ type Texto struct {
 	Body       string `json:"body"`
 }
 
-// Se encarga de devolver un body listo para ser enviado por api de whatsapp (Se quita el tercer dígito del numero.)
+// Se encarga de devolver un body listo para ser enviado por api de whatsapp (ver FormatRecipient)
 func NewSendMessageWhatsappBasic(messsage, numberPhone string) (messageBasic SendMessageBasic) {
-	// Verificar que el número de teléfono tenga al menos 3 dígitos
-	if len(numberPhone) >= 3 {
-		// Remover el tercer dígito del número de teléfono
-		numberPhone = numberPhone[:2] + numberPhone[3:]
-	}
-
 	messageBasic.MessagingProduct = "whatsapp"
-	messageBasic.To = numberPhone
+	messageBasic.To = FormatRecipient(numberPhone)
 	messageBasic.Type = "text"
 	messageBasic.RecipientType = "individual"
 	messageBasic.Text.PreviewURL = false
@@ -29,3 +25,9 @@ func NewSendMessageWhatsappBasic(messsage, numberPhone string) (messageBasic Sen
 
 	return
 }
+
+// FormatRecipient devuelve el número (E.164) como lo espera la API de WhatsApp: sin el + y, en los celulares de
+// Argentina, sin el 9 (ver phone.Number.WhatsAppRecipient)
+func FormatRecipient(numberPhone string) string {
+	return phone.Recipient(numberPhone)
+}
@@ -0,0 +1,71 @@
+package metaapi
+
+// SendMessageInteractive es un mensaje con botones de respuesta (button) o con un menú de opciones (list)
+type SendMessageInteractive struct {
+	MessagingProduct string      `json:"messaging_product"`
+	RecipientType    string      `json:"recipient_type"`
+	To               string      `json:"to"`
+	Type             string      `json:"type"`
+	Interactive      Interactive `json:"interactive"`
+}
+
+type Interactive struct {
+	Type   string             `json:"type"` // button o list
+	Header *InteractiveHeader `json:"header,omitempty"`
+	Body   InteractiveText    `json:"body"`
+	Footer *InteractiveText   `json:"footer,omitempty"`
+	Action InteractiveAction  `json:"action"`
+}
+
+type InteractiveHeader struct {
+	Type string `json:"type"` // Solo se usa text
+	Text string `json:"text"`
+}
+
+type InteractiveText struct {
+	Text string `json:"text"`
+}
+
+// InteractiveAction lleva Buttons en los mensajes button, o Button (texto del botón que abre el menú) y Sections en los list
+type InteractiveAction struct {
+	Buttons  []InteractiveButton  `json:"buttons,omitempty"`
+	Button   string               `json:"button,omitempty"`
+	Sections []InteractiveSection `json:"sections,omitempty"`
+}
+
+type InteractiveButton struct {
+	Type  string           `json:"type"` // reply
+	Reply InteractiveReply `json:"reply"`
+}
+
+type InteractiveReply struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type InteractiveSection struct {
+	Title string           `json:"title,omitempty"`
+	Rows  []InteractiveRow `json:"rows"`
+}
+
+type InteractiveRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// NewSendMessageInteractive arma el body para enviar un mensaje interactivo
+func NewSendMessageInteractive(interactive Interactive, numberPhone string) SendMessageInteractive {
+	for i := range interactive.Action.Buttons {
+		if interactive.Action.Buttons[i].Type == "" {
+			interactive.Action.Buttons[i].Type = "reply"
+		}
+	}
+	return SendMessageInteractive{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               FormatRecipient(numberPhone),
+		Type:             "interactive",
+		Interactive:      interactive,
+	}
+}
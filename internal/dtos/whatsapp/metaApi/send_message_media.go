@@ -0,0 +1,45 @@
+package metaapi
+
+// SendMessageMedia es un mensaje con un archivo: image, document, audio, video o sticker
+type SendMessageMedia struct {
+	MessagingProduct string `json:"messaging_product"`
+	RecipientType    string `json:"recipient_type"`
+	To               string `json:"to"`
+	Type             string `json:"type"`
+	Image            *Media `json:"image,omitempty"`
+	Document         *Media `json:"document,omitempty"`
+	Audio            *Media `json:"audio,omitempty"`
+	Video            *Media `json:"video,omitempty"`
+	Sticker          *Media `json:"sticker,omitempty"`
+}
+
+// Media referencia un archivo subido a WhatsApp (ID) o una URL pública (Link)
+type Media struct {
+	ID       string `json:"id,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Caption  string `json:"caption,omitempty"`  // Solo image, video y document
+	Filename string `json:"filename,omitempty"` // Solo document
+}
+
+// NewSendMessageMedia arma el body para enviar un archivo del tipo indicado
+func NewSendMessageMedia(mediaType string, media Media, numberPhone string) SendMessageMedia {
+	message := SendMessageMedia{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               FormatRecipient(numberPhone),
+		Type:             mediaType,
+	}
+	switch mediaType {
+	case "image":
+		message.Image = &media
+	case "document":
+		message.Document = &media
+	case "audio":
+		message.Audio = &media
+	case "video":
+		message.Video = &media
+	case "sticker":
+		message.Sticker = &media
+	}
+	return message
+}
@@ -17,30 +17,46 @@ type Language struct {
 	Code string `json:"code"`
 }
 
+// Component es un bloque del template (header, body o button) con sus parámetros. Los botones llevan
+// sub_type (quick_reply, url) e index.
 type Component struct {
 	Type       string      `json:"type"`
+	SubType    string      `json:"sub_type,omitempty"`
+	Index      string      `json:"index,omitempty"`
 	Parameters []Parameter `json:"parameters"`
 }
 
+// Parameter es un valor del template. Según Type se completa Text, Payload, Currency, DateTime o el media.
 type Parameter struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	Payload  string    `json:"payload,omitempty"` // Botones quick_reply
+	Currency *Currency `json:"currency,omitempty"`
+	DateTime *DateTime `json:"date_time,omitempty"`
+	Image    *Media    `json:"image,omitempty"`
+	Document *Media    `json:"document,omitempty"`
+	Video    *Media    `json:"video,omitempty"`
 }
 
-func NewBodyWhatsappTemplateCRUD(summary, startTime, endTime, contact, eventCode, numberPhone, templateName string) SendMessageTemplate {
-	// Remover tercer dígito del número de teléfono
-	if len(numberPhone) >= 3 {
-		numberPhone = numberPhone[:2] + numberPhone[3:]
-	}
+type Currency struct {
+	FallbackValue string `json:"fallback_value"`
+	Code          string `json:"code"`
+	Amount1000    int64  `json:"amount_1000"`
+}
 
+type DateTime struct {
+	FallbackValue string `json:"fallback_value"`
+}
+
+func NewBodyWhatsappTemplateCRUD(summary, startTime, endTime, contact, eventCode, numberPhone, templateName, languageCode string) SendMessageTemplate {
 	return SendMessageTemplate{
 		MessagingProduct: "whatsapp",
-		To:               numberPhone,
+		To:               FormatRecipient(numberPhone),
 		Type:             "template",
 		Template: Template{
 			Name: templateName,
 			Language: Language{
-				Code: "es",
+				Code: languageCode,
 			},
 			Components: []Component{
 				{
@@ -57,3 +73,45 @@ func NewBodyWhatsappTemplateCRUD(summary, startTime, endTime, contact, eventCode
 		},
 	}
 }
+
+// NewBodyWhatsappTemplateRecordatorio arma el recordatorio que se le envía al contacto antes del evento
+func NewBodyWhatsappTemplateRecordatorio(summary, startTime, eventCode, numberPhone, templateName, languageCode string) SendMessageTemplate {
+	return SendMessageTemplate{
+		MessagingProduct: "whatsapp",
+		To:               FormatRecipient(numberPhone),
+		Type:             "template",
+		Template: Template{
+			Name: templateName,
+			Language: Language{
+				Code: languageCode,
+			},
+			Components: []Component{
+				{
+					Type: "body",
+					Parameters: []Parameter{
+						{Type: "text", Text: summary},
+						{Type: "text", Text: startTime},
+						{Type: "text", Text: eventCode},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewSendMessageTemplate arma el body de un template con componentes arbitrarios
+func NewSendMessageTemplate(templateName, languageCode string, components []Component, numberPhone string) SendMessageTemplate {
+	if components == nil {
+		components = []Component{}
+	}
+	return SendMessageTemplate{
+		MessagingProduct: "whatsapp",
+		To:               FormatRecipient(numberPhone),
+		Type:             "template",
+		Template: Template{
+			Name:       templateName,
+			Language:   Language{Code: languageCode},
+			Components: components,
+		},
+	}
+}
@@ -4,4 +4,7 @@ const (
 	TemplateEventoCreado     = "evento_creado"
 	TemplateEventoModificado = "evento_modificado"
 	TemplateEventoCancelado  = "evento_eliminado"
+
+	// Recordatorio al contacto antes del evento, con botones de respuesta rápida "Confirmo" y "Cancelar"
+	TemplateRecordatorioEvento = "recordatorio_evento"
 )
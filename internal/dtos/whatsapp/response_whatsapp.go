@@ -51,24 +51,57 @@ type ResponseBasicWhatsapp struct {
 }
 
 type ResponseSent struct {
-	Statuses []struct {
-		ID           string `json:"id"`
-		Status       string `json:"status"`
-		Timestamp    string `json:"timestamp"`
-		RecipientID  string `json:"recipient_id"`
-		Conversation struct {
-			ID                  string `json:"id"`
-			ExpirationTimestamp string `json:"expiration_timestamp"`
-			Origin              struct {
-				Type string `json:"type"`
-			} `json:"origin"`
-		} `json:"conversation"`
-		Pricing struct {
-			Billable     bool   `json:"billable"`
-			PricingModel string `json:"pricing_model"`
-			Category     string `json:"category"`
-		} `json:"pricing"`
-	} `json:"statuses"`
+	Statuses []Status `json:"statuses"`
+}
+
+// Estados que informa WhatsApp para un mensaje saliente
+const (
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusRead      = "read"
+	StatusFailed    = "failed"
+)
+
+// Status es el callback de estado (sent, delivered, read, failed) de un mensaje enviado
+type Status struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	Timestamp    string `json:"timestamp"`
+	RecipientID  string `json:"recipient_id"`
+	Conversation struct {
+		ID                  string `json:"id"`
+		ExpirationTimestamp string `json:"expiration_timestamp"`
+		Origin              struct {
+			Type string `json:"type"`
+		} `json:"origin"`
+	} `json:"conversation"`
+	Pricing struct {
+		Billable     bool   `json:"billable"`
+		PricingModel string `json:"pricing_model"`
+		Category     string `json:"category"`
+	} `json:"pricing"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// SendMessageResponse es la respuesta de POST /{phone-number-id}/messages
+type SendMessageResponse struct {
+	MessagingProduct string `json:"messaging_product"`
+	Contacts         []struct {
+		Input string `json:"input"`
+		WaID  string `json:"wa_id"`
+	} `json:"contacts"`
+	Messages []struct {
+		ID            string `json:"id"`
+		MessageStatus string `json:"message_status,omitempty"`
+	} `json:"messages"`
+}
+
+// MessageID es el wamid que asignó WhatsApp al mensaje enviado
+func (r SendMessageResponse) MessageID() string {
+	if len(r.Messages) == 0 {
+		return ""
+	}
+	return r.Messages[0].ID
 }
 
 /* Objeto general que captura las respuestas de la API WPP */
@@ -92,11 +125,35 @@ type InteractiveListReply struct {
 	Description string `json:"description"`
 }
 
+type InteractiveButtonReply struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
 type InteractiveMessage struct {
-	Type      string               `json:"type"`
-	ListReply InteractiveListReply `json:"list_reply"`
+	Type        string                 `json:"type"`
+	ListReply   InteractiveListReply   `json:"list_reply"`
+	ButtonReply InteractiveButtonReply `json:"button_reply"`
 }
 
+// Tipos de mensajes que envía la Cloud API de WhatsApp en el webhook
+const (
+	MessageTypeText        = "text"
+	MessageTypeImage       = "image"
+	MessageTypeAudio       = "audio"
+	MessageTypeVideo       = "video"
+	MessageTypeDocument    = "document"
+	MessageTypeSticker     = "sticker"
+	MessageTypeLocation    = "location"
+	MessageTypeContacts    = "contacts"
+	MessageTypeInteractive = "interactive"
+	MessageTypeButton      = "button"
+	MessageTypeReaction    = "reaction"
+	MessageTypeOrder       = "order"
+	MessageTypeSystem      = "system"
+	MessageTypeUnsupported = "unsupported"
+)
+
 type Message struct {
 	Context     map[string]string  `json:"context"`
 	From        string             `json:"from"`
@@ -105,16 +162,112 @@ type Message struct {
 	Type        string             `json:"type"`
 	Interactive InteractiveMessage `json:"interactive"`
 	Text        Tex                `json:"text"`
+	Image       *Media             `json:"image,omitempty"`
+	Audio       *Media             `json:"audio,omitempty"`
+	Video       *Media             `json:"video,omitempty"`
+	Document    *Media             `json:"document,omitempty"`
+	Sticker     *Media             `json:"sticker,omitempty"`
+	Location    *Location          `json:"location,omitempty"`
+	Contacts    []SharedContact    `json:"contacts,omitempty"`
+	Button      *Button            `json:"button,omitempty"`
+	Reaction    *Reaction          `json:"reaction,omitempty"`
+	Order       *Order             `json:"order,omitempty"`
+	System      *System            `json:"system,omitempty"`
+	Errors      []Error            `json:"errors,omitempty"`
 }
 type Tex struct {
 	Body string `json:"body"`
 }
 
+// Media representa image, audio, video, document y sticker. El contenido se descarga con el ID por la Graph API.
+type Media struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Sha256   string `json:"sha256"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"` // Solo document
+	Voice    bool   `json:"voice,omitempty"`    // Solo audio: true si es una nota de voz
+	Animated bool   `json:"animated,omitempty"` // Solo sticker
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+	URL       string  `json:"url,omitempty"`
+}
+
+type SharedContact struct {
+	Name struct {
+		FormattedName string `json:"formatted_name"`
+		FirstName     string `json:"first_name,omitempty"`
+		LastName      string `json:"last_name,omitempty"`
+	} `json:"name"`
+	Phones []struct {
+		Phone string `json:"phone"`
+		Type  string `json:"type,omitempty"`
+		WaID  string `json:"wa_id,omitempty"`
+	} `json:"phones,omitempty"`
+	Emails []struct {
+		Email string `json:"email"`
+		Type  string `json:"type,omitempty"`
+	} `json:"emails,omitempty"`
+}
+
+// Button es la respuesta a un botón quick reply de un template
+type Button struct {
+	Payload string `json:"payload"`
+	Text    string `json:"text"`
+}
+
+type Reaction struct {
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+}
+
+type Order struct {
+	CatalogID    string `json:"catalog_id"`
+	Text         string `json:"text,omitempty"`
+	ProductItems []struct {
+		ProductRetailerID string  `json:"product_retailer_id"`
+		Quantity          int     `json:"quantity"`
+		ItemPrice         float64 `json:"item_price"`
+		Currency          string  `json:"currency"`
+	} `json:"product_items"`
+}
+
+type System struct {
+	Body    string `json:"body"`
+	Type    string `json:"type"`
+	NewWaID string `json:"new_wa_id,omitempty"`
+}
+
+type Error struct {
+	Code      int    `json:"code"`
+	Title     string `json:"title"`
+	Message   string `json:"message,omitempty"`
+	ErrorData struct {
+		Details string `json:"details"`
+	} `json:"error_data,omitempty"`
+}
+
+// MediaURLResponse es la respuesta de GET /{media-id} en la Graph API
+type MediaURLResponse struct {
+	MessagingProduct string `json:"messaging_product"`
+	URL              string `json:"url"`
+	MimeType         string `json:"mime_type"`
+	Sha256           string `json:"sha256"`
+	FileSize         int64  `json:"file_size"`
+	ID               string `json:"id"`
+}
+
 type Value struct {
 	MessagingProduct string    `json:"messaging_product"`
 	Metadata         Metadata  `json:"metadata"`
 	Contacts         []Contact `json:"contacts"`
 	Messages         []Message `json:"messages"`
+	Statuses         []Status  `json:"statuses"`
 }
 
 type Change struct {
@@ -12,6 +12,6 @@ type UserContactInfo struct {
 type InteractionSummary struct {
 	NumberPhoneEntity entities.NumberPhone
 	NumberPhoneID     int64
-	NumberPhone       int64
+	NumberPhone       string
 	Contacts          []UserContactInfo
 }
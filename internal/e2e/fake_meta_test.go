@@ -0,0 +1,153 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+)
+
+// Templates por página en GET /{version}/{waba_id}/message_templates, chico para que la sincronización pagine
+const templatesPageSize = 2
+
+// sentMessage es un mensaje que la API recibió en POST /{version}/{phone_number_id}/messages
+type sentMessage struct {
+	PhoneNumberID string
+	Token         string
+	To            string
+	Type          string
+	Text          string // text.body de los mensajes de texto
+	Template      string // template.name de los templates
+	Parameters    []string
+}
+
+// fakeMeta reemplaza a la Graph API de Meta (la URL del clients.WhatsappClient): guarda los mensajes enviados y responde con un wamid.
+// También lista, paginados, los templates cargados con SetTemplates.
+type fakeMeta struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	sent          []sentMessage
+	templates     []metaapi.MessageTemplate
+	templatePages int
+}
+
+func newFakeMeta(t *testing.T) *fakeMeta {
+	t.Helper()
+	meta := &fakeMeta{}
+	meta.server = httptest.NewServer(http.HandlerFunc(meta.handle))
+	t.Cleanup(meta.server.Close)
+	return meta
+}
+
+func (m *fakeMeta) handle(w http.ResponseWriter, r *http.Request) {
+	// /{version}/{phone_number_id}/messages
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "message_templates" {
+		m.listTemplates(w, r)
+		return
+	}
+	if r.Method != http.MethodPost || len(parts) != 3 || parts[2] != "messages" {
+		http.Error(w, `{"error":{"message":"unsupported endpoint","code":100}}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		To   string `json:"to"`
+		Type string `json:"type"`
+		Text struct {
+			Body string `json:"body"`
+		} `json:"text"`
+		Template struct {
+			Name       string `json:"name"`
+			Components []struct {
+				Parameters []struct {
+					Text string `json:"text"`
+				} `json:"parameters"`
+			} `json:"components"`
+		} `json:"template"`
+	}
+	raw, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(raw, &body); err != nil {
+		http.Error(w, `{"error":{"message":"invalid body","code":100}}`, http.StatusBadRequest)
+		return
+	}
+
+	message := sentMessage{
+		PhoneNumberID: parts[1],
+		Token:         strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		To:            body.To,
+		Type:          body.Type,
+		Text:          body.Text.Body,
+		Template:      body.Template.Name,
+	}
+	for _, component := range body.Template.Components {
+		for _, parameter := range component.Parameters {
+			message.Parameters = append(message.Parameters, parameter.Text)
+		}
+	}
+
+	m.mu.Lock()
+	m.sent = append(m.sent, message)
+	wamid := fmt.Sprintf("wamid.sent.%d", len(m.sent))
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"messaging_product":"whatsapp","contacts":[{"input":%q,"wa_id":%q}],"messages":[{"id":%q}]}`, body.To, body.To, wamid)
+}
+
+// Sent devuelve los mensajes enviados hasta ahora
+func (m *fakeMeta) Sent() []sentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sentMessage(nil), m.sent...)
+}
+
+// SentTo devuelve los mensajes enviados a un número (sin el +, como lo manda la API)
+func (m *fakeMeta) SentTo(to string) []sentMessage {
+	var messages []sentMessage
+	for _, message := range m.Sent() {
+		if message.To == to {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// listTemplates responde una página de templates; el cursor after es la posición del primero
+func (m *fakeMeta) listTemplates(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templatePages++
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	start = min(start, len(m.templates))
+	end := min(start+templatesPageSize, len(m.templates))
+	page := metaapi.MessageTemplatesPage{Data: append([]metaapi.MessageTemplate{}, m.templates[start:end]...)}
+	if end < len(m.templates) {
+		page.Paging.Next = fmt.Sprintf("%s%s?after=%d", m.server.URL, r.URL.Path, end)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, page)
+}
+
+// SetTemplates carga los templates del WhatsApp Business Account
+func (m *fakeMeta) SetTemplates(templates ...metaapi.MessageTemplate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = templates
+}
+
+// TemplatePages devuelve cuántas páginas de templates se pidieron
+func (m *fakeMeta) TemplatePages() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.templatePages
+}
@@ -0,0 +1,310 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// toolCall es una función que el assistant pide ejecutar en un run
+type toolCall struct {
+	Name      string
+	Arguments string
+}
+
+// assistantTurn es lo que hace el assistant en el próximo run: pide las tools de cada ronda (el run queda en
+// requires_action hasta que se envían sus resultados) y después responde Reply
+type assistantTurn struct {
+	Rounds [][]toolCall
+	Reply  string
+}
+
+// toolOutput es el resultado de una tool que la API envió con submit_tool_outputs
+type toolOutput struct {
+	Name   string
+	Output string
+}
+
+type fakeRun struct {
+	id       string
+	threadID string
+	turn     assistantTurn
+	round    int
+	calls    map[string]string // tool_call_id -> nombre de la tool
+}
+
+type fakeThreadMessage struct {
+	id   string
+	role string
+	text string
+}
+
+// fakeOpenAI reemplaza a la Assistants API de OpenAI (OPENAI_API_URL). Los runs siguen el guion cargado con Script,
+// en orden; un run sin guion falla el test.
+type fakeOpenAI struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	nextID      int
+	script      []assistantTurn
+	threads     map[string][]fakeThreadMessage
+	runs        map[string]*fakeRun
+	runsCreated int
+	outputs     []toolOutput
+	down        bool // Responde 503 a todo, como durante una caída de OpenAI
+	requests    int
+}
+
+func newFakeOpenAI(t *testing.T) *fakeOpenAI {
+	t.Helper()
+	openAI := &fakeOpenAI{t: t, threads: map[string][]fakeThreadMessage{}, runs: map[string]*fakeRun{}}
+	openAI.server = httptest.NewServer(http.HandlerFunc(openAI.handle))
+	t.Cleanup(openAI.server.Close)
+	return openAI
+}
+
+// Script agrega los turnos que va a seguir el assistant en los próximos runs
+func (o *fakeOpenAI) Script(turns ...assistantTurn) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.script = append(o.script, turns...)
+}
+
+// RunsCreated es la cantidad de runs que se crearon
+func (o *fakeOpenAI) RunsCreated() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.runsCreated
+}
+
+// ToolOutputs devuelve los resultados de tools recibidos hasta ahora
+func (o *fakeOpenAI) ToolOutputs() []toolOutput {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]toolOutput(nil), o.outputs...)
+}
+
+// SetDown simula una caída de la API (o su recuperación)
+func (o *fakeOpenAI) SetDown(down bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.down = down
+}
+
+// Requests es la cantidad de requests que llegaron a la API
+func (o *fakeOpenAI) Requests() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.requests
+}
+
+// UserMessages devuelve los textos que la API agregó como usuario a los threads
+func (o *fakeOpenAI) UserMessages() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var texts []string
+	for _, messages := range o.threads {
+		for _, message := range messages {
+			if message.role == "user" {
+				texts = append(texts, message.text)
+			}
+		}
+	}
+	return texts
+}
+
+func (o *fakeOpenAI) id(prefix string) string {
+	o.nextID++
+	return fmt.Sprintf("%s_%d", prefix, o.nextID)
+}
+
+func (o *fakeOpenAI) handle(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	w.Header().Set("Content-Type", "application/json")
+
+	o.requests++
+	if o.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"error":{"message":"The server is overloaded or not ready yet.","type":"server_error"}}`)
+		return
+	}
+
+	switch {
+	// POST /threads
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "threads":
+		threadID := o.id("thread")
+		o.threads[threadID] = nil
+		writeJSON(w, map[string]interface{}{"id": threadID, "object": "thread"})
+
+	// POST /threads/{id}/messages
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "messages":
+		var body struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		if !o.decode(w, r, &body) || !o.threadExists(w, parts[1]) {
+			return
+		}
+		var text []string
+		for _, content := range body.Content {
+			text = append(text, content.Text)
+		}
+		message := fakeThreadMessage{id: o.id("msg"), role: body.Role, text: strings.Join(text, "\n")}
+		o.threads[parts[1]] = append(o.threads[parts[1]], message)
+		writeJSON(w, map[string]interface{}{"id": message.id, "object": "thread.message"})
+
+	// GET /threads/{id}/messages: del más nuevo al más viejo, como la API por defecto
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "messages":
+		if !o.threadExists(w, parts[1]) {
+			return
+		}
+		messages := o.threads[parts[1]]
+		var data []map[string]interface{}
+		for i := len(messages) - 1; i >= 0; i-- {
+			data = append(data, map[string]interface{}{
+				"id":      messages[i].id,
+				"role":    messages[i].role,
+				"content": []map[string]interface{}{{"type": "text", "text": map[string]string{"value": messages[i].text}}},
+			})
+		}
+		firstID := ""
+		if len(data) > 0 {
+			firstID = data[0]["id"].(string)
+		}
+		writeJSON(w, map[string]interface{}{"object": "list", "data": data, "first_id": firstID})
+
+	// POST /threads/{id}/runs
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "runs":
+		if !o.threadExists(w, parts[1]) {
+			return
+		}
+		if len(o.script) == 0 {
+			o.t.Errorf("fake OpenAI: run created on %s without a scripted turn", parts[1])
+			http.Error(w, `{"error":{"message":"no scripted turn"}}`, http.StatusInternalServerError)
+			return
+		}
+		run := &fakeRun{id: o.id("run"), threadID: parts[1], turn: o.script[0], calls: map[string]string{}}
+		o.script = o.script[1:]
+		o.runs[run.id] = run
+		o.runsCreated++
+		o.completeIfDone(run)
+		writeJSON(w, o.runJSON(run))
+
+	// GET /threads/{id}/runs
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "runs":
+		var data []map[string]interface{}
+		for _, run := range o.runs {
+			if run.threadID == parts[1] {
+				data = append(data, o.runJSON(run))
+			}
+		}
+		writeJSON(w, map[string]interface{}{"object": "list", "data": data})
+
+	// GET /threads/{id}/runs/{run_id}
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[2] == "runs":
+		run, ok := o.runs[parts[3]]
+		if !ok {
+			http.Error(w, `{"error":{"message":"run not found"}}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, o.runJSON(run))
+
+	// POST /threads/{id}/runs/{run_id}/submit_tool_outputs
+	case r.Method == http.MethodPost && len(parts) == 5 && parts[4] == "submit_tool_outputs":
+		run, ok := o.runs[parts[3]]
+		if !ok || run.round >= len(run.turn.Rounds) {
+			http.Error(w, `{"error":{"message":"run does not require action"}}`, http.StatusBadRequest)
+			return
+		}
+		var body struct {
+			ToolOutputs []struct {
+				ToolCallID string `json:"tool_call_id"`
+				Output     string `json:"output"`
+			} `json:"tool_outputs"`
+		}
+		if !o.decode(w, r, &body) {
+			return
+		}
+		if len(body.ToolOutputs) != len(run.turn.Rounds[run.round]) {
+			o.t.Errorf("fake OpenAI: %d tool outputs for %d tool calls", len(body.ToolOutputs), len(run.turn.Rounds[run.round]))
+		}
+		for _, output := range body.ToolOutputs {
+			o.outputs = append(o.outputs, toolOutput{Name: run.calls[output.ToolCallID], Output: output.Output})
+		}
+		run.round++
+		o.completeIfDone(run)
+		writeJSON(w, o.runJSON(run))
+
+	// POST /threads/{id}: vector store del thread
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[0] == "threads":
+		writeJSON(w, map[string]interface{}{"id": parts[1], "object": "thread"})
+
+	default:
+		o.t.Errorf("fake OpenAI: unexpected %s %s", r.Method, r.URL.Path)
+		http.Error(w, `{"error":{"message":"unsupported endpoint"}}`, http.StatusNotFound)
+	}
+}
+
+// completeIfDone agrega la respuesta del assistant al thread cuando el run ya no pide tools
+func (o *fakeOpenAI) completeIfDone(run *fakeRun) {
+	if run.round < len(run.turn.Rounds) {
+		return
+	}
+	o.threads[run.threadID] = append(o.threads[run.threadID], fakeThreadMessage{id: o.id("msg"), role: "assistant", text: run.turn.Reply})
+}
+
+func (o *fakeOpenAI) runJSON(run *fakeRun) map[string]interface{} {
+	response := map[string]interface{}{"id": run.id, "object": "thread.run", "thread_id": run.threadID, "model": "gpt-4o-mini"}
+	if run.round >= len(run.turn.Rounds) {
+		response["status"] = "completed"
+		response["usage"] = map[string]int{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120}
+		return response
+	}
+
+	var calls []map[string]interface{}
+	for i, call := range run.turn.Rounds[run.round] {
+		callID := fmt.Sprintf("call_%s_%d_%d", run.id, run.round, i)
+		run.calls[callID] = call.Name
+		calls = append(calls, map[string]interface{}{
+			"id":       callID,
+			"type":     "function",
+			"function": map[string]string{"name": call.Name, "arguments": call.Arguments},
+		})
+	}
+	response["status"] = "requires_action"
+	response["required_action"] = map[string]interface{}{
+		"type":                "submit_tool_outputs",
+		"submit_tool_outputs": map[string]interface{}{"tool_calls": calls},
+	}
+	return response
+}
+
+func (o *fakeOpenAI) threadExists(w http.ResponseWriter, threadID string) bool {
+	if _, ok := o.threads[threadID]; !ok {
+		http.Error(w, `{"error":{"message":"thread not found"}}`, http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func (o *fakeOpenAI) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, `{"error":{"message":"invalid body"}}`, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,233 @@
+// Package e2e prueba el circuito completo de un mensaje de WhatsApp (webhook → contacto → thread → run → tools →
+// evento → notificación) contra fakes de la Graph API de Meta y de la Assistants API de OpenAI, sobre una base SQLite
+// en memoria.
+package e2e
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/api/middlewares"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/controllers"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/routes"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	testAppSecret        = "e2e-app-secret"
+	testMetaToken        = "e2e-meta-token"
+	testWhatsappNumberID = 1001
+	testNotifyNumber     = "+5491100000099"
+	testBreakerCooldown  = 200 * time.Millisecond
+)
+
+// harness es la API armada como en api/main.go, con un bussiness, un assistant y un número de WhatsApp
+type harness struct {
+	t      *testing.T
+	app    *fiber.App
+	db     *gorm.DB
+	repos  *repositories.Repositories
+	meta   *fakeMeta
+	openAI *fakeOpenAI
+
+	templates *services.TemplatesService
+
+	assistant   entities.Assistant
+	numberPhone entities.NumberPhone
+	nextMessage int
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	h := &harness{t: t, meta: newFakeMeta(t), openAI: newFakeOpenAI(t)}
+
+	// Ventana de debounce corta: alcanza para agrupar los reenvíos simultáneos sin demorar los tests
+	t.Setenv("MESSAGE_DEBOUNCE_MS", "100")
+
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	h.db = db
+	h.repos = sqlite_client.NewRepositories(db)
+
+	h.assistant = entities.Assistant{ID: 1, BussinessID: 1, Name: "Consultorio", OpenaiAssistantsID: "asst_e2e", Model: "gpt-4o-mini",
+		OpeningDays: 127, WorkingHours: "09:00-18:00", EventDuration: 30, EventType: "turno", EventCountPerDay: 1, SlotCapacity: 1, Active: true}
+	h.numberPhone = entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "e2e-number", TokenPermanent: testMetaToken,
+		WhatsappNumberPhoneId: testWhatsappNumberID, NumberPhoneToNotify: testNotifyNumber, AppSecret: testAppSecret}
+	records := []interface{}{
+		&entities.Bussines{ID: 1, Name: "Consultorio", Address: "calle 123"},
+		&h.assistant,
+		&h.numberPhone,
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+
+	h.app = h.buildApp()
+	return h
+}
+
+// buildApp instancia los servicios y las rutas igual que api/main.go (sin MinIO, Google Calendar ni Telegram)
+func (h *harness) buildApp() *fiber.App {
+	repos := h.repos
+	// Sin esperas entre reintentos; el circuit breaker se abre con dos fallas y se prueba de nuevo enseguida
+	openAIClient := services.NewOpenAIAssistantService(clients.NewOpenAIClient(clients.OpenAIConfig{
+		BaseURL:          h.openAI.server.URL,
+		APIKey:           "e2e-openai-key",
+		BaseBackoff:      time.Millisecond,
+		MaxBackoff:       time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCooldown:  testBreakerCooldown,
+	}))
+	utilService := services.NewUtilService()
+	whatsappClient := clients.NewWhatsappClient(h.meta.server.URL, "v21.0")
+
+	conversationStream := services.NewConversationStream(repos.Messages, repos.NumberPhones)
+	messagesService := services.NewMessagesService(repos.Messages, repos.MessageStatuses, conversationStream)
+	numberPhonesService := services.NewNumberPhonesService(repos.NumberPhones)
+	fileService := services.NewFileService(repos.Files, nil)
+	toolRegistry := services.NewToolRegistry()
+	assistantService := services.NewAssistantService(repos.Assistants, fileService, openAIClient, toolRegistry)
+	eventsService := services.NewEventsService(repos.Events, *utilService)
+	closuresService := services.NewClosuresService(repos.AssistantClosures, assistantService)
+	availabilityService := services.NewAvailabilityService(assistantService, closuresService, repos.Events, nil, nil)
+	availabilityService.RegisterTools(toolRegistry)
+	threadService := services.NewThreadService(repos.Threads, openAIClient)
+	llmProviders := services.NewLLMProviders(map[string]services.LLMProvider{
+		dtos.LLMProviderOpenAIAssistants: services.NewOpenAIAssistantsProvider(openAIClient, threadService, fileService),
+	})
+	handoffService := services.NewHandoffService(repos.Contacts, repos.Messages, conversationStream)
+	templatesService := services.NewTemplatesService(repos.MessageTemplates, repos.AssistantTemplates, repos.NumberPhones, assistantService, whatsappClient)
+	h.templates = templatesService
+	usageService := services.NewUsageService(repos.TokenUsages, repos.Bussiness, repos.Contacts, services.LLMPrices{"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60}})
+	whatsappService := services.NewWhatsappService(nil, nil, openAIClient, utilService, numberPhonesService, repos.Messages, assistantService, nil, nil, nil,
+		eventsService, threadService, fileService, messagesService, llmProviders, toolRegistry, availabilityService, repos.EventReminders, handoffService,
+		templatesService, usageService, repos.Contacts, whatsappClient)
+
+	// Los workers de la cola procesan las notificaciones del webhook como en producción
+	inboundJobsService := services.NewInboundJobsService(repos.InboundJobs, whatsappService)
+	inboundJobsService.Start()
+
+	middleware := middlewares.MiddlewareManager{TenantResolver: services.NewTenantService(repos.Tenant).ResolveScope}
+	app := fiber.New()
+	routes.Setup(app, &middleware,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		controllers.NewWhatsappController(whatsappService, inboundJobsService),
+		nil, nil, nil, nil, nil, nil, nil, nil,
+		numberPhonesService,
+		nil, nil, nil, nil, nil, nil, nil, nil)
+	return app
+}
+
+// receive simula que el contacto escribió text: Meta envía la notificación al webhook. Devuelve el wamid del mensaje.
+func (h *harness) receive(from, profileName, text string) string {
+	h.t.Helper()
+	h.nextMessage++
+	messageID := fmt.Sprintf("wamid.received.%d", h.nextMessage)
+	h.deliver(h.textPayload(from, profileName, messageID, text))
+	return messageID
+}
+
+// textPayload arma la notificación de un mensaje de texto tal como la envía Meta
+func (h *harness) textPayload(from, profileName, messageID, text string) string {
+	return fmt.Sprintf(`{"object":"whatsapp_business_account","entry":[{"id":"waba-1","changes":[{"field":"messages","value":{
+		"messaging_product":"whatsapp",
+		"metadata":{"display_phone_number":"5491100000001","phone_number_id":"%d"},
+		"contacts":[{"profile":{"name":%q},"wa_id":%q}],
+		"messages":[{"from":%q,"id":%q,"timestamp":"%d","type":"text","text":{"body":%q}}]}}]}]}`,
+		testWhatsappNumberID, profileName, from, from, messageID, time.Now().Unix(), text)
+}
+
+// deliver envía la notificación al webhook firmada con el app secret del número, como Meta
+func (h *harness) deliver(payload string) {
+	h.t.Helper()
+	mac := hmac.New(sha256.New, []byte(testAppSecret))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/webhook", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middlewares.HeaderHubSignature256, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := h.app.Test(req, -1)
+	if err != nil {
+		h.t.Fatalf("POST /api/webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		h.t.Fatalf("POST /api/webhook: status %d", resp.StatusCode)
+	}
+}
+
+// waitForJobs espera a que los workers terminen todas las notificaciones recibidas. Falla si alguna dio error.
+func (h *harness) waitForJobs() {
+	h.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var jobs []entities.InboundJob
+		if err := h.db.Find(&jobs).Error; err != nil {
+			h.t.Fatalf("listing inbound jobs: %v", err)
+		}
+		finished := true
+		for _, job := range jobs {
+			if job.Attempts > 0 || job.Status == entities.InboundJobStatusDead {
+				h.t.Fatalf("inbound job %d failed: %s", job.ID, job.LastError)
+			}
+			if job.Status != entities.InboundJobStatusDone {
+				finished = false
+			}
+		}
+		if finished {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("inbound jobs still running after 10s")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// contact busca el contacto por su número en E.164
+func (h *harness) contact(number string) entities.Contact {
+	h.t.Helper()
+	contact, err := h.repos.Contacts.FindByNumber(h.numberPhone.ID, number)
+	if err != nil {
+		h.t.Fatalf("contact %s: %v", number, err)
+	}
+	return contact
+}
+
+// events devuelve los eventos vigentes del contacto
+func (h *harness) events(contactID int64) []entities.Events {
+	h.t.Helper()
+	var events []entities.Events
+	if err := h.db.Where("contacts_id = ?", contactID).Order("id").Find(&events).Error; err != nil {
+		h.t.Fatalf("listing events: %v", err)
+	}
+	return events
+}
+
+// messages devuelve los mensajes guardados de la conversación con el contacto, en orden
+func (h *harness) messages(contactID int64) []entities.Message {
+	h.t.Helper()
+	var messages []entities.Message
+	if err := h.db.Where("contacts_id = ?", contactID).Order("id").Find(&messages).Error; err != nil {
+		h.t.Fatalf("listing messages: %v", err)
+	}
+	return messages
+}
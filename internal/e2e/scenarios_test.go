@@ -0,0 +1,314 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+)
+
+// Contacto de los escenarios: el wa_id que manda Meta y cómo queda guardado
+const (
+	contactWaID = "5493515550001"
+	contactE164 = "+5493515550001"
+)
+
+// meetingDate es una fecha y hora dentro del horario del assistant, en el formato que usan las tools
+func meetingDate(days int, clock string) string {
+	return time.Now().AddDate(0, 0, days).Format("2006-01-02") + "T" + clock + ":00"
+}
+
+// book agenda un turno a través de la conversación y devuelve el evento creado
+func (h *harness) book(date string) entities.Events {
+	h.t.Helper()
+	h.openAI.Script(assistantTurn{
+		Rounds: [][]toolCall{{{Name: "createMeeting", Arguments: fmt.Sprintf(`{"user_name":"Ana Gómez","user_email":"Ana@Example.com","meeting_date":%q}`, date)}}},
+		Reply:  "¡Listo Ana! Tu turno quedó agendado.",
+	})
+	h.receive(contactWaID, "Ana", "Hola, quiero un turno")
+	h.waitForJobs()
+
+	events := h.events(h.contact(contactE164).ID)
+	if len(events) != 1 {
+		h.t.Fatalf("after booking the contact has %d events, want 1", len(events))
+	}
+	return events[0]
+}
+
+// lastOutput es el resultado de la última tool ejecutada
+func (h *harness) lastOutput() toolOutput {
+	h.t.Helper()
+	outputs := h.openAI.ToolOutputs()
+	if len(outputs) == 0 {
+		h.t.Fatal("no tool was executed")
+	}
+	return outputs[len(outputs)-1]
+}
+
+func TestBookingScenario(t *testing.T) {
+	h := newHarness(t)
+	date := meetingDate(2, "10:00")
+
+	event := h.book(date)
+
+	// El contacto se crea con el nombre de WhatsApp y la tool completa su perfil
+	contact := h.contact(contactE164)
+	if contact.ProfileName != "Ana" || contact.Name != "Ana Gómez" || contact.Email != "ana@example.com" {
+		t.Errorf("contact = %q / %q / %q, want the WhatsApp name and the profile given to the tool", contact.ProfileName, contact.Name, contact.Email)
+	}
+	if output := h.lastOutput(); output.Name != "createMeeting" || !strings.Contains(output.Output, `"created":true`) {
+		t.Errorf("createMeeting output = %+v", output)
+	}
+	if !strings.HasPrefix(event.StartDate, date[:10]) || !strings.Contains(event.StartDate, "10:00") || event.AssistantsID != h.assistant.ID || event.CodeEvent == "" {
+		t.Errorf("event = %+v, want a booking on %s", event, date)
+	}
+
+	// El mensaje del contacto llega al thread con el contexto del assistant
+	if messages := h.openAI.UserMessages(); len(messages) != 1 || !strings.HasPrefix(messages[0], "Hola, quiero un turno") {
+		t.Errorf("thread user messages = %q", messages)
+	}
+	var threads int64
+	h.db.Model(&entities.Thread{}).Where("contacts_id = ?", contact.ID).Count(&threads)
+	if threads != 1 {
+		t.Errorf("%d threads for the contact, want 1", threads)
+	}
+
+	// Al dueño del número le llega el template de turno creado y al contacto la respuesta del assistant
+	notifications := h.meta.SentTo(phone.Recipient(testNotifyNumber))
+	if len(notifications) != 1 || notifications[0].Template != metaapi.TemplateEventoCreado || !containsString(notifications[0].Parameters, event.CodeEvent) {
+		t.Errorf("notifications = %+v, want the %s template with code %s", notifications, metaapi.TemplateEventoCreado, event.CodeEvent)
+	}
+	replies := h.meta.SentTo(phone.Recipient(contactE164))
+	if len(replies) != 1 || replies[0].Text != "¡Listo Ana! Tu turno quedó agendado." || replies[0].Token != testMetaToken ||
+		replies[0].PhoneNumberID != fmt.Sprint(testWhatsappNumberID) {
+		t.Errorf("replies = %+v", replies)
+	}
+
+	// La conversación queda guardada con el wamid que devolvió Meta y se registra el consumo del run
+	messages := h.messages(contact.ID)
+	if len(messages) != 2 || messages[0].IsFromBot || !messages[1].IsFromBot || !strings.HasPrefix(messages[1].MessageIdWhatsapp, "wamid.sent.") {
+		t.Errorf("messages = %+v", messages)
+	}
+	var usage entities.TokenUsage
+	if err := h.db.Where("contacts_id = ?", contact.ID).First(&usage).Error; err != nil || usage.PromptTokens+usage.CompletionTokens != 120 {
+		t.Errorf("token usage = %+v, %v; want 120 tokens", usage, err)
+	}
+}
+
+func TestReschedulingScenario(t *testing.T) {
+	h := newHarness(t)
+	event := h.book(meetingDate(2, "10:00"))
+	newDate := meetingDate(3, "15:30")
+
+	h.openAI.Script(assistantTurn{
+		Rounds: [][]toolCall{
+			{{Name: "getMeetingDetails", Arguments: fmt.Sprintf(`{"event_code":%q}`, event.CodeEvent)}},
+			{{Name: "updateEvents", Arguments: fmt.Sprintf(`{"event_code":%q,"new_date":%q}`, event.CodeEvent, newDate)}},
+		},
+		Reply: "Tu turno quedó para el nuevo horario.",
+	})
+	h.receive(contactWaID, "Ana", "¿Lo podemos pasar a pasado mañana a las 15:30?")
+	h.waitForJobs()
+
+	outputs := h.openAI.ToolOutputs()
+	if len(outputs) != 3 || !strings.Contains(outputs[1].Output, event.CodeEvent) || !strings.Contains(outputs[2].Output, `"updated":true`) {
+		t.Fatalf("tool outputs = %+v", outputs)
+	}
+	events := h.events(event.ContactsID)
+	if len(events) != 1 || events[0].ID != event.ID || events[0].StartDate != newDate || events[0].CodeEvent != event.CodeEvent {
+		t.Errorf("events = %+v, want event %d moved to %s", events, event.ID, newDate)
+	}
+
+	notifications := h.meta.SentTo(phone.Recipient(testNotifyNumber))
+	if len(notifications) != 2 || notifications[1].Template != metaapi.TemplateEventoModificado || !containsString(notifications[1].Parameters, newDate) {
+		t.Errorf("notifications = %+v, want the %s template with %s", notifications, metaapi.TemplateEventoModificado, newDate)
+	}
+	if replies := h.meta.SentTo(phone.Recipient(contactE164)); len(replies) != 2 || replies[1].Text != "Tu turno quedó para el nuevo horario." {
+		t.Errorf("replies = %+v", replies)
+	}
+}
+
+func TestRescheduleToTakenSlotScenario(t *testing.T) {
+	h := newHarness(t)
+	event := h.book(meetingDate(2, "10:00"))
+
+	// Otro contacto ya tiene el horario al que se quiere pasar
+	taken := meetingDate(3, "11:00")
+	other := entities.Contact{NumberPhonesID: h.numberPhone.ID, NumberPhone: "+5493515550002"}
+	h.db.Create(&other)
+	h.db.Create(&entities.Events{Summary: "Otro", StartDate: strings.Replace(taken, "T", " ", 1), EndDate: meetingDate(3, "11:30"), CodeEvent: "OTHER1",
+		AssistantsID: h.assistant.ID, ContactsID: other.ID})
+
+	h.openAI.Script(assistantTurn{
+		Rounds: [][]toolCall{{{Name: "updateEvents", Arguments: fmt.Sprintf(`{"event_code":%q,"new_date":%q}`, event.CodeEvent, taken)}}},
+		Reply:  "Ese horario está ocupado, ¿te sirve otro?",
+	})
+	h.receive(contactWaID, "Ana", "Pasalo a las 11")
+	h.waitForJobs()
+
+	if output := h.lastOutput(); !strings.Contains(output.Output, `"updated":false`) {
+		t.Errorf("updateEvents output = %+v, want the slot rejected", output)
+	}
+	if events := h.events(event.ContactsID); len(events) != 1 || events[0].StartDate != event.StartDate {
+		t.Errorf("events = %+v, want the booking unchanged", events)
+	}
+	if notifications := h.meta.SentTo(phone.Recipient(testNotifyNumber)); len(notifications) != 1 {
+		t.Errorf("%d notifications, want only the booking one", len(notifications))
+	}
+}
+
+func TestCancellationScenario(t *testing.T) {
+	h := newHarness(t)
+	event := h.book(meetingDate(2, "10:00"))
+
+	h.openAI.Script(assistantTurn{
+		Rounds: [][]toolCall{{{Name: "deleteEvent", Arguments: fmt.Sprintf(`{"event_code":%q}`, event.CodeEvent)}}},
+		Reply:  "Listo, cancelé tu turno.",
+	})
+	h.receive(contactWaID, "Ana", "Quiero cancelar mi turno")
+	h.waitForJobs()
+
+	if output := h.lastOutput(); output.Name != "deleteEvent" || !strings.Contains(output.Output, `"cancelled":true`) {
+		t.Errorf("deleteEvent output = %+v", output)
+	}
+	if events := h.events(event.ContactsID); len(events) != 0 {
+		t.Errorf("events = %+v, want the booking cancelled", events)
+	}
+
+	// Sin template de cancelación elegido, al dueño del número le llega el aviso de texto
+	notifications := h.meta.SentTo(phone.Recipient(testNotifyNumber))
+	if len(notifications) != 2 || notifications[1].Type != "text" || !strings.Contains(notifications[1].Text, "Cancelación de Turno") ||
+		!strings.Contains(notifications[1].Text, event.CodeEvent) {
+		t.Errorf("notifications = %+v, want the cancellation text", notifications)
+	}
+	if replies := h.meta.SentTo(phone.Recipient(contactE164)); len(replies) != 2 || replies[1].Text != "Listo, cancelé tu turno." {
+		t.Errorf("replies = %+v", replies)
+	}
+}
+
+// Meta reenvía una notificación si no recibió el 200 a tiempo: el mensaje se responde una sola vez
+func TestDuplicatedWebhookDelivery(t *testing.T) {
+	h := newHarness(t)
+	h.openAI.Script(assistantTurn{Reply: "¡Hola! ¿En qué te ayudo?"}, assistantTurn{Reply: "Atendemos de 9 a 18."})
+
+	payload := h.textPayload(contactWaID, "Ana", "wamid.duplicated.1", "Hola")
+	h.deliver(payload)
+	h.waitForJobs()
+	// Reenvío después de procesado
+	h.deliver(payload)
+	h.waitForJobs()
+
+	// Reenvíos simultáneos: los toman workers distintos
+	payload = h.textPayload(contactWaID, "Ana", "wamid.duplicated.2", "¿Qué horarios tienen?")
+	h.deliver(payload)
+	h.deliver(payload)
+	h.deliver(payload)
+	h.waitForJobs()
+
+	if runs := h.openAI.RunsCreated(); runs != 2 {
+		t.Errorf("%d runs, want one per distinct message", runs)
+	}
+	replies := h.meta.SentTo(phone.Recipient(contactE164))
+	if len(replies) != 2 || replies[0].Text != "¡Hola! ¿En qué te ayudo?" || replies[1].Text != "Atendemos de 9 a 18." {
+		t.Errorf("replies = %+v", replies)
+	}
+
+	contact := h.contact(contactE164)
+	var received []string
+	for _, message := range h.messages(contact.ID) {
+		if !message.IsFromBot {
+			received = append(received, message.MessageIdWhatsapp)
+		}
+	}
+	if strings.Join(received, ",") != "wamid.duplicated.1,wamid.duplicated.2" {
+		t.Errorf("saved contact messages = %v, want each one once", received)
+	}
+}
+
+// Con un solo worker, el mensaje de otro contacto se toma mientras el primero espera la ventana de debounce
+func TestDebounceDoesNotBlockWorker(t *testing.T) {
+	t.Setenv("INBOUND_JOBS_WORKERS", "1")
+	h := newHarness(t)
+	h.openAI.Script(assistantTurn{Reply: "¡Hola!"}, assistantTurn{Reply: "¡Buenas!"})
+
+	h.receive(contactWaID, "Ana", "Hola")
+	h.receive("5493515550002", "Beto", "Buenas")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var processing int64
+		h.db.Model(&entities.InboundJob{}).Where("status = ?", entities.InboundJobStatusProcessing).Count(&processing)
+		if processing == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the second job was not claimed while the first one waited in the mailbox")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.waitForJobs()
+
+	if replies := len(h.meta.SentTo(phone.Recipient(contactE164))) + len(h.meta.SentTo(phone.Recipient("+5493515550002"))); replies != 2 {
+		t.Errorf("%d replies, want one per contact", replies)
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Con OpenAI caído el contacto recibe el mensaje de reemplazo; con el circuit breaker abierto ni se consulta a la
+// API, y cuando vuelve el bot responde normalmente
+func TestOpenAIOutageScenario(t *testing.T) {
+	h := newHarness(t)
+	h.openAI.SetDown(true)
+
+	h.receive(contactWaID, "Ana", "Hola")
+	h.waitForJobs()
+	h.receive(contactWaID, "Ana", "¿Hay alguien?")
+	h.waitForJobs()
+
+	// Dos mensajes fallidos abren el circuito: el tercero no llega a la API
+	requests := h.openAI.Requests()
+	h.receive(contactWaID, "Ana", "¿Hola?")
+	h.waitForJobs()
+	if after := h.openAI.Requests(); after != requests {
+		t.Errorf("%d requests with the circuit open, want none", after-requests)
+	}
+
+	replies := h.meta.SentTo(phone.Recipient(contactE164))
+	if len(replies) != 3 {
+		t.Fatalf("replies = %+v, want the fallback for each message", replies)
+	}
+	for _, reply := range replies {
+		if reply.Text != dtos.DefaultLLMUnavailableMessage {
+			t.Errorf("reply = %q, want the fallback message", reply.Text)
+		}
+	}
+	contact := h.contact(contactE164)
+	if messages := h.messages(contact.ID); len(messages) != 6 {
+		t.Errorf("%d saved messages, want the contact messages and the fallback replies", len(messages))
+	}
+
+	// Pasado el cooldown el request de prueba funciona y el circuito se cierra
+	h.openAI.SetDown(false)
+	time.Sleep(testBreakerCooldown)
+	h.openAI.Script(assistantTurn{Reply: "¡Hola Ana! ¿En qué te ayudo?"})
+	h.receive(contactWaID, "Ana", "Hola de nuevo")
+	h.waitForJobs()
+
+	replies = h.meta.SentTo(phone.Recipient(contactE164))
+	if len(replies) != 4 || replies[3].Text != "¡Hola Ana! ¿En qué te ayudo?" {
+		t.Errorf("replies = %+v, want the assistant reply after the outage", replies)
+	}
+}
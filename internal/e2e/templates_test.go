@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+)
+
+// WhatsApp Business Account del número de los escenarios
+const testWhatsappBusinessID = 2002
+
+// Templates del WhatsApp Business Account: más de dos páginas del fake
+var businessTemplates = []metaapi.MessageTemplate{
+	{ID: "1", Name: "turno_creado", Language: "es", Status: "APPROVED", Category: "UTILITY", RejectedReason: "NONE", Components: []metaapi.TemplateDefinedPart{
+		{Type: "HEADER", Format: "TEXT", Text: "Turno de {{1}}"},
+		{Type: "BODY", Text: "Nuevo turno: {{1}}, del {{2}} al {{3}}, con {{4}}. Código {{5}}"},
+		{Type: "BUTTONS", Buttons: []metaapi.TemplateDefinedButton{{Type: "URL", Text: "Ver", URL: "https://turnos.example.com/{{1}}"}, {Type: "QUICK_REPLY", Text: "Ok"}}},
+	}},
+	{ID: "2", Name: "turno_creado", Language: "en", Status: "APPROVED", Category: "UTILITY", Components: []metaapi.TemplateDefinedPart{
+		{Type: "BODY", Text: "New meeting: {{1}} {{2}} {{3}} {{4}} {{5}}"},
+	}},
+	{ID: "3", Name: "recordatorio", Language: "es", Status: "APPROVED", Category: "UTILITY", Components: []metaapi.TemplateDefinedPart{
+		{Type: "BODY", Text: "Te recordamos {{1}} el {{2}}. Código {{3}}, guardá el código {{3}}"},
+	}},
+	{ID: "4", Name: "promo", Language: "es", Status: "REJECTED", Category: "MARKETING", RejectedReason: "INVALID_FORMAT", Components: []metaapi.TemplateDefinedPart{
+		{Type: "HEADER", Format: "IMAGE"},
+		{Type: "BODY", Text: "Hola {{1}}"},
+	}},
+	{ID: "5", Name: "aviso", Language: "es", Status: "PENDING", Category: "UTILITY", Components: []metaapi.TemplateDefinedPart{
+		{Type: "BODY", Text: "Sin variables"},
+	}},
+}
+
+// syncTemplates le da al número su WhatsApp Business Account y sincroniza los templates
+func (h *harness) syncTemplates(templates ...metaapi.MessageTemplate) []dtos.MessageTemplateDto {
+	h.t.Helper()
+	if err := h.db.Model(&entities.NumberPhone{}).Where("id = ?", h.numberPhone.ID).Update("whatsapp_business_id", testWhatsappBusinessID).Error; err != nil {
+		h.t.Fatalf("setting whatsapp_business_id: %v", err)
+	}
+	h.meta.SetTemplates(templates...)
+	synced, err := h.templates.GetByNumberPhone(h.numberPhone.ID, true)
+	if err != nil {
+		h.t.Fatalf("GetByNumberPhone(refresh): %v", err)
+	}
+	return synced
+}
+
+func TestTemplatesSyncScenario(t *testing.T) {
+	h := newHarness(t)
+
+	// Sin WhatsApp Business Account no hay de dónde traerlos
+	if err := h.templates.Sync(h.numberPhone.ID); !errors.Is(err, services.ErrMissingBusinessAccount) {
+		t.Fatalf("Sync without business account = %v, want ErrMissingBusinessAccount", err)
+	}
+
+	synced := h.syncTemplates(businessTemplates...)
+	if pages := h.meta.TemplatePages(); pages != 3 {
+		t.Errorf("%d pages requested, want the 5 templates in 3 pages", pages)
+	}
+
+	byName := map[string]dtos.MessageTemplateDto{}
+	for _, template := range synced {
+		byName[template.Name+"/"+template.Language] = template
+	}
+	if len(synced) != 5 || len(byName) != 5 {
+		t.Fatalf("synced = %+v, want the 5 templates", synced)
+	}
+	schemas := map[string]dtos.TemplateParameterSchemaDto{
+		"turno_creado/es": {HeaderFormat: "TEXT", HeaderParameters: 1, BodyParameters: 5,
+			Buttons: []dtos.TemplateButtonSchemaDto{{Index: 0, Type: "URL", Parameters: 1}, {Index: 1, Type: "QUICK_REPLY"}}},
+		"turno_creado/en": {BodyParameters: 5},
+		"recordatorio/es": {BodyParameters: 3},
+		"promo/es":        {HeaderFormat: "IMAGE", HeaderParameters: 1, BodyParameters: 1},
+		"aviso/es":        {},
+	}
+	for key, want := range schemas {
+		if got := byName[key].ParameterSchema; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s parameter schema = %+v, want %+v", key, got, want)
+		}
+	}
+	if promo, created := byName["promo/es"], byName["turno_creado/es"]; promo.Status != "REJECTED" || promo.RejectedReason != "INVALID_FORMAT" ||
+		created.RejectedReason != "" || created.MetaTemplateID != "1" || created.SyncedAt == nil {
+		t.Errorf("promo = %+v, turno_creado = %+v; want the status and rejection from Meta", promo, created)
+	}
+
+	// La copia local se reemplaza: lo que se borró en Meta desaparece
+	synced = h.syncTemplates(businessTemplates[2])
+	if len(synced) != 1 || synced[0].Name != "recordatorio" {
+		t.Errorf("after the second sync = %+v, want only recordatorio", synced)
+	}
+}
+
+func TestAssistantTemplatesValidation(t *testing.T) {
+	h := newHarness(t)
+	h.syncTemplates(businessTemplates...)
+	assistantID := h.assistant.ID
+
+	invalid := []struct {
+		name     string
+		requests []dtos.AssistantTemplateDto
+	}{
+		{"unknown event", []dtos.AssistantTemplateDto{{Event: "moved", TemplateName: "turno_creado"}}},
+		{"invalid name", []dtos.AssistantTemplateDto{{Event: dtos.TemplateEventCreated, TemplateName: "Turno Creado"}}},
+		{"repeated event", []dtos.AssistantTemplateDto{
+			{Event: dtos.TemplateEventCreated, TemplateName: "turno_creado"},
+			{Event: dtos.TemplateEventCreated, TemplateName: "turno_creado", Language: "en"},
+		}},
+		{"not synced", []dtos.AssistantTemplateDto{{Event: dtos.TemplateEventCreated, TemplateName: "inexistente"}}},
+		{"not synced language", []dtos.AssistantTemplateDto{{Event: dtos.TemplateEventCreated, TemplateName: "turno_creado", Language: "pt_BR"}}},
+		{"wrong body parameters", []dtos.AssistantTemplateDto{{Event: dtos.TemplateEventReminder, TemplateName: "turno_creado"}}},
+	}
+	for _, test := range invalid {
+		if _, err := h.templates.SetAssistantTemplates(assistantID, test.requests); !errors.Is(err, services.ErrInvalidTemplate) {
+			t.Errorf("%s: SetAssistantTemplates = %v, want ErrInvalidTemplate", test.name, err)
+		}
+	}
+	if h.templates.HasLifecycleTemplate(assistantID, dtos.TemplateEventCreated) {
+		t.Error("a rejected request saved templates")
+	}
+
+	templates, err := h.templates.SetAssistantTemplates(assistantID, []dtos.AssistantTemplateDto{
+		{Event: " Created ", TemplateName: "turno_creado"},
+		{Event: dtos.TemplateEventReminder, TemplateName: "recordatorio", Language: "es"},
+	})
+	if err != nil {
+		t.Fatalf("SetAssistantTemplates: %v", err)
+	}
+	want := []dtos.AssistantTemplateDto{
+		{Event: dtos.TemplateEventCreated, TemplateName: "turno_creado", Language: "es"},
+		{Event: dtos.TemplateEventUpdated, TemplateName: metaapi.TemplateEventoModificado, Language: "es"},
+		{Event: dtos.TemplateEventCancelled, TemplateName: metaapi.TemplateEventoCancelado, Language: "es"},
+		{Event: dtos.TemplateEventReminder, TemplateName: "recordatorio", Language: "es"},
+	}
+	if !reflect.DeepEqual(templates, want) {
+		t.Errorf("templates = %+v, want %+v", templates, want)
+	}
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"gorm.io/gorm"
+)
+
+// AssistantClosure es un cierre del assistant (feriado, vacaciones o cierre puntual) que se descuenta de su horario de trabajo
+type AssistantClosure struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	AssistantsID    int64     `gorm:"not null;index"`
+	Assistant       Assistant `gorm:"foreignKey:AssistantsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	StartDate       time.Time `gorm:"type:date;not null"`
+	EndDate         time.Time `gorm:"type:date;not null"`
+	StartTime       string    `gorm:"size:5"` // Vacío para cierres de día completo
+	EndTime         string    `gorm:"size:5"`
+	RecurringYearly bool      `gorm:"not null;default:false"`
+	Reason          string    `gorm:"size:255"`
+	Source          string    `gorm:"size:20;not null;default:manual"`
+	ExternalUID     string    `gorm:"size:255;index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// ClosedRangeOn indica si el cierre aplica al día y qué horario cierra (todo el día si no tiene start_time/end_time)
+func (c AssistantClosure) ClosedRangeOn(day time.Time) (dtos.TimeRange, bool) {
+	if !c.coversDay(day) {
+		return dtos.TimeRange{}, false
+	}
+
+	if c.StartTime == "" || c.EndTime == "" {
+		return dtos.TimeRange{Start: 0, End: 24 * time.Hour}, true
+	}
+
+	ranges, err := dtos.ParseTimeRanges(c.StartTime + "-" + c.EndTime)
+	if err != nil || len(ranges) == 0 {
+		// Un horario mal cargado cierra el día completo: es preferible a dar turnos en un feriado
+		return dtos.TimeRange{Start: 0, End: 24 * time.Hour}, true
+	}
+	return ranges[0], true
+}
+
+func (c AssistantClosure) coversDay(day time.Time) bool {
+	if !c.RecurringYearly {
+		date := day.Format("2006-01-02")
+		return date >= c.StartDate.Format("2006-01-02") && date <= c.EndDate.Format("2006-01-02")
+	}
+
+	// En los cierres anuales solo importan mes y día. Un rango puede cruzar el fin de año (ej: 24/12 al 02/01).
+	monthDay := day.Format("01-02")
+	start, end := c.StartDate.Format("01-02"), c.EndDate.Format("01-02")
+	if start <= end {
+		return monthDay >= start && monthDay <= end
+	}
+	return monthDay >= start || monthDay <= end
+}
+
+func MapEntityToAssistantClosureDto(entity AssistantClosure) dtos.AssistantClosureDto {
+	return dtos.AssistantClosureDto{
+		ID:              entity.ID,
+		AssistantsID:    entity.AssistantsID,
+		StartDate:       entity.StartDate.Format("2006-01-02"),
+		EndDate:         entity.EndDate.Format("2006-01-02"),
+		StartTime:       entity.StartTime,
+		EndTime:         entity.EndTime,
+		RecurringYearly: entity.RecurringYearly,
+		Reason:          entity.Reason,
+		Source:          entity.Source,
+		ExternalUID:     entity.ExternalUID,
+		CreatedAt:       entity.CreatedAt,
+		UpdatedAt:       entity.UpdatedAt,
+	}
+}
+
+// MapDtoToAssistantClosure espera un DTO ya validado (fechas en formato YYYY-MM-DD)
+func MapDtoToAssistantClosure(dto dtos.AssistantClosureDto) AssistantClosure {
+	startDate, _ := time.Parse("2006-01-02", dto.StartDate)
+	endDate, _ := time.Parse("2006-01-02", dto.EndDate)
+	source := dto.Source
+	if source == "" {
+		source = dtos.ClosureSourceManual
+	}
+
+	return AssistantClosure{
+		ID:              dto.ID,
+		AssistantsID:    dto.AssistantsID,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		StartTime:       dto.StartTime,
+		EndTime:         dto.EndTime,
+		RecurringYearly: dto.RecurringYearly,
+		Reason:          dto.Reason,
+		Source:          source,
+		ExternalUID:     dto.ExternalUID,
+	}
+}
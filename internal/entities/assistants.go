@@ -17,12 +17,20 @@ type Assistant struct {
 	Model              string
 	Instructions       string
 	// Horarios de trabajo
-	OpeningDays      uint8  `gorm:"not null"`         // Días de apertura
-	WorkingHours     string `gorm:"size:50;not null"` // Horarios de trabajo
+	OpeningDays      uint8  `gorm:"not null"`          // Días de apertura
+	WorkingHours     string `gorm:"size:100;not null"` // Horarios de trabajo, uno o más rangos "HH:MM-HH:MM" separados por coma
+	BreakHours       string `gorm:"size:100"`          // Pausas que se descuentan del horario de trabajo
 	Active           bool   `gorm:"not null;default:true"`
 	EventDuration    int64  `gorm:"not null;default:30"` // Duración por defecto de cada cita o turno
 	EventType        string `gorm:"size:50;"`
 	EventCountPerDay int16  `gorm:"not null;default:1"`
+	SlotCapacity     int16  `gorm:"not null;default:1"` // Eventos simultáneos que admite un mismo horario
+	ReminderOffsets  string `gorm:"size:50"`            // Horas antes del evento en que se envían recordatorios, ej: "24,2"
+	ReminderTemplate string `gorm:"size:100"`           // Template de Meta del recordatorio (por defecto recordatorio_evento)
+
+	// Proveedor del modelo (openai_assistants, openai_chat, ollama) y endpoint propio para los compatibles con OpenAI
+	LLMProvider string `gorm:"column:llm_provider;size:30;not null;default:openai_assistants"`
+	LLMBaseURL  string `gorm:"column:llm_base_url;size:255"`
 
 	AccountGoogle bool          `gorm:"default:false"`
 	NumberPhones  []NumberPhone `gorm:"foreignKey:AssistantsID"`
@@ -64,10 +72,16 @@ func MapAssistantToDto(a Assistant) dtos.AssistantDto {
 		EventDuration:      a.EventDuration,
 		OpeningDays:        a.OpeningDays,
 		WorkingHours:       a.WorkingHours,
+		BreakHours:         a.BreakHours,
+		SlotCapacity:       a.SlotCapacity,
+		ReminderOffsets:    a.ReminderOffsets,
+		ReminderTemplate:   a.ReminderTemplate,
 		EventType:          a.EventType,
 		EventCountPerDay:   a.EventCountPerDay,
 		//GoogleCalendarConfig: googleCalendarCredential,
 		AccountGoogle: a.AccountGoogle,
+		LLMProvider:   a.LLMProvider,
+		LLMBaseURL:    a.LLMBaseURL,
 	}
 }
 
@@ -96,8 +110,14 @@ func MapDtoToAssistant(dto dtos.AssistantDto) Assistant {
 		Active:             dto.Active,
 		OpeningDays:        dto.OpeningDays,
 		WorkingHours:       dto.WorkingHours,
+		BreakHours:         dto.BreakHours,
+		SlotCapacity:       dto.SlotCapacity,
+		ReminderOffsets:    dto.ReminderOffsets,
+		ReminderTemplate:   dto.ReminderTemplate,
 		EventType:          dto.EventType,
 		EventCountPerDay:   dto.EventCountPerDay,
+		LLMProvider:        dto.LLMProvider,
+		LLMBaseURL:         dto.LLMBaseURL,
 		//GoogleCalendarCredential: googleCalendarCredential,
 	}
 }
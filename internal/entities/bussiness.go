@@ -15,9 +15,16 @@ type Bussines struct {
 	WebSite    string
 	Users      []Users     `gorm:"many2many:bussiness_has_users;"` // Relación muchos a muchos
 	Assistants []Assistant `gorm:"foreignKey:BussinessID"`         // Relación de uno a muchos con Assistant
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"` // Soft delete
+
+	// Cuota mensual de consumo del modelo. Si se supera, el bot deja de responder con el modelo y contesta
+	// QuotaFallbackMessage hasta que empiece el mes siguiente o se aumente la cuota. En cero no hay límite.
+	MonthlyTokenQuota    int64   `gorm:"not null;default:0"`
+	MonthlyCostQuota     float64 `gorm:"not null;default:0"` // USD
+	QuotaFallbackMessage string  `gorm:"type:text"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"` // Soft delete
 }
 
 // TableName establece el nombre de la tabla en la base de datos
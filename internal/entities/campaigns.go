@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"gorm.io/gorm"
+)
+
+// Campaign es un envío masivo de un template a un segmento de contactos del número
+type Campaign struct {
+	ID               int64       `gorm:"primaryKey;autoIncrement"`
+	NumberPhonesID   int64       `gorm:"not null;index"`
+	NumberPhone      NumberPhone `gorm:"foreignKey:NumberPhonesID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name             string      `gorm:"size:255;not null"`
+	TemplateName     string      `gorm:"size:255;not null"`
+	TemplateLanguage string      `gorm:"size:15;not null"`
+	Components       string      `gorm:"type:text"` // JSON de []metaapi.Component con las variables sin completar
+	Segment          string      `gorm:"type:text"` // JSON de dtos.CampaignSegmentDto
+	Variables        string      `gorm:"type:text"` // JSON con las variables de cada contacto por ID
+	Status           string      `gorm:"size:20;not null;default:draft;index:idx_campaigns_status_scheduled"`
+	ScheduledAt      *time.Time  `gorm:"default:null;index:idx_campaigns_status_scheduled"`
+	StartedAt        *time.Time  `gorm:"default:null"`
+	FinishedAt       *time.Time  `gorm:"default:null"`
+	LockedAt         *time.Time  `gorm:"default:null"` // Última señal del proceso que la está enviando
+	LastError        string      `gorm:"type:text"`
+	UsersID          *int64      `gorm:"index"` // Usuario que la creó
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+// CampaignRecipient es un contacto del segmento de la campaña con el resultado de su envío. El índice único
+// (campaña, contacto) evita que un contacto reciba dos veces la misma campaña.
+type CampaignRecipient struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement"`
+	CampaignsID int64      `gorm:"not null;uniqueIndex:idx_campaign_recipients_contact;index:idx_campaign_recipients_status"`
+	Campaign    Campaign   `gorm:"foreignKey:CampaignsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ContactsID  int64      `gorm:"not null;uniqueIndex:idx_campaign_recipients_contact;index"`
+	Contact     Contact    `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status      string     `gorm:"size:20;not null;default:pending;index:idx_campaign_recipients_status"`
+	Error       string     `gorm:"type:text"`
+	MessagesID  *int64     `gorm:"default:null"`
+	Message     *Message   `gorm:"foreignKey:MessagesID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	SentAt      *time.Time `gorm:"default:null;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func MapEntityToCampaignDto(entity Campaign) dtos.CampaignDto {
+	dto := dtos.CampaignDto{
+		ID:             entity.ID,
+		NumberPhonesID: entity.NumberPhonesID,
+		Name:           entity.Name,
+		Template: dtos.OutboundTemplateDto{
+			Name:     entity.TemplateName,
+			Language: entity.TemplateLanguage,
+		},
+		Status:      entity.Status,
+		ScheduledAt: entity.ScheduledAt,
+		StartedAt:   entity.StartedAt,
+		FinishedAt:  entity.FinishedAt,
+		LastError:   entity.LastError,
+		CreatedAt:   entity.CreatedAt,
+	}
+	_ = json.Unmarshal([]byte(entity.Components), &dto.Template.Components)
+	_ = json.Unmarshal([]byte(entity.Segment), &dto.Segment)
+	_ = json.Unmarshal([]byte(entity.Variables), &dto.Variables)
+	return dto
+}
+
+// MapCampaignRequestToEntity arma la campaña (en borrador) con los datos del request
+func MapCampaignRequestToEntity(dto dtos.CampaignRequestDto) Campaign {
+	components, _ := json.Marshal(dto.Template.Components)
+	segment, _ := json.Marshal(dto.Segment)
+	variables, _ := json.Marshal(dto.Variables)
+	return Campaign{
+		NumberPhonesID:   dto.NumberPhoneID,
+		Name:             dto.Name,
+		TemplateName:     dto.Template.Name,
+		TemplateLanguage: dto.Template.Language,
+		Components:       string(components),
+		Segment:          string(segment),
+		Variables:        string(variables),
+		Status:           dtos.CampaignStatusDraft,
+	}
+}
+
+func MapEntityToCampaignRecipientDto(entity CampaignRecipient) dtos.CampaignRecipientDto {
+	dto := dtos.CampaignRecipientDto{
+		ID:            entity.ID,
+		ContactsID:    entity.ContactsID,
+		ContactNumber: entity.Contact.NumberPhone,
+		Status:        entity.Status,
+		Error:         entity.Error,
+		MessagesID:    entity.MessagesID,
+		SentAt:        entity.SentAt,
+	}
+	if entity.Message != nil {
+		dto.DeliveryStatus = entity.Message.Status
+		if dto.Error == "" {
+			dto.Error = entity.Message.ErrorTitle
+		}
+	}
+	return dto
+}
@@ -0,0 +1,17 @@
+package entities
+
+// ContactTag es una etiqueta del contacto, se usa para armar los segmentos de las campañas
+type ContactTag struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement"`
+	ContactsID int64  `gorm:"not null;uniqueIndex:idx_contact_tags_tag"`
+	Tag        string `gorm:"size:50;not null;uniqueIndex:idx_contact_tags_tag;index"`
+}
+
+// TagNames devuelve las etiquetas del contacto (tienen que estar cargadas con Preload("Tags"))
+func (c Contact) TagNames() []string {
+	names := make([]string, len(c.Tags))
+	for i, tag := range c.Tags {
+		names[i] = tag.Tag
+	}
+	return names
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
@@ -11,9 +12,29 @@ type Contact struct {
 	ID                int64       `gorm:"primaryKey;autoIncrement"`
 	NumberPhonesID    int64       `gorm:"not null"`
 	NumberPhoneEntity NumberPhone `gorm:"foreignKey:NumberPhonesID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	NumberPhone       int64       `gorm:"not null"`
+	NumberPhone       string      `gorm:"size:20;not null"` // E.164 ("+5493515551234")
 	IsBlocked         bool
 
+	// Perfil del contacto. ProfileName es el nombre que tiene en WhatsApp (llega en cada webhook); Name y Email
+	// los completa el assistant cuando agenda un turno o un operador desde el panel.
+	ProfileName  string `gorm:"size:255"`
+	Name         string `gorm:"size:255"`
+	Email        string `gorm:"size:255"`
+	CustomFields string `gorm:"type:text"` // JSON de map[string]string
+	Notes        string `gorm:"type:text"`
+
+	// Quién atiende la conversación (dtos.HandoffMode*). Mientras no sea bot el assistant no responde.
+	HandoffMode      string     `gorm:"size:10;not null;default:bot"`
+	HandoffReason    string     `gorm:"size:255"`
+	HandoffAt        *time.Time `gorm:"default:null"`
+	HandoffExpiresAt *time.Time `gorm:"default:null;index"` // Cuándo vuelve a responder el bot (nil = no vence)
+
+	// El contacto pidió no recibir más campañas (respondiendo BAJA/STOP o desde el panel). OptedInAt es cuándo
+	// aceptó recibirlas (respondiendo ALTA o desde el panel).
+	OptedInAt  *time.Time   `gorm:"default:null"`
+	OptedOutAt *time.Time   `gorm:"default:null"`
+	Tags       []ContactTag `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
 	CountTokens string
 	Events      []Events `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Relación con Events
 	Threads     []Thread `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Relación de uno a muchos con Thread
@@ -29,7 +50,61 @@ func MapEntityToContactDto(entity Contact) dtos.ContactDto {
 		ContactNumber:  entity.NumberPhone,
 		CountTokens:    entity.CountTokens,
 		IsBlocked:      entity.IsBlocked,
+		DisplayName:    entity.DisplayName(),
+		ProfileName:    entity.ProfileName,
+		Name:           entity.Name,
+		Email:          entity.Email,
+		CustomFields:   entity.CustomFieldValues(),
+		Notes:          entity.Notes,
+		OptedInAt:      entity.OptedInAt,
+		OptedOutAt:     entity.OptedOutAt,
+		Tags:           entity.TagNames(),
+		CreatedAt:      entity.CreatedAt,
+		Handoff: dtos.HandoffStateDto{
+			Mode:      entity.CurrentHandoffMode(time.Now()),
+			Reason:    entity.HandoffReason,
+			Since:     entity.HandoffAt,
+			ExpiresAt: entity.HandoffExpiresAt,
+		},
+	}
+}
+
+// DisplayName es el nombre que se muestra: el cargado en el perfil o, si no hay, el de WhatsApp
+func (c Contact) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.ProfileName
+}
+
+// CustomFieldValues devuelve los campos personalizados del contacto (nunca nil)
+func (c Contact) CustomFieldValues() map[string]string {
+	fields := map[string]string{}
+	if c.CustomFields != "" {
+		_ = json.Unmarshal([]byte(c.CustomFields), &fields)
+	}
+	return fields
+}
+
+// EncodeCustomFields serializa los campos personalizados como se guardan en la columna custom_fields
+func EncodeCustomFields(fields map[string]string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	raw, _ := json.Marshal(fields)
+	return string(raw)
+}
+
+// CurrentHandoffMode devuelve quién atiende la conversación, teniendo en cuenta que el modo human o paused
+// puede haber vencido aunque todavía no lo haya liberado el proceso automático
+func (c Contact) CurrentHandoffMode(now time.Time) string {
+	if c.HandoffMode == "" || c.HandoffMode == dtos.HandoffModeBot {
+		return dtos.HandoffModeBot
+	}
+	if c.HandoffExpiresAt != nil && !now.Before(*c.HandoffExpiresAt) {
+		return dtos.HandoffModeBot
 	}
+	return c.HandoffMode
 }
 
 func MapDtoToContact(dto dtos.ContactDto) Contact {
@@ -39,5 +114,10 @@ func MapDtoToContact(dto dtos.ContactDto) Contact {
 		NumberPhone:    dto.ContactNumber,
 		CountTokens:    dto.CountTokens,
 		IsBlocked:      dto.IsBlocked,
+		ProfileName:    dto.ProfileName,
+		Name:           dto.Name,
+		Email:          dto.Email,
+		CustomFields:   EncodeCustomFields(dto.CustomFields),
+		Notes:          dto.Notes,
 	}
 }
@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+// Estados de un recordatorio
+const (
+	EventReminderStatusSending = "sending" // Reservado por el scheduler, todavía no se confirmó el envío
+	EventReminderStatusSent    = "sent"
+	EventReminderStatusFailed  = "failed"
+	EventReminderStatusSkipped = "skipped" // El evento se agendó después del momento del recordatorio
+)
+
+// EventReminder registra cada recordatorio de un evento. El índice único (evento, horas de anticipación) hace que
+// el envío sea idempotente: aunque la API se reinicie o corra en varias instancias, cada recordatorio se envía una vez.
+// Los que fallaron o quedaron en sending se vuelven a reservar hasta agotar los intentos. Si el evento se reprograma
+// se borran, porque corresponden al horario anterior.
+type EventReminder struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement"`
+	EventsID          int        `gorm:"not null;uniqueIndex:idx_event_reminders_event_offset"`
+	Event             Events     `gorm:"foreignKey:EventsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OffsetHours       int        `gorm:"not null;uniqueIndex:idx_event_reminders_event_offset"`
+	ContactsID        int64      `gorm:"not null;index"`
+	Status            string     `gorm:"size:20;not null;default:sending"`
+	Attempts          int        `gorm:"not null;default:0"` // Veces que se reservó para enviarlo
+	MessageIdWhatsapp string     `gorm:"size:255;index"`     // wamid del template enviado, para reconocer las respuestas
+	Error             string     `gorm:"type:text"`
+	SentAt            *time.Time `gorm:"default:null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
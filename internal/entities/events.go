@@ -15,7 +15,9 @@ type Events struct {
 	StartDate             string `gorm:"type:text;not null"`
 	EndDate               string `gorm:"type:text;not null"`
 	EventGoogleCalendarID string
-	CodeEvent             string `gorm:"type:text;not null"`
+	CodeEvent             string     `gorm:"type:text;not null"`
+	ConfirmedAt           *time.Time `gorm:"default:null"` // El contacto confirmó asistencia respondiendo al recordatorio
+	RescheduledAt         *time.Time `gorm:"default:null"` // Última vez que se cambió la fecha de inicio
 
 	AssistantsID int64     `gorm:"not null"` // Relación con Assistant (un asistente tiene muchos eventos)
 	Assistant    Assistant `gorm:"foreignKey:AssistantsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
@@ -40,6 +42,7 @@ func MapEntityToEventsDto(entity Events) dtos.EventsDto {
 		AssistantsID:          entity.AssistantsID,
 		ContactsID:            entity.ContactsID,
 		CodeEvent:             entity.CodeEvent,
+		ConfirmedAt:           entity.ConfirmedAt,
 		CreatedAt:             createdAtToString,
 	}
 }
@@ -48,7 +51,8 @@ func MapDtoToEvents(dto dtos.EventsDto) Events {
 	var createdAtToTime time.Time
 	if len(dto.CreatedAt) > 0 {
 		var err error
-		createdAtToTime, err = time.Parse("2006-01-02T15:04:05-03:00", dto.CreatedAt)
+		// Mismo formato que MapEntitiesToEventsDto, sin importar la zona horaria del servidor
+		createdAtToTime, err = time.Parse(time.RFC3339, dto.CreatedAt)
 		if err != nil {
 			fmt.Println("Error al parsear la fecha:", err)
 			return Events{}
@@ -64,6 +68,7 @@ func MapDtoToEvents(dto dtos.EventsDto) Events {
 		AssistantsID:          dto.AssistantsID,
 		ContactsID:            dto.ContactsID,
 		CodeEvent:             dto.CodeEvent,
+		ConfirmedAt:           dto.ConfirmedAt,
 		CreatedAt:             createdAtToTime,
 	}
 }
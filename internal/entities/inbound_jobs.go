@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// Estados de un job del webhook de WhatsApp
+const (
+	InboundJobStatusPending    = "pending"
+	InboundJobStatusProcessing = "processing"
+	InboundJobStatusDone       = "done"
+	InboundJobStatusDead       = "dead" // Agotó los reintentos, queda para revisión/replay manual
+)
+
+// InboundJob guarda cada notificación recibida en el webhook para procesarla en segundo plano con reintentos
+type InboundJob struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement"`
+	Payload     string     `gorm:"type:text;not null"` // Cuerpo original de la notificación de Meta
+	Status      string     `gorm:"size:20;not null;default:pending;index:idx_inbound_jobs_status_next_run"`
+	Attempts    int        `gorm:"not null;default:0"`
+	MaxAttempts int        `gorm:"not null;default:5"`
+	LastError   string     `gorm:"type:text"`
+	NextRunAt   time.Time  `gorm:"not null;index:idx_inbound_jobs_status_next_run"`
+	LockedAt    *time.Time `gorm:"default:null"` // Momento en que un worker tomó el job
+	ProcessedAt *time.Time `gorm:"default:null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func MapEntityToInboundJobDto(entity InboundJob) dtos.InboundJobDto {
+	return dtos.InboundJobDto{
+		ID:          entity.ID,
+		Payload:     entity.Payload,
+		Status:      entity.Status,
+		Attempts:    entity.Attempts,
+		MaxAttempts: entity.MaxAttempts,
+		LastError:   entity.LastError,
+		NextRunAt:   entity.NextRunAt,
+		LockedAt:    entity.LockedAt,
+		ProcessedAt: entity.ProcessedAt,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// MessageStatus guarda cada callback de estado (sent, delivered, read, failed) que informa WhatsApp para un mensaje saliente.
+// El índice único (wamid, estado, fecha) evita duplicar el historial cuando Meta reenvía el callback o se reintenta el job.
+type MessageStatus struct {
+	ID                 int64     `gorm:"primaryKey;autoIncrement"`
+	MessagesID         *int64    `gorm:"index"` // Puede llegar el estado antes de que se guarde el mensaje
+	Message            *Message  `gorm:"foreignKey:MessagesID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MessageIdWhatsapp  string    `gorm:"size:255;not null;index;uniqueIndex:idx_message_statuses_event"` // wamid del mensaje
+	Status             string    `gorm:"size:20;not null;uniqueIndex:idx_message_statuses_event"`
+	StatusTimestamp    time.Time `gorm:"not null;uniqueIndex:idx_message_statuses_event"` // Fecha informada por WhatsApp
+	RecipientID        string    `gorm:"size:30"`
+	ConversationID     string    `gorm:"size:255"`
+	ConversationOrigin string    `gorm:"size:50"` // service, marketing, utility, authentication...
+	PricingCategory    string    `gorm:"size:50"`
+	Billable           bool
+	ErrorCode          int
+	ErrorTitle         string `gorm:"type:text"`
+	ErrorDetails       string `gorm:"type:text"`
+	CreatedAt          time.Time
+}
+
+func MapEntityToMessageStatusDto(entity MessageStatus) dtos.MessageStatusDto {
+	var messageID int64
+	if entity.MessagesID != nil {
+		messageID = *entity.MessagesID
+	}
+	return dtos.MessageStatusDto{
+		ID:                 entity.ID,
+		MessagesID:         messageID,
+		MessageIdWhatsapp:  entity.MessageIdWhatsapp,
+		Status:             entity.Status,
+		StatusTimestamp:    entity.StatusTimestamp,
+		RecipientID:        entity.RecipientID,
+		ConversationID:     entity.ConversationID,
+		ConversationOrigin: entity.ConversationOrigin,
+		PricingCategory:    entity.PricingCategory,
+		Billable:           entity.Billable,
+		ErrorCode:          entity.ErrorCode,
+		ErrorTitle:         entity.ErrorTitle,
+		ErrorDetails:       entity.ErrorDetails,
+		CreatedAt:          entity.CreatedAt,
+	}
+}
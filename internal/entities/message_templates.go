@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// MessageTemplate es la copia local de un template del WhatsApp Business Account del número (se sincroniza con Meta)
+type MessageTemplate struct {
+	ID              int64       `gorm:"primaryKey;autoIncrement"`
+	NumberPhonesID  int64       `gorm:"not null;uniqueIndex:idx_message_templates_name_language"`
+	NumberPhone     NumberPhone `gorm:"foreignKey:NumberPhonesID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MetaTemplateID  string      `gorm:"size:50"`
+	Name            string      `gorm:"size:255;not null;uniqueIndex:idx_message_templates_name_language"`
+	Language        string      `gorm:"size:15;not null;uniqueIndex:idx_message_templates_name_language"`
+	Category        string      `gorm:"size:30"`
+	Status          string      `gorm:"size:20;not null;index"` // APPROVED, PENDING, REJECTED, PAUSED o DISABLED
+	RejectedReason  string      `gorm:"size:255"`
+	Components      string      `gorm:"type:text"` // JSON de los componentes tal como los devuelve Meta
+	ParameterSchema string      `gorm:"type:text"` // JSON de dtos.TemplateParameterSchemaDto
+	SyncedAt        *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Schema devuelve los parámetros que espera el template
+func (t MessageTemplate) Schema() dtos.TemplateParameterSchemaDto {
+	var schema dtos.TemplateParameterSchemaDto
+	_ = json.Unmarshal([]byte(t.ParameterSchema), &schema)
+	return schema
+}
+
+// AssistantTemplate es el template que usa el assistant en un momento del ciclo de vida de los eventos
+type AssistantTemplate struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	AssistantsID int64     `gorm:"not null;uniqueIndex:idx_assistant_templates_event"`
+	Assistant    Assistant `gorm:"foreignKey:AssistantsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Event        string    `gorm:"size:20;not null;uniqueIndex:idx_assistant_templates_event"` // created, updated, cancelled o reminder
+	TemplateName string    `gorm:"size:255;not null"`
+	Language     string    `gorm:"size:15;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func MapEntityToMessageTemplateDto(entity MessageTemplate) dtos.MessageTemplateDto {
+	var components interface{}
+	_ = json.Unmarshal([]byte(entity.Components), &components)
+
+	return dtos.MessageTemplateDto{
+		ID:              entity.ID,
+		NumberPhonesID:  entity.NumberPhonesID,
+		MetaTemplateID:  entity.MetaTemplateID,
+		Name:            entity.Name,
+		Language:        entity.Language,
+		Category:        entity.Category,
+		Status:          entity.Status,
+		RejectedReason:  entity.RejectedReason,
+		Components:      components,
+		ParameterSchema: entity.Schema(),
+		SyncedAt:        entity.SyncedAt,
+	}
+}
+
+func MapEntityToAssistantTemplateDto(entity AssistantTemplate) dtos.AssistantTemplateDto {
+	return dtos.AssistantTemplateDto{
+		Event:        entity.Event,
+		TemplateName: entity.TemplateName,
+		Language:     entity.Language,
+	}
+}
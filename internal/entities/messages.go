@@ -15,32 +15,57 @@ type Message struct {
 	Contact           Contact        `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`     // Relación con Contact
 	MessageText       string         `gorm:"type:text;not null"`                                                      // Texto del mensaje
 	IsFromBot         bool           `gorm:"not null"`                                                                // Indica si el mensaje fue enviado por el bot (Assistant)
-	MessageIdWhatsapp string         `gorm:"type:message_id_whatsapp;not null"`                                       // Texto del mensaje
+	MessageIdWhatsapp string         `gorm:"size:255;not null;index"`                                                 // wamid del mensaje
+	MessageType       string         `gorm:"size:20;not null;default:text"`                                           // Tipo de mensaje de WhatsApp (text, audio, image, document, location...)
+	MediaPath         string         `gorm:"type:text"`                                                               // Nombre del objeto en MinIO si el mensaje trae un archivo
+	MediaMimeType     string         `gorm:"size:100"`                                                                // Mime type del archivo recibido
+	Status            string         `gorm:"size:20"`                                                                 // Último estado informado por WhatsApp (sent, delivered, read, failed)
+	StatusUpdatedAt   *time.Time     `gorm:"default:null"`                                                            // Fecha del último estado
+	ErrorCode         int            `gorm:"default:0"`                                                               // Código de error de WhatsApp si el envío falló
+	ErrorTitle        string         `gorm:"type:text"`                                                               // Descripción del error si el envío falló
+	UsersID           *int64         `gorm:"index"`                                                                   // Operador que lo envió desde la bandeja (nil si lo escribió el bot)
 	CreatedAt         time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP"`                                      // Fecha de creación
-	UpdatedAt         time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP"`          // Fecha de última actualización
+	UpdatedAt         time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP"`                                      // Fecha de última actualización
 	DeletedAt         gorm.DeletedAt `gorm:"index"`                                                                   // Soft delete
 }
 
 func MapEntityToMessageDto(entity Message) dtos.MessageDto {
 	return dtos.MessageDto{
-		ID:             entity.ID,
-		NumberPhonesID: entity.NumberPhonesID,
-		ContactsID:     entity.ContactsID,
-		MessageText:    entity.MessageText,
-		IsFromBot:      entity.IsFromBot,
-		CreatedAt:      entity.CreatedAt,
-		UpdatedAt:      entity.UpdatedAt,
+		ID:                entity.ID,
+		NumberPhonesID:    entity.NumberPhonesID,
+		ContactsID:        entity.ContactsID,
+		MessageText:       entity.MessageText,
+		IsFromBot:         entity.IsFromBot,
+		MessageType:       entity.MessageType,
+		MediaPath:         entity.MediaPath,
+		MediaMimeType:     entity.MediaMimeType,
+		MessageIdWhatsapp: entity.MessageIdWhatsapp,
+		Status:            entity.Status,
+		StatusUpdatedAt:   entity.StatusUpdatedAt,
+		ErrorCode:         entity.ErrorCode,
+		ErrorTitle:        entity.ErrorTitle,
+		UsersID:           entity.UsersID,
+		CreatedAt:         entity.CreatedAt,
+		UpdatedAt:         entity.UpdatedAt,
 	}
 }
 
 func MapDtoToMessage(dto dtos.MessageDto) Message {
 	return Message{
-		ID:             dto.ID,
-		NumberPhonesID: dto.NumberPhonesID,
-		ContactsID:     dto.ContactsID,
-		MessageText:    dto.MessageText,
-		IsFromBot:      dto.IsFromBot,
-		CreatedAt:      dto.CreatedAt,
-		UpdatedAt:      dto.UpdatedAt,
+		ID:                dto.ID,
+		NumberPhonesID:    dto.NumberPhonesID,
+		ContactsID:        dto.ContactsID,
+		MessageText:       dto.MessageText,
+		IsFromBot:         dto.IsFromBot,
+		MessageType:       dto.MessageType,
+		MediaPath:         dto.MediaPath,
+		MediaMimeType:     dto.MediaMimeType,
+		MessageIdWhatsapp: dto.MessageIdWhatsapp,
+		Status:            dto.Status,
+		StatusUpdatedAt:   dto.StatusUpdatedAt,
+		ErrorCode:         dto.ErrorCode,
+		ErrorTitle:        dto.ErrorTitle,
+		CreatedAt:         dto.CreatedAt,
+		UpdatedAt:         dto.UpdatedAt,
 	}
 }
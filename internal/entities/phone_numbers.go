@@ -11,11 +11,14 @@ type NumberPhone struct {
 	ID                    int64     `gorm:"primaryKey;autoIncrement"`
 	AssistantsID          int64     `gorm:"not null"`                                                              // Clave foránea hacia Assistant
 	Assistant             Assistant `gorm:"foreignKey:AssistantsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // Relación con Assistant
-	NumberPhone           int64     `gorm:"not null"`
+	NumberPhone           string    `gorm:"size:20;not null"`                                                      // E.164 ("+5493515551234")
 	UUID                  string    `gorm:"not null;unique"`
-	NumberPhoneToNotify   int64     `gorm:"default:0"`
+	NumberPhoneToNotify   string    `gorm:"size:20"` // E.164, vacío si no se notifica a nadie
 	TokenPermanent        string    `gorm:"not null;unique"`
 	WhatsappNumberPhoneId int64     `gorm:"not null;unique"`
+	WhatsappBusinessID    int64     `gorm:"default:0"`                 // WhatsApp Business Account (WABA) del número, donde están sus templates
+	MessagingLimitTier    string    `gorm:"size:20"`                   // Tier de mensajes de Meta: cuántos contactos distintos se pueden iniciar por día (vacío = TIER_250)
+	AppSecret             string    `gorm:"size:255"`                  // App secret de la app de Meta, se usa para validar la firma X-Hub-Signature-256 del webhook
 	Active                bool      `gorm:"default:false"`             // Activo cuando el usuario escanea con éxito el QR
 	Contacts              []Contact `gorm:"foreignKey:NumberPhonesID"` // Relación de uno a muchos con Contact
 	CreatedAt             time.Time
@@ -23,16 +26,20 @@ type NumberPhone struct {
 	DeletedAt             gorm.DeletedAt `gorm:"index"` // Soft delete
 }
 
+// MapEntityToNumberPhoneDto arma el DTO que devuelve la API. El app secret no se copia: firma los webhooks y no
+// tiene que salir de la base.
 func MapEntityToNumberPhoneDto(entity NumberPhone) dtos.NumberPhoneDto {
 
 	return dtos.NumberPhoneDto{
 		ID:                    entity.ID,
 		AssistantsID:          entity.AssistantsID,
-		NumberPhone:           entity.NumberPhone,
+		NumberPhone:           dtos.PhoneNumber(entity.NumberPhone),
 		UUID:                  entity.UUID,
 		TokenPermanent:        entity.TokenPermanent,
-		NumberPhoneToNotify:   entity.NumberPhoneToNotify,
+		NumberPhoneToNotify:   dtos.PhoneNumber(entity.NumberPhoneToNotify),
 		WhatsappNumberPhoneId: entity.AssistantsID,
+		WhatsappBusinessID:    entity.WhatsappBusinessID,
+		MessagingLimitTier:    entity.MessagingLimitTier,
 		Active:                entity.Active,
 	}
 }
@@ -41,11 +48,14 @@ func MapDtoToNumberPhone(dto dtos.NumberPhoneDto) NumberPhone {
 	return NumberPhone{
 		ID:                    dto.ID,
 		AssistantsID:          dto.AssistantsID,
-		NumberPhone:           dto.NumberPhone,
+		NumberPhone:           string(dto.NumberPhone),
 		UUID:                  dto.UUID,
-		NumberPhoneToNotify:   dto.NumberPhoneToNotify,
+		NumberPhoneToNotify:   string(dto.NumberPhoneToNotify),
 		TokenPermanent:        dto.TokenPermanent,
 		WhatsappNumberPhoneId: dto.WhatsappNumberPhoneId,
+		WhatsappBusinessID:    dto.WhatsappBusinessID,
+		MessagingLimitTier:    dto.MessagingLimitTier,
+		AppSecret:             dto.AppSecret,
 		Active:                dto.Active,
 	}
 }
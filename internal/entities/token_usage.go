@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+)
+
+// TokenUsage son los tokens que consumió una respuesta del modelo a un contacto. Se guardan el bussiness, el
+// assistant y el número para poder agrupar sin joins, y el día (en la zona horaria de Argentina) para los
+// reportes diarios y la cuota mensual.
+type TokenUsage struct {
+	ID               int64     `gorm:"primaryKey;autoIncrement"`
+	BussinessID      int64     `gorm:"not null;index:idx_token_usages_bussiness_day"`
+	AssistantsID     int64     `gorm:"not null;index"`
+	NumberPhonesID   int64     `gorm:"not null;index"`
+	ContactsID       int64     `gorm:"not null;index"`
+	Contact          Contact   `gorm:"foreignKey:ContactsID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Day              string    `gorm:"size:10;not null;index:idx_token_usages_bussiness_day"` // YYYY-MM-DD
+	Provider         string    `gorm:"size:50"`
+	Model            string    `gorm:"size:100"`
+	PromptTokens     int64     `gorm:"not null;default:0"`
+	CompletionTokens int64     `gorm:"not null;default:0"`
+	Cost             float64   `gorm:"not null;default:0"` // USD, con los precios vigentes cuando se respondió
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,400 @@
+// Package migrations aplica los cambios de esquema versionados que van embebidos en el binario (sql/*.sql) y
+// carga los datos iniciales (roles y catálogo de permisos).
+//
+// Cada migración son dos archivos: NNNN_nombre.up.sql y NNNN_nombre.down.sql. Las versiones aplicadas se guardan
+// en schema_migrations y cada migración corre en su propia transacción.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Tabla donde se registran las versiones aplicadas
+const versionsTable = "schema_migrations"
+
+// Número arbitrario para el advisory lock de Postgres, evita que dos instancias migren a la vez
+const advisoryLockID = 7423150021
+
+var (
+	// ErrSchemaBehind indica que hay migraciones embebidas que todavía no se aplicaron
+	ErrSchemaBehind = errors.New("el esquema de la base está desactualizado")
+	// ErrSchemaAhead indica que la base tiene migraciones aplicadas que este binario no conoce (una versión más nueva)
+	ErrSchemaAhead = errors.New("el esquema de la base es más nuevo que el binario")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration es un cambio de esquema con su SQL de ida y de vuelta
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus es una migración con la fecha en que se aplicó (nil si está pendiente)
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return versionsTable
+}
+
+// Load lee las migraciones de fsys (archivos *.sql en la raíz) ordenadas por versión
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s (se espera NNNN_nombre.up.sql o NNNN_nombre.down.sql)", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("la versión %d tiene dos nombres: %s y %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("a la migración %04d_%s le falta el archivo up", migration.Version, migration.Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("a la migración %04d_%s le falta el archivo down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Embedded devuelve las migraciones que van dentro del binario
+func Embedded() ([]Migration, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Migrator aplica y revierte migraciones sobre una base
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New arma un Migrator con las migraciones embebidas
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Embedded()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations), nil
+}
+
+// NewWithMigrations arma un Migrator con otras migraciones (se usa en los tests)
+func NewWithMigrations(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+func (m *Migrator) ensureVersionsTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS ` + versionsTable + ` (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+}
+
+func (m *Migrator) applied() (map[int64]appliedMigration, error) {
+	if err := m.ensureVersionsTable(); err != nil {
+		return nil, err
+	}
+	var rows []appliedMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// withLock corre fn con el advisory lock tomado en Postgres, así dos instancias no migran a la vez. En otros motores
+// la corre directamente.
+func (m *Migrator) withLock(fn func(locked *Migrator) error) error {
+	if m.db.Dialector.Name() != "postgres" {
+		return fn(m)
+	}
+	// El lock es de la sesión: todo tiene que correr sobre la misma conexión
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockID)
+		return fn(&Migrator{db: conn, migrations: m.migrations})
+	})
+}
+
+// Up aplica las migraciones pendientes en orden, como mucho steps (0 = todas). Devuelve las que aplicó.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(func(locked *Migrator) error {
+		applied, err := locked.applied()
+		if err != nil {
+			return err
+		}
+		for _, migration := range locked.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if err := locked.run(migration, migration.Up, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down revierte las últimas steps migraciones aplicadas (como mínimo una). Devuelve las que revirtió.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	var done []Migration
+	err := m.withLock(func(locked *Migrator) error {
+		applied, err := locked.applied()
+		if err != nil {
+			return err
+		}
+		for i := len(locked.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := locked.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := locked.run(migration, migration.Down, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) run(migration Migration, script string, up bool) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		}
+		return tx.Delete(&appliedMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		direction := "up"
+		if !up {
+			direction = "down"
+		}
+		return fmt.Errorf("migración %04d_%s (%s): %w", migration.Version, migration.Name, direction, err)
+	}
+	return nil
+}
+
+// Status devuelve todas las migraciones conocidas y las aplicadas que el binario no conoce, ordenadas por versión
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := map[int64]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, row := range applied {
+		if !known[version] {
+			appliedAt := row.AppliedAt
+			statuses = append(statuses, MigrationStatus{Version: version, Name: row.Name, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check verifica que la base tenga aplicadas exactamente las migraciones del binario. Se llama al arrancar la API
+// para no atender requests con un esquema que no corresponde.
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	known := map[int64]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	var unknown []string
+	for version, row := range applied {
+		if !known[version] {
+			unknown = append(unknown, fmt.Sprintf("%04d_%s", version, row.Name))
+		}
+	}
+	sort.Strings(unknown)
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: migraciones aplicadas desconocidas %s", ErrSchemaAhead, strings.Join(unknown, ", "))
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: migraciones pendientes %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Create genera los archivos vacíos de una migración nueva en dir, con la versión siguiente a la última que haya
+// ahí. Devuelve las rutas creadas. Hay que recompilar para que la migración quede embebida.
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("falta el nombre de la migración")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	version := int64(1)
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	paths := []string{base + ".up.sql", base + ".down.sql"}
+	for _, path := range paths {
+		header := fmt.Sprintf("-- %04d_%s\n", version, name)
+		if err := os.WriteFile(path, []byte(header), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// splitStatements separa un script en sentencias por los ";" que no están dentro de strings, comentarios o
+// bloques $$ (el driver de Postgres no acepta varias sentencias en un Exec con parámetros)
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	inString, inDollar, inComment := false, false, false
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		char := script[i]
+		switch {
+		case inComment:
+			if char == '\n' {
+				inComment = false
+				current.WriteByte(char)
+			}
+			continue
+		case inString:
+			if char == '\'' {
+				inString = false
+			}
+		case inDollar:
+			if char == '$' && i+1 < len(script) && script[i+1] == '$' {
+				inDollar = false
+				current.WriteString("$$")
+				i++
+				continue
+			}
+		case char == '-' && i+1 < len(script) && script[i+1] == '-':
+			inComment = true
+			continue
+		case char == '\'':
+			inString = true
+		case char == '$' && i+1 < len(script) && script[i+1] == '$':
+			inDollar = true
+			current.WriteString("$$")
+			i++
+			continue
+		case char == ';':
+			flush()
+			continue
+		}
+		current.WriteByte(char)
+	}
+	flush()
+	return statements
+}
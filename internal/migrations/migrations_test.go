@@ -0,0 +1,248 @@
+package migrations
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	return db
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil {
+		t.Fatalf("loading embedded migrations: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must be consecutive starting at 1", migration.Version, migration.Name)
+		}
+	}
+}
+
+// Las migraciones tienen que tener todas las tablas y columnas de las entities: las tablas se crean en el esquema
+// inicial y las columnas nuevas se agregan con ALTER TABLE en las siguientes
+func TestMigrationsMatchEntities(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("loading embedded migrations: %v", err)
+	}
+	up, down := migrations[0].Up, migrations[0].Down
+	var later strings.Builder
+	for _, migration := range migrations[1:] {
+		later.WriteString(migration.Up)
+	}
+
+	for _, model := range Models {
+		parsed, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("parsing %T: %v", model, err)
+		}
+		block := regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS ` + parsed.Table + ` \((.*?)\n\);`).FindStringSubmatch(up)
+		if block == nil {
+			t.Errorf("%s: table missing from %s", parsed.Table, "0001 up")
+			continue
+		}
+		for _, field := range parsed.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			inTable := regexp.MustCompile(`(?m)^\s+` + field.DBName + `\s`).MatchString(block[1])
+			added := strings.Contains(later.String(), "ALTER TABLE "+parsed.Table+" ADD COLUMN IF NOT EXISTS "+field.DBName+" ")
+			if !inTable && !added {
+				t.Errorf("%s: column %s missing from the migrations", parsed.Table, field.DBName)
+			}
+		}
+		if !strings.Contains(down, "DROP TABLE IF EXISTS "+parsed.Table+";") {
+			t.Errorf("%s: table missing from 0001 down", parsed.Table)
+		}
+	}
+}
+
+func TestMigratorUpDownAndCheck(t *testing.T) {
+	db := testDB(t)
+	migrations, err := Load(fstest.MapFS{
+		"0001_create_notes.up.sql":   {Data: []byte("-- notas; con punto y coma en el comentario\nCREATE TABLE notes (id INTEGER PRIMARY KEY, text TEXT);\nINSERT INTO notes (text) VALUES ('hola; chau');")},
+		"0001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+		"0002_add_author.up.sql":     {Data: []byte("ALTER TABLE notes ADD COLUMN author TEXT;")},
+		"0002_add_author.down.sql":   {Data: []byte("ALTER TABLE notes DROP COLUMN author;")},
+	})
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	migrator := NewWithMigrations(db, migrations)
+
+	if err := migrator.Check(); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("Check on an empty database = %v, want ErrSchemaBehind", err)
+	}
+
+	applied, err := migrator.Up(1)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("Up(1) = %d migrations, %v", len(applied), err)
+	}
+	if err := migrator.Check(); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("Check with a pending migration = %v, want ErrSchemaBehind", err)
+	}
+	if applied, err := migrator.Up(0); err != nil || len(applied) != 1 || applied[0].Version != 2 {
+		t.Fatalf("Up(0) = %v, %v; want migration 2", applied, err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Errorf("Check after Up = %v", err)
+	}
+	var text string
+	if err := db.Raw("SELECT text FROM notes").Scan(&text).Error; err != nil || text != "hola; chau" {
+		t.Errorf("migration data = %q, %v", text, err)
+	}
+
+	// Un binario que solo conoce la primera migración no arranca contra esta base
+	if err := NewWithMigrations(db, migrations[:1]).Check(); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("Check with an unknown applied migration = %v, want ErrSchemaAhead", err)
+	}
+
+	if reverted, err := migrator.Down(0); err != nil || len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("Down(0) = %v, %v; want migration 2", reverted, err)
+	}
+	statuses, err := migrator.Status()
+	if err != nil || len(statuses) != 2 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("Status after Down = %+v, %v", statuses, err)
+	}
+	if db.Migrator().HasColumn("notes", "author") {
+		t.Error("Down did not revert migration 2")
+	}
+}
+
+func TestMigratorRollsBackFailedMigration(t *testing.T) {
+	db := testDB(t)
+	migrations, _ := Load(fstest.MapFS{
+		"0001_broken.up.sql":   {Data: []byte("CREATE TABLE broken (id INTEGER);\nINSERT INTO missing_table VALUES (1);")},
+		"0001_broken.down.sql": {Data: []byte("DROP TABLE broken;")},
+	})
+	migrator := NewWithMigrations(db, migrations)
+
+	if _, err := migrator.Up(0); err == nil {
+		t.Fatal("Up with a broken migration did not fail")
+	}
+	if db.Migrator().HasTable("broken") {
+		t.Error("the failed migration was not rolled back")
+	}
+	if err := migrator.Check(); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("Check after a failed migration = %v, want ErrSchemaBehind", err)
+	}
+}
+
+func TestLoadRejectsIncompleteMigrations(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {"0001_a.up.sql": {Data: []byte("SELECT 1;")}},
+		"bad name":     {"create_users.sql": {Data: []byte("SELECT 1;")}},
+		"two names": {
+			"0001_a.up.sql": {Data: []byte("SELECT 1;")}, "0001_a.down.sql": {Data: []byte("SELECT 1;")},
+			"0001_b.up.sql": {Data: []byte("SELECT 1;")}, "0001_b.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load did not fail", name)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/0001_initial.up.sql", []byte("SELECT 1;"), 0o644)
+	os.WriteFile(dir+"/0001_initial.down.sql", []byte("SELECT 1;"), 0o644)
+
+	paths, err := Create(dir, "Add contact birthday")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	want := []string{dir + "/0002_add_contact_birthday.up.sql", dir + "/0002_add_contact_birthday.down.sql"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Create = %v, want %v", paths, want)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&entities.Roles{}, &entities.Permissions{}); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	if err := Seed(db, "admin"); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	var admin, user entities.Roles
+	db.Preload("Permissions").Where("rol = ?", "admin").First(&admin)
+	db.Preload("Permissions").Where("rol = ?", DefaultUserRole).First(&user)
+	if len(admin.Permissions) != len(PermissionCatalogue) {
+		t.Errorf("admin has %d permissions, want %d", len(admin.Permissions), len(PermissionCatalogue))
+	}
+	for _, permission := range user.Permissions {
+		if strings.HasPrefix(permission.Permission, "users.") {
+			t.Errorf("user role has %s", permission.Permission)
+		}
+	}
+
+	// Lo que se quitó a mano no vuelve a asignarse, y no se duplica nada
+	userPermissions := len(user.Permissions)
+	if err := db.Model(&user).Association("Permissions").Delete(&user.Permissions[0]); err != nil {
+		t.Fatalf("removing permission: %v", err)
+	}
+	if err := Seed(db, "admin"); err != nil {
+		t.Fatalf("second Seed: %v", err)
+	}
+	var roles, permissions int64
+	db.Model(&entities.Roles{}).Count(&roles)
+	db.Model(&entities.Permissions{}).Count(&permissions)
+	if roles != 2 || permissions != int64(len(PermissionCatalogue)) {
+		t.Errorf("after seeding twice: %d roles, %d permissions", roles, permissions)
+	}
+	if count := db.Model(&user).Association("Permissions").Count(); count != int64(userPermissions-1) {
+		t.Errorf("user role has %d permissions after seeding again, want %d", count, userPermissions-1)
+	}
+}
+
+// Todos los permisos que usan las rutas tienen que estar en el catálogo, y el catálogo no tiene permisos sin uso
+func TestPermissionCatalogueMatchesRoutes(t *testing.T) {
+	source, err := os.ReadFile("../routes/handler.go")
+	if err != nil {
+		t.Fatalf("reading routes: %v", err)
+	}
+	used := map[string]bool{}
+	for _, match := range regexp.MustCompile(`ValidarPermiso\("([^"]+)"\)`).FindAllStringSubmatch(string(source), -1) {
+		used[match[1]] = true
+	}
+
+	catalogue := map[string]bool{}
+	for _, seed := range PermissionCatalogue {
+		if catalogue[seed.Permission] {
+			t.Errorf("%s is twice in the catalogue", seed.Permission)
+		}
+		catalogue[seed.Permission] = true
+		if !used[seed.Permission] {
+			t.Errorf("%s is in the catalogue but no route uses it", seed.Permission)
+		}
+	}
+	for permission := range used {
+		if !catalogue[permission] {
+			t.Errorf("%s is used by a route but missing from PermissionCatalogue", permission)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// Models son todas las entities que tienen tabla, en orden de dependencia (las referenciadas primero). El esquema de
+// 0001_initial_schema tiene que tener las mismas tablas y columnas.
+var Models = []interface{}{&entities.Roles{}, &entities.Permissions{}, &entities.Users{}, &entities.PasswordResets{}, &entities.Bussines{},
+	&entities.BussinessHasUsers{}, &entities.Assistant{}, &entities.NumberPhone{}, &entities.Contact{}, &entities.ContactTag{}, &entities.Thread{},
+	&entities.Message{}, &entities.MessageStatus{}, &entities.Events{}, &entities.EventReminder{}, &entities.File{}, &entities.AssistantClosure{},
+	&entities.MessageTemplate{}, &entities.AssistantTemplate{}, &entities.Campaign{}, &entities.CampaignRecipient{}, &entities.InboundJob{},
+	&entities.Configuration{}, &entities.GoogleCalendarCredential{}, &entities.Logs{}, &entities.TokenUsage{}}
+
+// AutoMigrate crea o completa las tablas a partir de las entities. Las migraciones versionadas son SQL de Postgres:
+// en MySQL y SQLite (tests y desarrollo local) el esquema se arma así.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(Models...)
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// DefaultUserRole es el rol de los usuarios de un bussiness. El del administrador se configura con ROL_ADMIN.
+const DefaultUserRole = "user"
+
+// PermissionSeed es un permiso del catálogo y si lo tiene el rol de usuario (el administrador los tiene todos)
+type PermissionSeed struct {
+	Permission  string
+	Description string
+	ForUsers    bool
+}
+
+// PermissionCatalogue son todos los permisos que valida la API (middleware.ValidarPermiso). Al agregar una ruta con
+// un permiso nuevo hay que sumarlo acá.
+var PermissionCatalogue = []PermissionSeed{
+	{"assistants.index", "Listar assistants y archivos", true},
+	{"assistants.show", "Ver assistants, sus cierres y templates", true},
+	{"assistants.create", "Crear assistants y subir archivos", true},
+	{"assistants.edit", "Editar assistants, archivos, cierres y templates", true},
+	{"assistants.delete", "Eliminar assistants y archivos", true},
+	{"assistants.google_account", "Vincular la cuenta de Google Calendar del assistant", true},
+	{"bussiness.index", "Listar bussiness", true},
+	{"bussiness.show", "Ver un bussiness, su cuota y su consumo", true},
+	{"bussiness.create", "Crear bussiness", false},
+	{"bussiness.edit", "Editar bussiness", true},
+	{"bussiness.quota", "Cambiar la cuota mensual de tokens y costo de un bussiness", false},
+	{"bussiness.delete", "Eliminar bussiness", false},
+	{"contacts.index", "Listar y exportar contactos", true},
+	{"contacts.block", "Editar, bloquear, etiquetar e importar contactos", true},
+	{"events.index", "Ver eventos y disponibilidad, administrar números de WhatsApp", true},
+	{"events.create", "Crear eventos", true},
+	{"events.edit", "Editar eventos", true},
+	{"events.delete", "Cancelar eventos", true},
+	{"messages.index", "Ver conversaciones, estados de mensajes y campañas", true},
+	{"whatsapp.send_message", "Enviar mensajes de WhatsApp y administrar campañas", true},
+	{"telegram.send_message", "Enviar mensajes de Telegram", false},
+	{"inbound_jobs.index", "Ver la cola de webhooks de WhatsApp", false},
+	{"inbound_jobs.replay", "Reprocesar webhooks fallidos", false},
+	{"logs.index", "Ver logs", false},
+	{"users.index", "Listar usuarios", false},
+	{"users.show", "Ver un usuario", false},
+	{"users.create", "Crear usuarios", false},
+	{"users.edit", "Editar usuarios", false},
+	{"users.delete", "Eliminar usuarios", false},
+	{"roles.index", "Administrar roles", false},
+	{"permissions.index", "Administrar permisos", false},
+}
+
+// Seed crea los roles de administrador (adminRole) y de usuario y el catálogo de permisos. Se puede correr muchas
+// veces: solo crea lo que falta, y a los roles solo les asigna los permisos nuevos, así no pisa los cambios que se
+// hayan hecho a mano.
+func Seed(db *gorm.DB, adminRole string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		admin, adminCreated, err := seedRole(tx, adminRole, "Administrador, accede a todos los bussiness")
+		if err != nil {
+			return err
+		}
+		user, userCreated, err := seedRole(tx, DefaultUserRole, "Usuario de uno o más bussiness")
+		if err != nil {
+			return err
+		}
+
+		for _, seed := range PermissionCatalogue {
+			permission := entities.Permissions{Permission: seed.Permission}
+			result := tx.Where("permission = ?", seed.Permission).Attrs(entities.Permissions{Description: seed.Description}).FirstOrCreate(&permission)
+			if result.Error != nil {
+				return result.Error
+			}
+			created := result.RowsAffected > 0
+
+			if created || adminCreated {
+				if err := tx.Model(&admin).Association("Permissions").Append(&permission); err != nil {
+					return err
+				}
+			}
+			if seed.ForUsers && (created || userCreated) {
+				if err := tx.Model(&user).Association("Permissions").Append(&permission); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func seedRole(tx *gorm.DB, name, description string) (entities.Roles, bool, error) {
+	role := entities.Roles{Rol: name}
+	result := tx.Where("rol = ?", name).Attrs(entities.Roles{Description: description}).FirstOrCreate(&role)
+	return role, result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,27 @@
+DROP TABLE IF EXISTS token_usages;
+DROP TABLE IF EXISTS logs;
+DROP TABLE IF EXISTS google_calendar_credentials;
+DROP TABLE IF EXISTS configurations;
+DROP TABLE IF EXISTS inbound_jobs;
+DROP TABLE IF EXISTS campaign_recipients;
+DROP TABLE IF EXISTS campaigns;
+DROP TABLE IF EXISTS assistant_templates;
+DROP TABLE IF EXISTS message_templates;
+DROP TABLE IF EXISTS assistant_closures;
+DROP TABLE IF EXISTS files;
+DROP TABLE IF EXISTS event_reminders;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS message_statuses;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS threads;
+DROP TABLE IF EXISTS contact_tags;
+DROP TABLE IF EXISTS contacts;
+DROP TABLE IF EXISTS number_phones;
+DROP TABLE IF EXISTS assistants;
+DROP TABLE IF EXISTS bussiness_has_users;
+DROP TABLE IF EXISTS bussiness;
+DROP TABLE IF EXISTS password_resets;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles_has_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,416 @@
+-- Esquema inicial: las tablas tal como las usan las entities. Usa IF NOT EXISTS para poder aplicarse sobre una
+-- base que ya tenía las tablas creadas a mano.
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          BIGSERIAL PRIMARY KEY,
+    rol         TEXT,
+    description TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id          BIGSERIAL PRIMARY KEY,
+    permission  TEXT,
+    description TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles_has_permissions (
+    roles_id       BIGINT NOT NULL REFERENCES roles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    permissions_id BIGINT NOT NULL REFERENCES permissions (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (roles_id, permissions_id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id             BIGSERIAL PRIMARY KEY,
+    name           TEXT,
+    email          TEXT,
+    password       TEXT,
+    remember_token TEXT,
+    activo         BOOLEAN,
+    telefono       TEXT,
+    cuil_cuit      TEXT,
+    roles_id       BIGINT REFERENCES roles (id),
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS password_resets (
+    id         BIGSERIAL PRIMARY KEY,
+    users_id   BIGINT NOT NULL REFERENCES users (id),
+    token      TEXT UNIQUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_password_resets_deleted_at ON password_resets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bussiness (
+    id                     BIGSERIAL PRIMARY KEY,
+    name                   TEXT NOT NULL,
+    address                TEXT NOT NULL,
+    cuil_cuit              TEXT,
+    web_site               TEXT,
+    monthly_token_quota    BIGINT NOT NULL DEFAULT 0,
+    monthly_cost_quota     DECIMAL NOT NULL DEFAULT 0,
+    quota_fallback_message TEXT,
+    created_at             TIMESTAMPTZ,
+    updated_at             TIMESTAMPTZ,
+    deleted_at             TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_bussiness_deleted_at ON bussiness (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bussiness_has_users (
+    bussiness_id BIGINT NOT NULL REFERENCES bussiness (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    users_id     BIGINT NOT NULL REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ,
+    PRIMARY KEY (bussiness_id, users_id)
+);
+CREATE INDEX IF NOT EXISTS idx_bussiness_has_users_deleted_at ON bussiness_has_users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS assistants (
+    id                   BIGSERIAL PRIMARY KEY,
+    bussiness_id         BIGINT NOT NULL REFERENCES bussiness (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name                 TEXT NOT NULL,
+    openai_assistants_id TEXT,
+    description          TEXT,
+    model                TEXT,
+    instructions         TEXT,
+    opening_days         SMALLINT NOT NULL,
+    working_hours        VARCHAR(100) NOT NULL,
+    break_hours          VARCHAR(100),
+    active               BOOLEAN NOT NULL DEFAULT true,
+    event_duration       BIGINT NOT NULL DEFAULT 30,
+    event_type           VARCHAR(50),
+    event_count_per_day  SMALLINT NOT NULL DEFAULT 1,
+    slot_capacity        SMALLINT NOT NULL DEFAULT 1,
+    reminder_offsets     VARCHAR(50),
+    reminder_template    VARCHAR(100),
+    llm_provider         VARCHAR(30) NOT NULL DEFAULT 'openai_assistants',
+    llm_base_url         VARCHAR(255),
+    account_google       BOOLEAN DEFAULT false,
+    created_at           TIMESTAMPTZ,
+    updated_at           TIMESTAMPTZ,
+    deleted_at           TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_assistants_deleted_at ON assistants (deleted_at);
+
+CREATE TABLE IF NOT EXISTS number_phones (
+    id                       BIGSERIAL PRIMARY KEY,
+    assistants_id            BIGINT NOT NULL REFERENCES assistants (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    number_phone             VARCHAR(20) NOT NULL,
+    uuid                     TEXT NOT NULL UNIQUE,
+    number_phone_to_notify   VARCHAR(20),
+    token_permanent          TEXT NOT NULL UNIQUE,
+    whatsapp_number_phone_id BIGINT NOT NULL UNIQUE,
+    whatsapp_business_id     BIGINT DEFAULT 0,
+    messaging_limit_tier     VARCHAR(20),
+    app_secret               VARCHAR(255),
+    active                   BOOLEAN DEFAULT false,
+    created_at               TIMESTAMPTZ,
+    updated_at               TIMESTAMPTZ,
+    deleted_at               TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_number_phones_deleted_at ON number_phones (deleted_at);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id                 BIGSERIAL PRIMARY KEY,
+    number_phones_id   BIGINT NOT NULL REFERENCES number_phones (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    number_phone       VARCHAR(20) NOT NULL,
+    is_blocked         BOOLEAN,
+    profile_name       VARCHAR(255),
+    name               VARCHAR(255),
+    email              VARCHAR(255),
+    custom_fields      TEXT,
+    notes              TEXT,
+    handoff_mode       VARCHAR(10) NOT NULL DEFAULT 'bot',
+    handoff_reason     VARCHAR(255),
+    handoff_at         TIMESTAMPTZ,
+    handoff_expires_at TIMESTAMPTZ,
+    opted_in_at        TIMESTAMPTZ,
+    opted_out_at       TIMESTAMPTZ,
+    count_tokens       TEXT,
+    created_at         TIMESTAMPTZ,
+    updated_at         TIMESTAMPTZ,
+    deleted_at         TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_contacts_number_phones_id ON contacts (number_phones_id);
+CREATE INDEX IF NOT EXISTS idx_contacts_handoff_expires_at ON contacts (handoff_expires_at);
+CREATE INDEX IF NOT EXISTS idx_contacts_deleted_at ON contacts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS contact_tags (
+    id          BIGSERIAL PRIMARY KEY,
+    contacts_id BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    tag         VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_tags_tag ON contact_tags (contacts_id, tag);
+CREATE INDEX IF NOT EXISTS idx_contact_tags_tag_name ON contact_tags (tag);
+
+CREATE TABLE IF NOT EXISTS threads (
+    id                BIGSERIAL PRIMARY KEY,
+    openai_threads_id TEXT NOT NULL UNIQUE,
+    active            SMALLINT NOT NULL,
+    contacts_id       BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at        TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ,
+    deleted_at        TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_threads_deleted_at ON threads (deleted_at);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id                  BIGSERIAL PRIMARY KEY,
+    number_phones_id    BIGINT NOT NULL REFERENCES number_phones (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    contacts_id         BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    message_text        TEXT NOT NULL,
+    is_from_bot         BOOLEAN NOT NULL,
+    message_id_whatsapp VARCHAR(255) NOT NULL,
+    message_type        VARCHAR(20) NOT NULL DEFAULT 'text',
+    media_path          TEXT,
+    media_mime_type     VARCHAR(100),
+    status              VARCHAR(20),
+    status_updated_at   TIMESTAMPTZ,
+    error_code          BIGINT DEFAULT 0,
+    error_title         TEXT,
+    users_id            BIGINT,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_messages_contacts_id ON messages (contacts_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_message_id_whatsapp ON messages (message_id_whatsapp);
+CREATE INDEX IF NOT EXISTS idx_messages_users_id ON messages (users_id);
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages (deleted_at);
+
+CREATE TABLE IF NOT EXISTS message_statuses (
+    id                  BIGSERIAL PRIMARY KEY,
+    messages_id         BIGINT REFERENCES messages (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    message_id_whatsapp VARCHAR(255) NOT NULL,
+    status              VARCHAR(20) NOT NULL,
+    status_timestamp    TIMESTAMPTZ NOT NULL,
+    recipient_id        VARCHAR(30),
+    conversation_id     VARCHAR(255),
+    conversation_origin VARCHAR(50),
+    pricing_category    VARCHAR(50),
+    billable            BOOLEAN,
+    error_code          BIGINT,
+    error_title         TEXT,
+    error_details       TEXT,
+    created_at          TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_message_statuses_message_id_whatsapp ON message_statuses (message_id_whatsapp);
+CREATE INDEX IF NOT EXISTS idx_message_statuses_messages_id ON message_statuses (messages_id);
+
+CREATE TABLE IF NOT EXISTS events (
+    id                       BIGSERIAL PRIMARY KEY,
+    summary                  TEXT NOT NULL,
+    description              TEXT NOT NULL,
+    start_date               TEXT NOT NULL,
+    end_date                 TEXT NOT NULL,
+    event_google_calendar_id TEXT,
+    code_event               TEXT NOT NULL,
+    confirmed_at             TIMESTAMPTZ,
+    assistants_id            BIGINT NOT NULL REFERENCES assistants (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    contacts_id              BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at               TIMESTAMPTZ,
+    updated_at               TIMESTAMPTZ,
+    deleted_at               TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_events_assistants_id ON events (assistants_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_events_code_event ON events (code_event);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events (deleted_at);
+
+CREATE TABLE IF NOT EXISTS event_reminders (
+    id                  BIGSERIAL PRIMARY KEY,
+    events_id           BIGINT NOT NULL REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    offset_hours        BIGINT NOT NULL,
+    contacts_id         BIGINT NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'sending',
+    message_id_whatsapp VARCHAR(255),
+    error               TEXT,
+    sent_at             TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_reminders_event_offset ON event_reminders (events_id, offset_hours);
+CREATE INDEX IF NOT EXISTS idx_event_reminders_contacts_id ON event_reminders (contacts_id);
+CREATE INDEX IF NOT EXISTS idx_event_reminders_message_id_whatsapp ON event_reminders (message_id_whatsapp);
+
+CREATE TABLE IF NOT EXISTS files (
+    id                           BIGSERIAL PRIMARY KEY,
+    assistants_id                BIGINT NOT NULL REFERENCES assistants (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    openai_files_id              TEXT,
+    filename                     TEXT,
+    purpose                      TEXT,
+    openai_vector_store_ids      TEXT,
+    openai_vector_store_file_ids TEXT,
+    created_at                   TIMESTAMPTZ,
+    updated_at                   TIMESTAMPTZ,
+    deleted_at                   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_files_deleted_at ON files (deleted_at);
+
+CREATE TABLE IF NOT EXISTS assistant_closures (
+    id               BIGSERIAL PRIMARY KEY,
+    assistants_id    BIGINT NOT NULL REFERENCES assistants (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    start_date       DATE NOT NULL,
+    end_date         DATE NOT NULL,
+    start_time       VARCHAR(5),
+    end_time         VARCHAR(5),
+    recurring_yearly BOOLEAN NOT NULL DEFAULT false,
+    reason           VARCHAR(255),
+    source           VARCHAR(20) NOT NULL DEFAULT 'manual',
+    external_uid     VARCHAR(255),
+    created_at       TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ,
+    deleted_at       TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_assistant_closures_assistants_id ON assistant_closures (assistants_id);
+CREATE INDEX IF NOT EXISTS idx_assistant_closures_external_uid ON assistant_closures (external_uid);
+CREATE INDEX IF NOT EXISTS idx_assistant_closures_deleted_at ON assistant_closures (deleted_at);
+
+CREATE TABLE IF NOT EXISTS message_templates (
+    id               BIGSERIAL PRIMARY KEY,
+    number_phones_id BIGINT NOT NULL REFERENCES number_phones (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    meta_template_id VARCHAR(50),
+    name             VARCHAR(255) NOT NULL,
+    language         VARCHAR(15) NOT NULL,
+    category         VARCHAR(30),
+    status           VARCHAR(20) NOT NULL,
+    rejected_reason  VARCHAR(255),
+    components       TEXT,
+    parameter_schema TEXT,
+    synced_at        TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_templates_name_language ON message_templates (number_phones_id, name, language);
+CREATE INDEX IF NOT EXISTS idx_message_templates_status ON message_templates (status);
+
+CREATE TABLE IF NOT EXISTS assistant_templates (
+    id            BIGSERIAL PRIMARY KEY,
+    assistants_id BIGINT NOT NULL REFERENCES assistants (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    event         VARCHAR(20) NOT NULL,
+    template_name VARCHAR(255) NOT NULL,
+    language      VARCHAR(15) NOT NULL,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assistant_templates_event ON assistant_templates (assistants_id, event);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id                BIGSERIAL PRIMARY KEY,
+    number_phones_id  BIGINT NOT NULL REFERENCES number_phones (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name              VARCHAR(255) NOT NULL,
+    template_name     VARCHAR(255) NOT NULL,
+    template_language VARCHAR(15) NOT NULL,
+    components        TEXT,
+    segment           TEXT,
+    variables         TEXT,
+    status            VARCHAR(20) NOT NULL DEFAULT 'draft',
+    scheduled_at      TIMESTAMPTZ,
+    started_at        TIMESTAMPTZ,
+    finished_at       TIMESTAMPTZ,
+    locked_at         TIMESTAMPTZ,
+    last_error        TEXT,
+    users_id          BIGINT,
+    created_at        TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ,
+    deleted_at        TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_number_phones_id ON campaigns (number_phones_id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_status_scheduled ON campaigns (status, scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_campaigns_users_id ON campaigns (users_id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_deleted_at ON campaigns (deleted_at);
+
+CREATE TABLE IF NOT EXISTS campaign_recipients (
+    id           BIGSERIAL PRIMARY KEY,
+    campaigns_id BIGINT NOT NULL REFERENCES campaigns (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    contacts_id  BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error        TEXT,
+    messages_id  BIGINT REFERENCES messages (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    sent_at      TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaign_recipients_contact ON campaign_recipients (campaigns_id, contacts_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_status ON campaign_recipients (campaigns_id, status);
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_contacts_id ON campaign_recipients (contacts_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_recipients_sent_at ON campaign_recipients (sent_at);
+
+CREATE TABLE IF NOT EXISTS inbound_jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    payload      TEXT NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts     BIGINT NOT NULL DEFAULT 0,
+    max_attempts BIGINT NOT NULL DEFAULT 5,
+    last_error   TEXT,
+    next_run_at  TIMESTAMPTZ NOT NULL,
+    locked_at    TIMESTAMPTZ,
+    processed_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_inbound_jobs_status_next_run ON inbound_jobs (status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS configurations (
+    id          BIGSERIAL PRIMARY KEY,
+    key_name    TEXT NOT NULL UNIQUE,
+    value       TEXT,
+    description TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_configurations_deleted_at ON configurations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS google_calendar_credentials (
+    id             BIGSERIAL PRIMARY KEY,
+    assistants_id  BIGINT NOT NULL,
+    google_user_id TEXT NOT NULL,
+    access_token   TEXT NOT NULL,
+    refresh_token  TEXT NOT NULL,
+    email          TEXT,
+    token_expiry   TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_google_calendar_credentials_assistants_id ON google_calendar_credentials (assistants_id);
+
+CREATE TABLE IF NOT EXISTS logs (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_logs_deleted_at ON logs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS token_usages (
+    id                BIGSERIAL PRIMARY KEY,
+    bussiness_id      BIGINT NOT NULL,
+    assistants_id     BIGINT NOT NULL,
+    number_phones_id  BIGINT NOT NULL,
+    contacts_id       BIGINT NOT NULL REFERENCES contacts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    day               VARCHAR(10) NOT NULL,
+    provider          VARCHAR(50),
+    model             VARCHAR(100),
+    prompt_tokens     BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost              DECIMAL NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_token_usages_bussiness_day ON token_usages (bussiness_id, day);
+CREATE INDEX IF NOT EXISTS idx_token_usages_assistants_id ON token_usages (assistants_id);
+CREATE INDEX IF NOT EXISTS idx_token_usages_number_phones_id ON token_usages (number_phones_id);
+CREATE INDEX IF NOT EXISTS idx_token_usages_contacts_id ON token_usages (contacts_id);
@@ -0,0 +1,2 @@
+ALTER TABLE events DROP COLUMN IF EXISTS rescheduled_at;
+ALTER TABLE event_reminders DROP COLUMN IF EXISTS attempts;
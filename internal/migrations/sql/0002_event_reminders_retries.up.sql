@@ -0,0 +1,5 @@
+-- Reintentos de los recordatorios y fecha de reprogramación de los eventos: si cambia la fecha de inicio se
+-- borran sus recordatorios y los que se agendan tarde se saltean según esa fecha.
+
+ALTER TABLE event_reminders ADD COLUMN IF NOT EXISTS attempts BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS rescheduled_at TIMESTAMPTZ;
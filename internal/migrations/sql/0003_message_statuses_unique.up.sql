@@ -0,0 +1,9 @@
+-- Un callback de estado repetido (Meta lo reenvía o se reintenta el job de la cola) no duplica el historial.
+-- Antes de crear el índice se borran los duplicados que ya había, dejando el primero.
+
+DELETE FROM message_statuses a USING message_statuses b
+WHERE a.message_id_whatsapp = b.message_id_whatsapp
+  AND a.status = b.status
+  AND a.status_timestamp = b.status_timestamp
+  AND a.id > b.id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_statuses_event ON message_statuses (message_id_whatsapp, status, status_timestamp);
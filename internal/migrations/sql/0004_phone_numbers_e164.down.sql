@@ -0,0 +1,2 @@
+-- La conversión a E.164 no se revierte: los contactos que se unificaron no se pueden volver a separar y las columnas
+-- en texto son las que ya define 0001.
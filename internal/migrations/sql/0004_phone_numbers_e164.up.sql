@@ -0,0 +1,65 @@
+-- Los números de teléfono se guardaban como bigint con el wa_id de WhatsApp; ahora son texto en E.164
+-- ("+5493515551234"). En las bases creadas con 0001 las columnas ya son texto y solo se convierten las filas que no
+-- empiezan con +. Las reglas son las de phone.FromWhatsApp: a los celulares de Argentina se les agrega el 9 y a los
+-- de México se les saca el 1; un número que no encaja queda con el + adelante, como venía.
+
+ALTER TABLE contacts ALTER COLUMN number_phone TYPE VARCHAR(20) USING number_phone::text;
+ALTER TABLE number_phones ALTER COLUMN number_phone TYPE VARCHAR(20) USING number_phone::text;
+ALTER TABLE number_phones ALTER COLUMN number_phone_to_notify TYPE VARCHAR(20) USING number_phone_to_notify::text;
+ALTER TABLE number_phones ALTER COLUMN number_phone_to_notify DROP NOT NULL;
+
+-- mobile indica si el número es de un contacto (el wa_id de un celular) o de la plataforma (se deja como está)
+CREATE FUNCTION pg_temp.phone_e164(stored TEXT, mobile BOOLEAN) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN stored LIKE '+%' THEN stored
+        WHEN mobile AND digits ~ '^54[0-9]{10}$' THEN '+549' || substr(digits, 3)
+        WHEN digits ~ '^521[0-9]{10}$' THEN '+52' || substr(digits, 4)
+        ELSE '+' || digits
+    END
+    FROM (SELECT regexp_replace(stored, '[^0-9]', '', 'g') AS digits) AS cleaned
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Dos contactos del mismo número de la plataforma pueden quedar con el mismo E.164 (ej: 54351... y 549351...).
+-- Se conserva uno (el que no está borrado, el que ya estaba en E.164, el más viejo) y se le pasa el historial de
+-- los demás antes de borrarlos.
+CREATE TEMPORARY TABLE contact_merges ON COMMIT DROP AS
+SELECT id, keep_id FROM (
+    SELECT id, first_value(id) OVER (
+        PARTITION BY number_phones_id, pg_temp.phone_e164(number_phone, true)
+        ORDER BY deleted_at IS NOT NULL, number_phone NOT LIKE '+%', id
+    ) AS keep_id
+    FROM contacts
+) AS grouped
+WHERE id <> keep_id;
+
+DELETE FROM contact_tags t USING contact_merges m, contact_tags kept
+WHERE t.contacts_id = m.id AND kept.contacts_id = m.keep_id AND kept.tag = t.tag;
+UPDATE contact_tags t SET contacts_id = m.keep_id FROM contact_merges m WHERE t.contacts_id = m.id;
+
+DELETE FROM campaign_recipients r USING contact_merges m, campaign_recipients kept
+WHERE r.contacts_id = m.id AND kept.contacts_id = m.keep_id AND kept.campaigns_id = r.campaigns_id;
+UPDATE campaign_recipients r SET contacts_id = m.keep_id FROM contact_merges m WHERE r.contacts_id = m.id;
+
+-- Si el que se conserva ya tiene un thread activo, los de los otros quedan inactivos
+UPDATE threads t SET contacts_id = m.keep_id,
+    active = CASE WHEN EXISTS (SELECT 1 FROM threads kept WHERE kept.contacts_id = m.keep_id AND kept.active = 1) THEN 0 ELSE t.active END
+FROM contact_merges m WHERE t.contacts_id = m.id;
+UPDATE messages t SET contacts_id = m.keep_id FROM contact_merges m WHERE t.contacts_id = m.id;
+UPDATE events t SET contacts_id = m.keep_id FROM contact_merges m WHERE t.contacts_id = m.id;
+UPDATE event_reminders t SET contacts_id = m.keep_id FROM contact_merges m WHERE t.contacts_id = m.id;
+UPDATE token_usages t SET contacts_id = m.keep_id FROM contact_merges m WHERE t.contacts_id = m.id;
+
+DELETE FROM contacts c USING contact_merges m WHERE c.id = m.id;
+
+UPDATE contacts SET number_phone = pg_temp.phone_e164(number_phone, true) WHERE number_phone NOT LIKE '+%';
+
+-- Antes se guardaba 0 cuando el número no tenía a quién notificar
+UPDATE number_phones SET
+    number_phone = pg_temp.phone_e164(number_phone, false),
+    number_phone_to_notify = CASE
+        WHEN COALESCE(number_phone_to_notify, '') IN ('', '0') THEN ''
+        ELSE pg_temp.phone_e164(number_phone_to_notify, true)
+    END
+WHERE number_phone NOT LIKE '+%' OR COALESCE(number_phone_to_notify, '') NOT LIKE '+%';
+
+DROP FUNCTION pg_temp.phone_e164(TEXT, BOOLEAN);
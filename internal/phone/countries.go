@@ -0,0 +1,76 @@
+package phone
+
+import "strings"
+
+// country tiene las reglas de longitud del número nacional significativo (sin código de país ni prefijo de larga
+// distancia) de cada país. Para los países que no están en countries se usan las de defaultCountry.
+type country struct {
+	minLength int
+	maxLength int
+	trunk     string // Prefijo que se marca adelante para llamadas nacionales ("0" en casi toda Latinoamérica)
+}
+
+// stripTrunk saca el prefijo nacional de un número escrito en formato nacional
+func (c country) stripTrunk(digits string) string {
+	if c.trunk != "" && strings.HasPrefix(digits, c.trunk) {
+		return digits[len(c.trunk):]
+	}
+	return digits
+}
+
+var defaultCountry = country{minLength: 6, maxLength: 13}
+
+var countries = map[string]country{
+	"1":   {minLength: 10, maxLength: 10, trunk: "1"}, // Estados Unidos, Canadá y el resto del NANP
+	"34":  {minLength: 9, maxLength: 9},               // España
+	"51":  {minLength: 8, maxLength: 9, trunk: "0"},   // Perú
+	"52":  {minLength: 10, maxLength: 10},             // México
+	"54":  {minLength: 10, maxLength: 11, trunk: "0"}, // Argentina (11 con el 9 de celular)
+	"55":  {minLength: 10, maxLength: 11, trunk: "0"}, // Brasil
+	"56":  {minLength: 9, maxLength: 9},               // Chile
+	"57":  {minLength: 10, maxLength: 10},             // Colombia
+	"58":  {minLength: 10, maxLength: 10, trunk: "0"}, // Venezuela
+	"591": {minLength: 8, maxLength: 8, trunk: "0"},   // Bolivia
+	"593": {minLength: 8, maxLength: 9, trunk: "0"},   // Ecuador
+	"595": {minLength: 9, maxLength: 9, trunk: "0"},   // Paraguay
+	"598": {minLength: 8, maxLength: 8, trunk: "0"},   // Uruguay
+}
+
+func countryRules(countryCode string) country {
+	if rules, ok := countries[countryCode]; ok {
+		return rules
+	}
+	return defaultCountry
+}
+
+// callingCodes son los códigos de país asignados por la UIT (E.164). Ninguno es prefijo de otro, así que alcanza
+// con probar de 1 a 3 dígitos.
+var callingCodes = map[string]struct{}{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		1 7 20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58 60 61 62 63 64 65 66
+		81 82 84 86 90 91 92 93 94 95 98
+		211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234 235 236 237 238 239 240
+		241 242 243 244 245 246 247 248 249 250 251 252 253 254 255 256 257 258 260 261 262 263 264 265 266 267
+		268 269 290 291 297 298 299
+		350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379 380 381 382 383 385 386
+		387 389
+		420 421 423
+		500 501 502 503 504 505 506 507 508 509 590 591 592 593 594 595 596 597 598 599
+		670 672 673 674 675 676 677 678 679 680 681 682 683 685 686 687 688 689 690 691 692
+		850 852 853 855 856 880 886
+		960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 995 996 998`) {
+		callingCodes[code] = struct{}{}
+	}
+}
+
+// splitCountryCode devuelve el código de país con el que empieza un número internacional
+func splitCountryCode(digits string) (string, bool) {
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		if _, ok := callingCodes[digits[:length]]; ok {
+			return digits[:length], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,206 @@
+// Package phone interpreta números de teléfono escritos de cualquier forma ("+54 9 351 555-1234",
+// "0351 15 555-1234", el wa_id "5493515551234" de WhatsApp...) y los normaliza a E.164 ("+5493515551234"),
+// que es como se guardan los contactos y los números de la plataforma.
+//
+// Argentina y México tienen prefijos de celular que cambian según cómo se marque:
+//   - Argentina: en formato internacional los celulares llevan un 9 después del 54 (+54 9 351 555-1234) y en
+//     formato nacional llevan 15 después del código de área (0351 15 555-1234). El número canónico es +549...
+//     La API de WhatsApp, en cambio, espera el destinatario sin el 9 (54351...).
+//   - México: los celulares llevaban un 1 después del 52 (+52 1 55 1234 5678) que se eliminó en 2019, pero
+//     WhatsApp lo sigue mandando en el wa_id. El número canónico es +52 más los 10 dígitos, sin el 1.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidNumber se devuelve cuando el texto no es un número de teléfono válido
+var ErrInvalidNumber = errors.New("número de teléfono inválido")
+
+// DefaultCountryCode es el código de país que se asume para los números escritos sin él
+const DefaultCountryCode = "54"
+
+const (
+	countryArgentina = "54"
+	countryMexico    = "52"
+)
+
+// Number es un número de teléfono ya interpretado
+type Number struct {
+	CountryCode string // Código de país sin el + ("54")
+	National    string // Número nacional significativo: sin 0 ni 15 (en los celulares de Argentina empieza con el 9)
+}
+
+// E164 devuelve el número en formato E.164 ("+5493515551234")
+func (n Number) E164() string {
+	return "+" + n.CountryCode + n.National
+}
+
+// Digits devuelve el número en formato E.164 sin el + ("5493515551234")
+func (n Number) Digits() string {
+	return n.CountryCode + n.National
+}
+
+// WhatsAppRecipient devuelve el número como lo espera la API de WhatsApp en el campo "to". Es el E.164 sin el +,
+// salvo los celulares de Argentina, a los que se les saca el 9 (54 351 5551234).
+func (n Number) WhatsAppRecipient() string {
+	if n.CountryCode == countryArgentina && len(n.National) == 11 && n.National[0] == '9' {
+		return n.CountryCode + n.National[1:]
+	}
+	return n.Digits()
+}
+
+// Parse interpreta un número escrito en formato internacional (con + o 00) o nacional. A los números
+// nacionales se les agrega defaultCountryCode (si es vacío se usa DefaultCountryCode). Los números de
+// Argentina sin el 9 de celular se dejan como están porque pueden ser fijos; ver ParseMobile.
+func Parse(raw, defaultCountryCode string) (Number, error) {
+	return parse(raw, defaultCountryCode, false)
+}
+
+// ParseMobile es como Parse pero asume que el número es un celular (todos los contactos de WhatsApp lo son):
+// a los números de Argentina sin el 9 se les agrega
+func ParseMobile(raw, defaultCountryCode string) (Number, error) {
+	return parse(raw, defaultCountryCode, true)
+}
+
+// FromWhatsApp interpreta el wa_id que manda WhatsApp en los webhooks (siempre internacional, sin el +)
+func FromWhatsApp(waID string) (Number, error) {
+	return parse("+"+strings.TrimPrefix(strings.TrimSpace(waID), "+"), "", true)
+}
+
+// Normalize devuelve el número en E.164 (ver Parse)
+func Normalize(raw, defaultCountryCode string) (string, error) {
+	number, err := Parse(raw, defaultCountryCode)
+	if err != nil {
+		return "", err
+	}
+	return number.E164(), nil
+}
+
+// NormalizeMobile devuelve el número en E.164 asumiendo que es un celular (ver ParseMobile)
+func NormalizeMobile(raw, defaultCountryCode string) (string, error) {
+	number, err := ParseMobile(raw, defaultCountryCode)
+	if err != nil {
+		return "", err
+	}
+	return number.E164(), nil
+}
+
+// Recipient devuelve el destinatario para la API de WhatsApp de un número guardado (E.164 o solo dígitos con el
+// código de país). Si no se puede interpretar se devuelven sus dígitos tal cual y que WhatsApp decida.
+func Recipient(stored string) string {
+	number, err := FromWhatsApp(stored)
+	if err != nil {
+		return strings.TrimPrefix(strings.TrimSpace(stored), "+")
+	}
+	return number.WhatsAppRecipient()
+}
+
+func parse(raw, defaultCountryCode string, mobile bool) (Number, error) {
+	digits, international, err := cleanDigits(raw)
+	if err != nil {
+		return Number{}, err
+	}
+	if defaultCountryCode == "" {
+		defaultCountryCode = DefaultCountryCode
+	}
+
+	var number Number
+	if international {
+		countryCode, ok := splitCountryCode(digits)
+		if !ok {
+			return Number{}, fmt.Errorf("%w: código de país desconocido en %q", ErrInvalidNumber, raw)
+		}
+		number = Number{CountryCode: countryCode, National: digits[len(countryCode):]}
+	} else {
+		// Sin + ni 00 puede venir con el código de país igual (como los exporta WhatsApp) o en formato nacional
+		country := countryRules(defaultCountryCode)
+		if strings.HasPrefix(digits, defaultCountryCode) && len(digits)-len(defaultCountryCode) >= country.minLength {
+			number = Number{CountryCode: defaultCountryCode, National: digits[len(defaultCountryCode):]}
+		} else {
+			number = Number{CountryCode: defaultCountryCode, National: country.stripTrunk(digits)}
+		}
+	}
+
+	switch number.CountryCode {
+	case countryArgentina:
+		number.National = normalizeArgentina(number.National, mobile)
+	case countryMexico:
+		number.National = normalizeMexico(number.National)
+	}
+
+	country := countryRules(number.CountryCode)
+	if len(number.National) < country.minLength || len(number.National) > country.maxLength ||
+		len(number.Digits()) > 15 || number.National[0] == '0' {
+		return Number{}, fmt.Errorf("%w: %q no tiene la cantidad de dígitos de un número de +%s", ErrInvalidNumber, raw, number.CountryCode)
+	}
+	return number, nil
+}
+
+// cleanDigits saca los separadores habituales (espacios, guiones, puntos, paréntesis) e indica si el número
+// está en formato internacional (empieza con + o 00)
+func cleanDigits(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(raw), "tel:"))
+	if raw == "" {
+		return "", false, fmt.Errorf("%w: vacío", ErrInvalidNumber)
+	}
+
+	var digits strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0, r == ' ', r == '-', r == '.', r == '(', r == ')', r == '/':
+		default:
+			return "", false, fmt.Errorf("%w: %q", ErrInvalidNumber, raw)
+		}
+	}
+
+	result := digits.String()
+	if strings.HasPrefix(raw, "+") {
+		return result, true, nil
+	}
+	if strings.HasPrefix(result, "00") {
+		return result[2:], true, nil
+	}
+	return result, false, nil
+}
+
+// normalizeArgentina deja el número nacional de Argentina sin el 15 y, si es celular, con el 9 adelante
+func normalizeArgentina(national string, mobile bool) string {
+	hasNine := len(national) > 10 && national[0] == '9'
+	rest := national
+	if hasNine {
+		rest = national[1:]
+	}
+
+	// Código de área (2 a 4 dígitos) + 15 + número: el 15 indica celular
+	if len(rest) == 12 {
+		for areaLength := 2; areaLength <= 4; areaLength++ {
+			if rest[areaLength:areaLength+2] == "15" {
+				rest = rest[:areaLength] + rest[areaLength+2:]
+				hasNine = true
+				break
+			}
+		}
+	}
+
+	if len(rest) == 10 && (hasNine || mobile) {
+		return "9" + rest
+	}
+	if hasNine && len(rest) != 10 {
+		// No tiene la forma de un celular: se deja como vino para que falle la validación de longitud
+		return national
+	}
+	return rest
+}
+
+// normalizeMexico saca el 1 de celular que todavía manda WhatsApp (52 1 55 1234 5678)
+func normalizeMexico(national string) string {
+	if len(national) == 11 && national[0] == '1' {
+		return national[1:]
+	}
+	return national
+}
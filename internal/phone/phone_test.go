@@ -0,0 +1,81 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMobile(t *testing.T) {
+	cases := []struct {
+		raw       string
+		want      string
+		recipient string
+	}{
+		{"+54 9 351 555-1234", "+5493515551234", "543515551234"},
+		{"0054 9 351 5551234", "+5493515551234", "543515551234"},
+		{"5493515551234", "+5493515551234", "543515551234"},
+		{"543515551234", "+5493515551234", "543515551234"},
+		{"0351 15 555-1234", "+5493515551234", "543515551234"},
+		{"11 15 5555-1234", "+5491155551234", "541155551234"},
+		{"(0351) 555-1234", "+5493515551234", "543515551234"},
+		{"+52 1 55 1234 5678", "+525512345678", "525512345678"},
+		{"+52 55 1234 5678", "+525512345678", "525512345678"},
+		{"+1 (415) 555-0100", "+14155550100", "14155550100"},
+		{"+598 94 123 456", "+59894123456", "59894123456"},
+		{"+34 612 34 56 78", "+34612345678", "34612345678"},
+	}
+	for _, c := range cases {
+		got, err := ParseMobile(c.raw, "54")
+		if err != nil || got.E164() != c.want || got.WhatsAppRecipient() != c.recipient {
+			t.Errorf("ParseMobile(%q) = %q (%q), %v; want %q (%q)", c.raw, got.E164(), got.WhatsAppRecipient(), err, c.want, c.recipient)
+		}
+	}
+
+	for _, raw := range []string{"", "123", "+54 351 abc", "+1234567890123456", "+54 9 351 555", "+52 55 1234"} {
+		if got, err := ParseMobile(raw, "54"); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("ParseMobile(%q) = %q, %v; want ErrInvalidNumber", raw, got.E164(), err)
+		}
+	}
+}
+
+func TestParseKeepsLandlines(t *testing.T) {
+	got, err := Parse("+54 351 400-0000", "")
+	if err != nil || got.E164() != "+543514000000" {
+		t.Errorf("Parse landline = %q, %v; want +543514000000", got.E164(), err)
+	}
+
+	got, err = Parse("011 4000-0000", "")
+	if err != nil || got.E164() != "+541140000000" {
+		t.Errorf("Parse national landline = %q, %v; want +541140000000", got.E164(), err)
+	}
+}
+
+func TestFromWhatsApp(t *testing.T) {
+	cases := map[string]string{
+		"5493515551234": "+5493515551234",
+		"543515551234":  "+5493515551234",
+		"5215512345678": "+525512345678",
+		"14155550100":   "+14155550100",
+	}
+	for waID, want := range cases {
+		got, err := FromWhatsApp(waID)
+		if err != nil || got.E164() != want {
+			t.Errorf("FromWhatsApp(%q) = %q, %v; want %q", waID, got.E164(), err, want)
+		}
+	}
+}
+
+func TestRecipient(t *testing.T) {
+	cases := map[string]string{
+		"+5493515551234": "543515551234",
+		"5493515551234":  "543515551234",
+		"+525512345678":  "525512345678",
+		"+14155550100":   "14155550100",
+		"+999123":        "999123",
+	}
+	for stored, want := range cases {
+		if got := Recipient(stored); got != want {
+			t.Errorf("Recipient(%q) = %q, want %q", stored, got, want)
+		}
+	}
+}
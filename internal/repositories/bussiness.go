@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// BussinessRepository guarda los bussiness y sus usuarios
+type BussinessRepository interface {
+	Create(record entities.Bussines) (uint, error)
+	FindByID(id int64) (entities.Bussines, error)
+	Update(id int64, record entities.Bussines) error
+	UpdateQuota(id int64, values map[string]interface{}) error
+	Delete(id int64) error
+	List() ([]entities.Bussines, error)
+	FindByUserId(userId int64) ([]entities.Bussines, error)
+	AddUserToBussiness(businessID, userID int64) error
+	RemoveUserFromBussiness(businessID, userID int64) error
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) BussinessRepository
+}
+
+// AssistantRepository guarda los assistants de cada bussiness
+type AssistantRepository interface {
+	CheckBussiness(bussinessID int64) error
+	Create(data *entities.Assistant) error
+	IsWithinWorkingHours(assistantID int64, dateTime time.Time) (bool, error)
+	FindByAssistantID(assistantID int64) ([]entities.NumberPhone, error)
+	FindAll() ([]entities.Assistant, error)
+	FindById(id int64) (entities.Assistant, error)
+	Update(id int64, data entities.Assistant) error
+	Delete(id int64) error
+	GetAllAssistantsByBussinessId(businessId int64) ([]entities.Assistant, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) AssistantRepository
+}
+
+// FileRepository guarda los archivos que se suben a los assistants
+type FileRepository interface {
+	CheckAssistant(assistantID int64) error
+	Create(file entities.File) error
+	FindAll() ([]entities.File, error)
+	FindById(id int64) (entities.File, error)
+	FindByAssistantID(id int64) ([]entities.File, error)
+	Update(file entities.File) error
+	Delete(id int64) error
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) FileRepository
+}
+
+// AssistantClosuresRepository guarda los cierres (feriados, vacaciones) de cada assistant
+type AssistantClosuresRepository interface {
+	Create(record *entities.AssistantClosure) error
+	Update(record *entities.AssistantClosure) error
+	FindByID(assistantID, id int64) (entities.AssistantClosure, error)
+	FindByExternalUID(assistantID int64, uid string) (entities.AssistantClosure, error)
+	FindByAssistant(assistantID int64, from string) ([]entities.AssistantClosure, error)
+	FindByAssistantAndDate(assistantID int64, day time.Time) ([]entities.AssistantClosure, error)
+	Delete(assistantID, id int64) error
+}
+
+// AssistantTemplatesRepository guarda qué template de Meta usa cada assistant en cada evento del turno
+type AssistantTemplatesRepository interface {
+	FindByAssistant(assistantID int64) ([]entities.AssistantTemplate, error)
+	FindByEvent(assistantID int64, event string) (entities.AssistantTemplate, error)
+	Replace(assistantID int64, records []entities.AssistantTemplate) error
+}
+
+// GoogleCalendarCredentialsRepository guarda los tokens de Google Calendar de cada assistant
+type GoogleCalendarCredentialsRepository interface {
+	Create(data *entities.GoogleCalendarCredential) error
+	FindByAssistantID(assistantID int) (*entities.GoogleCalendarCredential, error)
+	Update(data *entities.GoogleCalendarCredential) error
+	Delete(assistantID int) error
+}
+
+// TokenUsagesRepository guarda los tokens que consume el modelo y arma los reportes de consumo
+type TokenUsagesRepository interface {
+	Create(record *entities.TokenUsage) error
+	ContactTotal(contactID int64) (int64, error)
+	BussinessTotals(bussinessID int64, from, to string) (int64, float64, error)
+	Report(filter dtos.UsageFilterDto) ([]dtos.UsageReportRowDto, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) TokenUsagesRepository
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// CampaignsRepository guarda las campañas de difusión y sus destinatarios
+type CampaignsRepository interface {
+	Create(record *entities.Campaign) error
+	Update(record *entities.Campaign) error
+	FindByID(id int64) (entities.Campaign, error)
+	FindByNumberPhone(numberPhoneID int64) ([]entities.Campaign, error)
+	Delete(id int64) error
+	UpdateStatus(id int64, from []string, values map[string]interface{}) (bool, error)
+	ClaimDue(now, staleBefore time.Time) (*entities.Campaign, error)
+	Heartbeat(id int64, now time.Time) (string, error)
+	AddRecipients(campaignID int64, contactIDs []int64) error
+	CountRecipients(campaignID int64) (int64, error)
+	SkipPendingRecipients(campaignID int64, reason string) error
+	FindPendingRecipients(campaignID int64, limit int) ([]entities.CampaignRecipient, error)
+	UpdateRecipient(id int64, status, sendError string, messageID *int64, sentAt *time.Time) error
+	FindRecipients(campaignID int64, status string, page, limit int) ([]entities.CampaignRecipient, int, error)
+	Stats(campaignID int64) (dtos.CampaignStatsDto, error)
+	CountRecentRecipients(numberPhoneID int64, since time.Time) (int64, error)
+	WasRecentlyMessaged(numberPhoneID, contactID int64, since time.Time) (bool, error)
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// ContactsRepository guarda los contactos de cada número, sus etiquetas y su estado de derivación
+type ContactsRepository interface {
+	GetContactsByNumberPhone(numberPhoneID int64, filter dtos.ContactFilterDto, page int, limit int) ([]entities.Contact, int, error)
+	ListByNumberPhone(numberPhoneID int64, filter dtos.ContactFilterDto) ([]entities.Contact, error)
+	FindByNumbers(numberPhoneID int64, numbers []string) ([]entities.Contact, error)
+	CreateMany(numberPhoneID int64, records []entities.Contact) error
+	LastInteractions(contactIDs []int64) (map[int64]time.Time, error)
+	EventCounts(contactIDs []int64) (map[int64]int64, error)
+	Create(record entities.Contact) error
+	FindByID(id int64) (entities.Contact, error)
+	FindByPhoneNumber(phoneNumber string) (entities.Contact, error)
+	Update(id string, record entities.Contact) error
+	Delete(id string) error
+	List() ([]entities.Contact, error)
+	DoesNumberPhoneExist(numberPhoneID int64) (bool, error)
+	UpdateIsBlocked(contactID int64, isBlocked bool) error
+	UpdateHandoff(contactID int64, mode, reason string, since, expiresAt *time.Time) error
+	ReleaseExpiredHandoffs(now time.Time) ([]entities.Contact, error)
+	FindHandedOffByNumberPhone(numberPhoneID int64, now time.Time) ([]entities.Contact, error)
+	ReplaceTags(contactID int64, tags []string) error
+	UpdateOptOut(contactID int64, optedOutAt *time.Time) error
+	UpdateCountTokens(contactID int64, total int64) error
+	UpdateProfile(contactID int64, values map[string]interface{}) error
+	FindIDsBySegment(numberPhoneID int64, segment dtos.CampaignSegmentDto, now time.Time) ([]int64, error)
+	FindByNumber(numberPhoneID int64, number string) (entities.Contact, error)
+	CreateAndReturn(record entities.Contact) (entities.Contact, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) ContactsRepository
+}
@@ -0,0 +1,25 @@
+// Package drivers elige el dialecto de los repositorios según el motor de la conexión
+package drivers
+
+import (
+	"fmt"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/gorm_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"gorm.io/gorm"
+)
+
+// New crea los repositorios del motor de db (postgres, mysql o sqlite)
+func New(db *gorm.DB) (*repositories.Repositories, error) {
+	switch name := db.Dialector.Name(); name {
+	case "postgres":
+		return gorm_client.NewRepositories(db, gorm_client.Postgres), nil
+	case "mysql":
+		return gorm_client.NewRepositories(db, gorm_client.MySQL), nil
+	case "sqlite":
+		return sqlite_client.NewRepositories(db), nil
+	default:
+		return nil, fmt.Errorf("no hay repositorios para la base %s", name)
+	}
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
+)
+
+// EventsRepository guarda los eventos (turnos) de cada assistant
+type EventsRepository interface {
+	FindByContactDateAndNumberPhone(contactID int64, date string, assistantID int64) ([]entities.Events, error)
+	FindByAssistantAndDate(assistantID int64, date string) ([]entities.Events, error)
+	FindByContactAndCodeEvent(contactID int64, codeEvent string) (entities.Events, error)
+	FindByContactAndDateAndTime(contactID int64, date string, currentTime string) ([]entities.Events, error)
+	ExistsByCode(code string) (bool, error)
+	Create(event *entities.Events) error
+	// Crea el evento con el assistant bloqueado: allow recibe los eventos del mismo día y si devuelve error no se crea
+	CreateChecked(event *entities.Events, allow func(sameDay []entities.Events) error) error
+	FindByID(id int) (*entities.Events, error)
+	FindAll(request *filters.EventsFilter, pagination *dtos.Pagination) (events []entities.Events, total int64, err error)
+	Update(event *entities.Events) error
+	// Actualiza el evento con el assistant bloqueado: allow recibe los eventos del día del nuevo horario
+	UpdateChecked(event *entities.Events, allow func(sameDay []entities.Events) error) error
+	Delete(id int) error
+	Confirm(id int, confirmedAt time.Time) error
+	Cancel(codeEvent string) error
+	// Próximo evento del contacto que empieza desde from (YYYY-MM-DDTHH:MM:SS)
+	FindNextByContact(contactID int64, from string) (entities.Events, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) EventsRepository
+}
+
+// EventRemindersRepository registra los recordatorios enviados de cada evento
+type EventRemindersRepository interface {
+	// Reserva el recordatorio para enviarlo; retoma los fallidos y los que quedaron en sending desde antes de staleBefore
+	Claim(record *entities.EventReminder, staleBefore time.Time, maxAttempts int) (bool, error)
+	MarkSent(id int64, messageID string, sentAt time.Time) error
+	MarkFailed(id int64, lastError string) error
+	FindUpcomingEvents(fromDate, toDate string) ([]entities.Events, error)
+	FindByMessageID(messageID string) (*entities.EventReminder, error)
+	FindLastSentToContact(contactID int64, since time.Time) (*entities.EventReminder, error)
+}
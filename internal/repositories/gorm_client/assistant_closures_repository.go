@@ -0,0 +1,89 @@
+package gorm_client
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// AssistantClosuresRepository maneja los feriados y cierres de cada assistant
+type AssistantClosuresRepository struct {
+	db *gorm.DB
+}
+
+func NewAssistantClosuresRepository(db *gorm.DB) *AssistantClosuresRepository {
+	return &AssistantClosuresRepository{db: db}
+}
+
+func (r *AssistantClosuresRepository) Create(record *entities.AssistantClosure) error {
+	return r.db.Create(record).Error
+}
+
+func (r *AssistantClosuresRepository) Update(record *entities.AssistantClosure) error {
+	return r.db.Save(record).Error
+}
+
+// FindByID busca un cierre del assistant. Filtrar por assistant evita editar cierres de otro assistant por ID.
+func (r *AssistantClosuresRepository) FindByID(assistantID, id int64) (entities.AssistantClosure, error) {
+	var record entities.AssistantClosure
+	err := r.db.Where("assistants_id = ?", assistantID).First(&record, id).Error
+	return record, err
+}
+
+// FindByExternalUID busca un cierre importado desde iCal por el UID del evento
+func (r *AssistantClosuresRepository) FindByExternalUID(assistantID int64, uid string) (entities.AssistantClosure, error) {
+	var record entities.AssistantClosure
+	err := r.db.Where("assistants_id = ? AND external_uid = ?", assistantID, uid).First(&record).Error
+	return record, err
+}
+
+// FindByAssistant lista los cierres de un assistant. Si from no es vacío ("YYYY-MM-DD") se omiten los que ya pasaron.
+func (r *AssistantClosuresRepository) FindByAssistant(assistantID int64, from string) ([]entities.AssistantClosure, error) {
+	var records []entities.AssistantClosure
+	query := r.db.Where("assistants_id = ?", assistantID)
+	if from != "" {
+		query = query.Where("recurring_yearly = ? OR end_date >= ?", true, from)
+	}
+	err := query.Order("start_date ASC").Find(&records).Error
+	return records, err
+}
+
+// FindByAssistantAndDate devuelve los cierres que aplican al día
+func (r *AssistantClosuresRepository) FindByAssistantAndDate(assistantID int64, day time.Time) ([]entities.AssistantClosure, error) {
+	return findClosuresOn(r.db, assistantID, day)
+}
+
+func (r *AssistantClosuresRepository) Delete(assistantID, id int64) error {
+	result := r.db.Where("assistants_id = ?", assistantID).Delete(&entities.AssistantClosure{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// findClosuresOn trae los cierres candidatos de la base y se queda con los que cubren el día
+// (los anuales se comparan por mes y día, por eso no se pueden filtrar del todo en SQL)
+func findClosuresOn(db *gorm.DB, assistantID int64, day time.Time) ([]entities.AssistantClosure, error) {
+	date := day.Format("2006-01-02")
+
+	// Se compara con DATE(): SQLite guarda las fechas con hora y como texto "2026-03-02 00:00:00" es mayor que "2026-03-02"
+	var candidates []entities.AssistantClosure
+	err := db.
+		Where("assistants_id = ? AND (recurring_yearly = ? OR (DATE(start_date) <= ? AND DATE(end_date) >= ?))", assistantID, true, date, date).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var closures []entities.AssistantClosure
+	for _, closure := range candidates {
+		if _, ok := closure.ClosedRangeOn(day); ok {
+			closures = append(closures, closure)
+		}
+	}
+	return closures, nil
+}
@@ -0,0 +1,44 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// AssistantTemplatesRepository maneja qué template usa cada assistant en cada momento del ciclo de vida de los eventos
+type AssistantTemplatesRepository struct {
+	db *gorm.DB
+}
+
+func NewAssistantTemplatesRepository(db *gorm.DB) *AssistantTemplatesRepository {
+	return &AssistantTemplatesRepository{db: db}
+}
+
+func (r *AssistantTemplatesRepository) FindByAssistant(assistantID int64) ([]entities.AssistantTemplate, error) {
+	var records []entities.AssistantTemplate
+	err := r.db.Where("assistants_id = ?", assistantID).Order("event ASC").Find(&records).Error
+	return records, err
+}
+
+// FindByEvent devuelve el template configurado para el momento (gorm.ErrRecordNotFound si no tiene)
+func (r *AssistantTemplatesRepository) FindByEvent(assistantID int64, event string) (entities.AssistantTemplate, error) {
+	var record entities.AssistantTemplate
+	err := r.db.Where("assistants_id = ? AND event = ?", assistantID, event).First(&record).Error
+	return record, err
+}
+
+// Replace reemplaza todos los templates configurados del assistant
+func (r *AssistantTemplatesRepository) Replace(assistantID int64, records []entities.AssistantTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("assistants_id = ?", assistantID).Delete(&entities.AssistantTemplate{}).Error; err != nil {
+			return err
+		}
+		for i := range records {
+			records[i].AssistantsID = assistantID
+			if err := tx.Create(&records[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
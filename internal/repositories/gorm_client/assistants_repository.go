@@ -0,0 +1,143 @@
+package gorm_client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type AssistantRepository struct {
+	db     *gorm.DB
+	tenant dtos.TenantScope
+}
+
+func NewAssistantRepository(db *gorm.DB) *AssistantRepository {
+	return &AssistantRepository{db: db}
+}
+
+// WithTenant devuelve una copia del repositorio que solo ve los assistants de los bussiness del scope
+func (r *AssistantRepository) WithTenant(tenant dtos.TenantScope) repositories.AssistantRepository {
+	return &AssistantRepository{db: r.db, tenant: tenant}
+}
+
+func (r *AssistantRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByBussiness(r.tenant, "assistants.bussiness_id"))
+}
+
+// CheckBussiness verifica que el bussiness sea del scope antes de crear un assistant en él
+func (r *AssistantRepository) CheckBussiness(bussinessID int64) error {
+	if !r.tenant.Allows(bussinessID) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AssistantRepository) Create(data *entities.Assistant) error {
+	if err := r.CheckBussiness(data.BussinessID); err != nil {
+		return err
+	}
+
+	// GORM automáticamente asigna el ID a data.ID después de la creación
+	if err := r.db.Create(data).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsWithinWorkingHours verifica si una fecha y hora están dentro del horario de atención de un asistente
+func (r *AssistantRepository) IsWithinWorkingHours(assistantID int64, dateTime time.Time) (bool, error) {
+	var assistant entities.Assistant
+	err := r.scoped().First(&assistant, assistantID).Error
+	if err != nil {
+		return false, fmt.Errorf("assistant not found: %v", err)
+	}
+
+	// Obtener el día de la semana (0 = Domingo, 1 = Lunes, ..., 6 = Sábado)
+	weekday := int(dateTime.Weekday())
+
+	// Verificar si el bit del día está activado en OpeningDays
+	if (assistant.OpeningDays & (1 << weekday)) == 0 {
+		return false, nil // El asistente no trabaja en este día
+	}
+
+	// Feriados y cierres del assistant
+	closures, err := findClosuresOn(r.db, assistantID, dateTime)
+	if err != nil {
+		return false, fmt.Errorf("error finding closures: %v", err)
+	}
+	midnight := time.Date(dateTime.Year(), dateTime.Month(), dateTime.Day(), 0, 0, 0, 0, dateTime.Location())
+	offset := dateTime.Sub(midnight)
+	for _, closure := range closures {
+		if closed, ok := closure.ClosedRangeOn(dateTime); ok && offset >= closed.Start && offset < closed.End {
+			return false, nil
+		}
+	}
+
+	// El horario de trabajo puede tener varios rangos ("09:00-13:00,14:00-18:00")
+	ranges, err := dtos.ParseTimeRanges(assistant.WorkingHours)
+	if err != nil || len(ranges) == 0 {
+		return false, errors.New("invalid WorkingHours format")
+	}
+
+	// Verificar si la hora está dentro de alguno de los rangos
+	for _, timeRange := range ranges {
+		if offset >= timeRange.Start && offset <= timeRange.End {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FindByAssistantID retrieves all number phones associated with a specific assistant
+func (r *AssistantRepository) FindByAssistantID(assistantID int64) ([]entities.NumberPhone, error) {
+	var records []entities.NumberPhone
+	err := r.db.Scopes(tenantByAssistant(r.tenant, "number_phones.assistants_id")).Where("assistants_id = ?", assistantID).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *AssistantRepository) FindAll() ([]entities.Assistant, error) {
+	var assistants []entities.Assistant
+	err := r.scoped().Preload("Bussiness").Find(&assistants).Error
+	return assistants, err
+}
+
+func (r *AssistantRepository) FindById(id int64) (entities.Assistant, error) {
+	var assistant entities.Assistant
+	err := r.scoped().Preload("Bussiness").First(&assistant, id).Error
+	return assistant, err
+}
+
+func (r *AssistantRepository) Update(id int64, data entities.Assistant) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), id); err != nil {
+		return err
+	}
+	// No se puede mover el assistant a un bussiness de otro tenant
+	if data.BussinessID != 0 && !r.tenant.Allows(data.BussinessID) {
+		return gorm.ErrRecordNotFound
+	}
+	return r.scoped().Model(&data).Where("id = ?", id).Updates(data).Error
+}
+
+func (r *AssistantRepository) Delete(id int64) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), id); err != nil {
+		return err
+	}
+	return r.scoped().Delete(&entities.Assistant{}, id).Error
+}
+
+func (r *AssistantRepository) GetAllAssistantsByBussinessId(businessId int64) ([]entities.Assistant, error) {
+	var assistants []entities.Assistant
+	err := r.scoped().Where("bussiness_id = ?", businessId).
+		Preload("Bussiness").
+		Find(&assistants).Error
+	return assistants, err
+}
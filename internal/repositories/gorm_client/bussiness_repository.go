@@ -1,13 +1,16 @@
-package postgres_client
+package gorm_client
 
 import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"gorm.io/gorm"
 )
 
 // BussinessRepository is the repository for Bussiness entities
 type BussinessRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant dtos.TenantScope
 }
 
 // NewBussinessRepository creates a new instance of BussinessRepository
@@ -15,6 +18,15 @@ func NewBussinessRepository(db *gorm.DB) *BussinessRepository {
 	return &BussinessRepository{db: db}
 }
 
+// WithTenant returns a copy of the repository that only sees the businesses of the scope
+func (r *BussinessRepository) WithTenant(tenant dtos.TenantScope) repositories.BussinessRepository {
+	return &BussinessRepository{db: r.db, tenant: tenant}
+}
+
+func (r *BussinessRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByBussiness(r.tenant, "bussiness.id"))
+}
+
 // Create inserts a new bussiness record into the database and returns the ID
 func (r *BussinessRepository) Create(record entities.Bussines) (uint, error) {
 	if err := r.db.Create(&record).Error; err != nil {
@@ -27,24 +39,38 @@ func (r *BussinessRepository) Create(record entities.Bussines) (uint, error) {
 // FindByID retrieves a bussiness record by its ID
 func (r *BussinessRepository) FindByID(id int64) (entities.Bussines, error) {
 	var record entities.Bussines
-	err := r.db.First(&record, id).Error
+	err := r.scoped().First(&record, id).Error
 	return record, err
 }
 
 // Update modifies an existing bussiness record
 func (r *BussinessRepository) Update(id int64, record entities.Bussines) error {
+	if !r.tenant.Allows(id) {
+		return gorm.ErrRecordNotFound
+	}
 	return r.db.Model(&record).Where("id = ?", id).Updates(record).Error
 }
 
+// UpdateQuota modifies the bussiness's monthly usage quota (values can be zero to remove the limit)
+func (r *BussinessRepository) UpdateQuota(id int64, values map[string]interface{}) error {
+	if !r.tenant.Allows(id) {
+		return gorm.ErrRecordNotFound
+	}
+	return r.db.Model(&entities.Bussines{}).Where("id = ?", id).Updates(values).Error
+}
+
 // Delete removes a bussiness record from the database
 func (r *BussinessRepository) Delete(id int64) error {
+	if !r.tenant.Allows(id) {
+		return gorm.ErrRecordNotFound
+	}
 	return r.db.Delete(&entities.Bussines{}, id).Error
 }
 
 // List retrieves all bussiness records
 func (r *BussinessRepository) List() ([]entities.Bussines, error) {
 	var records []entities.Bussines
-	err := r.db.Preload("Users").Find(&records).Error
+	err := r.scoped().Preload("Users").Find(&records).Error
 	return records, err
 }
 
@@ -52,7 +78,7 @@ func (r *BussinessRepository) List() ([]entities.Bussines, error) {
 func (r *BussinessRepository) FindByUserId(userId int64) ([]entities.Bussines, error) {
 	var businesses []entities.Bussines
 
-	err := r.db.Joins("JOIN bussiness_has_users ON bussiness_has_users.bussiness_id = bussiness.id").
+	err := r.scoped().Joins("JOIN bussiness_has_users ON bussiness_has_users.bussiness_id = bussiness.id").
 		Where("bussiness_has_users.users_id = ?", userId).
 		Find(&businesses).Error
 
@@ -0,0 +1,271 @@
+package gorm_client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CampaignsRepository maneja las campañas y el resultado del envío a cada destinatario
+type CampaignsRepository struct {
+	db *gorm.DB
+}
+
+func NewCampaignsRepository(db *gorm.DB) *CampaignsRepository {
+	return &CampaignsRepository{db: db}
+}
+
+func (r *CampaignsRepository) Create(record *entities.Campaign) error {
+	return r.db.Create(record).Error
+}
+
+func (r *CampaignsRepository) Update(record *entities.Campaign) error {
+	return r.db.Save(record).Error
+}
+
+func (r *CampaignsRepository) FindByID(id int64) (entities.Campaign, error) {
+	var record entities.Campaign
+	err := r.db.First(&record, id).Error
+	return record, err
+}
+
+// FindByNumberPhone lista las campañas del número, las más nuevas primero
+func (r *CampaignsRepository) FindByNumberPhone(numberPhoneID int64) ([]entities.Campaign, error) {
+	var records []entities.Campaign
+	err := r.db.Where("number_phones_id = ?", numberPhoneID).Order("created_at DESC, id DESC").Find(&records).Error
+	return records, err
+}
+
+func (r *CampaignsRepository) Delete(id int64) error {
+	return r.db.Delete(&entities.Campaign{}, id).Error
+}
+
+// UpdateStatus cambia el estado de la campaña solo si está en alguno de los estados from. Devuelve si se cambió.
+func (r *CampaignsRepository) UpdateStatus(id int64, from []string, values map[string]interface{}) (bool, error) {
+	result := r.db.Model(&entities.Campaign{}).Where("id = ? AND status IN ?", id, from).Updates(values)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimDue toma la próxima campaña para enviar: una programada que ya llegó a su hora o una en running que quedó
+// abandonada (su proceso dejó de dar señales antes de staleBefore). No toma campañas de un número que ya tiene otra
+// enviándose, así el límite de mensajes por segundo es por número. Devuelve nil si no hay ninguna.
+func (r *CampaignsRepository) ClaimDue(now, staleBefore time.Time) (*entities.Campaign, error) {
+	var record entities.Campaign
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND scheduled_at <= ?) OR (status = ? AND (locked_at IS NULL OR locked_at < ?))",
+				dtos.CampaignStatusScheduled, now, dtos.CampaignStatusRunning, staleBefore).
+			Where("NOT EXISTS (SELECT 1 FROM campaigns running WHERE running.number_phones_id = campaigns.number_phones_id AND running.id <> campaigns.id AND running.status = ? AND running.locked_at >= ? AND running.deleted_at IS NULL)",
+				dtos.CampaignStatusRunning, staleBefore).
+			Order("scheduled_at ASC, id ASC").
+			First(&record).Error
+		if err != nil {
+			return err
+		}
+
+		values := map[string]interface{}{
+			"status":     dtos.CampaignStatusRunning,
+			"locked_at":  now,
+			"last_error": "",
+		}
+		if record.StartedAt == nil {
+			values["started_at"] = now
+			record.StartedAt = &now
+		}
+		record.Status = dtos.CampaignStatusRunning
+		record.LockedAt = &now
+		return tx.Model(&record).Updates(values).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Heartbeat renueva la señal del proceso que envía la campaña y devuelve su estado actual
+// (para frenar si la pausaron o cancelaron mientras se enviaba)
+func (r *CampaignsRepository) Heartbeat(id int64, now time.Time) (string, error) {
+	if err := r.db.Model(&entities.Campaign{}).
+		Where("id = ? AND status = ?", id, dtos.CampaignStatusRunning).
+		Update("locked_at", now).Error; err != nil {
+		return "", err
+	}
+	var record entities.Campaign
+	err := r.db.Select("status").First(&record, id).Error
+	return record.Status, err
+}
+
+// AddRecipients agrega los contactos del segmento como destinatarios pendientes (los que ya estaban se ignoran)
+func (r *CampaignsRepository) AddRecipients(campaignID int64, contactIDs []int64) error {
+	if len(contactIDs) == 0 {
+		return nil
+	}
+	records := make([]entities.CampaignRecipient, len(contactIDs))
+	for i, contactID := range contactIDs {
+		records[i] = entities.CampaignRecipient{
+			CampaignsID: campaignID,
+			ContactsID:  contactID,
+			Status:      dtos.CampaignRecipientPending,
+		}
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&records, 500).Error
+}
+
+// CountRecipients cuenta los destinatarios de la campaña
+func (r *CampaignsRepository) CountRecipients(campaignID int64) (int64, error) {
+	var total int64
+	err := r.db.Model(&entities.CampaignRecipient{}).Where("campaigns_id = ?", campaignID).Count(&total).Error
+	return total, err
+}
+
+// SkipPendingRecipients marca como salteados los destinatarios que todavía no se enviaron
+func (r *CampaignsRepository) SkipPendingRecipients(campaignID int64, reason string) error {
+	return r.db.Model(&entities.CampaignRecipient{}).
+		Where("campaigns_id = ? AND status = ?", campaignID, dtos.CampaignRecipientPending).
+		Updates(map[string]interface{}{
+			"status": dtos.CampaignRecipientSkipped,
+			"error":  reason,
+		}).Error
+}
+
+// FindPendingRecipients devuelve los próximos destinatarios pendientes con su contacto
+func (r *CampaignsRepository) FindPendingRecipients(campaignID int64, limit int) ([]entities.CampaignRecipient, error) {
+	var records []entities.CampaignRecipient
+	err := r.db.Preload("Contact").
+		Where("campaigns_id = ? AND status = ?", campaignID, dtos.CampaignRecipientPending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// UpdateRecipient registra el resultado del envío a un destinatario
+func (r *CampaignsRepository) UpdateRecipient(id int64, status, sendError string, messageID *int64, sentAt *time.Time) error {
+	return r.db.Model(&entities.CampaignRecipient{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"error":       sendError,
+			"messages_id": messageID,
+			"sent_at":     sentAt,
+		}).Error
+}
+
+// FindRecipients lista los destinatarios de la campaña (filtrados por estado si no es vacío) con paginación
+func (r *CampaignsRepository) FindRecipients(campaignID int64, status string, page, limit int) ([]entities.CampaignRecipient, int, error) {
+	var records []entities.CampaignRecipient
+	var total int64
+
+	query := r.db.Model(&entities.CampaignRecipient{}).Where("campaigns_id = ?", campaignID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	err := query.Preload("Contact").Preload("Message").
+		Order("id ASC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, int(total), nil
+}
+
+// Stats cuenta los destinatarios por estado y, de los enviados, cuántos mensajes se entregaron y leyeron
+func (r *CampaignsRepository) Stats(campaignID int64) (dtos.CampaignStatsDto, error) {
+	var stats dtos.CampaignStatsDto
+
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	err := r.db.Model(&entities.CampaignRecipient{}).
+		Select("status, COUNT(*) AS total").
+		Where("campaigns_id = ?", campaignID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return stats, err
+	}
+	for _, row := range rows {
+		stats.Total += row.Total
+		switch row.Status {
+		case dtos.CampaignRecipientPending:
+			stats.Pending = row.Total
+		case dtos.CampaignRecipientSent:
+			stats.Sent = row.Total
+		case dtos.CampaignRecipientFailed:
+			stats.Failed = row.Total
+		case dtos.CampaignRecipientSkipped:
+			stats.Skipped = row.Total
+		}
+	}
+
+	var delivery []struct {
+		Status string
+		Total  int64
+	}
+	err = r.db.Model(&entities.CampaignRecipient{}).
+		Select("messages.status AS status, COUNT(*) AS total").
+		Joins("JOIN messages ON messages.id = campaign_recipients.messages_id").
+		Where("campaign_recipients.campaigns_id = ? AND campaign_recipients.status = ?", campaignID, dtos.CampaignRecipientSent).
+		Group("messages.status").
+		Scan(&delivery).Error
+	if err != nil {
+		return stats, err
+	}
+	for _, row := range delivery {
+		switch row.Status {
+		case "read":
+			stats.Read += row.Total
+			stats.Delivered += row.Total
+		case "delivered":
+			stats.Delivered += row.Total
+		case "failed":
+			// WhatsApp aceptó el envío pero después no lo pudo entregar
+			stats.Sent -= row.Total
+			stats.Failed += row.Total
+		}
+	}
+	return stats, nil
+}
+
+// CountRecentRecipients cuenta los contactos distintos a los que el número les envió campañas desde since
+// (es lo que limita el tier de Meta)
+func (r *CampaignsRepository) CountRecentRecipients(numberPhoneID int64, since time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&entities.CampaignRecipient{}).
+		Joins("JOIN campaigns ON campaigns.id = campaign_recipients.campaigns_id").
+		Where("campaigns.number_phones_id = ? AND campaign_recipients.status = ? AND campaign_recipients.sent_at >= ?", numberPhoneID, dtos.CampaignRecipientSent, since).
+		Distinct("campaign_recipients.contacts_id").
+		Count(&total).Error
+	return total, err
+}
+
+// WasRecentlyMessaged indica si el contacto ya recibió alguna campaña del número desde since
+func (r *CampaignsRepository) WasRecentlyMessaged(numberPhoneID, contactID int64, since time.Time) (bool, error) {
+	var total int64
+	err := r.db.Model(&entities.CampaignRecipient{}).
+		Joins("JOIN campaigns ON campaigns.id = campaign_recipients.campaigns_id").
+		Where("campaigns.number_phones_id = ? AND campaign_recipients.contacts_id = ? AND campaign_recipients.status = ? AND campaign_recipients.sent_at >= ?", numberPhoneID, contactID, dtos.CampaignRecipientSent, since).
+		Count(&total).Error
+	return total > 0, err
+}
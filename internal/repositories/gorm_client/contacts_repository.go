@@ -0,0 +1,413 @@
+package gorm_client
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// contactsBatchSize limits the IDs per IN clause and the rows per insert on bulk operations
+const contactsBatchSize = 500
+
+// ContactsRepository is the repository for Contacts entities
+type ContactsRepository struct {
+	db     *gorm.DB
+	tenant dtos.TenantScope
+}
+
+// NewContactsRepository creates a new instance of ContactsRepository
+func NewContactsRepository(db *gorm.DB) *ContactsRepository {
+	return &ContactsRepository{db: db}
+}
+
+// WithTenant returns a copy of the repository that only sees the contacts of the scope's number phones
+func (r *ContactsRepository) WithTenant(tenant dtos.TenantScope) repositories.ContactsRepository {
+	return &ContactsRepository{db: r.db, tenant: tenant}
+}
+
+func (r *ContactsRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByNumberPhone(r.tenant, "contacts.number_phones_id"))
+}
+
+// GetContactsByNumberPhone retrieves the number phone's contacts that match the filter, newest first
+func (r *ContactsRepository) GetContactsByNumberPhone(numberPhoneID int64, filter dtos.ContactFilterDto, page int, limit int) ([]entities.Contact, int, error) {
+	var contacts []entities.Contact
+	var total int64
+
+	query := r.filtered(numberPhoneID, filter)
+
+	// Contar el total de registros antes de aplicar paginación
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Evitar valores inválidos en paginación
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	// Obtener los registros paginados
+	err := query.
+		Preload("Tags").
+		Order("contacts.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&contacts).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return contacts, int(total), nil
+}
+
+// filtered builds the query for the number phone's contacts that match the filter
+func (r *ContactsRepository) filtered(numberPhoneID int64, filter dtos.ContactFilterDto) *gorm.DB {
+	query := r.scoped().Model(&entities.Contact{}).
+		Where("contacts.number_phones_id = ?", numberPhoneID).
+		Where("contacts.deleted_at IS NULL")
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(contacts.name) LIKE ? OR LOWER(contacts.profile_name) LIKE ? OR LOWER(contacts.email) LIKE ? OR contacts.number_phone LIKE ?",
+			like, like, like, like)
+	}
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM contact_tags WHERE contact_tags.contacts_id = contacts.id AND contact_tags.tag = ?)", filter.Tag)
+	}
+	if filter.OptedOut != nil {
+		if *filter.OptedOut {
+			query = query.Where("contacts.opted_out_at IS NOT NULL")
+		} else {
+			query = query.Where("contacts.opted_out_at IS NULL")
+		}
+	}
+	if filter.Blocked != nil {
+		query = query.Where("contacts.is_blocked = ?", *filter.Blocked)
+	}
+	return query
+}
+
+// ListByNumberPhone retrieves every contact of the number phone that matches the filter, with its tags
+func (r *ContactsRepository) ListByNumberPhone(numberPhoneID int64, filter dtos.ContactFilterDto) ([]entities.Contact, error) {
+	var records []entities.Contact
+	err := r.filtered(numberPhoneID, filter).Preload("Tags").Order("contacts.id ASC").Find(&records).Error
+	return records, err
+}
+
+// FindByNumbers retrieves the number phone's contacts with the given phone numbers (E.164), with their tags
+func (r *ContactsRepository) FindByNumbers(numberPhoneID int64, numbers []string) ([]entities.Contact, error) {
+	var records []entities.Contact
+	for start := 0; start < len(numbers); start += contactsBatchSize {
+		end := min(start+contactsBatchSize, len(numbers))
+		var batch []entities.Contact
+		err := r.scoped().Preload("Tags").
+			Where("number_phones_id = ? AND number_phone IN ?", numberPhoneID, numbers[start:end]).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, batch...)
+	}
+	return records, nil
+}
+
+// CreateMany inserts the contacts (and their tags) of a number phone in batches
+func (r *ContactsRepository) CreateMany(numberPhoneID int64, records []entities.Contact) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.NumberPhone{}, tenantByAssistant(r.tenant, "number_phones.assistants_id"), numberPhoneID); err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&records, contactsBatchSize).Error
+}
+
+// LastInteractions retrieves when each contact last wrote (its last inbound message), by contact ID
+func (r *ContactsRepository) LastInteractions(contactIDs []int64) (map[int64]time.Time, error) {
+	result := make(map[int64]time.Time, len(contactIDs))
+	for start := 0; start < len(contactIDs); start += contactsBatchSize {
+		end := min(start+contactsBatchSize, len(contactIDs))
+		var rows []struct {
+			ContactsID int64
+			CreatedAt  time.Time
+		}
+		// Se busca el mensaje con el mayor ID en vez de MAX(created_at) para que el driver reciba una columna de fecha
+		err := r.db.Model(&entities.Message{}).
+			Select("contacts_id, created_at").
+			Where("id IN (?)", r.db.Model(&entities.Message{}).
+				Select("MAX(id)").
+				Where("contacts_id IN ? AND is_from_bot = ?", contactIDs[start:end], false).
+				Group("contacts_id")).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			result[row.ContactsID] = row.CreatedAt
+		}
+	}
+	return result, nil
+}
+
+// EventCounts retrieves how many events (not cancelled) each contact has, by contact ID
+func (r *ContactsRepository) EventCounts(contactIDs []int64) (map[int64]int64, error) {
+	result := make(map[int64]int64, len(contactIDs))
+	for start := 0; start < len(contactIDs); start += contactsBatchSize {
+		end := min(start+contactsBatchSize, len(contactIDs))
+		var rows []struct {
+			ContactsID int64
+			Total      int64
+		}
+		err := r.db.Model(&entities.Events{}).
+			Select("contacts_id, COUNT(*) AS total").
+			Where("contacts_id IN ?", contactIDs[start:end]).
+			Group("contacts_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			result[row.ContactsID] = row.Total
+		}
+	}
+	return result, nil
+}
+
+// Create inserts a new contact record into the database
+func (r *ContactsRepository) Create(record entities.Contact) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.NumberPhone{}, tenantByAssistant(r.tenant, "number_phones.assistants_id"), record.NumberPhonesID); err != nil {
+		return err
+	}
+	return r.db.Create(&record).Error
+}
+
+// CreateAndReturn inserts a new contact record and returns it with its generated ID
+func (r *ContactsRepository) CreateAndReturn(record entities.Contact) (entities.Contact, error) {
+	if err := tenantAllows(r.db, r.tenant, &entities.NumberPhone{}, tenantByAssistant(r.tenant, "number_phones.assistants_id"), record.NumberPhonesID); err != nil {
+		return entities.Contact{}, err
+	}
+	err := r.db.Create(&record).Error
+	return record, err
+}
+
+// FindByID retrieves a contact record by its ID
+func (r *ContactsRepository) FindByID(id int64) (entities.Contact, error) {
+	var record entities.Contact
+	err := r.scoped().Preload("Tags").First(&record, id).Error
+	return record, err
+}
+
+func (r *ContactsRepository) FindByPhoneNumber(phoneNumber string) (entities.Contact, error) {
+	var record entities.Contact
+	err := r.scoped().Where("number_phone = ?", phoneNumber).
+		Find(&record).Error
+	return record, err
+}
+
+// FindByNumber retrieves the number phone's contact with the given phone number (E.164)
+func (r *ContactsRepository) FindByNumber(numberPhoneID int64, number string) (entities.Contact, error) {
+	var record entities.Contact
+	err := r.scoped().Where("number_phones_id = ? AND number_phone = ?", numberPhoneID, number).First(&record).Error
+	return record, err
+}
+
+// Update modifies an existing contact record
+func (r *ContactsRepository) Update(id string, record entities.Contact) error {
+	return r.scoped().Model(&record).Where("id = ?", id).Updates(record).Error
+}
+
+// Delete removes a contact record from the database
+func (r *ContactsRepository) Delete(id string) error {
+	return r.scoped().Delete(&entities.Contact{}, id).Error
+}
+
+// List retrieves all contact records
+func (r *ContactsRepository) List() ([]entities.Contact, error) {
+	var records []entities.Contact
+	err := r.scoped().Find(&records).Error
+	return records, err
+}
+
+// DoesNumberPhoneExist checks that the number phone exists and belongs to the scope
+func (r *ContactsRepository) DoesNumberPhoneExist(numberPhoneID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.NumberPhone{}).
+		Scopes(tenantByAssistant(r.tenant, "number_phones.assistants_id")).
+		Where("id = ?", numberPhoneID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ContactsRepository) UpdateIsBlocked(contactID int64, isBlocked bool) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), contactID); err != nil {
+		return err
+	}
+	return r.scoped().Model(&entities.Contact{}).
+		Where("id = ?", contactID).
+		Update("is_blocked", isBlocked).Error
+}
+
+// UpdateHandoff sets who handles the contact's conversation. Reason, since and expiresAt are overwritten even when nil/empty.
+func (r *ContactsRepository) UpdateHandoff(contactID int64, mode, reason string, since, expiresAt *time.Time) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), contactID); err != nil {
+		return err
+	}
+	return r.scoped().Model(&entities.Contact{}).
+		Where("id = ?", contactID).
+		Updates(map[string]interface{}{
+			"handoff_mode":       mode,
+			"handoff_reason":     reason,
+			"handoff_at":         since,
+			"handoff_expires_at": expiresAt,
+		}).Error
+}
+
+// ReleaseExpiredHandoffs returns to the bot the conversations whose human or paused mode expired and retrieves the released contacts
+func (r *ContactsRepository) ReleaseExpiredHandoffs(now time.Time) ([]entities.Contact, error) {
+	const expired = "handoff_mode <> ? AND handoff_expires_at IS NOT NULL AND handoff_expires_at <= ?"
+
+	var records []entities.Contact
+	if err := r.scoped().Where(expired, dtos.HandoffModeBot, now).Find(&records).Error; err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	err := r.scoped().Model(&entities.Contact{}).
+		Where("id IN ?", ids).
+		Where(expired, dtos.HandoffModeBot, now).
+		Updates(map[string]interface{}{
+			"handoff_mode":       dtos.HandoffModeBot,
+			"handoff_reason":     "",
+			"handoff_at":         nil,
+			"handoff_expires_at": nil,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		records[i].HandoffMode = dtos.HandoffModeBot
+		records[i].HandoffReason = ""
+		records[i].HandoffAt = nil
+		records[i].HandoffExpiresAt = nil
+	}
+	return records, nil
+}
+
+// FindHandedOffByNumberPhone retrieves the contacts of a number phone that are not handled by the bot, oldest handoff first
+func (r *ContactsRepository) FindHandedOffByNumberPhone(numberPhoneID int64, now time.Time) ([]entities.Contact, error) {
+	var records []entities.Contact
+	err := r.scoped().
+		Where("number_phones_id = ? AND handoff_mode <> ?", numberPhoneID, dtos.HandoffModeBot).
+		Where("handoff_expires_at IS NULL OR handoff_expires_at > ?", now).
+		Order("handoff_at ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// ReplaceTags leaves the contact with exactly the given tags
+func (r *ContactsRepository) ReplaceTags(contactID int64, tags []string) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), contactID); err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("contacts_id = ?", contactID).Delete(&entities.ContactTag{}).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		records := make([]entities.ContactTag, len(tags))
+		for i, tag := range tags {
+			records[i] = entities.ContactTag{ContactsID: contactID, Tag: tag}
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+// UpdateOptOut sets when the contact opted out of campaigns. A nil optedOutAt means the contact subscribed again,
+// so opted_in_at is set to now.
+func (r *ContactsRepository) UpdateOptOut(contactID int64, optedOutAt *time.Time) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), contactID); err != nil {
+		return err
+	}
+	values := map[string]interface{}{"opted_out_at": optedOutAt}
+	if optedOutAt == nil {
+		values["opted_in_at"] = time.Now()
+	}
+	return r.scoped().Model(&entities.Contact{}).
+		Where("id = ?", contactID).
+		Updates(values).Error
+}
+
+// UpdateCountTokens stores the contact's total model tokens without touching updated_at
+func (r *ContactsRepository) UpdateCountTokens(contactID int64, total int64) error {
+	return r.db.Model(&entities.Contact{}).Where("id = ?", contactID).
+		UpdateColumn("count_tokens", strconv.FormatInt(total, 10)).Error
+}
+
+// UpdateProfile sets the given profile columns (name, email, notes, custom_fields...), empty values included
+func (r *ContactsRepository) UpdateProfile(contactID int64, values map[string]interface{}) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), contactID); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return r.scoped().Model(&entities.Contact{}).
+		Where("id = ?", contactID).
+		Updates(values).Error
+}
+
+// FindIDsBySegment retrieves the IDs of the number phone's contacts that match the campaign segment.
+// Blocked and opted out contacts are always left out.
+func (r *ContactsRepository) FindIDsBySegment(numberPhoneID int64, segment dtos.CampaignSegmentDto, now time.Time) ([]int64, error) {
+	query := r.scoped().Model(&entities.Contact{}).
+		Where("contacts.number_phones_id = ? AND contacts.is_blocked = ? AND contacts.opted_out_at IS NULL", numberPhoneID, false)
+
+	if len(segment.ContactIDs) > 0 {
+		query = query.Where("contacts.id IN ?", segment.ContactIDs)
+	}
+	if len(segment.Tags) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM contact_tags WHERE contact_tags.contacts_id = contacts.id AND contact_tags.tag IN ?)", segment.Tags)
+	}
+	if len(segment.ExcludeTags) > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM contact_tags WHERE contact_tags.contacts_id = contacts.id AND contact_tags.tag IN ?)", segment.ExcludeTags)
+	}
+
+	// Última interacción = último mensaje que escribió el contacto (si nunca escribió, cuando se creó)
+	const lastInteraction = "COALESCE((SELECT MAX(messages.created_at) FROM messages WHERE messages.contacts_id = contacts.id AND messages.is_from_bot = ? AND messages.deleted_at IS NULL), contacts.created_at)"
+	if segment.LastInteractionAfter != nil {
+		query = query.Where(lastInteraction+" >= ?", false, *segment.LastInteractionAfter)
+	}
+	if segment.LastInteractionBefore != nil {
+		query = query.Where(lastInteraction+" < ?", false, *segment.LastInteractionBefore)
+	}
+
+	if segment.HasUpcomingEvent != nil {
+		// start_date se guarda como texto YYYY-MM-DDTHH:MM:SS, así que se puede comparar como string
+		const upcoming = "EXISTS (SELECT 1 FROM events WHERE events.contacts_id = contacts.id AND events.deleted_at IS NULL AND events.start_date >= ?)"
+		if *segment.HasUpcomingEvent {
+			query = query.Where(upcoming, now.Format("2006-01-02T15:04:05"))
+		} else {
+			query = query.Where("NOT "+upcoming, now.Format("2006-01-02T15:04:05"))
+		}
+	}
+
+	var ids []int64
+	err := query.Order("contacts.id ASC").Pluck("contacts.id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,20 @@
+package gorm_client
+
+import "fmt"
+
+// Dialect es lo poco que cambia entre motores. Se elige una vez al crear los repositorios (ver drivers.New), así el
+// resto de las consultas son las mismas para Postgres, MySQL y SQLite.
+type Dialect struct {
+	yearMonth string // Formato de la expresión "YYYY-MM", con %s para la columna
+}
+
+var (
+	Postgres = Dialect{yearMonth: "TO_CHAR(CAST(%s AS timestamp), 'YYYY-MM')"}
+	MySQL    = Dialect{yearMonth: "DATE_FORMAT(%s, '%%Y-%%m')"}
+	SQLite   = Dialect{yearMonth: "strftime('%%Y-%%m', %s)"}
+)
+
+// YearMonth devuelve la expresión SQL que da el "YYYY-MM" de column (una fecha o un texto ISO 8601)
+func (d Dialect) YearMonth(column string) string {
+	return fmt.Sprintf(d.yearMonth, column)
+}
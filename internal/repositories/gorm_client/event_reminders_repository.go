@@ -0,0 +1,101 @@
+package gorm_client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventRemindersRepository guarda el seguimiento de los recordatorios enviados por cada evento
+type EventRemindersRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRemindersRepository(db *gorm.DB) *EventRemindersRepository {
+	return &EventRemindersRepository{db: db}
+}
+
+// Claim reserva el recordatorio (evento, horas de anticipación). Si ya existía solo lo vuelve a reservar cuando
+// falló o quedó en sending desde antes de staleBefore (la instancia que lo enviaba se cayó), y mientras tenga menos
+// de maxAttempts intentos. Devuelve false si otro proceso lo envió o lo está enviando.
+func (r *EventRemindersRepository) Claim(record *entities.EventReminder, staleBefore time.Time, maxAttempts int) (bool, error) {
+	record.Attempts = 1
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// El update condicional es atómico: si dos procesos intentan retomarlo, solo uno lo consigue
+	result = r.db.Model(&entities.EventReminder{}).
+		Where("events_id = ? AND offset_hours = ? AND attempts < ?", record.EventsID, record.OffsetHours, maxAttempts).
+		Where("status = ? OR (status = ? AND updated_at < ?)", entities.EventReminderStatusFailed, entities.EventReminderStatusSending, staleBefore).
+		Updates(map[string]interface{}{
+			"status":   record.Status,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	err := r.db.Where("events_id = ? AND offset_hours = ?", record.EventsID, record.OffsetHours).First(record).Error
+	return err == nil, err
+}
+
+func (r *EventRemindersRepository) MarkSent(id int64, messageID string, sentAt time.Time) error {
+	return r.db.Model(&entities.EventReminder{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":              entities.EventReminderStatusSent,
+		"message_id_whatsapp": messageID,
+		"sent_at":             sentAt,
+	}).Error
+}
+
+func (r *EventRemindersRepository) MarkFailed(id int64, lastError string) error {
+	return r.db.Model(&entities.EventReminder{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": entities.EventReminderStatusFailed,
+		"error":  lastError,
+	}).Error
+}
+
+// FindUpcomingEvents devuelve los eventos entre dos fechas ("YYYY-MM-DD") de los assistants que tienen recordatorios
+// configurados, con su assistant y el contacto (con el número de WhatsApp por el que se comunicó)
+func (r *EventRemindersRepository) FindUpcomingEvents(fromDate, toDate string) ([]entities.Events, error) {
+	var events []entities.Events
+	err := r.db.
+		Joins("JOIN assistants ON assistants.id = events.assistants_id AND assistants.deleted_at IS NULL").
+		Where("assistants.reminder_offsets <> '' AND assistants.reminder_offsets IS NOT NULL").
+		Where("DATE(events.start_date) BETWEEN ? AND ?", fromDate, toDate).
+		Preload("Assistant").
+		Preload("Contact.NumberPhoneEntity").
+		Find(&events).Error
+	return events, err
+}
+
+// FindByMessageID busca el recordatorio al que responde un mensaje (context.id del webhook)
+func (r *EventRemindersRepository) FindByMessageID(messageID string) (*entities.EventReminder, error) {
+	var record entities.EventReminder
+	err := r.db.Preload("Event").
+		Where("message_id_whatsapp = ? AND status = ?", messageID, entities.EventReminderStatusSent).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, err
+}
+
+// FindLastSentToContact devuelve el último recordatorio enviado al contacto desde since
+func (r *EventRemindersRepository) FindLastSentToContact(contactID int64, since time.Time) (*entities.EventReminder, error) {
+	var record entities.EventReminder
+	err := r.db.Preload("Event").
+		Where("contacts_id = ? AND status = ? AND sent_at >= ?", contactID, entities.EventReminderStatusSent, since).
+		Order("sent_at DESC").
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, err
+}
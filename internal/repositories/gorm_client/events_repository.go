@@ -0,0 +1,290 @@
+package gorm_client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Implementación del repositorio
+type eventsRepositoryImpl struct {
+	db      *gorm.DB
+	dialect Dialect
+	tenant  dtos.TenantScope
+}
+
+func NewEventsRepository(db *gorm.DB, dialect Dialect) repositories.EventsRepository {
+	return &eventsRepositoryImpl{db: db, dialect: dialect}
+}
+
+func (r *eventsRepositoryImpl) WithTenant(tenant dtos.TenantScope) repositories.EventsRepository {
+	return &eventsRepositoryImpl{db: r.db, dialect: r.dialect, tenant: tenant}
+}
+
+func (r *eventsRepositoryImpl) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByAssistant(r.tenant, "events.assistants_id"))
+}
+
+// allowsReferences verifica que el assistant y el contacto del evento sean del scope
+func (r *eventsRepositoryImpl) allowsReferences(event *entities.Events) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), event.AssistantsID); err != nil {
+		return err
+	}
+	return tenantAllows(r.db, r.tenant, &entities.Contact{}, tenantByNumberPhone(r.tenant, "contacts.number_phones_id"), event.ContactsID)
+}
+
+func (r *eventsRepositoryImpl) FindByContactDateAndNumberPhone(contactID int64, date string, assistantID int64) ([]entities.Events, error) {
+	var events []entities.Events
+
+	// Realizamos la consulta filtrando por contacts_id, fecha y number_phones_id
+	err := r.scoped().
+		Where("contacts_id = ? AND DATE(start_date) = ? AND assistants_id = ?", contactID, date, assistantID).
+		Find(&events).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("error finding events by contact, date, and number_phone_id: %v", err)
+	}
+	return events, nil
+}
+
+// FindByAssistantAndDate obtiene los eventos de todos los contactos de un assistant para una fecha ("YYYY-MM-DD")
+func (r *eventsRepositoryImpl) FindByAssistantAndDate(assistantID int64, date string) ([]entities.Events, error) {
+	var events []entities.Events
+	err := r.scoped().
+		Where("assistants_id = ? AND DATE(start_date) = ?", assistantID, date).
+		Order("start_date ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding events by assistant and date: %v", err)
+	}
+	return events, nil
+}
+
+func (r *eventsRepositoryImpl) FindByContactAndCodeEvent(contactID int64, codeEvent string) (entities.Events, error) {
+	var event entities.Events
+
+	// Realizamos la consulta para obtener un evento por contactID y code_event
+	err := r.scoped().
+		Where("contacts_id = ? AND code_event = ?", contactID, codeEvent).
+		First(&event).Error // Usamos First() porque esperamos solo un evento
+	if err != nil {
+		return entities.Events{}, fmt.Errorf("error finding event by code_event: %v", err)
+	}
+	return event, nil
+}
+
+func (r *eventsRepositoryImpl) FindByContactAndDateAndTime(contactID int64, date string, currentTime string) ([]entities.Events, error) {
+	var events []entities.Events
+
+	// La función espera:
+	// - 'date' en formato "YYYY-MM-DD" (solo la parte de la fecha) para comparar con DATE(start_date).
+	// - 'currentTime' en formato "YYYY-MM-DD HH:MM:SS", que se usará para filtrar los eventos a partir de esa hora.
+	// Sin embargo, currentTime puede venir en distintos formatos. Por ello, se intenta parsearlo usando varios formatos:
+	formats := []string{
+		time.RFC3339,          // Ejemplo: "2006-01-02T15:04:05Z07:00"
+		"2006-01-02 15:04:05", // Ejemplo: "2025-02-03 22:43:00"
+		"2006-01-02",          // Ejemplo: "2025-02-03"
+	}
+
+	var ct time.Time
+	var err error
+	parsed := false
+	for _, format := range formats {
+		ct, err = time.Parse(format, currentTime)
+		if err == nil {
+			parsed = true
+			break
+		}
+	}
+	if !parsed {
+		return nil, fmt.Errorf("error parsing currentTime: %v", err)
+	}
+
+	// Convertir la hora parseada al formato DATETIME deseado: "YYYY-MM-DD HH:MM:SS"
+	formattedCurrentTime := ct.Format("2006-01-02 15:04:05")
+
+	// Realizar la consulta: se buscan los eventos donde:
+	// - contacts_id coincide con el parámetro contactID.
+	// - La fecha de start_date (sin la hora) coincide con 'date' (formato "YYYY-MM-DD").
+	// - start_date es mayor o igual que formattedCurrentTime.
+	err = r.scoped().
+		Where("contacts_id = ? AND DATE(start_date) = ? AND start_date >= ?", contactID, date, formattedCurrentTime).
+		Order("start_date ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *eventsRepositoryImpl) ExistsByCode(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.Events{}).Where("code_event = ?", code).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *eventsRepositoryImpl) Create(event *entities.Events) error {
+	if err := r.allowsReferences(event); err != nil {
+		return err
+	}
+	return r.db.Create(event).Error
+}
+
+// CreateChecked crea el evento en una transacción que bloquea la fila del assistant, así dos altas para el mismo
+// assistant no verifican el horario a la vez. allow recibe los eventos del mismo día leídos con el lock tomado.
+func (r *eventsRepositoryImpl) CreateChecked(event *entities.Events, allow func(sameDay []entities.Events) error) error {
+	if err := r.allowsReferences(event); err != nil {
+		return err
+	}
+	if len(event.StartDate) < len("2006-01-02") {
+		return fmt.Errorf("invalid start date %q", event.StartDate)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkSameDay(tx, event, allow); err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
+}
+
+// checkSameDay bloquea la fila del assistant del evento y le pasa a allow los eventos del día en que empieza
+func checkSameDay(tx *gorm.DB, event *entities.Events, allow func(sameDay []entities.Events) error) error {
+	var assistant entities.Assistant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&assistant, event.AssistantsID).Error; err != nil {
+		return err
+	}
+	var sameDay []entities.Events
+	if err := tx.Where("assistants_id = ? AND DATE(start_date) = ?", event.AssistantsID, event.StartDate[:len("2006-01-02")]).Find(&sameDay).Error; err != nil {
+		return err
+	}
+	return allow(sameDay)
+}
+
+func (r *eventsRepositoryImpl) FindByID(id int) (*entities.Events, error) {
+	var event entities.Events
+	err := r.scoped().Preload("Contact").First(&event, id).Error
+	return &event, err
+}
+
+func (r *eventsRepositoryImpl) FindAll(request *filters.EventsFilter, pagination *dtos.Pagination) (events []entities.Events, total int64, err error) {
+	query := r.scoped().Model(&entities.Events{}).Preload("Contact")
+
+	if request.AssistantsID != nil {
+		if *request.AssistantsID != 0 {
+			query = query.Where("assistants_id = ?", request.AssistantsID)
+		}
+
+	}
+	if request.MonthYear != "" {
+		query = query.Where(r.dialect.YearMonth("start_date")+" = ?", request.MonthYear)
+	}
+	if request.StartDate != "" {
+		query = query.Where("DATE(start_date) = ?", request.StartDate)
+	}
+	// Obtener total sin paginación
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Aplicar paginación si corresponde
+	if pagination.Number > 0 && pagination.Size > 0 {
+		offset := int((pagination.Number - 1) * pagination.Size)
+		query = query.Offset(offset).Limit(int(pagination.Size))
+	}
+
+	if err := query.Order("start_date DESC").Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *eventsRepositoryImpl) Update(event *entities.Events) error {
+	return r.update(event, nil)
+}
+
+// UpdateChecked es Update verificando el nuevo horario con el assistant bloqueado, igual que CreateChecked, para
+// que una reprogramación no ocupe un lugar que tomó otro evento a la vez
+func (r *eventsRepositoryImpl) UpdateChecked(event *entities.Events, allow func(sameDay []entities.Events) error) error {
+	if len(event.StartDate) < len("2006-01-02") {
+		return fmt.Errorf("invalid start date %q", event.StartDate)
+	}
+	return r.update(event, allow)
+}
+
+func (r *eventsRepositoryImpl) update(event *entities.Events, allow func(sameDay []entities.Events) error) error {
+	// Save inserta el registro si no encuentra el ID, por eso se verifica antes que el evento sea del scope
+	if err := tenantAllows(r.db, r.tenant, &entities.Events{}, tenantByAssistant(r.tenant, "events.assistants_id"), int64(event.ID)); err != nil {
+		return err
+	}
+	if err := r.allowsReferences(event); err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if allow != nil {
+			if err := checkSameDay(tx, event, allow); err != nil {
+				return err
+			}
+		}
+		var current entities.Events
+		if err := tx.Select("id", "start_date", "rescheduled_at").First(&current, event.ID).Error; err != nil {
+			return err
+		}
+		// Si cambió la fecha de inicio los recordatorios registrados son del horario anterior: se borran para que
+		// se envíen de nuevo para el nuevo horario
+		event.RescheduledAt = current.RescheduledAt
+		if current.StartDate != event.StartDate {
+			now := time.Now()
+			event.RescheduledAt = &now
+			if err := tx.Where("events_id = ?", event.ID).Delete(&entities.EventReminder{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(event).Error
+	})
+}
+
+func (r *eventsRepositoryImpl) Delete(id int) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Events{}, tenantByAssistant(r.tenant, "events.assistants_id"), int64(id)); err != nil {
+		return err
+	}
+	return r.scoped().Delete(&entities.Events{}, id).Error
+}
+
+// Confirm marca que el contacto confirmó su asistencia al evento
+func (r *eventsRepositoryImpl) Confirm(id int, confirmedAt time.Time) error {
+	return r.scoped().Model(&entities.Events{}).Where("id = ?", id).Update("confirmed_at", confirmedAt).Error
+}
+
+func (r *eventsRepositoryImpl) Cancel(codeEvent string) error {
+	var event entities.Events
+	// Primero obtenemos el primer registro que coincida con el código
+	err := r.scoped().Where("code_event = ?", codeEvent).First(&event).Error
+	if err != nil {
+		return fmt.Errorf("no se pudo eliminar el evento con el código '%s': %w", codeEvent, err)
+	}
+	// Luego eliminamos el registro encontrado
+	err = r.db.Delete(&event).Error
+	if err != nil {
+		return fmt.Errorf("error cancelando evento: %v", err)
+	}
+	return nil
+}
+
+func (r *eventsRepositoryImpl) FindNextByContact(contactID int64, from string) (entities.Events, error) {
+	var event entities.Events
+	err := r.scoped().
+		Where("contacts_id = ? AND start_date >= ?", contactID, from).
+		Order("start_date ASC").
+		First(&event).Error
+	return event, err
+}
@@ -0,0 +1,71 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type FileRepository struct {
+	db     *gorm.DB
+	tenant dtos.TenantScope
+}
+
+func NewFileRepository(db *gorm.DB) *FileRepository {
+	return &FileRepository{db: db}
+}
+
+// WithTenant devuelve una copia del repositorio que solo ve los archivos de los assistants del scope
+func (r *FileRepository) WithTenant(tenant dtos.TenantScope) repositories.FileRepository {
+	return &FileRepository{db: r.db, tenant: tenant}
+}
+
+func (r *FileRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByAssistant(r.tenant, "files.assistants_id"))
+}
+
+// CheckAssistant verifica que el assistant sea del scope (gorm.ErrRecordNotFound si no)
+func (r *FileRepository) CheckAssistant(assistantID int64) error {
+	return tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), assistantID)
+}
+
+func (r *FileRepository) Create(file entities.File) error {
+	if err := r.CheckAssistant(file.AssistantsID); err != nil {
+		return err
+	}
+	return r.db.Create(&file).Error
+}
+
+func (r *FileRepository) FindAll() ([]entities.File, error) {
+	var files []entities.File
+	err := r.scoped().Find(&files).Error
+	return files, err
+}
+
+func (r *FileRepository) FindById(id int64) (entities.File, error) {
+	var file entities.File
+	err := r.scoped().First(&file, id).Error
+	return file, err
+}
+
+func (r *FileRepository) FindByAssistantID(id int64) ([]entities.File, error) {
+	var file []entities.File
+	err := r.scoped().Where("assistants_id = ?", id).Find(&file).Error
+	return file, err
+}
+
+func (r *FileRepository) Update(file entities.File) error {
+	// Save inserta el registro si no encuentra el ID, por eso se verifica antes que el archivo y su assistant sean del scope
+	if err := tenantAllows(r.db, r.tenant, &entities.File{}, tenantByAssistant(r.tenant, "files.assistants_id"), file.ID); err != nil {
+		return err
+	}
+	if err := r.CheckAssistant(file.AssistantsID); err != nil {
+		return err
+	}
+	return r.db.Save(&file).Error
+}
+
+func (r *FileRepository) Delete(id int64) error {
+	return r.scoped().Delete(&entities.File{}, id).Error
+}
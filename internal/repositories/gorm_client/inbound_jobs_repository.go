@@ -0,0 +1,144 @@
+package gorm_client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboundJobsRepository handles the queue of webhook notifications pending to be processed
+type InboundJobsRepository struct {
+	db *gorm.DB
+}
+
+// NewInboundJobsRepository creates a new instance of InboundJobsRepository
+func NewInboundJobsRepository(db *gorm.DB) *InboundJobsRepository {
+	return &InboundJobsRepository{db: db}
+}
+
+// Create inserts a new job into the queue
+func (r *InboundJobsRepository) Create(record *entities.InboundJob) error {
+	return r.db.Create(record).Error
+}
+
+// FindByID retrieves a job by its ID
+func (r *InboundJobsRepository) FindByID(id int64) (entities.InboundJob, error) {
+	var record entities.InboundJob
+	err := r.db.First(&record, id).Error
+	return record, err
+}
+
+// ClaimNext toma el próximo job pendiente y lo marca como processing.
+// SKIP LOCKED permite que varios workers (o varias instancias de la API) tomen jobs distintos sin bloquearse.
+// Devuelve nil si no hay jobs para procesar.
+func (r *InboundJobsRepository) ClaimNext(now time.Time) (*entities.InboundJob, error) {
+	var record entities.InboundJob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", entities.InboundJobStatusPending, now).
+			Order("next_run_at ASC, id ASC").
+			First(&record).Error
+		if err != nil {
+			return err
+		}
+
+		record.Status = entities.InboundJobStatusProcessing
+		record.LockedAt = &now
+		return tx.Model(&record).Updates(map[string]interface{}{
+			"status":    record.Status,
+			"locked_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// MarkDone marca el job como procesado
+func (r *InboundJobsRepository) MarkDone(id int64, processedAt time.Time) error {
+	return r.db.Model(&entities.InboundJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       entities.InboundJobStatusDone,
+			"processed_at": processedAt,
+			"locked_at":    nil,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed registra un intento fallido y deja el job con el estado indicado (pending para reintentar o dead)
+func (r *InboundJobsRepository) MarkFailed(id int64, status string, attempts int, lastError string, nextRunAt time.Time) error {
+	return r.db.Model(&entities.InboundJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"attempts":    attempts,
+			"last_error":  lastError,
+			"next_run_at": nextRunAt,
+			"locked_at":   nil,
+		}).Error
+}
+
+// Replay vuelve a encolar un job muerto reiniciando sus intentos
+func (r *InboundJobsRepository) Replay(id int64, now time.Time) (int64, error) {
+	result := r.db.Model(&entities.InboundJob{}).
+		Where("id = ? AND status = ?", id, entities.InboundJobStatusDead).
+		Updates(map[string]interface{}{
+			"status":      entities.InboundJobStatusPending,
+			"attempts":    0,
+			"next_run_at": now,
+			"locked_at":   nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// RequeueStale devuelve a pending los jobs que quedaron en processing (por ejemplo, si se reinició el proceso a mitad de camino)
+func (r *InboundJobsRepository) RequeueStale(lockedBefore time.Time) (int64, error) {
+	result := r.db.Model(&entities.InboundJob{}).
+		Where("status = ? AND locked_at < ?", entities.InboundJobStatusProcessing, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":    entities.InboundJobStatusPending,
+			"locked_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetByStatus - Obtiene los jobs con un estado específico (vacío = todos) con paginación
+func (r *InboundJobsRepository) GetByStatus(status string, page int, limit int) ([]entities.InboundJob, int, error) {
+	var records []entities.InboundJob
+	var total int64
+
+	query := r.db.Model(&entities.InboundJob{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, int(total), nil
+}
@@ -0,0 +1,39 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MessageStatusesRepository handles the status history of outbound messages
+type MessageStatusesRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageStatusesRepository creates a new instance of MessageStatusesRepository
+func NewMessageStatusesRepository(db *gorm.DB) *MessageStatusesRepository {
+	return &MessageStatusesRepository{db: db}
+}
+
+// Create inserts a new status record into the database. A status already registered for the message (same status
+// and timestamp) is ignored.
+func (r *MessageStatusesRepository) Create(record *entities.MessageStatus) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record).Error
+}
+
+// FindByMessageIdWhatsapp retrieves the status history of a message by its wamid
+func (r *MessageStatusesRepository) FindByMessageIdWhatsapp(messageIdWhatsapp string) ([]entities.MessageStatus, error) {
+	var records []entities.MessageStatus
+	err := r.db.Where("message_id_whatsapp = ?", messageIdWhatsapp).
+		Order("status_timestamp ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// LinkToMessage asocia al mensaje los estados que llegaron antes de que se guardara
+func (r *MessageStatusesRepository) LinkToMessage(messageIdWhatsapp string, messageID int64) error {
+	return r.db.Model(&entities.MessageStatus{}).
+		Where("message_id_whatsapp = ? AND messages_id IS NULL", messageIdWhatsapp).
+		Update("messages_id", messageID).Error
+}
@@ -0,0 +1,93 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// MessageTemplatesRepository maneja la copia local de los templates de Meta de cada número
+type MessageTemplatesRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageTemplatesRepository(db *gorm.DB) *MessageTemplatesRepository {
+	return &MessageTemplatesRepository{db: db}
+}
+
+// FindByNumberPhone lista los templates del número ordenados por nombre e idioma
+func (r *MessageTemplatesRepository) FindByNumberPhone(numberPhoneID int64) ([]entities.MessageTemplate, error) {
+	var records []entities.MessageTemplate
+	err := r.db.Where("number_phones_id = ?", numberPhoneID).Order("name ASC, language ASC").Find(&records).Error
+	return records, err
+}
+
+// FindByName busca el template del número con ese nombre e idioma
+func (r *MessageTemplatesRepository) FindByName(numberPhoneID int64, name, language string) (entities.MessageTemplate, error) {
+	var record entities.MessageTemplate
+	err := r.db.Where("number_phones_id = ? AND name = ? AND language = ?", numberPhoneID, name, language).First(&record).Error
+	return record, err
+}
+
+// FindForAssistant busca el template con ese nombre e idioma en alguno de los números del assistant
+func (r *MessageTemplatesRepository) FindForAssistant(assistantID int64, name, language string) (entities.MessageTemplate, error) {
+	var record entities.MessageTemplate
+	err := r.db.Joins("JOIN number_phones ON number_phones.id = message_templates.number_phones_id AND number_phones.deleted_at IS NULL").
+		Where("number_phones.assistants_id = ? AND message_templates.name = ? AND message_templates.language = ?", assistantID, name, language).
+		First(&record).Error
+	return record, err
+}
+
+// Upsert crea o actualiza el template del número con ese nombre e idioma
+func (r *MessageTemplatesRepository) Upsert(record *entities.MessageTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return upsertMessageTemplate(tx, record)
+	})
+}
+
+// ReplaceForNumberPhone deja en la base exactamente los templates recibidos para el número
+func (r *MessageTemplatesRepository) ReplaceForNumberPhone(numberPhoneID int64, records []entities.MessageTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		keep := make([]int64, 0, len(records))
+		for i := range records {
+			records[i].NumberPhonesID = numberPhoneID
+			if err := upsertMessageTemplate(tx, &records[i]); err != nil {
+				return err
+			}
+			keep = append(keep, records[i].ID)
+		}
+
+		query := tx.Where("number_phones_id = ?", numberPhoneID)
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		return query.Delete(&entities.MessageTemplate{}).Error
+	})
+}
+
+// DeleteByName borra el template del número en todos sus idiomas
+func (r *MessageTemplatesRepository) DeleteByName(numberPhoneID int64, name string) error {
+	return r.db.Where("number_phones_id = ? AND name = ?", numberPhoneID, name).Delete(&entities.MessageTemplate{}).Error
+}
+
+// FindNumberPhoneIDsWithStatus devuelve los números que tienen templates en ese estado
+func (r *MessageTemplatesRepository) FindNumberPhoneIDsWithStatus(status string) ([]int64, error) {
+	var ids []int64
+	err := r.db.Model(&entities.MessageTemplate{}).Where("status = ?", status).Distinct().Pluck("number_phones_id", &ids).Error
+	return ids, err
+}
+
+func upsertMessageTemplate(tx *gorm.DB, record *entities.MessageTemplate) error {
+	var existing entities.MessageTemplate
+	err := tx.Where("number_phones_id = ? AND name = ? AND language = ?", record.NumberPhonesID, record.Name, record.Language).
+		First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return tx.Create(record).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	record.ID = existing.ID
+	record.CreatedAt = existing.CreatedAt
+	return tx.Save(record).Error
+}
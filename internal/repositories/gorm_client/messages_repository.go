@@ -0,0 +1,315 @@
+package gorm_client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// MessagesRepository handles operations related to messages
+type MessagesRepository struct {
+	db     *gorm.DB
+	tenant dtos.TenantScope
+}
+
+// NewMessagesRepository creates a new instance of MessagesRepository
+func NewMessagesRepository(db *gorm.DB) *MessagesRepository {
+	return &MessagesRepository{db: db}
+}
+
+// WithTenant returns a copy of the repository that only sees the messages of the scope's number phones
+func (r *MessagesRepository) WithTenant(tenant dtos.TenantScope) repositories.MessagesRepository {
+	return &MessagesRepository{db: r.db, tenant: tenant}
+}
+
+func (r *MessagesRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByNumberPhone(r.tenant, "messages.number_phones_id"))
+}
+
+// Create inserts a new message record into the database
+func (r *MessagesRepository) Create(record entities.Message) error {
+	return r.db.Create(&record).Error
+}
+
+// CreateAndReturn inserts a new message record and returns it with the generated ID
+func (r *MessagesRepository) CreateAndReturn(record entities.Message) (entities.Message, error) {
+	err := r.db.Create(&record).Error
+	return record, err
+}
+
+// FindByID retrieves a message by its ID
+func (r *MessagesRepository) FindByID(id int64) (entities.Message, error) {
+	var record entities.Message
+	err := r.scoped().First(&record, id).Error
+	return record, err
+}
+
+// FindByMessageIdWhatsapp retrieves a message by the wamid assigned by WhatsApp
+func (r *MessagesRepository) FindByMessageIdWhatsapp(messageIdWhatsapp string) (entities.Message, error) {
+	var record entities.Message
+	err := r.scoped().Where("message_id_whatsapp = ?", messageIdWhatsapp).First(&record).Error
+	return record, err
+}
+
+// UpdateStatus actualiza el último estado informado por WhatsApp para un mensaje
+func (r *MessagesRepository) UpdateStatus(id int64, status string, statusAt time.Time, errorCode int, errorTitle string) error {
+	return r.scoped().Model(&entities.Message{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":            status,
+			"status_updated_at": statusAt,
+			"error_code":        errorCode,
+			"error_title":       errorTitle,
+		}).Error
+}
+
+// GetMessagesByNumberPhoneAndStatus - Obtiene los mensajes de un número de teléfono con un estado específico (ej: failed) con paginación
+func (r *MessagesRepository) GetMessagesByNumberPhoneAndStatus(numberPhoneID int64, status string, page int, limit int) ([]entities.Message, int, error) {
+	var messages []entities.Message
+	var total int64
+
+	query := r.scoped().Model(&entities.Message{}).
+		Joins("JOIN contacts ON contacts.id = messages.contacts_id").
+		Where("messages.number_phones_id = ? AND messages.status = ? AND contacts.deleted_at IS NULL", numberPhoneID, status)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	err := query.
+		Order("messages.created_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Preload("Contact").
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, int(total), nil
+}
+
+// Verifyca si existe un registro con el messageID.
+func (r *MessagesRepository) ExistsByMessageID(messageID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.Message{}).Where("message_id_whatsapp = ?", messageID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Obtener todos los mensajes entre un assistant y un contact
+func (r *MessagesRepository) GetMessagesByAssistantAndContact(assistantID, contactID int64) ([]entities.Message, error) {
+	var messages []entities.Message
+	err := r.scoped().Where("assistant_id = ? AND contact_id = ?", assistantID, contactID).Order("created_at ASC").Find(&messages).Error
+	return messages, err
+}
+
+// GetMessagesByNumber retrieves all messages associated with a specific number within a given time range
+func (r *MessagesRepository) GetMessagesByNumber(numberID, contacID int64, since time.Time) ([]entities.Message, error) {
+	var messages []entities.Message
+	err := r.scoped().Where("number_phones_id = ? AND contacts_id = ? AND  created_at >= ?", numberID, contacID, since).Order("created_at ASC").Preload("Contact").Find(&messages).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *MessagesRepository) GetConversation(assistantID, contactID int64, sinceMinutes int) ([]entities.Message, error) {
+	var messages []entities.Message
+	query := r.scoped().Where("assistants_id = ? AND contacts_id = ?", assistantID, contactID).Order("created_at ASC")
+
+	if sinceMinutes > 0 {
+		threshold := time.Now().Add(-time.Duration(sinceMinutes) * time.Minute)
+		query = query.Where("created_at >= ?", threshold)
+	}
+
+	err := query.Find(&messages).Error
+	return messages, err
+}
+
+func (r *MessagesRepository) GetMessagesWithContacts(numberIDs []int64, since time.Time) ([]entities.Message, error) {
+	var messages []entities.Message
+
+	err := r.scoped().
+		Where("number_phones_id IN ? AND created_at >= ?", numberIDs, since).
+		Preload("NumberPhone").
+		Preload("Contact").
+		Order("created_at DESC").
+		Find(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("error fetching messages with contacts: %w", err)
+	}
+
+	return messages, nil
+}
+
+// DoesNumberPhoneExist - Verifica si un número de teléfono existe en la base de datos
+func (r *MessagesRepository) DoesNumberPhoneExist(numberPhoneID int64) (bool, error) {
+	var count int64
+
+	err := r.db.Model(&entities.NumberPhone{}).
+		Scopes(tenantByAssistant(r.tenant, "number_phones.assistants_id")).
+		Where("id = ?", numberPhoneID).
+		Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetMessagesByNumberPhone - Obtiene los mensajes asociados a un número de teléfono específico con paginación
+func (r *MessagesRepository) GetMessagesByNumberPhone(numberPhoneID int64, page int, limit int) ([]entities.Message, int, error) {
+	var messages []entities.Message
+	var total int64
+
+	// Contar el total de registros antes de aplicar paginación
+	err := r.scoped().Model(&entities.Message{}).
+		Joins("JOIN contacts ON contacts.id = messages.contacts_id").
+		Where("messages.number_phones_id = ? AND contacts.deleted_at IS NULL", numberPhoneID).
+		Count(&total).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Evitar valores inválidos en paginación
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	// Obtener los registros paginados
+	err = r.scoped().
+		Joins("JOIN contacts ON contacts.id = messages.contacts_id").
+		Where("messages.number_phones_id = ? AND contacts.deleted_at IS NULL", numberPhoneID).
+		Order("messages.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Preload("Contact").
+		Find(&messages).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, int(total), nil
+}
+
+// GetMessagesByNumberPhoneAndContact - Obtiene los mensajes asociados a un número de teléfono y contacto específico con paginación
+func (r *MessagesRepository) GetMessagesByNumberPhoneAndContact(numberPhoneID int64, contactID int64, page int, limit int) ([]entities.Message, int, error) {
+	var messages []entities.Message
+	var total int64
+
+	// Contar el total de registros antes de aplicar paginación
+	err := r.scoped().Model(&entities.Message{}).
+		Joins("JOIN contacts ON contacts.id = messages.contacts_id").
+		Where("messages.number_phones_id = ? AND messages.contacts_id = ? AND contacts.deleted_at IS NULL", numberPhoneID, contactID).
+		Count(&total).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Evitar valores inválidos en paginación
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	// Obtener los registros paginados
+	err = r.scoped().
+		Joins("JOIN contacts ON contacts.id = messages.contacts_id").
+		Where("messages.number_phones_id = ? AND messages.contacts_id = ? AND contacts.deleted_at IS NULL", numberPhoneID, contactID).
+		Order("messages.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Preload("Contact").
+		Find(&messages).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, int(total), nil
+}
+
+// GetRecentByContact - Obtiene los últimos mensajes de un contacto desde una fecha, ordenados del más viejo al más nuevo
+func (r *MessagesRepository) GetRecentByContact(contactID int64, since time.Time, limit int) ([]entities.Message, error) {
+	var messages []entities.Message
+	err := r.scoped().Where("contacts_id = ? AND created_at >= ?", contactID, since).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Se buscan los más nuevos y se invierten para devolverlos en orden cronológico
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// FindLastByContact retrieves the most recent message of a contact (nil if the contact has no messages)
+func (r *MessagesRepository) FindLastByContact(contactID int64) (*entities.Message, error) {
+	var messages []entities.Message
+	err := r.scoped().Where("contacts_id = ?", contactID).
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Find(&messages).Error
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+// FindAfterID retrieves the messages with an ID greater than afterID, oldest first. With numberPhoneID 0 it
+// returns the messages of every number phone in the scope.
+func (r *MessagesRepository) FindAfterID(afterID, numberPhoneID int64, limit int) ([]entities.Message, error) {
+	query := r.scoped().Where("messages.id > ?", afterID)
+	if numberPhoneID > 0 {
+		query = query.Where("messages.number_phones_id = ?", numberPhoneID)
+	}
+
+	var messages []entities.Message
+	err := query.Order("messages.id ASC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// LastInboundAt returns when the contact last wrote to the number (nil if the contact never wrote)
+func (r *MessagesRepository) LastInboundAt(contactID int64) (*time.Time, error) {
+	var messages []entities.Message
+	err := r.scoped().Where("contacts_id = ? AND is_from_bot = ?", contactID, false).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&messages).Error
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0].CreatedAt, nil
+}
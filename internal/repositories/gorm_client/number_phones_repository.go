@@ -1,16 +1,19 @@
-package postgres_client
+package gorm_client
 
 import (
 	"fmt"
 
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"gorm.io/gorm"
 )
 
 // NumberPhonesRepository is the repository for NumberPhone entities
 type NumberPhonesRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant dtos.TenantScope
 }
 
 // NewNumberPhonesRepository creates a new instance of NumberPhonesRepository
@@ -18,39 +21,73 @@ func NewNumberPhonesRepository(db *gorm.DB) *NumberPhonesRepository {
 	return &NumberPhonesRepository{db: db}
 }
 
+// WithTenant returns a copy of the repository that only sees the number phones of the scope's assistants
+func (r *NumberPhonesRepository) WithTenant(tenant dtos.TenantScope) repositories.NumberPhonesRepository {
+	return &NumberPhonesRepository{db: r.db, tenant: tenant}
+}
+
+func (r *NumberPhonesRepository) scoped() *gorm.DB {
+	return r.db.Scopes(tenantByAssistant(r.tenant, "number_phones.assistants_id"))
+}
+
 // Create inserts a new number phone record into the database
 func (r *NumberPhonesRepository) Create(record entities.NumberPhone) error {
+	if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), record.AssistantsID); err != nil {
+		return err
+	}
 	return r.db.Create(&record).Error
 }
 
 // FindByID retrieves a number phone record by its ID
 func (r *NumberPhonesRepository) FindByID(id string) (entities.NumberPhone, error) {
 	var record entities.NumberPhone
-	err := r.db.First(&record, id).Error
+	err := r.scoped().First(&record, id).Error
+	return record, err
+}
+
+// FindByWhatsappNumberPhoneID retrieves a number phone by the phone_number_id assigned by Meta
+func (r *NumberPhonesRepository) FindByWhatsappNumberPhoneID(whatsappNumberPhoneID string) (entities.NumberPhone, error) {
+	var record entities.NumberPhone
+	err := r.scoped().Where("whatsapp_number_phone_id = ?", whatsappNumberPhoneID).First(&record).Error
 	return record, err
 }
 
 // Update modifies an existing number phone record
 func (r *NumberPhonesRepository) Update(id string, record entities.NumberPhone) error {
-	return r.db.Model(&record).Where("id = ?", id).Updates(record).Error
+	if err := r.allows(id); err != nil {
+		return err
+	}
+	if record.AssistantsID != 0 {
+		if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), record.AssistantsID); err != nil {
+			return err
+		}
+	}
+	return r.scoped().Model(&record).Where("id = ?", id).Updates(record).Error
 }
 
 // Delete removes a number phone record from the database
 func (r *NumberPhonesRepository) Delete(id string) error {
-	return r.db.Delete(&entities.NumberPhone{}, id).Error
+	if err := r.allows(id); err != nil {
+		return err
+	}
+	return r.scoped().Delete(&entities.NumberPhone{}, id).Error
 }
 
 // List retrieves all number phone records
 func (r *NumberPhonesRepository) List() ([]entities.NumberPhone, error) {
 	var records []entities.NumberPhone
-	err := r.db.Find(&records).Error
+	err := r.scoped().Find(&records).Error
 	return records, err
 }
 
 // GetNumberPhonesByAssistantID retrieves all number phones associated with a specific assistant
 func (r *NumberPhonesRepository) GetNumberPhonesByAssistantID(assistantID int64) ([]entities.NumberPhone, error) {
+	// Un assistant de otro bussiness se informa como inexistente y no como una lista vacía
+	if err := tenantAllows(r.db, r.tenant, &entities.Assistant{}, tenantByBussiness(r.tenant, "assistants.bussiness_id"), assistantID); err != nil {
+		return nil, err
+	}
 	var numberPhones []entities.NumberPhone
-	err := r.db.Where("assistants_id = ?", assistantID).Find(&numberPhones).Error
+	err := r.scoped().Where("assistants_id = ?", assistantID).Find(&numberPhones).Error
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving number phones for assistant ID %d: %w", assistantID, err)
 	}
@@ -60,7 +97,7 @@ func (r *NumberPhonesRepository) GetNumberPhonesByAssistantID(assistantID int64)
 // FindByAssistantID retrieves all number phones associated with a specific assistant
 func (r *NumberPhonesRepository) FindByAssistantID(assistantID int64) ([]entities.NumberPhone, error) {
 	var records []entities.NumberPhone
-	err := r.db.Where("assistants_id = ?", assistantID).Find(&records).Error
+	err := r.scoped().Where("assistants_id = ?", assistantID).Find(&records).Error
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +108,7 @@ func (r *NumberPhonesRepository) ListByFilter(filter filters.AssistantsFiltro) (
 	var records []entities.NumberPhone
 
 	// Base de la consulta
-	query := r.db.Where("number_phone_to_notify > ?", 0)
+	query := r.scoped().Where("number_phone_to_notify <> ?", "")
 
 	// Aplicar Preload para relaciones si es necesario
 	if filter.UpladContacts {
@@ -98,3 +135,12 @@ func (r *NumberPhonesRepository) UUIDExists(uuid string) (bool, error) {
 	// Si count > 0, significa que ya existe un número de teléfono con ese UUID
 	return count > 0, nil
 }
+
+// allows checks that the number phone belongs to the scope (gorm.ErrRecordNotFound otherwise)
+func (r *NumberPhonesRepository) allows(id string) error {
+	if !r.tenant.Restricted {
+		return nil
+	}
+	var record entities.NumberPhone
+	return r.scoped().Select("id").First(&record, id).Error
+}
@@ -0,0 +1,36 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// NewRepositories crea todos los repositorios sobre la misma conexión, con las consultas propias del motor en dialect
+func NewRepositories(db *gorm.DB, dialect Dialect) *repositories.Repositories {
+	return &repositories.Repositories{
+		Users:                     NewUsersRepository(db),
+		Roles:                     NewRolesRepository(db),
+		Permissions:               NewPermissionsRepository(db),
+		PasswordResets:            NewPasswordResetsRepository(db),
+		Tenant:                    NewTenantRepository(db),
+		Logs:                      NewLogsRepository(db),
+		Configurations:            NewConfigurationsRepository(db),
+		Bussiness:                 NewBussinessRepository(db),
+		Assistants:                NewAssistantRepository(db),
+		Files:                     NewFileRepository(db),
+		AssistantClosures:         NewAssistantClosuresRepository(db),
+		AssistantTemplates:        NewAssistantTemplatesRepository(db),
+		GoogleCalendarCredentials: NewGoogleCalendarConfigsRepository(db),
+		TokenUsages:               NewTokenUsagesRepository(db),
+		NumberPhones:              NewNumberPhonesRepository(db),
+		MessageTemplates:          NewMessageTemplatesRepository(db),
+		Messages:                  NewMessagesRepository(db),
+		MessageStatuses:           NewMessageStatusesRepository(db),
+		InboundJobs:               NewInboundJobsRepository(db),
+		Threads:                   NewThreadRepository(db),
+		Contacts:                  NewContactsRepository(db),
+		Events:                    NewEventsRepository(db, dialect),
+		EventReminders:            NewEventRemindersRepository(db),
+		Campaigns:                 NewCampaignsRepository(db),
+	}
+}
@@ -0,0 +1,99 @@
+package gorm_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// TenantRepository resuelve a qué bussiness pertenece cada usuario
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// FindBussinessIDsByUser devuelve los IDs de los bussiness (no eliminados) a los que está asociado el usuario
+func (r *TenantRepository) FindBussinessIDsByUser(userID int64) ([]int64, error) {
+	ids := []int64{}
+	err := r.db.Model(&entities.BussinessHasUsers{}).
+		Joins("JOIN bussiness ON bussiness.id = bussiness_has_users.bussiness_id AND bussiness.deleted_at IS NULL").
+		Where("bussiness_has_users.users_id = ?", userID).
+		Pluck("bussiness_has_users.bussiness_id", &ids).Error
+	return ids, err
+}
+
+// Los siguientes scopes filtran una consulta por los bussiness del TenantScope. Reciben la columna (con el nombre
+// de la tabla) que referencia al bussiness, assistant, número o contacto. Si el scope no está restringido no filtran.
+
+func tenantByBussiness(scope dtos.TenantScope, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !scope.Restricted {
+			return db
+		}
+		if len(scope.BussinessIDs) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where(column+" IN ?", scope.BussinessIDs)
+	}
+}
+
+func tenantByAssistant(scope dtos.TenantScope, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !scope.Restricted {
+			return db
+		}
+		return db.Where(column+" IN (?)", tenantAssistantIDs(db, scope))
+	}
+}
+
+func tenantByNumberPhone(scope dtos.TenantScope, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !scope.Restricted {
+			return db
+		}
+		return db.Where(column+" IN (?)", tenantNumberPhoneIDs(db, scope))
+	}
+}
+
+func tenantByContact(scope dtos.TenantScope, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !scope.Restricted {
+			return db
+		}
+		contactIDs := db.Session(&gorm.Session{NewDB: true}).Model(&entities.Contact{}).Select("contacts.id").
+			Where("contacts.number_phones_id IN (?)", tenantNumberPhoneIDs(db, scope))
+		return db.Where(column+" IN (?)", contactIDs)
+	}
+}
+
+// tenantAssistantIDs es la subconsulta con los IDs de los assistants del scope
+func tenantAssistantIDs(db *gorm.DB, scope dtos.TenantScope) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&entities.Assistant{}).Select("assistants.id").
+		Scopes(tenantByBussiness(scope, "assistants.bussiness_id"))
+}
+
+// tenantNumberPhoneIDs es la subconsulta con los IDs de los números de los assistants del scope
+func tenantNumberPhoneIDs(db *gorm.DB, scope dtos.TenantScope) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&entities.NumberPhone{}).Select("number_phones.id").
+		Where("number_phones.assistants_id IN (?)", tenantAssistantIDs(db, scope))
+}
+
+// tenantAllows verifica que el registro con ese ID exista y pase el filtro del scope. Se usa antes de crear
+// o mover registros que referencian a otro (ej: un evento a un assistant). Devuelve gorm.ErrRecordNotFound
+// si no corresponde, para que la API responda 404 igual que cuando el registro no existe.
+func tenantAllows(db *gorm.DB, scope dtos.TenantScope, model interface{}, filter func(*gorm.DB) *gorm.DB, id int64) error {
+	if !scope.Restricted {
+		return nil
+	}
+	var count int64
+	if err := db.Model(model).Scopes(filter).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package gorm_client
+
+import (
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// Columna de token_usages por la que se agrupa cada dimensión del reporte
+var usageGroupColumns = map[string]string{
+	dtos.UsageGroupDay:         "day",
+	dtos.UsageGroupBussiness:   "bussiness_id",
+	dtos.UsageGroupAssistant:   "assistants_id",
+	dtos.UsageGroupNumberPhone: "number_phones_id",
+	dtos.UsageGroupContact:     "contacts_id",
+	dtos.UsageGroupModel:       "model",
+}
+
+// TokenUsagesRepository guarda los tokens que consume el modelo en cada respuesta y arma los reportes de consumo
+type TokenUsagesRepository struct {
+	db     *gorm.DB
+	tenant dtos.TenantScope
+}
+
+func NewTokenUsagesRepository(db *gorm.DB) *TokenUsagesRepository {
+	return &TokenUsagesRepository{db: db}
+}
+
+// WithTenant devuelve una copia del repositorio que solo ve el consumo de los bussiness del scope
+func (r *TokenUsagesRepository) WithTenant(tenant dtos.TenantScope) repositories.TokenUsagesRepository {
+	return &TokenUsagesRepository{db: r.db, tenant: tenant}
+}
+
+func (r *TokenUsagesRepository) scoped() *gorm.DB {
+	return r.db.Model(&entities.TokenUsage{}).Scopes(tenantByBussiness(r.tenant, "token_usages.bussiness_id"))
+}
+
+func (r *TokenUsagesRepository) Create(record *entities.TokenUsage) error {
+	return r.db.Create(record).Error
+}
+
+// ContactTotal devuelve todos los tokens que consumió el contacto
+func (r *TokenUsagesRepository) ContactTotal(contactID int64) (int64, error) {
+	var total int64
+	err := r.scoped().Where("contacts_id = ?", contactID).
+		Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+// BussinessTotals devuelve los tokens y el costo del bussiness entre los días from y to (inclusive)
+func (r *TokenUsagesRepository) BussinessTotals(bussinessID int64, from, to string) (int64, float64, error) {
+	var totals struct {
+		Tokens int64
+		Cost   float64
+	}
+	err := r.scoped().Where("bussiness_id = ? AND day >= ? AND day <= ?", bussinessID, from, to).
+		Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0) AS tokens, COALESCE(SUM(cost), 0) AS cost").
+		Scan(&totals).Error
+	return totals.Tokens, totals.Cost, err
+}
+
+// Report suma el consumo del período agrupado por las dimensiones del filtro (ya validado)
+func (r *TokenUsagesRepository) Report(filter dtos.UsageFilterDto) ([]dtos.UsageReportRowDto, error) {
+	columns := make([]string, 0, len(filter.GroupBy))
+	for _, group := range filter.GroupBy {
+		columns = append(columns, usageGroupColumns[group])
+	}
+
+	query := r.scoped().Where("day >= ? AND day <= ?", filter.From, filter.To)
+	if filter.BussinessID > 0 {
+		query = query.Where("bussiness_id = ?", filter.BussinessID)
+	}
+	if filter.AssistantID > 0 {
+		query = query.Where("assistants_id = ?", filter.AssistantID)
+	}
+	if filter.NumberPhoneID > 0 {
+		query = query.Where("number_phones_id = ?", filter.NumberPhoneID)
+	}
+	if filter.ContactID > 0 {
+		query = query.Where("contacts_id = ?", filter.ContactID)
+	}
+
+	sums := "SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, COUNT(*) AS runs, SUM(cost) AS cost"
+	if len(columns) > 0 {
+		grouped := strings.Join(columns, ", ")
+		query = query.Select(grouped + ", " + sums).Group(grouped).Order(grouped)
+	} else {
+		query = query.Select(sums)
+	}
+
+	var rows []dtos.UsageReportRowDto
+	err := query.Scan(&rows).Error
+	return rows, err
+}
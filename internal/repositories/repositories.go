@@ -0,0 +1,34 @@
+// Package repositories define los repositorios de los que dependen los services, sin atarlos a un motor de base.
+//
+// La implementación está en gorm_client y es la misma para todos los motores: lo que cambia entre ellos está aislado
+// en su Dialect. drivers.New elige el dialecto según la conexión, y sqlite_client la arma sobre una base SQLite para
+// correr los services sin un servidor de base.
+package repositories
+
+// Repositories agrupa todos los repositorios de un mismo backend
+type Repositories struct {
+	Users                     UsersRepository
+	Roles                     RolesRepository
+	Permissions               PermissionsRepository
+	PasswordResets            PasswordResetsRepository
+	Tenant                    TenantRepository
+	Logs                      LogsRepository
+	Configurations            ConfigurationsRepository
+	Bussiness                 BussinessRepository
+	Assistants                AssistantRepository
+	Files                     FileRepository
+	AssistantClosures         AssistantClosuresRepository
+	AssistantTemplates        AssistantTemplatesRepository
+	GoogleCalendarCredentials GoogleCalendarCredentialsRepository
+	TokenUsages               TokenUsagesRepository
+	NumberPhones              NumberPhonesRepository
+	MessageTemplates          MessageTemplatesRepository
+	Messages                  MessagesRepository
+	MessageStatuses           MessageStatusesRepository
+	InboundJobs               InboundJobsRepository
+	Threads                   ThreadRepository
+	Contacts                  ContactsRepository
+	Events                    EventsRepository
+	EventReminders            EventRemindersRepository
+	Campaigns                 CampaignsRepository
+}
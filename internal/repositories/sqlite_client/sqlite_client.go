@@ -0,0 +1,37 @@
+// Package sqlite_client arma los repositorios sobre una base SQLite, para correr los services y los tests sin un
+// servidor de base. Usa la misma implementación que Postgres y MySQL (gorm_client) con el dialecto de SQLite.
+package sqlite_client
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/migrations"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/gorm_client"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// OpenInMemory abre una base SQLite en memoria con todas las tablas creadas. Cada llamada es una base nueva.
+func OpenInMemory() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cada conexión a :memory: es una base distinta, así que todo tiene que pasar por la misma
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := migrations.AutoMigrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewRepositories crea todos los repositorios sobre la base SQLite
+func NewRepositories(db *gorm.DB) *repositories.Repositories {
+	return gorm_client.NewRepositories(db, gorm_client.SQLite)
+}
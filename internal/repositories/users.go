@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// UsersRepository guarda los usuarios de la API
+type UsersRepository interface {
+	Create(record entities.Users) error
+	FindByID(id uint) (*entities.Users, error)
+	Update(id int64, record entities.Users) error
+	Delete(id int64) error
+	List() ([]entities.Users, error)
+	FindByEmail(email string) (entities.Users, error)
+}
+
+// RolesRepository guarda los roles y sus permisos
+type RolesRepository interface {
+	Create(record entities.Roles) error
+	FindByID(id string) (entities.Roles, error)
+	Update(id string, record entities.Roles) error
+	GetByRol(rol string) (entities.Roles, error)
+	Delete(id string) error
+	List() ([]entities.Roles, error)
+}
+
+// PermissionsRepository guarda el catálogo de permisos
+type PermissionsRepository interface {
+	Create(record entities.Permissions) error
+	FindByID(id string) (entities.Permissions, error)
+	Update(id string, record entities.Permissions) error
+	Delete(id string) error
+	List() ([]entities.Permissions, error)
+}
+
+// PasswordResetsRepository guarda los tokens para restablecer contraseñas
+type PasswordResetsRepository interface {
+	Create(record entities.PasswordResets) error
+	FindByID(id string) (entities.PasswordResets, error)
+	Update(id string, record entities.PasswordResets) error
+	Delete(id string) error
+	List() ([]entities.PasswordResets, error)
+	FindByToken(token string) (entities.PasswordResets, error)
+	DeleteByToken(token string) error
+}
+
+// TenantRepository resuelve a qué bussiness puede acceder cada usuario
+type TenantRepository interface {
+	FindBussinessIDsByUser(userID int64) ([]int64, error)
+}
+
+// LogsRepository guarda los logs de la API
+type LogsRepository interface {
+	Create(record entities.Logs) error
+	FindByID(id string) (entities.Logs, error)
+	Update(id string, record entities.Logs) error
+	Delete(id string) error
+	List() ([]entities.Logs, error)
+}
+
+// ConfigurationsRepository guarda las configuraciones clave/valor
+type ConfigurationsRepository interface {
+	Create(record entities.Configuration) error
+	FindByID(id int64) (entities.Configuration, error)
+	FindByKey(keyName string) (entities.Configuration, error)
+	List() ([]entities.Configuration, error)
+	Update(record entities.Configuration) error
+	Delete(id int64) error
+}
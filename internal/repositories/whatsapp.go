@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
+)
+
+// NumberPhonesRepository guarda los números de WhatsApp de cada assistant
+type NumberPhonesRepository interface {
+	Create(record entities.NumberPhone) error
+	FindByID(id string) (entities.NumberPhone, error)
+	FindByWhatsappNumberPhoneID(whatsappNumberPhoneID string) (entities.NumberPhone, error)
+	Update(id string, record entities.NumberPhone) error
+	Delete(id string) error
+	List() ([]entities.NumberPhone, error)
+	GetNumberPhonesByAssistantID(assistantID int64) ([]entities.NumberPhone, error)
+	FindByAssistantID(assistantID int64) ([]entities.NumberPhone, error)
+	ListByFilter(filter filters.AssistantsFiltro) ([]entities.NumberPhone, error)
+	UUIDExists(uuid string) (bool, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) NumberPhonesRepository
+}
+
+// MessageTemplatesRepository guarda los templates de Meta sincronizados de cada número
+type MessageTemplatesRepository interface {
+	FindByNumberPhone(numberPhoneID int64) ([]entities.MessageTemplate, error)
+	FindByName(numberPhoneID int64, name, language string) (entities.MessageTemplate, error)
+	FindForAssistant(assistantID int64, name, language string) (entities.MessageTemplate, error)
+	Upsert(record *entities.MessageTemplate) error
+	ReplaceForNumberPhone(numberPhoneID int64, records []entities.MessageTemplate) error
+	DeleteByName(numberPhoneID int64, name string) error
+	FindNumberPhoneIDsWithStatus(status string) ([]int64, error)
+}
+
+// MessagesRepository guarda los mensajes de las conversaciones
+type MessagesRepository interface {
+	Create(record entities.Message) error
+	CreateAndReturn(record entities.Message) (entities.Message, error)
+	FindByID(id int64) (entities.Message, error)
+	FindByMessageIdWhatsapp(messageIdWhatsapp string) (entities.Message, error)
+	UpdateStatus(id int64, status string, statusAt time.Time, errorCode int, errorTitle string) error
+	GetMessagesByNumberPhoneAndStatus(numberPhoneID int64, status string, page int, limit int) ([]entities.Message, int, error)
+	ExistsByMessageID(messageID string) (bool, error)
+	GetMessagesByAssistantAndContact(assistantID, contactID int64) ([]entities.Message, error)
+	GetMessagesByNumber(numberID, contacID int64, since time.Time) ([]entities.Message, error)
+	GetConversation(assistantID, contactID int64, sinceMinutes int) ([]entities.Message, error)
+	GetMessagesWithContacts(numberIDs []int64, since time.Time) ([]entities.Message, error)
+	DoesNumberPhoneExist(numberPhoneID int64) (bool, error)
+	GetMessagesByNumberPhone(numberPhoneID int64, page int, limit int) ([]entities.Message, int, error)
+	GetMessagesByNumberPhoneAndContact(numberPhoneID int64, contactID int64, page int, limit int) ([]entities.Message, int, error)
+	GetRecentByContact(contactID int64, since time.Time, limit int) ([]entities.Message, error)
+	FindLastByContact(contactID int64) (*entities.Message, error)
+	FindAfterID(afterID, numberPhoneID int64, limit int) ([]entities.Message, error)
+	LastInboundAt(contactID int64) (*time.Time, error)
+
+	// Copia del repositorio que solo ve los datos de los bussiness del scope
+	WithTenant(tenant dtos.TenantScope) MessagesRepository
+}
+
+// MessageStatusesRepository guarda el historial de estados que informa WhatsApp por mensaje
+type MessageStatusesRepository interface {
+	Create(record *entities.MessageStatus) error
+	FindByMessageIdWhatsapp(messageIdWhatsapp string) ([]entities.MessageStatus, error)
+	LinkToMessage(messageIdWhatsapp string, messageID int64) error
+}
+
+// InboundJobsRepository es la cola de notificaciones del webhook de WhatsApp
+type InboundJobsRepository interface {
+	Create(record *entities.InboundJob) error
+	FindByID(id int64) (entities.InboundJob, error)
+	ClaimNext(now time.Time) (*entities.InboundJob, error)
+	MarkDone(id int64, processedAt time.Time) error
+	MarkFailed(id int64, status string, attempts int, lastError string, nextRunAt time.Time) error
+	Replay(id int64, now time.Time) (int64, error)
+	RequeueStale(lockedBefore time.Time) (int64, error)
+	GetByStatus(status string, page int, limit int) ([]entities.InboundJob, int, error)
+}
+
+// ThreadRepository guarda los threads de OpenAI de cada contacto
+type ThreadRepository interface {
+	Create(thread entities.Thread) (*entities.Thread, error)
+	FindLastActiveByContactID(contactID int64) (*entities.Thread, error)
+	FindByID(id int64) (*entities.Thread, error)
+	FindByThreadsId(threadsId string) (*entities.Thread, error)
+	GetAll() ([]entities.Thread, error)
+	Update(id int64, updatedThread entities.Thread) error
+	Delete(id int64) error
+}
@@ -27,7 +27,16 @@ func Setup(app *fiber.App,
 	MessageController *controllers.MessagesController,
 	ContactController *controllers.ContactsController,
 	ContactService *services.ContactsService,
-	EventController *controllers.EventsController) {
+	EventController *controllers.EventsController,
+	NumberPhonesService *services.NumberPhonesService,
+	InboundJobsController *controllers.InboundJobsController,
+	AvailabilityController *controllers.AvailabilityController,
+	ClosuresController *controllers.ClosuresController,
+	HandoffController *controllers.HandoffController,
+	StreamController *controllers.StreamController,
+	TemplatesController *controllers.TemplatesController,
+	CampaignsController *controllers.CampaignsController,
+	UsageController *controllers.UsageController) {
 
 	app.Get("/", middleware.ValidarPermiso("assistants.create"), func(c *fiber.Ctx) error {
 		return c.Send([]byte("Api chatbot whatsapp by OVNICORE  ®️ "))
@@ -43,6 +52,18 @@ func Setup(app *fiber.App,
 	api.Patch("/assistants/:id", middleware.ValidarPermiso("assistants.edit"), AssistantController.UpdateAssistant)
 	api.Get("/assistants/getAssistantsByBussiness/:id", middleware.ValidarPermiso("assistants.show"), AssistantController.GetAllAssistantsByBussinessId)
 	api.Delete("/assistants/:id", middleware.ValidarPermiso("assistants.delete"), AssistantController.DeleteAssistant)
+	api.Get("/assistants/:id/availability", middleware.ValidarPermiso("events.index"), AvailabilityController.GetAvailability) // Horarios libres (?date=YYYY-MM-DD)
+
+	// Feriados y cierres del assistant
+	api.Get("/assistants/:id/closures", middleware.ValidarPermiso("assistants.show"), ClosuresController.GetClosures)
+	api.Post("/assistants/:id/closures", middleware.ValidarPermiso("assistants.edit"), ClosuresController.CreateClosure)
+	api.Post("/assistants/:id/closures/import", middleware.ValidarPermiso("assistants.edit"), ClosuresController.ImportICal) // Archivo .ics en el campo "file"
+	api.Get("/assistants/:id/closures/:closure_id", middleware.ValidarPermiso("assistants.show"), ClosuresController.GetClosure)
+	api.Put("/assistants/:id/closures/:closure_id", middleware.ValidarPermiso("assistants.edit"), ClosuresController.UpdateClosure)
+	api.Delete("/assistants/:id/closures/:closure_id", middleware.ValidarPermiso("assistants.edit"), ClosuresController.DeleteClosure)
+	// Templates de WhatsApp que usa el assistant al crear, modificar, cancelar y recordar eventos
+	api.Get("/assistants/:id/templates", middleware.ValidarPermiso("assistants.show"), TemplatesController.GetAssistantTemplates)
+	api.Put("/assistants/:id/templates", middleware.ValidarPermiso("assistants.edit"), TemplatesController.UpdateAssistantTemplates) // Body: [{event, template_name, language}]
 
 	api.Post("/files/create", middleware.ValidarPermiso("assistants.create"), FileController.CreateFile)
 	api.Get("/files/", middleware.ValidarPermiso("assistants.index"), FileController.GetAllFiles)
@@ -52,6 +73,8 @@ func Setup(app *fiber.App,
 
 	// MESSAGE
 	api.Get("/messages/:number_phone_id", middleware.ValidarPermiso("messages.index"), MessageController.GetMessagesByNumberPhone)
+	api.Get("/messages/:number_phone_id/failed", middleware.ValidarPermiso("messages.index"), MessageController.GetFailedMessagesByNumberPhone)
+	api.Get("/messages/:id/statuses", middleware.ValidarPermiso("messages.index"), MessageController.GetMessageStatuses)
 
 	api.Post("/login", AuthController.Login)
 	api.Post("/restore-password", AuthController.RestorePassword)
@@ -78,8 +101,35 @@ func Setup(app *fiber.App,
 	api.Get("/events/contact/:contactID/date/:date/time/:currentTime", middleware.ValidarPermiso("events.index"), EventController.GetEventsByContactAndDate) // Obtener eventos por contacto y fecha
 
 	// CONTACTS
-	api.Get("/contacts/number_phone/:number_phone_id", middleware.ValidarPermiso("contacts.index"), ContactController.GetMessagesByNumberPhone)
+	api.Get("/contacts/number_phone/:number_phone_id", middleware.ValidarPermiso("contacts.index"), ContactController.GetMessagesByNumberPhone) // ?search=&tag=&opted_out=&blocked=&page=&limit=
+	api.Get("/contacts/number_phone/:number_phone_id/export", middleware.ValidarPermiso("contacts.index"), ContactController.ExportContacts)    // CSV; mismos filtros que el listado
+	api.Post("/contacts/number_phone/:number_phone_id/import", middleware.ValidarPermiso("contacts.block"), ContactController.ImportContacts)   // Archivo .csv o .vcf en "file"
+	api.Get("/contacts/:id", middleware.ValidarPermiso("contacts.index"), ContactController.GetContact)
+	api.Put("/contacts/:id", middleware.ValidarPermiso("contacts.block"), ContactController.UpdateContact) // Body: {name, email, notes, tags, custom_fields, opted_out}; solo los que vienen
 	api.Patch("/contacts/:id/number_phone/:number_phone_id", middleware.ValidarPermiso("contacts.block"), ContactController.UpdateIsBlocked)
+	api.Put("/contacts/:id/handoff", middleware.ValidarPermiso("contacts.block"), HandoffController.UpdateHandoff)        // Quién atiende la conversación: bot, human o paused
+	api.Post("/contacts/:id/messages", middleware.ValidarPermiso("whatsapp.send_message"), HandoffController.SendMessage) // Respuesta de un operador
+	api.Put("/contacts/:id/tags", middleware.ValidarPermiso("contacts.block"), ContactController.UpdateTags)              // Body: {"tags": [...]}
+	api.Put("/contacts/:id/opt-out", middleware.ValidarPermiso("contacts.block"), ContactController.UpdateOptOut)         // Body: {"opted_out": true}
+
+	// CAMPAIGNS (envíos masivos de templates a un segmento de contactos)
+	api.Get("/campaigns", middleware.ValidarPermiso("messages.index"), CampaignsController.GetCampaigns) // ?number_phone_id=
+	api.Post("/campaigns", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.CreateCampaign)
+	api.Get("/campaigns/:id", middleware.ValidarPermiso("messages.index"), CampaignsController.GetCampaign)
+	api.Put("/campaigns/:id", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.UpdateCampaign)
+	api.Delete("/campaigns/:id", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.DeleteCampaign)
+	api.Get("/campaigns/:id/audience", middleware.ValidarPermiso("messages.index"), CampaignsController.GetAudience)
+	api.Get("/campaigns/:id/recipients", middleware.ValidarPermiso("messages.index"), CampaignsController.GetRecipients)          // ?status=&page=&limit=
+	api.Post("/campaigns/:id/schedule", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.ScheduleCampaign) // Body: {"scheduled_at": ...}; vacío = ahora
+	api.Post("/campaigns/:id/pause", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.PauseCampaign)
+	api.Post("/campaigns/:id/resume", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.ResumeCampaign)
+	api.Post("/campaigns/:id/cancel", middleware.ValidarPermiso("whatsapp.send_message"), CampaignsController.CancelCampaign)
+
+	// INBOX
+	api.Get("/inbox/:number_phone_id", middleware.ValidarPermiso("messages.index"), HandoffController.GetInbox) // Conversaciones atendidas por operadores o pausadas
+
+	// STREAM (Server-Sent Events; el token puede ir en ?access_token= porque EventSource no manda headers)
+	api.Get("/stream", middleware.TokenFromQuery(), middleware.ValidarPermiso("messages.index"), StreamController.Stream)
 
 	api.Get("/users", middleware.ValidarPermiso("users.index"), UsersController.GetAll)
 	api.Get("/users/:id", middleware.ValidarPermiso("users.show"), UsersController.GetById)
@@ -94,12 +144,23 @@ func Setup(app *fiber.App,
 	api.Get("/bussiness/:id", middleware.ValidarPermiso("bussiness.show"), BussinessController.GetBussinessById)
 	api.Put("/bussiness/:id", middleware.ValidarPermiso("bussiness.edit"), BussinessController.UpdateBussiness)
 	api.Delete("/bussiness/:id", middleware.ValidarPermiso("bussiness.delete"), BussinessController.DeleteBussiness)
+	api.Get("/bussiness/:id/quota", middleware.ValidarPermiso("bussiness.show"), UsageController.GetQuota)     // Cuota mensual y consumo del mes
+	api.Put("/bussiness/:id/quota", middleware.ValidarPermiso("bussiness.quota"), UsageController.UpdateQuota) // Solo administradores. Body: {"monthly_token_quota", "monthly_cost_quota", "fallback_message"}
+
+	// Consumo de tokens del modelo (?bussiness_id=&assistant_id=&number_phone_id=&contact_id=&from=&to=&group_by=day,assistant)
+	api.Get("/usage", middleware.ValidarPermiso("bussiness.show"), UsageController.GetReport)
 
 	api.Get("/webhook", WhatsappController.GetWhatsapp)
-	api.Post("/webhook", WhatsappController.PostWhatsapp)
+	api.Post("/webhook", middleware.ValidarFirmaWebhook(NumberPhonesService.GetAppSecretByWhatsappNumberPhoneID), WhatsappController.PostWhatsapp)
 	api.Post("/notificar-datos-clientes", middleware.ValidarPermiso("events.index"), WhatsappController.DemoNotifyInteractions)
+	// Envío de mensajes: texto, template, media o interactivo según el campo type (ver dtos.OutboundMessageDto)
+	api.Post("/send-message", middleware.ValidarPermiso("whatsapp.send_message"), WhatsappController.PostSendMessageWhatsapp)
 	api.Post("/send-message-basic", middleware.ValidarPermiso("whatsapp.send_message"), WhatsappController.PostSendMessageWhatsapp)
-	api.Post("/send-message-template", WhatsappController.DemoFunctionWhatsappController)
+	api.Post("/send-message-template", middleware.ValidarPermiso("whatsapp.send_message"), WhatsappController.PostSendMessageWhatsapp)
+
+	// Cola de notificaciones del webhook
+	api.Get("/inbound-jobs", middleware.ValidarPermiso("inbound_jobs.index"), InboundJobsController.GetJobs)
+	api.Post("/inbound-jobs/:id/replay", middleware.ValidarPermiso("inbound_jobs.replay"), InboundJobsController.ReplayJob)
 
 	api.Post("/telegram/send-message", middleware.ValidarPermiso("telegram.send_message"), TelegramService.SendMessageBasic)
 
@@ -136,4 +197,10 @@ func Setup(app *fiber.App,
 	api.Put("/number-phones/:id", middleware.ValidarPermiso("events.index"), NumberPhonesController.Update)
 	api.Delete("/number-phones/:id", middleware.ValidarPermiso("events.index"), NumberPhonesController.Delete)
 
+	// Templates del WhatsApp Business Account del número (se sincronizan con Meta)
+	api.Get("/number-phones/:id/templates", middleware.ValidarPermiso("events.index"), TemplatesController.GetTemplates) // ?refresh=true sincroniza antes de listar
+	api.Post("/number-phones/:id/templates/sync", middleware.ValidarPermiso("events.index"), TemplatesController.SyncTemplates)
+	api.Post("/number-phones/:id/templates", middleware.ValidarPermiso("events.index"), TemplatesController.CreateTemplate)
+	api.Delete("/number-phones/:id/templates/:name", middleware.ValidarPermiso("events.index"), TemplatesController.DeleteTemplate)
+
 }
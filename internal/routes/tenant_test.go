@@ -0,0 +1,358 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/api/middlewares"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/controllers"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+const testJWTSecret = "tenant-test-secret"
+
+// Permisos que tienen los tokens de prueba: todos los de las rutas que se prueban
+var testPermissions = []string{
+	"assistants.index", "assistants.show", "assistants.edit", "assistants.delete", "assistants.create",
+	"events.index", "events.create", "events.edit", "events.delete",
+	"messages.index", "contacts.index", "contacts.block", "whatsapp.send_message",
+	"bussiness.index", "bussiness.show", "bussiness.edit", "bussiness.delete",
+}
+
+// tenantTestApp levanta las rutas reales contra una base SQLite en memoria con dos bussiness:
+// el usuario 1 es del bussiness 1 y el usuario 2 del bussiness 2. Cada bussiness tiene un registro
+// de cada tabla con el mismo ID que el bussiness (y un segundo evento, 10 + ID, para probar la cancelación).
+func tenantTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", testJWTSecret)
+	t.Setenv("ROL_ADMIN", "admin")
+
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	repos := sqlite_client.NewRepositories(db)
+
+	for id := int64(1); id <= 2; id++ {
+		records := []interface{}{
+			&entities.Users{ID: id, Name: fmt.Sprintf("user %d", id)},
+			&entities.Bussines{ID: id, Name: fmt.Sprintf("bussiness %d", id), Address: "calle 123"},
+			&entities.BussinessHasUsers{BussinessID: id, UsersID: id},
+			&entities.Assistant{ID: id, BussinessID: id, Name: fmt.Sprintf("assistant %d", id), OpeningDays: 31, WorkingHours: "09:00-18:00"},
+			&entities.NumberPhone{ID: id, AssistantsID: id, NumberPhone: fmt.Sprintf("+%d", 5491100000000+id), UUID: fmt.Sprintf("uuid-%d", id),
+				TokenPermanent: fmt.Sprintf("token-%d", id), WhatsappNumberPhoneId: 100 + id},
+			&entities.Contact{ID: id, NumberPhonesID: id, NumberPhone: fmt.Sprintf("+%d", 5493510000000+id)},
+			&entities.Message{ID: id, NumberPhonesID: id, ContactsID: id, MessageText: "hola", MessageIdWhatsapp: fmt.Sprintf("wamid.%d", id), MessageType: "text"},
+			&entities.Events{ID: int(id), Summary: "turno", Description: "turno", StartDate: "2030-01-02T10:00:00", EndDate: "2030-01-02T10:30:00",
+				CodeEvent: fmt.Sprintf("CODE%d", id), AssistantsID: id, ContactsID: id},
+			&entities.Events{ID: int(10 + id), Summary: "turno", Description: "turno", StartDate: "2030-01-02T11:00:00", EndDate: "2030-01-02T11:30:00",
+				CodeEvent: fmt.Sprintf("CANCEL%d", id), AssistantsID: id, ContactsID: id},
+			&entities.File{ID: id, AssistantsID: id, Filename: fmt.Sprintf("file-%d.pdf", id), Purpose: "assistants"},
+			&entities.Campaign{ID: id, NumberPhonesID: id, Name: fmt.Sprintf("campaña %d", id), TemplateName: "promo", TemplateLanguage: "es", Status: "draft"},
+		}
+		for _, record := range records {
+			if err := db.Omit("Users", "Bussiness").Create(record).Error; err != nil {
+				t.Fatalf("seeding %T: %v", record, err)
+			}
+		}
+	}
+
+	tenantService := services.NewTenantService(repos.Tenant)
+	middleware := middlewares.MiddlewareManager{TenantResolver: tenantService.ResolveScope}
+
+	fileService := services.NewFileService(repos.Files, nil)
+	assistantService := services.NewAssistantService(repos.Assistants, fileService, nil, services.NewToolRegistry())
+	numberPhonesService := services.NewNumberPhonesService(repos.NumberPhones)
+	contactsRepository := repos.Contacts
+	contactsService := services.NewContactsService(contactsRepository)
+	eventsRepository := repos.Events
+	eventsService := services.NewEventsService(eventsRepository, services.UtilService{})
+	closuresService := services.NewClosuresService(repos.AssistantClosures, assistantService)
+	messagesRepository := repos.Messages
+	messagesService := services.NewMessagesService(messagesRepository, repos.MessageStatuses, nil)
+	handoffService := services.NewHandoffService(contactsRepository, messagesRepository, nil)
+	// Sin URL de la Cloud API: los envíos a WhatsApp fallan
+	whatsappClient := clients.NewWhatsappClient("", "")
+	templatesService := services.NewTemplatesService(repos.MessageTemplates, repos.AssistantTemplates,
+		repos.NumberPhones, assistantService, whatsappClient)
+	usageService := services.NewUsageService(repos.TokenUsages, repos.Bussiness, contactsRepository,
+		services.LLMPrices{"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60}})
+	whatsappService := services.NewWhatsappService(nil, nil, nil, nil, numberPhonesService, messagesRepository, assistantService, nil, nil, nil,
+		eventsService, nil, fileService, messagesService, nil, services.NewToolRegistry(), nil, nil, handoffService, templatesService, usageService, contactsRepository, whatsappClient)
+
+	campaignsService := services.NewCampaignsService(repos.Campaigns, contactsRepository, repos.NumberPhones,
+		repos.MessageTemplates, eventsRepository, whatsappService)
+
+	app := fiber.New()
+	Setup(app, &middleware,
+		nil,
+		controllers.NewFileController(fileService),
+		controllers.NewAssistantController(assistantService),
+		controllers.NewBussinessController(services.NewBussinessService(repos.Bussiness)),
+		nil, nil, nil, nil, nil,
+		controllers.NewWhatsappController(whatsappService, nil),
+		controllers.NewNumberPhonesController(numberPhonesService),
+		nil, nil, nil,
+		controllers.NewMessagesController(messagesService),
+		controllers.NewContactsController(contactsService),
+		contactsService,
+		controllers.NewEventsController(eventsService),
+		numberPhonesService,
+		nil,
+		controllers.NewAvailabilityController(services.NewAvailabilityService(assistantService, closuresService, eventsRepository, nil, nil)),
+		controllers.NewClosuresController(closuresService),
+		controllers.NewHandoffController(handoffService, whatsappService),
+		controllers.NewStreamController(services.NewConversationStream(messagesRepository, repos.NumberPhones)),
+		controllers.NewTemplatesController(templatesService),
+		controllers.NewCampaignsController(campaignsService),
+		controllers.NewUsageController(usageService),
+	)
+	return app, db
+}
+
+// testToken firma un token con testPermissions y los permisos extra indicados
+func testToken(t *testing.T, userID int64, role string, extra ...string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId":      userID,
+		"role":        role,
+		"permissions": append(append([]string{}, testPermissions...), extra...),
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func doRequest(t *testing.T, app *fiber.App, token, method, path, body string) (int, string) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewBufferString(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(respBody)
+}
+
+// tenantRequest es un request que hace el usuario 1 sobre un recurso del bussiness indicado
+type tenantRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+func requestsFor(id int64) []tenantRequest {
+	return []tenantRequest{
+		{http.MethodGet, fmt.Sprintf("/api/assistants/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/assistants/getAssistantsByBussiness/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/assistants/%d/closures", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/assistants/%d/templates", id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/assistants/%d/templates", id), "[]"},
+		{http.MethodGet, fmt.Sprintf("/api/assistants/%d/availability?date=2030-01-02", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/files/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/number-phones/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/number-phones/get-by-assistantID/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/number-phones/%d/templates", id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/number-phones/%d", id), fmt.Sprintf(`{"assistants_id":%d,"number_phone":5491199999999}`, id)},
+		{http.MethodGet, fmt.Sprintf("/api/contacts/number_phone/%d", id), ""},
+		{http.MethodPatch, fmt.Sprintf("/api/contacts/%d/number_phone/%d?block=true", id, id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/contacts/%d/handoff", id), `{"mode":"paused"}`},
+		{http.MethodGet, fmt.Sprintf("/api/contacts/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/contacts/number_phone/%d/export", id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/contacts/%d", id), `{"name":"Juan","email":"juan@mail.com","custom_fields":{"plan":"oro"}}`},
+		{http.MethodPut, fmt.Sprintf("/api/contacts/%d/tags", id), `{"tags":["vip"]}`},
+		{http.MethodPut, fmt.Sprintf("/api/contacts/%d/opt-out", id), `{"opted_out":true}`},
+		{http.MethodGet, fmt.Sprintf("/api/campaigns?number_phone_id=%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/campaigns/%d", id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/campaigns/%d", id), fmt.Sprintf(`{"number_phone_id":%d,"name":"cambio","template":{"name":"promo","language":"es"}}`, id)},
+		{http.MethodGet, fmt.Sprintf("/api/campaigns/%d/audience", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/campaigns/%d/recipients", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/inbox/%d", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/messages/%d?contact_id=%d", id, id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/messages/%d/failed", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/messages/%d/statuses", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/events/%d", id), ""},
+		{http.MethodPut, "/api/events/", fmt.Sprintf(`{"id":%d,"summary":"cambio","description":"cambio","start_date":"2030-01-03T10:00:00","end_date":"2030-01-03T10:30:00","assistants_id":%d,"contacts_id":%d,"code_event":"CODE%d"}`, id, id, id, id)},
+		{http.MethodGet, fmt.Sprintf("/api/bussiness/%d", id), ""},
+		{http.MethodPut, fmt.Sprintf("/api/bussiness/%d", id), `{"name":"cambio","address":"otra calle"}`},
+		{http.MethodGet, fmt.Sprintf("/api/bussiness/%d/quota", id), ""},
+		{http.MethodGet, fmt.Sprintf("/api/usage?bussiness_id=%d", id), ""},
+	}
+}
+
+// deleteRequestsFor son los requests que borran; se prueban aparte porque el recurso deja de existir
+func deleteRequestsFor(id int64) []tenantRequest {
+	return []tenantRequest{
+		{http.MethodDelete, fmt.Sprintf("/api/events/cancel/CANCEL%d", id), ""},
+		{http.MethodDelete, fmt.Sprintf("/api/campaigns/%d", id), ""},
+		{http.MethodDelete, fmt.Sprintf("/api/events/%d", id), ""},
+		{http.MethodDelete, fmt.Sprintf("/api/number-phones/%d", id), ""},
+		{http.MethodDelete, fmt.Sprintf("/api/bussiness/%d", id), ""},
+	}
+}
+
+func TestTenantCrossAccessReturnsNotFound(t *testing.T) {
+	app, db := tenantTestApp(t)
+	token := testToken(t, 1, "user")
+
+	// La respuesta de un operador y el stream se prueban solo contra otro bussiness: al propio se enviaría
+	// por WhatsApp y el stream no termina
+	cross := append(requestsFor(2), deleteRequestsFor(2)...)
+	cross = append(cross, tenantRequest{http.MethodPost, "/api/contacts/2/messages", `{"text":"hola"}`},
+		tenantRequest{http.MethodGet, "/api/stream?number_phone_id=2", ""},
+		tenantRequest{http.MethodPost, "/api/send-message", `{"number_phone_id":2,"contact_id":2,"type":"text","text":"hola"}`},
+		tenantRequest{http.MethodPost, "/api/send-message", `{"number_phone_id":1,"contact_id":2,"type":"text","text":"hola"}`},
+		tenantRequest{http.MethodPost, "/api/send-message", `{"number_phone_id":2,"to":"5491177777777","type":"template","template":{"name":"hola"}}`},
+		tenantRequest{http.MethodPost, "/api/campaigns", `{"number_phone_id":2,"name":"ajena","template":{"name":"promo","language":"es"}}`},
+		tenantRequest{http.MethodPost, "/api/campaigns/2/schedule", ""},
+		tenantRequest{http.MethodPost, "/api/campaigns/2/cancel", ""})
+	for _, req := range cross {
+		status, body := doRequest(t, app, token, req.method, req.path, req.body)
+		if status != fiber.StatusNotFound {
+			t.Errorf("%s %s: status %d, want 404 (body %s)", req.method, req.path, status, body)
+		}
+	}
+
+	// Nada del bussiness 2 se modificó ni se borró
+	var numberPhone entities.NumberPhone
+	if err := db.First(&numberPhone, 2).Error; err != nil || numberPhone.NumberPhone != "+5491100000002" {
+		t.Errorf("number phone 2 was modified: %+v, %v", numberPhone, err)
+	}
+	var contact entities.Contact
+	if err := db.First(&contact, 2).Error; err != nil || contact.IsBlocked || contact.HandoffMode != "bot" || contact.OptedOutAt != nil || contact.Name != "" {
+		t.Errorf("contact 2 was modified: %+v, %v", contact, err)
+	}
+	var event entities.Events
+	if err := db.First(&event, 2).Error; err != nil || event.Summary != "turno" {
+		t.Errorf("event 2 was modified: %+v, %v", event, err)
+	}
+	var bussiness entities.Bussines
+	if err := db.First(&bussiness, 2).Error; err != nil || bussiness.Name != "bussiness 2" {
+		t.Errorf("bussiness 2 was modified: %+v, %v", bussiness, err)
+	}
+}
+
+func TestTenantOwnAccessSucceeds(t *testing.T) {
+	app, _ := tenantTestApp(t)
+	token := testToken(t, 1, "user")
+
+	for _, req := range append(requestsFor(1), deleteRequestsFor(1)...) {
+		status, body := doRequest(t, app, token, req.method, req.path, req.body)
+		if status >= 300 {
+			t.Errorf("%s %s: status %d, want 2xx (body %s)", req.method, req.path, status, body)
+		}
+	}
+}
+
+func TestTenantCreateRejectsForeignReferences(t *testing.T) {
+	app, db := tenantTestApp(t)
+	token := testToken(t, 1, "user")
+
+	cases := []tenantRequest{
+		{http.MethodPost, "/api/events", `{"summary":"turno","description":"turno","start_date":"2030-01-05T10:00:00","end_date":"2030-01-05T10:30:00","assistants_id":2,"contacts_id":2,"code_event":"NEW1"}`},
+		{http.MethodPost, "/api/events", `{"summary":"turno","description":"turno","start_date":"2030-01-05T10:00:00","end_date":"2030-01-05T10:30:00","assistants_id":1,"contacts_id":2,"code_event":"NEW2"}`},
+		{http.MethodPost, "/api/number-phones", `{"assistants_id":2,"number_phone":5491188888888,"token_permanent":"new-token","whatsapp_number_phone_id":999}`},
+		{http.MethodPost, "/api/assistants/add", `{"bussiness_id":2,"name":"ajeno","description":"assistant de otro negocio","openai_assistants_id":"asst_test","model":"gpt-4o","instructions":"atender los turnos del negocio","opening_days":31,"working_hours":"09:00-18:00","event_duration":30}`},
+	}
+	for _, req := range cases {
+		status, body := doRequest(t, app, token, req.method, req.path, req.body)
+		if status != fiber.StatusNotFound {
+			t.Errorf("%s %s: status %d, want 404 (body %s)", req.method, req.path, status, body)
+		}
+	}
+
+	var count int64
+	db.Model(&entities.Events{}).Count(&count)
+	if count != 4 {
+		t.Errorf("events count = %d, want 4", count)
+	}
+	db.Model(&entities.NumberPhone{}).Count(&count)
+	if count != 2 {
+		t.Errorf("number phones count = %d, want 2", count)
+	}
+}
+
+func TestTenantListsOnlyOwnRecords(t *testing.T) {
+	app, _ := tenantTestApp(t)
+	token := testToken(t, 1, "user")
+
+	for _, path := range []string{"/api/assistants/", "/api/number-phones", "/api/files/", "/api/events/?page=1&per_page=10"} {
+		status, body := doRequest(t, app, token, http.MethodGet, path, "")
+		if status >= 300 {
+			t.Errorf("GET %s: status %d (body %s)", path, status, body)
+			continue
+		}
+		if bytes.Contains([]byte(body), []byte(`"id":2`)) || bytes.Contains([]byte(body), []byte(`"ID":2`)) {
+			t.Errorf("GET %s returned a record of another bussiness: %s", path, body)
+		}
+	}
+}
+
+func TestTenantAdminSeesEveryBussiness(t *testing.T) {
+	app, _ := tenantTestApp(t)
+	token := testToken(t, 1, "admin")
+
+	for _, path := range []string{"/api/assistants/2", "/api/number-phones/2", "/api/events/2", "/api/bussiness/2"} {
+		if status, body := doRequest(t, app, token, http.MethodGet, path, ""); status >= 300 {
+			t.Errorf("GET %s as admin: status %d, want 2xx (body %s)", path, status, body)
+		}
+	}
+}
+
+func TestTenantUserWithoutBussinessSeesNothing(t *testing.T) {
+	app, _ := tenantTestApp(t)
+	token := testToken(t, 3, "user")
+
+	for _, path := range []string{"/api/assistants/1", "/api/number-phones/1", "/api/events/1", "/api/bussiness/1"} {
+		if status, body := doRequest(t, app, token, http.MethodGet, path, ""); status != fiber.StatusNotFound {
+			t.Errorf("GET %s: status %d, want 404 (body %s)", path, status, body)
+		}
+	}
+}
+
+// La cuota limita el consumo del bussiness: la cambia un administrador, no los usuarios del bussiness
+func TestTenantQuotaIsAdminOnly(t *testing.T) {
+	app, db := tenantTestApp(t)
+	body := `{"monthly_token_quota":0,"monthly_cost_quota":0}`
+
+	if status, body := doRequest(t, app, testToken(t, 1, "user"), http.MethodPut, "/api/bussiness/1/quota", body); status != fiber.StatusForbidden {
+		t.Errorf("PUT /api/bussiness/1/quota as user: status %d, want 403 (body %s)", status, body)
+	}
+	if status, body := doRequest(t, app, testToken(t, 1, "user"), http.MethodGet, "/api/bussiness/1/quota", ""); status != fiber.StatusOK {
+		t.Errorf("GET /api/bussiness/1/quota as user: status %d, want 200 (body %s)", status, body)
+	}
+	if status, body := doRequest(t, app, testToken(t, 1, "admin", "bussiness.quota"), http.MethodPut, "/api/bussiness/2/quota", `{"monthly_token_quota":1000}`); status != fiber.StatusOK {
+		t.Errorf("PUT /api/bussiness/2/quota as admin: status %d, want 200 (body %s)", status, body)
+	}
+
+	var bussiness entities.Bussines
+	db.First(&bussiness, 2)
+	if bussiness.MonthlyTokenQuota != 1000 {
+		t.Errorf("bussiness 2 quota = %d, want 1000", bussiness.MonthlyTokenQuota)
+	}
+}
@@ -1,89 +1,53 @@
 package services
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type AssistantService struct {
-	repository             *postgres_client.AssistantRepository
+	repository             repositories.AssistantRepository
 	serviceFile            *FileService
 	openAIAssistantService *OpenAIAssistantService
-	client                 *http.Client
+	tools                  *ToolRegistry
 }
 
-func NewAssistantService(repository *postgres_client.AssistantRepository, serviceFile *FileService, openAIAssistantService *OpenAIAssistantService) *AssistantService {
+func NewAssistantService(repository repositories.AssistantRepository, serviceFile *FileService, openAIAssistantService *OpenAIAssistantService, tools *ToolRegistry) *AssistantService {
 	return &AssistantService{
 		repository:             repository,
 		serviceFile:            serviceFile,
 		openAIAssistantService: openAIAssistantService,
-		client:                 &http.Client{},
+		tools:                  tools,
 	}
 }
 
-func (s *AssistantService) UploadFileToGPT(fileContent io.Reader, filename string) (string, error) {
-	// Preparar el cuerpo de la solicitud como multipart
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// Crear la parte de archivo en la solicitud multipart
-	part, err := writer.CreateFormFile("file", ".txt")
-	if err != nil {
-		return "", err
-	}
-	if _, err = io.Copy(part, fileContent); err != nil {
-		return "", err
-	}
-
-	// Añadir el propósito requerido por OpenAI
-	writer.WriteField("purpose", "fine-tune")
-	writer.Close()
-
-	// Crear la solicitud HTTP
-	req, err := http.NewRequest("POST", os.Getenv("OPENAI_API_URL")+"/files", body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY"))
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// Realizar la solicitud
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	// Verificar si la solicitud fue exitosa
-	if resp.StatusCode >= 400 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed: %s", string(bodyBytes))
-	}
-
-	// Decodificar la respuesta para obtener el file_id
-	var result struct {
-		ID string `json:"id"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
-	}
+// WithTenant devuelve una copia del servicio que solo accede a los assistants de los bussiness del scope
+func (s *AssistantService) WithTenant(tenant dtos.TenantScope) *AssistantService {
+	scoped := *s
+	scoped.repository = s.repository.WithTenant(tenant)
+	scoped.serviceFile = s.serviceFile.WithTenant(tenant)
+	return &scoped
+}
 
-	return result.ID, nil
+// UploadFileToGPT sube un archivo de entrenamiento (purpose fine-tune) a OpenAI y devuelve su ID
+func (s *AssistantService) UploadFileToGPT(fileContent io.Reader, filename string) (string, error) {
+	return s.openAIAssistantService.UploadFile(context.Background(), fileContent, ".txt", "fine-tune")
 }
 
 func (m *AssistantService) CreateAssistantWithFile(data dtos.AssistantDto, fileHeader *multipart.FileHeader) (dtos.AssistantDto, error) {
+	if err := m.repository.CheckBussiness(data.BussinessID); err != nil {
+		return dtos.AssistantDto{}, err
+	}
+
 	// Abrir el archivo
 	fileContent, err := fileHeader.Open()
 	if err != nil {
@@ -92,7 +56,7 @@ func (m *AssistantService) CreateAssistantWithFile(data dtos.AssistantDto, fileH
 	defer fileContent.Close()
 
 	// Subir archivo a OpenAI
-	fileIDOpenAI, err := m.openAIAssistantService.UploadFileToGPT(fileContent, fileHeader.Filename)
+	fileIDOpenAI, err := m.openAIAssistantService.UploadFileToGPT(context.Background(), fileContent, fileHeader.Filename)
 	if err != nil {
 		return dtos.AssistantDto{}, err
 	}
@@ -106,24 +70,26 @@ func (m *AssistantService) CreateAssistantWithFile(data dtos.AssistantDto, fileH
 	}
 
 	// Crear vector store en OpenAI
-	vectorStoreID, err := m.openAIAssistantService.CreateVectorStore(fileHeader.Filename)
+	vectorStoreID, err := m.openAIAssistantService.CreateVectorStore(context.Background(), fileHeader.Filename)
 	if err != nil {
 		return dtos.AssistantDto{}, err
 	}
 
 	// Asignar archivo al vector store
-	err = m.openAIAssistantService.addFileToVectorStore(vectorStoreID, fileIDOpenAI)
+	err = m.openAIAssistantService.addFileToVectorStore(context.Background(), vectorStoreID, fileIDOpenAI)
 	if err != nil {
 		return dtos.AssistantDto{}, err
 	}
 
-	// Crear el asistente en OpenAI
-	assistantID, err := m.openAIAssistantService.CreateAssistant(data.Name, data.Instructions, data.Model, vectorStoreID)
-	if err != nil {
-		return dtos.AssistantDto{}, err
-	}
+	// Crear el asistente en OpenAI (solo si usa la Assistants API)
+	if data.UsesOpenAIAssistants() {
+		assistantID, err := m.openAIAssistantService.CreateAssistant(context.Background(), data.Name, data.Instructions, data.Model, vectorStoreID, m.tools.Definitions())
+		if err != nil {
+			return dtos.AssistantDto{}, err
+		}
 
-	data.OpenaiAssistantsID = assistantID
+		data.OpenaiAssistantsID = assistantID
+	}
 	assistantDB := entities.MapDtoToAssistant(data)
 
 	// Guardar el asistente en la base de datos
@@ -144,13 +110,19 @@ func (m *AssistantService) CreateAssistantWithFile(data dtos.AssistantDto, fileH
 }
 
 func (s *AssistantService) CreateAssistant(data dtos.AssistantDto) (dtos.AssistantDto, error) {
-	// Crear el asistente en OpenAI
-	assistantID, err := s.openAIAssistantService.CreateAssistant(data.Name, data.Instructions, data.Model, "")
-	if err != nil {
+	if err := s.repository.CheckBussiness(data.BussinessID); err != nil {
 		return dtos.AssistantDto{}, err
 	}
 
-	data.OpenaiAssistantsID = assistantID
+	// Crear el asistente en OpenAI (los que usan Chat Completions u Ollama no lo necesitan)
+	if data.UsesOpenAIAssistants() {
+		assistantID, err := s.openAIAssistantService.CreateAssistant(context.Background(), data.Name, data.Instructions, data.Model, "", s.tools.Definitions())
+		if err != nil {
+			return dtos.AssistantDto{}, err
+		}
+
+		data.OpenaiAssistantsID = assistantID
+	}
 
 	assistant := entities.MapDtoToAssistant(data)
 	if err := s.repository.Create(&assistant); err != nil {
@@ -172,28 +144,7 @@ func (s *AssistantService) FindNumberPhonesByAssistantID(assistantID uint64) (dt
 }
 
 func (s *AssistantService) DeleteOpenAIAssistant(assistantID string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/assistants/%s", os.Getenv("OPENAI_API_URL"), assistantID), nil)
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY"))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	// Verifica si la solicitud fue exitosa
-	if resp.StatusCode >= 400 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request to delete assistant failed: %s", string(bodyBytes))
-	}
-
-	return nil
+	return s.openAIAssistantService.DeleteAssistant(context.Background(), assistantID)
 }
 
 func (s *AssistantService) FindAllAssistants() ([]dtos.AssistantDto, error) {
@@ -275,8 +226,31 @@ func (s *AssistantService) PartialUpdateAssistant(id int64, data dtos.AssistantD
 	if data.WorkingHours != "" {
 		existingAssistant.WorkingHours = data.WorkingHours
 	}
+	if data.BreakHours != "" {
+		existingAssistant.BreakHours = data.BreakHours
+	}
+	if data.SlotCapacity > 0 {
+		existingAssistant.SlotCapacity = data.SlotCapacity
+	}
+	if data.ReminderOffsets != "" {
+		existingAssistant.ReminderOffsets = data.ReminderOffsets
+	}
+	if data.ReminderTemplate != "" {
+		existingAssistant.ReminderTemplate = data.ReminderTemplate
+	}
+	if data.LLMProvider != "" {
+		existingAssistant.LLMProvider = data.LLMProvider
+	}
+	if data.LLMBaseURL != "" {
+		existingAssistant.LLMBaseURL = data.LLMBaseURL
+	}
+	// Si deja de usar Ollama no se conserva el endpoint propio
+	if existingAssistant.LLMProvider != dtos.LLMProviderOllama {
+		existingAssistant.LLMBaseURL = ""
+	}
 
-	if EditInstructionsOpenAI || EditModelOpenAI || EditNameOpenAI {
+	usesOpenAIAssistants := existingAssistant.LLMProvider == "" || existingAssistant.LLMProvider == dtos.LLMProviderOpenAIAssistants
+	if usesOpenAIAssistants && existingAssistant.OpenaiAssistantsID != "" && (EditInstructionsOpenAI || EditModelOpenAI || EditNameOpenAI) {
 		// Se actualiza solo el campo que vino y si no se le coloca el que ya tenía porque se envia a actualizar a openAI
 		if !EditInstructionsOpenAI {
 			data.Instructions = existingAssistant.Instructions
@@ -289,7 +263,7 @@ func (s *AssistantService) PartialUpdateAssistant(id int64, data dtos.AssistantD
 		}
 		data.OpenaiAssistantsID = existingAssistant.OpenaiAssistantsID
 		// Actualizo los datos del assistant en OPEN AI
-		if _, err := s.openAIAssistantService.EditAssistant(data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model); err != nil {
+		if _, err := s.openAIAssistantService.EditAssistant(context.Background(), data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model, s.tools.Definitions()); err != nil {
 			return dtos.AssistantDto{}, err
 		}
 	}
@@ -303,10 +277,16 @@ func (s *AssistantService) PartialUpdateAssistant(id int64, data dtos.AssistantD
 }
 
 func (s *AssistantService) UpdateAssistant(id int64, data dtos.AssistantDto) (dtos.AssistantDto, error) {
+	// El assistant tiene que existir (y ser del tenant) antes de tocar nada en OpenAI
+	if _, err := s.repository.FindById(id); err != nil {
+		return dtos.AssistantDto{}, errors.New("assistant not found")
+	}
 
 	// Actualizo los datos del assistant en OPEN AI
-	if _, err := s.openAIAssistantService.EditAssistant(data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model); err != nil {
-		return dtos.AssistantDto{}, err
+	if data.UsesOpenAIAssistants() {
+		if _, err := s.openAIAssistantService.EditAssistant(context.Background(), data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model, s.tools.Definitions()); err != nil {
+			return dtos.AssistantDto{}, err
+		}
 	}
 
 	assistant := entities.MapDtoToAssistant(data)
@@ -317,6 +297,10 @@ func (s *AssistantService) UpdateAssistant(id int64, data dtos.AssistantDto) (dt
 }
 
 func (s *AssistantService) UpdateAssistantWithFile(id int64, data dtos.AssistantDto, fileHeader *multipart.FileHeader) (dtos.AssistantDto, error) {
+	if _, err := s.repository.FindById(id); err != nil {
+		return dtos.AssistantDto{}, errors.New("assistant not found")
+	}
+
 	// Busco los files asociados a este assistente, por ahora solo debe tener uno. La relacion es para tener un respaldo de los otros solamente y porque un assistente puede tener muchos archivos en GPT pero no lo usamos así.
 
 	files, err := s.serviceFile.GetFileByAssistantID(id)
@@ -329,7 +313,7 @@ func (s *AssistantService) UpdateAssistantWithFile(id int64, data dtos.Assistant
 	}
 
 	// Desvinculo el archivo con el vector store OpenAI. Con esto se logra que el archivo quede vivo por si se quiere usar en otra ocación
-	err = s.openAIAssistantService.DeleteFileFromVectorStore(files[0].OpenaiVectorStoreIDs, files[0].OpenaiFilesID)
+	err = s.openAIAssistantService.DeleteFileFromVectorStore(context.Background(), files[0].OpenaiVectorStoreIDs, files[0].OpenaiFilesID)
 	if err != nil {
 		return dtos.AssistantDto{}, err
 	}
@@ -342,16 +326,16 @@ func (s *AssistantService) UpdateAssistantWithFile(id int64, data dtos.Assistant
 	defer fileContent.Close()
 
 	// Subir archivo a OpenAI
-	fileIDOpenAI, err := s.openAIAssistantService.UploadFileToGPT(fileContent, fileHeader.Filename)
+	fileIDOpenAI, err := s.openAIAssistantService.UploadFileToGPT(context.Background(), fileContent, fileHeader.Filename)
 	if err != nil {
 		return dtos.AssistantDto{}, err
 	}
 
 	// Asignar archivo al vector store
-	err = s.openAIAssistantService.addFileToVectorStore(files[0].OpenaiVectorStoreIDs, fileIDOpenAI)
+	err = s.openAIAssistantService.addFileToVectorStore(context.Background(), files[0].OpenaiVectorStoreIDs, fileIDOpenAI)
 	if err != nil {
 		// Si falla la desvinculación, elimino el archivo de OpenAI directamente y con estó logramos que se desvincule también el archvo.
-		err = s.openAIAssistantService.DeleteFile(files[0].OpenaiFilesID)
+		err = s.openAIAssistantService.DeleteFile(context.Background(), files[0].OpenaiFilesID)
 		if err != nil {
 			return dtos.AssistantDto{}, err
 		}
@@ -372,9 +356,11 @@ func (s *AssistantService) UpdateAssistantWithFile(id int64, data dtos.Assistant
 	}
 
 	// Actualizo los datos del assistant en OPEN AI
-	_, err = s.openAIAssistantService.EditAssistant(data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model)
-	if err != nil {
-		return dtos.AssistantDto{}, err
+	if data.UsesOpenAIAssistants() {
+		_, err = s.openAIAssistantService.EditAssistant(context.Background(), data.OpenaiAssistantsID, data.Name, data.Instructions, data.Model, s.tools.Definitions())
+		if err != nil {
+			return dtos.AssistantDto{}, err
+		}
 	}
 
 	// Actualizo los otros campos del assistente
@@ -12,7 +12,7 @@ import (
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	repositories "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
@@ -21,11 +21,11 @@ import (
 // AuthService estructura para los métodos de autenticación
 type AuthService struct {
 	userService        *UsersService
-	passwordResetsRepo *repositories.PasswordResetsRepository // Si decides crear uno
+	passwordResetsRepo repositories.PasswordResetsRepository // Si decides crear uno
 }
 
 // NewAuthService inicializa un nuevo AuthService
-func NewAuthService(userService *UsersService, passwordResetsRepo *repositories.PasswordResetsRepository) *AuthService {
+func NewAuthService(userService *UsersService, passwordResetsRepo repositories.PasswordResetsRepository) *AuthService {
 	return &AuthService{
 		userService:        userService,
 		passwordResetsRepo: passwordResetsRepo,
@@ -58,6 +58,36 @@ func (s *AutoProcessService) Start() error {
 		return fmt.Errorf("error scheduling 17:00 process: %v", err)
 	}
 
+	// Recordatorios de eventos: cada 5 minutos se envían los que vencieron según las horas configuradas en cada assistant
+	_, err = c.AddFunc("*/5 * * * *", func() {
+		if err := s.whatsappService.SendDueReminders(); err != nil {
+			log.Printf("Error en SendDueReminders: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error scheduling reminders process: %v", err)
+	}
+
+	// Conversaciones derivadas a un operador que vencieron: vuelven a ser atendidas por el bot
+	_, err = c.AddFunc("* * * * *", func() {
+		if err := s.whatsappService.handoffService.ReleaseExpired(); err != nil {
+			log.Printf("Error en ReleaseExpired: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error scheduling handoff release process: %v", err)
+	}
+
+	// Templates enviados a aprobación: cada 30 minutos se vuelven a sincronizar los números que tienen alguno pendiente
+	_, err = c.AddFunc("*/30 * * * *", func() {
+		if err := s.whatsappService.templatesService.SyncPending(); err != nil {
+			log.Printf("Error en SyncPending: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error scheduling templates sync process: %v", err)
+	}
+
 	// Iniciar el cron
 	c.Start()
 
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"golang.org/x/oauth2"
+)
+
+// Zona horaria en la que se cargan los horarios de trabajo y se guardan las fechas de los eventos
+const availabilityTimezone = "America/Argentina/Buenos_Aires"
+
+// Formatos en los que pueden estar guardados start_date y end_date de los eventos
+var eventDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// Motivos por los que no se puede agendar en un horario
+const (
+	SlotUnavailableClosedDay    = "closed_day"
+	SlotUnavailableOutsideHours = "outside_working_hours"
+	SlotUnavailableFull         = "slot_full"
+	SlotUnavailableGoogleBusy   = "google_calendar_busy"
+	SlotUnavailablePast         = "in_the_past"
+	SlotUnavailableClosure      = "closure" // Feriado o cierre cargado en el assistant
+)
+
+// ErrInvalidAvailabilityDate se devuelve cuando la fecha consultada no tiene el formato YYYY-MM-DD
+var ErrInvalidAvailabilityDate = errors.New("invalid date")
+
+// ErrSlotTaken se devuelve al crear o reprogramar un evento cuando otro ocupó el horario después de verificarlo
+// con CheckSlot
+var ErrSlotTaken = errors.New("el horario ya no está disponible")
+
+// busyInterval es un horario ocupado por un evento del assistant o por su Google Calendar
+type busyInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (b busyInterval) overlaps(start, end time.Time) bool {
+	return b.Start.Before(end) && start.Before(b.End)
+}
+
+// AvailabilityService calcula los horarios libres de un assistant a partir de su horario de trabajo,
+// las pausas, la duración de cada evento, los eventos ya agendados y, si tiene cuenta de Google, su calendario.
+type AvailabilityService struct {
+	assistantService      *AssistantService
+	closuresService       *ClosuresService
+	eventsRepository      repositories.EventsRepository
+	googleCalendarService *GoogleCalendarService
+	oauthConfig           *oauth2.Config
+	location              *time.Location
+	now                   func() time.Time
+}
+
+func NewAvailabilityService(assistantService *AssistantService, closuresService *ClosuresService, eventsRepository repositories.EventsRepository, googleCalendarService *GoogleCalendarService, oauthConfig *oauth2.Config) *AvailabilityService {
+	return &AvailabilityService{
+		assistantService:      assistantService,
+		closuresService:       closuresService,
+		eventsRepository:      eventsRepository,
+		googleCalendarService: googleCalendarService,
+		oauthConfig:           oauthConfig,
+		location:              availabilityLocation(),
+		now:                   time.Now,
+	}
+}
+
+// WithTenant devuelve una copia del servicio que solo calcula la disponibilidad de los assistants del scope
+func (s *AvailabilityService) WithTenant(tenant dtos.TenantScope) *AvailabilityService {
+	scoped := *s
+	scoped.assistantService = s.assistantService.WithTenant(tenant)
+	scoped.closuresService = s.closuresService.WithTenant(tenant)
+	scoped.eventsRepository = s.eventsRepository.WithTenant(tenant)
+	return &scoped
+}
+
+// GetAvailability devuelve los horarios libres del assistant para la fecha indicada ("YYYY-MM-DD")
+func (s *AvailabilityService) GetAvailability(assistantID int64, date string) (dtos.AvailabilityDto, error) {
+	assistant, err := s.assistantService.FindAssistantById(assistantID)
+	if err != nil {
+		return dtos.AvailabilityDto{}, fmt.Errorf("assistant not found: %w", err)
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, s.location)
+	if err != nil {
+		return dtos.AvailabilityDto{}, fmt.Errorf("%w: %q, expected format YYYY-MM-DD", ErrInvalidAvailabilityDate, date)
+	}
+
+	availability := dtos.AvailabilityDto{
+		AssistantID: assistant.ID,
+		Date:        day.Format("2006-01-02"),
+		Duration:    assistant.EventDuration,
+		Slots:       []dtos.SlotDto{},
+		Timezone:    s.location.String(),
+	}
+
+	ranges, err := workingRanges(assistant)
+	if err != nil {
+		return availability, err
+	}
+	if !opensOn(assistant, day) || len(ranges) == 0 {
+		return availability, nil
+	}
+
+	// Feriados y cierres: se descuentan del horario de trabajo como si fueran pausas
+	closed, reason, err := s.closuresService.ClosedRangesOn(assistant.ID, day)
+	if err != nil {
+		return availability, err
+	}
+	if len(closed) > 0 {
+		availability.ClosedReason = reason
+		if reason == "" {
+			availability.ClosedReason = "Cerrado"
+		}
+		ranges = subtractRanges(ranges, closed)
+	}
+	if len(ranges) == 0 {
+		return availability, nil
+	}
+	availability.Open = true
+
+	events, err := s.eventIntervals(assistant.ID, day, 0)
+	if err != nil {
+		return availability, err
+	}
+	googleBusy, checkedGoogle := s.googleBusyIntervals(assistant, day)
+	availability.GoogleBusy = checkedGoogle
+
+	availability.Slots = computeSlots(day, ranges, eventDuration(assistant), slotCapacity(assistant), events, googleBusy, s.now().In(s.location))
+	return availability, nil
+}
+
+// CheckSlot verifica si se puede agendar un evento que empieza en start. excludeEventID permite ignorar el propio
+// evento al reprogramarlo. Si no está disponible devuelve el motivo (ver SlotUnavailable*).
+func (s *AvailabilityService) CheckSlot(assistant dtos.AssistantDto, start time.Time, excludeEventID int) (bool, string, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, s.location)
+	end := start.Add(eventDuration(assistant))
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, s.location)
+
+	if start.Before(s.now()) {
+		return false, SlotUnavailablePast, nil
+	}
+	if !opensOn(assistant, day) {
+		return false, SlotUnavailableClosedDay, nil
+	}
+
+	ranges, err := workingRanges(assistant)
+	if err != nil {
+		return false, "", err
+	}
+	if !fitsInRanges(day, ranges, start, end) {
+		return false, SlotUnavailableOutsideHours, nil
+	}
+
+	closed, _, err := s.closuresService.ClosedRangesOn(assistant.ID, day)
+	if err != nil {
+		return false, "", err
+	}
+	if !fitsInRanges(day, subtractRanges(ranges, closed), start, end) {
+		return false, SlotUnavailableClosure, nil
+	}
+
+	events, err := s.eventIntervals(assistant.ID, day, excludeEventID)
+	if err != nil {
+		return false, "", err
+	}
+	if countOverlaps(events, start, end) >= slotCapacity(assistant) {
+		return false, SlotUnavailableFull, nil
+	}
+
+	googleBusy, _ := s.googleBusyIntervals(assistant, day)
+	if countOverlaps(googleBusy, start, end) > 0 {
+		return false, SlotUnavailableGoogleBusy, nil
+	}
+
+	return true, "", nil
+}
+
+// SlotGuard devuelve la verificación de capacidad que se repite al crear o reprogramar el evento, sobre los eventos
+// del día que el repositorio lee con el assistant bloqueado (ver EventsService.CreateChecked y UpdateChecked).
+// CheckSlot valida antes el resto; esto cubre dos altas o reprogramaciones simultáneas para el mismo horario.
+func (s *AvailabilityService) SlotGuard(assistant dtos.AssistantDto, start time.Time, excludeEventID int) func(sameDay []entities.Events) error {
+	start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, s.location)
+	end := start.Add(eventDuration(assistant))
+	return func(sameDay []entities.Events) error {
+		if countOverlaps(s.busyIntervals(sameDay, excludeEventID), start, end) >= slotCapacity(assistant) {
+			return ErrSlotTaken
+		}
+		return nil
+	}
+}
+
+// RegisterTools registra la tool para que el assistant consulte los horarios libres
+func (s *AvailabilityService) RegisterTools(registry *ToolRegistry) {
+	registry.Register(AssistantTool{
+		Name:        "getAvailableSlots",
+		Description: "Devuelve los horarios libres para agendar un evento en una fecha. Usala antes de proponer o crear un turno.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"date": {"type": "string", "description": "Fecha a consultar en formato YYYY-MM-DD"}
+			},
+			"required": ["date"]
+		}`),
+		Handler: func(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+			var args struct {
+				Date string `json:"date"`
+			}
+			if err := json.Unmarshal(arguments, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %v", err)
+			}
+			return s.GetAvailability(ctx.Assistant.ID, args.Date)
+		},
+		ReadOnly: true,
+	})
+}
+
+// eventIntervals devuelve los horarios ocupados por eventos del assistant en el día
+func (s *AvailabilityService) eventIntervals(assistantID int64, day time.Time, excludeEventID int) ([]busyInterval, error) {
+	events, err := s.eventsRepository.FindByAssistantAndDate(assistantID, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return s.busyIntervals(events, excludeEventID), nil
+}
+
+// busyIntervals devuelve los horarios que ocupan los eventos, sin contar excludeEventID
+func (s *AvailabilityService) busyIntervals(events []entities.Events, excludeEventID int) []busyInterval {
+	var intervals []busyInterval
+	for _, event := range events {
+		if excludeEventID != 0 && event.ID == excludeEventID {
+			continue
+		}
+		interval, ok := s.parseEventInterval(event)
+		if !ok {
+			log.Printf("Evento %s con fechas inválidas (%s - %s), no se tiene en cuenta para la disponibilidad", event.CodeEvent, event.StartDate, event.EndDate)
+			continue
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals
+}
+
+func (s *AvailabilityService) parseEventInterval(event entities.Events) (busyInterval, bool) {
+	start, okStart := s.parseEventDate(event.StartDate)
+	end, okEnd := s.parseEventDate(event.EndDate)
+	if !okStart || !okEnd || !end.After(start) {
+		return busyInterval{}, false
+	}
+	return busyInterval{Start: start, End: end}, true
+}
+
+func (s *AvailabilityService) parseEventDate(value string) (time.Time, bool) {
+	for _, layout := range eventDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, s.location); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// googleBusyIntervals obtiene los eventos del Google Calendar del assistant para el día. Los que se crearon
+// desde el bot ya se cuentan como eventos propios. Si Google falla se sigue sin ellos (el segundo valor es false).
+func (s *AvailabilityService) googleBusyIntervals(assistant dtos.AssistantDto, day time.Time) ([]busyInterval, bool) {
+	if !assistant.AccountGoogle || s.googleCalendarService == nil {
+		return nil, false
+	}
+
+	ctx := context.Background()
+	token, err := s.googleCalendarService.GetOrRefreshToken(int(assistant.ID), s.oauthConfig, ctx)
+	if err != nil {
+		log.Printf("No se pudo obtener el token de Google del assistant %d: %v", assistant.ID, err)
+		return nil, false
+	}
+
+	googleEvents, err := s.googleCalendarService.FetchGoogleCalendarEventsByDate(token, ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("No se pudieron obtener los eventos de Google Calendar del assistant %d: %v", assistant.ID, err)
+		return nil, false
+	}
+
+	ownEvents, err := s.eventsRepository.FindByAssistantAndDate(assistant.ID, day.Format("2006-01-02"))
+	if err != nil {
+		log.Printf("Error obteniendo los eventos del assistant %d: %v", assistant.ID, err)
+		return nil, false
+	}
+	ownGoogleIDs := make(map[string]bool)
+	for _, event := range ownEvents {
+		ownGoogleIDs[event.EventGoogleCalendarID] = true
+	}
+
+	var intervals []busyInterval
+	for _, item := range googleEvents.Items {
+		// Los eventos marcados como "disponible" en Google no ocupan el horario
+		if ownGoogleIDs[item.Id] || item.Transparency == "transparent" || item.Start == nil || item.End == nil {
+			continue
+		}
+
+		// Eventos de día completo
+		if item.Start.DateTime == "" {
+			intervals = append(intervals, busyInterval{Start: day, End: day.AddDate(0, 0, 1)})
+			continue
+		}
+
+		start, errStart := time.Parse(time.RFC3339, item.Start.DateTime)
+		end, errEnd := time.Parse(time.RFC3339, item.End.DateTime)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		intervals = append(intervals, busyInterval{Start: start.In(s.location), End: end.In(s.location)})
+	}
+	return intervals, true
+}
+
+// availabilityLocation carga la zona horaria de los turnos. Si el sistema no tiene la base de zonas se usa UTC-3.
+func availabilityLocation() *time.Location {
+	location, err := time.LoadLocation(availabilityTimezone)
+	if err != nil {
+		log.Printf("Error cargando la zona horaria %s, se usa UTC-3: %v", availabilityTimezone, err)
+		return time.FixedZone("UTC-3", -3*60*60)
+	}
+	return location
+}
+
+// workingRanges devuelve los rangos de trabajo del assistant sin las pausas
+func workingRanges(assistant dtos.AssistantDto) ([]dtos.TimeRange, error) {
+	ranges, err := dtos.ParseTimeRanges(assistant.WorkingHours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid working hours of assistant %d: %v", assistant.ID, err)
+	}
+	breaks, err := dtos.ParseTimeRanges(assistant.BreakHours)
+	if err != nil {
+		return nil, fmt.Errorf("invalid break hours of assistant %d: %v", assistant.ID, err)
+	}
+	return subtractRanges(ranges, breaks), nil
+}
+
+// subtractRanges quita las pausas de los rangos de trabajo, partiendo un rango en dos si la pausa cae en el medio
+func subtractRanges(ranges, breaks []dtos.TimeRange) []dtos.TimeRange {
+	result := ranges
+	for _, pause := range breaks {
+		var next []dtos.TimeRange
+		for _, r := range result {
+			if pause.End <= r.Start || pause.Start >= r.End {
+				next = append(next, r)
+				continue
+			}
+			if pause.Start > r.Start {
+				next = append(next, dtos.TimeRange{Start: r.Start, End: pause.Start})
+			}
+			if pause.End < r.End {
+				next = append(next, dtos.TimeRange{Start: pause.End, End: r.End})
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// computeSlots arma los horarios de duración fija dentro de cada rango y se queda con los que tienen lugar
+func computeSlots(day time.Time, ranges []dtos.TimeRange, duration time.Duration, capacity int, events, googleBusy []busyInterval, now time.Time) []dtos.SlotDto {
+	slots := []dtos.SlotDto{}
+	for _, r := range ranges {
+		for offset := r.Start; offset+duration <= r.End; offset += duration {
+			start := day.Add(offset)
+			end := start.Add(duration)
+			if start.Before(now) || countOverlaps(googleBusy, start, end) > 0 {
+				continue
+			}
+
+			available := capacity - countOverlaps(events, start, end)
+			if available <= 0 {
+				continue
+			}
+			slots = append(slots, dtos.SlotDto{
+				Start:     start.Format("2006-01-02T15:04:05"),
+				End:       end.Format("2006-01-02T15:04:05"),
+				Available: available,
+			})
+		}
+	}
+	return slots
+}
+
+func fitsInRanges(day time.Time, ranges []dtos.TimeRange, start, end time.Time) bool {
+	for _, r := range ranges {
+		if !start.Before(day.Add(r.Start)) && !end.After(day.Add(r.End)) {
+			return true
+		}
+	}
+	return false
+}
+
+func countOverlaps(intervals []busyInterval, start, end time.Time) int {
+	count := 0
+	for _, interval := range intervals {
+		if interval.overlaps(start, end) {
+			count++
+		}
+	}
+	return count
+}
+
+func opensOn(assistant dtos.AssistantDto, day time.Time) bool {
+	// Bit por día de la semana (0 = Domingo, 1 = Lunes, ..., 6 = Sábado)
+	return assistant.OpeningDays&(1<<uint(day.Weekday())) != 0
+}
+
+func eventDuration(assistant dtos.AssistantDto) time.Duration {
+	if assistant.EventDuration <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(assistant.EventDuration) * time.Minute
+}
+
+func slotCapacity(assistant dtos.AssistantDto) int {
+	if assistant.SlotCapacity <= 0 {
+		return 1
+	}
+	return int(assistant.SlotCapacity)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+)
+
+// Zona fija de UTC-3 para que los tests no dependan de la base de zonas horarias del sistema
+var testLocation = time.FixedZone("UTC-3", -3*60*60)
+
+// hours arma un TimeRange a partir de "HH:MM-HH:MM"
+func hours(t *testing.T, value string) []dtos.TimeRange {
+	t.Helper()
+	ranges, err := dtos.ParseTimeRanges(value)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", value, err)
+	}
+	return ranges
+}
+
+func TestSubtractRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges string
+		breaks string
+		want   string
+	}{
+		{"no breaks", "09:00-18:00", "", "09:00-18:00"},
+		{"break in the middle", "09:00-18:00", "13:00-14:00", "09:00-13:00,14:00-18:00"},
+		{"break at the start", "09:00-18:00", "08:00-10:00", "10:00-18:00"},
+		{"break at the end", "09:00-18:00", "17:00-19:00", "09:00-17:00"},
+		{"break covering the range", "09:00-13:00,14:00-18:00", "08:00-13:30", "14:00-18:00"},
+		{"break outside", "09:00-13:00", "13:00-14:00", "09:00-13:00"},
+		{"break across two ranges", "09:00-13:00,14:00-18:00", "12:00-15:00", "09:00-12:00,15:00-18:00"},
+		{"overlapping breaks", "09:00-18:00", "12:00-13:00,12:30-14:00", "09:00-12:00,14:00-18:00"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := subtractRanges(hours(t, test.ranges), hours(t, test.breaks))
+			if want := hours(t, test.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("subtractRanges = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestComputeSlots(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, testLocation)
+	at := func(clock string) time.Time {
+		parsed, _ := time.ParseInLocation("2006-01-02 15:04", "2026-03-02 "+clock, testLocation)
+		return parsed
+	}
+	busy := func(from, to string) busyInterval { return busyInterval{Start: at(from), End: at(to)} }
+	dayBefore := day.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		ranges     string
+		duration   time.Duration
+		capacity   int
+		events     []busyInterval
+		googleBusy []busyInterval
+		now        time.Time
+		want       []string // "HH:MM available"
+	}{
+		{
+			name: "free day", ranges: "09:00-11:00", duration: 30 * time.Minute, capacity: 1, now: dayBefore,
+			want: []string{"09:00 1", "09:30 1", "10:00 1", "10:30 1"},
+		},
+		{
+			name: "event overlapping two slots", ranges: "09:00-11:00", duration: 30 * time.Minute, capacity: 1, now: dayBefore,
+			events: []busyInterval{busy("09:15", "09:45")},
+			want:   []string{"10:00 1", "10:30 1"},
+		},
+		{
+			name: "back to back events do not overlap", ranges: "09:00-10:00", duration: 30 * time.Minute, capacity: 1, now: dayBefore,
+			events: []busyInterval{busy("08:30", "09:00"), busy("10:00", "10:30")},
+			want:   []string{"09:00 1", "09:30 1"},
+		},
+		{
+			name: "capacity greater than 1", ranges: "09:00-10:30", duration: 30 * time.Minute, capacity: 2, now: dayBefore,
+			events: []busyInterval{busy("09:00", "09:30"), busy("09:00", "09:30"), busy("09:30", "10:00")},
+			want:   []string{"09:30 1", "10:00 2"},
+		},
+		{
+			name: "google busy blocks with free capacity", ranges: "09:00-10:00", duration: 30 * time.Minute, capacity: 3, now: dayBefore,
+			googleBusy: []busyInterval{busy("09:10", "09:20")},
+			want:       []string{"09:30 3"},
+		},
+		{
+			name: "slots that do not fit at the end of a range", ranges: "09:00-10:00,11:00-12:00", duration: 45 * time.Minute, capacity: 1, now: dayBefore,
+			want: []string{"09:00 1", "11:00 1"},
+		},
+		{
+			// 12:40 UTC son las 09:40 en UTC-3: el turno de las 09:30 ya empezó
+			name: "now in another timezone", ranges: "09:00-11:00", duration: 30 * time.Minute, capacity: 1,
+			now:  time.Date(2026, 3, 2, 12, 40, 0, 0, time.UTC),
+			want: []string{"10:00 1", "10:30 1"},
+		},
+		{
+			// Un evento guardado en UTC ocupa el horario local que le corresponde
+			name: "event stored in UTC", ranges: "09:00-10:00", duration: 30 * time.Minute, capacity: 1, now: dayBefore,
+			events: []busyInterval{{Start: time.Date(2026, 3, 2, 12, 30, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC)}},
+			want:   []string{"09:00 1"},
+		},
+		{
+			name: "last slot ends at midnight", ranges: "23:00-24:00", duration: 30 * time.Minute, capacity: 1, now: dayBefore,
+			events: []busyInterval{busy("23:00", "23:30")},
+			want:   []string{"23:30 1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slots := computeSlots(day, hours(t, test.ranges), test.duration, test.capacity, test.events, test.googleBusy, test.now)
+			got := []string{}
+			for _, slot := range slots {
+				got = append(got, fmt.Sprintf("%s %d", slot.Start[11:16], slot.Available))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("slots = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	slots := computeSlots(day, hours(t, "23:30-24:00"), 30*time.Minute, 1, nil, nil, dayBefore)
+	if len(slots) != 1 || slots[0].End != "2026-03-03T00:00:00" {
+		t.Errorf("slots = %+v, want the last slot to end at 00:00 of the next day", slots)
+	}
+}
+
+func TestCheckSlot(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+
+	// Lunes 2 de marzo de 2026, de 09 a 13 y de 14 a 18, turnos de 30 minutos para dos personas a la vez
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, testLocation)
+	assistant := dtos.AssistantDto{ID: 1, EventDuration: 30, SlotCapacity: 2, OpeningDays: 0b0111110, WorkingHours: "09:00-13:00,14:00-18:00"}
+	records := []interface{}{
+		&entities.Bussines{ID: 1, Name: "Consultorio", Address: "calle 123"},
+		&entities.Assistant{ID: 1, BussinessID: 1, Name: "Consultorio", EventType: "turno", Active: true},
+		&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101},
+		&entities.Contact{ID: 1, NumberPhonesID: 1, NumberPhone: "+5493510000001"},
+		// Cierra de 16 a 17 ese día y el miércoles todo el día
+		&entities.AssistantClosure{AssistantsID: 1, StartDate: monday, EndDate: monday, StartTime: "16:00", EndTime: "17:00", Reason: "reunión"},
+		&entities.AssistantClosure{AssistantsID: 1, StartDate: monday.AddDate(0, 0, 2), EndDate: monday.AddDate(0, 0, 2), Reason: "feriado"},
+	}
+	for i, start := range []string{"10:00", "10:00", "11:00"} {
+		records = append(records, &entities.Events{ID: i + 1, Summary: "turno", Description: "turno", StartDate: "2026-03-02T" + start + ":00", EndDate: "2026-03-02T" + start[:3] + "30:00",
+			CodeEvent: fmt.Sprintf("EV%d", i+1), AssistantsID: 1, ContactsID: 1})
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+
+	service := &AvailabilityService{
+		closuresService:  NewClosuresService(repos.AssistantClosures, nil),
+		eventsRepository: repos.Events,
+		location:         testLocation,
+		now:              func() time.Time { return monday.Add(9*time.Hour + 15*time.Minute) },
+	}
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		exclude int
+		want    string // motivo, "" si está disponible
+	}{
+		{"free slot", monday.Add(9*time.Hour + 30*time.Minute), 0, ""},
+		{"in the past", monday.Add(9 * time.Hour), 0, SlotUnavailablePast},
+		{"sunday", monday.AddDate(0, 0, 6).Add(10 * time.Hour), 0, SlotUnavailableClosedDay},
+		{"before opening", monday.AddDate(0, 0, 1).Add(8*time.Hour + 45*time.Minute), 0, SlotUnavailableOutsideHours},
+		{"straddles the break", monday.Add(12*time.Hour + 45*time.Minute), 0, SlotUnavailableOutsideHours},
+		{"inside the closure", monday.Add(16 * time.Hour), 0, SlotUnavailableClosure},
+		{"straddles the closure", monday.Add(15*time.Hour + 45*time.Minute), 0, SlotUnavailableClosure},
+		{"right after the closure", monday.Add(17 * time.Hour), 0, ""},
+		{"closed all day", monday.AddDate(0, 0, 2).Add(10 * time.Hour), 0, SlotUnavailableClosure},
+		{"day after the closure", monday.AddDate(0, 0, 3).Add(10 * time.Hour), 0, ""},
+		{"full with capacity 2", monday.Add(10 * time.Hour), 0, SlotUnavailableFull},
+		{"one of two places taken", monday.Add(11 * time.Hour), 0, ""},
+		{"overlaps the full slot", monday.Add(9*time.Hour + 45*time.Minute), 0, SlotUnavailableFull},
+		{"rescheduling an event of the full slot", monday.Add(10 * time.Hour), 1, ""},
+		// El horario se interpreta en la zona del assistant aunque venga en otra
+		{"wall clock of another timezone", time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), 0, SlotUnavailableFull},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, reason, err := service.CheckSlot(assistant, test.start, test.exclude)
+			if err != nil {
+				t.Fatalf("CheckSlot: %v", err)
+			}
+			if ok != (test.want == "") || reason != test.want {
+				t.Errorf("CheckSlot = %v, %q; want %q", ok, reason, test.want)
+			}
+		})
+	}
+}
@@ -5,17 +5,23 @@ import (
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type BussinessService struct {
-	repository *postgres_client.BussinessRepository
+	repository repositories.BussinessRepository
+	tenant     dtos.TenantScope
 }
 
-func NewBussinessService(repository *postgres_client.BussinessRepository) *BussinessService {
+func NewBussinessService(repository repositories.BussinessRepository) *BussinessService {
 	return &BussinessService{repository: repository}
 }
 
+// WithTenant devuelve una copia del servicio que solo accede a los negocios del scope
+func (s *BussinessService) WithTenant(tenant dtos.TenantScope) *BussinessService {
+	return &BussinessService{repository: s.repository.WithTenant(tenant), tenant: tenant}
+}
+
 // Crear un nuevo negocio
 func (s *BussinessService) CreateBussiness(data dtos.BussinessDto) (dtos.BussinessDto, error) {
 	// Convertir el DTO a entidad
@@ -27,6 +33,13 @@ func (s *BussinessService) CreateBussiness(data dtos.BussinessDto) (dtos.Bussine
 		return dtos.BussinessDto{}, err
 	}
 
+	// Si lo crea un usuario que no es administrador queda asociado a él, si no no podría volver a verlo
+	if s.tenant.Restricted {
+		if err := s.repository.AddUserToBussiness(int64(idBussiness), s.tenant.UserID); err != nil {
+			return dtos.BussinessDto{}, err
+		}
+	}
+
 	bussinessDTO := entities.MapEntitiesToBussinessDto(bussiness)
 	bussinessDTO.ID = int64(idBussiness)
 	// Devolver el DTO del negocio creado
@@ -0,0 +1,662 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCampaignWorkers           = 2
+	defaultCampaignMessagesPerSecond = 20
+	campaignPollInterval             = 15 * time.Second
+	campaignBatchSize                = 100
+	campaignStatusCheckEvery         = 10
+	// Una campaña en running que no da señales hace más de este tiempo se considera abandonada (ej: reinicio del proceso)
+	campaignStaleAfter = 5 * time.Minute
+	// Cuando se agota el tier de Meta la campaña se reprograma para volver a intentar después de este tiempo
+	campaignTierRetryAfter       = time.Hour
+	campaignRateLimitBaseBackoff = 15 * time.Second
+	campaignRateLimitMaxBackoff  = 2 * time.Minute
+	// Mientras espera para reintentar, el worker mira cada este tiempo si pausaron o cancelaron la campaña
+	campaignStatusPollInterval = 5 * time.Second
+	// Intentos de envío a un destinatario mientras Meta limita la velocidad. Si se agotan la campaña se reprograma
+	// para después de campaignRateLimitRetryAfter y el destinatario queda pendiente.
+	campaignRateLimitMaxAttempts = 5
+	campaignRateLimitRetryAfter  = 15 * time.Minute
+	// Ventana en la que Meta cuenta los contactos distintos para el tier
+	messagingLimitWindow = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidCampaign es el error de una campaña que no se puede guardar o programar con esos datos
+	ErrInvalidCampaign = errors.New("campaña inválida")
+	// ErrCampaignState es el error de una acción que no se puede hacer en el estado actual de la campaña
+	ErrCampaignState = errors.New("la campaña no se puede modificar en su estado actual")
+
+	// errCampaignStopped: la campaña se pausó o canceló mientras se esperaba para reintentar un envío
+	errCampaignStopped = errors.New("campaign stopped")
+	// errCampaignRateLimited: Meta siguió limitando la velocidad después de campaignRateLimitMaxAttempts intentos
+	errCampaignRateLimited = errors.New("campaign rate limited by meta")
+)
+
+// CampaignsService arma y envía campañas de templates a segmentos de contactos. El envío lo hace un pool de
+// workers que respeta los mensajes por segundo (CAMPAIGN_MESSAGES_PER_SECOND), el tier de Meta de cada número
+// y las bajas de los contactos.
+type CampaignsService struct {
+	repository             repositories.CampaignsRepository
+	contactsRepository     repositories.ContactsRepository
+	numberPhonesRepository repositories.NumberPhonesRepository
+	templatesRepository    repositories.MessageTemplatesRepository
+	eventsRepository       repositories.EventsRepository
+	whatsappService        *WhatsappService
+	workers                int
+	messagesPerSecond      int
+	rateLimitBackoff       time.Duration // Primera espera cuando Meta limita la velocidad, se duplica en cada intento
+	wake                   chan struct{}
+}
+
+// NewCampaignsService inicializa el servicio. La concurrencia y la velocidad se configuran con CAMPAIGN_WORKERS
+// y CAMPAIGN_MESSAGES_PER_SECOND.
+func NewCampaignsService(repository repositories.CampaignsRepository, contactsRepository repositories.ContactsRepository, numberPhonesRepository repositories.NumberPhonesRepository, templatesRepository repositories.MessageTemplatesRepository, eventsRepository repositories.EventsRepository, whatsappService *WhatsappService) *CampaignsService {
+	return &CampaignsService{
+		repository:             repository,
+		contactsRepository:     contactsRepository,
+		numberPhonesRepository: numberPhonesRepository,
+		templatesRepository:    templatesRepository,
+		eventsRepository:       eventsRepository,
+		whatsappService:        whatsappService,
+		workers:                envPositiveInt("CAMPAIGN_WORKERS", defaultCampaignWorkers),
+		messagesPerSecond:      envPositiveInt("CAMPAIGN_MESSAGES_PER_SECOND", defaultCampaignMessagesPerSecond),
+		rateLimitBackoff:       campaignRateLimitBaseBackoff,
+		wake:                   make(chan struct{}, 1),
+	}
+}
+
+// WithTenant devuelve una copia del servicio que solo accede a las campañas y contactos de los números del scope
+func (s *CampaignsService) WithTenant(tenant dtos.TenantScope) *CampaignsService {
+	scoped := *s
+	scoped.contactsRepository = s.contactsRepository.WithTenant(tenant)
+	scoped.numberPhonesRepository = s.numberPhonesRepository.WithTenant(tenant)
+	return &scoped
+}
+
+// GetByNumberPhone lista las campañas del número
+func (s *CampaignsService) GetByNumberPhone(numberPhoneID int64) ([]dtos.CampaignDto, error) {
+	if _, err := s.findNumberPhone(numberPhoneID); err != nil {
+		return nil, err
+	}
+	records, err := s.repository.FindByNumberPhone(numberPhoneID)
+	if err != nil {
+		return nil, err
+	}
+	campaigns := make([]dtos.CampaignDto, len(records))
+	for i, record := range records {
+		campaigns[i] = entities.MapEntityToCampaignDto(record)
+	}
+	return campaigns, nil
+}
+
+// GetByID devuelve la campaña con el resumen del envío
+func (s *CampaignsService) GetByID(id int64) (dtos.CampaignDto, error) {
+	record, err := s.findCampaign(id)
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	campaign := entities.MapEntityToCampaignDto(record)
+	stats, err := s.repository.Stats(id)
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	campaign.Stats = &stats
+	return campaign, nil
+}
+
+// Create guarda la campaña como borrador
+func (s *CampaignsService) Create(request dtos.CampaignRequestDto, usersID int64) (dtos.CampaignDto, error) {
+	if err := request.Validate(); err != nil {
+		return dtos.CampaignDto{}, fmt.Errorf("%w: %v", ErrInvalidCampaign, err)
+	}
+	if _, err := s.findNumberPhone(request.NumberPhoneID); err != nil {
+		return dtos.CampaignDto{}, err
+	}
+
+	record := entities.MapCampaignRequestToEntity(request)
+	if usersID > 0 {
+		record.UsersID = &usersID
+	}
+	if err := s.repository.Create(&record); err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	return entities.MapEntityToCampaignDto(record), nil
+}
+
+// Update reemplaza los datos de una campaña que todavía es borrador
+func (s *CampaignsService) Update(id int64, request dtos.CampaignRequestDto) (dtos.CampaignDto, error) {
+	record, err := s.findCampaign(id)
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	if record.Status != dtos.CampaignStatusDraft {
+		return dtos.CampaignDto{}, ErrCampaignState
+	}
+	if err := request.Validate(); err != nil {
+		return dtos.CampaignDto{}, fmt.Errorf("%w: %v", ErrInvalidCampaign, err)
+	}
+	if _, err := s.findNumberPhone(request.NumberPhoneID); err != nil {
+		return dtos.CampaignDto{}, err
+	}
+
+	updated := entities.MapCampaignRequestToEntity(request)
+	updated.ID = record.ID
+	updated.UsersID = record.UsersID
+	updated.CreatedAt = record.CreatedAt
+	if err := s.repository.Update(&updated); err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	return entities.MapEntityToCampaignDto(updated), nil
+}
+
+// Delete borra una campaña que no se está enviando
+func (s *CampaignsService) Delete(id int64) error {
+	record, err := s.findCampaign(id)
+	if err != nil {
+		return err
+	}
+	if record.Status == dtos.CampaignStatusRunning {
+		return ErrCampaignState
+	}
+	return s.repository.Delete(id)
+}
+
+// Audience devuelve cuántos contactos recibirían hoy la campaña según su segmento
+func (s *CampaignsService) Audience(id int64) (int, error) {
+	record, err := s.findCampaign(id)
+	if err != nil {
+		return 0, err
+	}
+	segment := entities.MapEntityToCampaignDto(record).Segment
+	ids, err := s.contactsRepository.FindIDsBySegment(record.NumberPhonesID, segment, time.Now())
+	return len(ids), err
+}
+
+// Schedule programa un borrador para que se envíe en scheduledAt (nil = ahora). El template tiene que estar
+// aprobado en el número.
+func (s *CampaignsService) Schedule(id int64, scheduledAt *time.Time) (dtos.CampaignDto, error) {
+	record, err := s.findCampaign(id)
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	if record.Status != dtos.CampaignStatusDraft {
+		return dtos.CampaignDto{}, ErrCampaignState
+	}
+
+	template, err := s.templatesRepository.FindByName(record.NumberPhonesID, record.TemplateName, record.TemplateLanguage)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.CampaignDto{}, fmt.Errorf("%w: el template %s (%s) no está sincronizado en el número", ErrInvalidCampaign, record.TemplateName, record.TemplateLanguage)
+	}
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	if template.Status != dtos.TemplateStatusApproved {
+		return dtos.CampaignDto{}, fmt.Errorf("%w: el template %s está en estado %s", ErrInvalidCampaign, template.Name, template.Status)
+	}
+
+	when := time.Now()
+	if scheduledAt != nil && scheduledAt.After(when) {
+		when = *scheduledAt
+	}
+	changed, err := s.repository.UpdateStatus(id, []string{dtos.CampaignStatusDraft}, map[string]interface{}{
+		"status":       dtos.CampaignStatusScheduled,
+		"scheduled_at": when,
+	})
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	if !changed {
+		return dtos.CampaignDto{}, ErrCampaignState
+	}
+	s.notify()
+	return s.GetByID(id)
+}
+
+// Pause frena el envío; los destinatarios pendientes se envían al reanudarla
+func (s *CampaignsService) Pause(id int64) (dtos.CampaignDto, error) {
+	return s.changeStatus(id, []string{dtos.CampaignStatusScheduled, dtos.CampaignStatusRunning}, map[string]interface{}{
+		"status": dtos.CampaignStatusPaused,
+	})
+}
+
+// Resume vuelve a programar una campaña pausada para que siga enviándose
+func (s *CampaignsService) Resume(id int64) (dtos.CampaignDto, error) {
+	campaign, err := s.changeStatus(id, []string{dtos.CampaignStatusPaused}, map[string]interface{}{
+		"status":       dtos.CampaignStatusScheduled,
+		"scheduled_at": time.Now(),
+		"last_error":   "",
+	})
+	if err == nil {
+		s.notify()
+	}
+	return campaign, err
+}
+
+// Cancel termina la campaña; a los destinatarios pendientes ya no se les envía
+func (s *CampaignsService) Cancel(id int64) (dtos.CampaignDto, error) {
+	campaign, err := s.changeStatus(id, []string{dtos.CampaignStatusDraft, dtos.CampaignStatusScheduled, dtos.CampaignStatusRunning, dtos.CampaignStatusPaused}, map[string]interface{}{
+		"status":      dtos.CampaignStatusCancelled,
+		"finished_at": time.Now(),
+	})
+	if err != nil {
+		return campaign, err
+	}
+	if err := s.repository.SkipPendingRecipients(id, "campaña cancelada"); err != nil {
+		return campaign, err
+	}
+	return s.GetByID(id)
+}
+
+// GetRecipients lista el resultado del envío a cada destinatario
+func (s *CampaignsService) GetRecipients(id int64, status string, page, limit int) ([]dtos.CampaignRecipientDto, int, error) {
+	if _, err := s.findCampaign(id); err != nil {
+		return nil, 0, err
+	}
+	records, total, err := s.repository.FindRecipients(id, status, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	recipients := make([]dtos.CampaignRecipientDto, len(records))
+	for i, record := range records {
+		recipients[i] = entities.MapEntityToCampaignRecipientDto(record)
+	}
+	return recipients, total, nil
+}
+
+func (s *CampaignsService) changeStatus(id int64, from []string, values map[string]interface{}) (dtos.CampaignDto, error) {
+	if _, err := s.findCampaign(id); err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	changed, err := s.repository.UpdateStatus(id, from, values)
+	if err != nil {
+		return dtos.CampaignDto{}, err
+	}
+	if !changed {
+		return dtos.CampaignDto{}, ErrCampaignState
+	}
+	return s.GetByID(id)
+}
+
+// findCampaign busca la campaña y verifica que su número sea del scope
+func (s *CampaignsService) findCampaign(id int64) (entities.Campaign, error) {
+	record, err := s.repository.FindByID(id)
+	if err != nil {
+		return record, err
+	}
+	if _, err := s.findNumberPhone(record.NumberPhonesID); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+func (s *CampaignsService) findNumberPhone(numberPhoneID int64) (entities.NumberPhone, error) {
+	return s.numberPhonesRepository.FindByID(strconv.FormatInt(numberPhoneID, 10))
+}
+
+// notify despierta a un worker sin esperar al próximo poll
+func (s *CampaignsService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start levanta los workers que envían las campañas programadas
+func (s *CampaignsService) Start() {
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+	log.Printf("CampaignsService iniciado con %d workers (%d mensajes por segundo).", s.workers, s.messagesPerSecond)
+}
+
+func (s *CampaignsService) worker() {
+	ticker := time.NewTicker(campaignPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.runNext() {
+		}
+
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext toma y envía una campaña. Devuelve false si no había campañas para enviar.
+func (s *CampaignsService) runNext() bool {
+	now := time.Now()
+	campaign, err := s.repository.ClaimDue(now, now.Add(-campaignStaleAfter))
+	if err != nil {
+		log.Printf("Error claiming campaign: %v", err)
+		return false
+	}
+	if campaign == nil {
+		return false
+	}
+
+	if err := s.run(*campaign); err != nil {
+		log.Printf("Error enviando la campaña %d: %v", campaign.ID, err)
+		// Queda en running sin señales y la vuelve a tomar un worker cuando se considere abandonada
+		if _, updateErr := s.repository.UpdateStatus(campaign.ID, []string{dtos.CampaignStatusRunning}, map[string]interface{}{"last_error": err.Error()}); updateErr != nil {
+			log.Printf("Error updating campaign %d: %v", campaign.ID, updateErr)
+		}
+	}
+	return true
+}
+
+// campaignRun es el estado de una campaña mientras se envía
+type campaignRun struct {
+	campaign    entities.Campaign
+	numberPhone entities.NumberPhone
+	components  []metaapi.Component
+	variables   map[string]map[string]string
+	usesEvent   bool
+	tierLimit   int
+}
+
+// run envía los destinatarios pendientes de la campaña hasta terminarlos, hasta que la pausen o cancelen,
+// o hasta que se agote el tier del número (en ese caso se reprograma)
+func (s *CampaignsService) run(campaign entities.Campaign) error {
+	numberPhone, err := s.numberPhonesRepository.FindByID(strconv.FormatInt(campaign.NumberPhonesID, 10))
+	if err != nil {
+		return fmt.Errorf("number phone not found: %v", err)
+	}
+
+	dto := entities.MapEntityToCampaignDto(campaign)
+	state := campaignRun{
+		campaign:    campaign,
+		numberPhone: numberPhone,
+		components:  dto.Template.Components,
+		variables:   dto.Variables,
+		tierLimit:   messagingLimit(numberPhone.MessagingLimitTier),
+	}
+	for _, name := range dtos.CampaignTemplateVariables(state.components) {
+		if strings.HasPrefix(name, "event.") {
+			state.usesEvent = true
+		}
+	}
+
+	// La primera vez se arma la lista de destinatarios con el segmento; al retomarla se siguen los pendientes
+	total, err := s.repository.CountRecipients(campaign.ID)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		ids, err := s.contactsRepository.FindIDsBySegment(campaign.NumberPhonesID, dto.Segment, time.Now())
+		if err != nil {
+			return fmt.Errorf("error resolving segment: %v", err)
+		}
+		if err := s.repository.AddRecipients(campaign.ID, ids); err != nil {
+			return fmt.Errorf("error adding recipients: %v", err)
+		}
+	}
+
+	limiter := time.NewTicker(time.Second / time.Duration(s.messagesPerSecond))
+	defer limiter.Stop()
+
+	for {
+		status, err := s.repository.Heartbeat(campaign.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if status != dtos.CampaignStatusRunning {
+			log.Printf("La campaña %d se frenó (%s)", campaign.ID, status)
+			return nil
+		}
+
+		recipients, err := s.repository.FindPendingRecipients(campaign.ID, campaignBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			_, err := s.repository.UpdateStatus(campaign.ID, []string{dtos.CampaignStatusRunning}, map[string]interface{}{
+				"status":      dtos.CampaignStatusCompleted,
+				"finished_at": time.Now(),
+				"locked_at":   nil,
+			})
+			return err
+		}
+
+		windowStart := time.Now().Add(-messagingLimitWindow)
+		used, err := s.repository.CountRecentRecipients(numberPhone.ID, windowStart)
+		if err != nil {
+			return err
+		}
+
+		for i, recipient := range recipients {
+			// Si la pausaron o cancelaron se frena sin esperar a terminar el lote
+			if i > 0 && i%campaignStatusCheckEvery == 0 {
+				if status, err := s.repository.Heartbeat(campaign.ID, time.Now()); err != nil || status != dtos.CampaignStatusRunning {
+					break
+				}
+			}
+
+			// Un contacto que ya recibió una campaña en la ventana no suma al tier
+			isNew := true
+			if state.tierLimit > 0 && used >= int64(state.tierLimit) {
+				isNew, err = s.repository.WasRecentlyMessaged(numberPhone.ID, recipient.ContactsID, windowStart)
+				if err != nil {
+					return err
+				}
+				isNew = !isNew
+				if isNew {
+					return s.deferForTier(campaign.ID, state.tierLimit)
+				}
+			}
+
+			<-limiter.C
+			sent, err := s.sendToRecipient(&state, recipient)
+			if errors.Is(err, errCampaignStopped) {
+				log.Printf("La campaña %d se frenó mientras Meta limitaba el envío", campaign.ID)
+				return nil
+			}
+			if errors.Is(err, errCampaignRateLimited) {
+				retryAt := time.Now().Add(campaignRateLimitRetryAfter)
+				log.Printf("Meta sigue limitando el envío de la campaña %d, se retoma a las %s", campaign.ID, retryAt.Format("15:04"))
+				return s.deferCampaign(campaign.ID, retryAt, fmt.Sprintf("Meta limitó la velocidad de envío %d veces seguidas, se retoma a las %s", campaignRateLimitMaxAttempts, retryAt.Format("02/01/2006 15:04")))
+			}
+			if err != nil {
+				return err
+			}
+			if sent && isNew {
+				used++
+			}
+		}
+	}
+}
+
+// deferForTier reprograma la campaña porque el número ya inició conversaciones con todos los contactos que le permite su tier
+func (s *CampaignsService) deferForTier(campaignID int64, limit int) error {
+	retryAt := time.Now().Add(campaignTierRetryAfter)
+	log.Printf("La campaña %d alcanzó el tier del número (%d contactos en 24 horas), se retoma a las %s", campaignID, limit, retryAt.Format("15:04"))
+	return s.deferCampaign(campaignID, retryAt, fmt.Sprintf("se alcanzó el límite del tier del número (%d contactos en 24 horas), se retoma a las %s", limit, retryAt.Format("02/01/2006 15:04")))
+}
+
+// deferCampaign vuelve a programar la campaña para retryAt. Los destinatarios pendientes se envían al retomarla.
+func (s *CampaignsService) deferCampaign(campaignID int64, retryAt time.Time, reason string) error {
+	_, err := s.repository.UpdateStatus(campaignID, []string{dtos.CampaignStatusRunning}, map[string]interface{}{
+		"status":       dtos.CampaignStatusScheduled,
+		"scheduled_at": retryAt,
+		"locked_at":    nil,
+		"last_error":   reason,
+	})
+	return err
+}
+
+// sendToRecipient envía el template a un destinatario y registra el resultado. Devuelve true si WhatsApp aceptó
+// el mensaje. Los límites de velocidad de Meta se reintentan con backoff hasta campaignRateLimitMaxAttempts veces;
+// si se agotan devuelve errCampaignRateLimited y si mientras tanto pausaron o cancelaron la campaña,
+// errCampaignStopped. En los dos casos el destinatario queda pendiente. Cualquier otro error es de la base.
+func (s *CampaignsService) sendToRecipient(state *campaignRun, recipient entities.CampaignRecipient) (bool, error) {
+	contact := recipient.Contact
+	if contact.IsBlocked || contact.OptedOutAt != nil {
+		return false, s.repository.UpdateRecipient(recipient.ID, dtos.CampaignRecipientSkipped, "el contacto está bloqueado o se dio de baja", nil, nil)
+	}
+
+	components, err := s.renderComponents(state, contact)
+	if err != nil {
+		return false, s.repository.UpdateRecipient(recipient.ID, dtos.CampaignRecipientSkipped, err.Error(), nil, nil)
+	}
+
+	payload := metaapi.NewSendMessageTemplate(state.campaign.TemplateName, state.campaign.TemplateLanguage, components, contact.NumberPhone)
+
+	var messageID string
+	var sendErr error
+	backoff := s.rateLimitBackoff
+	for attempt := 1; ; attempt++ {
+		messageID, sendErr = s.whatsappService.postMessage(state.numberPhone, payload)
+		if !errors.Is(sendErr, clients.ErrWhatsappRateLimited) {
+			break
+		}
+		if attempt == campaignRateLimitMaxAttempts {
+			return false, errCampaignRateLimited
+		}
+		log.Printf("Meta limitó el envío de la campaña %d, se reintenta en %s", state.campaign.ID, backoff)
+		if err := s.waitWhileRunning(state.campaign.ID, backoff); err != nil {
+			return false, err
+		}
+		if backoff *= 2; backoff > campaignRateLimitMaxBackoff {
+			backoff = campaignRateLimitMaxBackoff
+		}
+	}
+
+	now := time.Now()
+	record := entities.Message{
+		NumberPhonesID:    state.numberPhone.ID,
+		ContactsID:        contact.ID,
+		MessageIdWhatsapp: messageID,
+		MessageText:       "[campaña] " + state.campaign.Name,
+		IsFromBot:         true,
+		MessageType:       dtos.OutboundTypeTemplate,
+	}
+	if sendErr != nil {
+		markSendFailed(&record, sendErr)
+	}
+
+	saved, err := saveBotMessage(s.whatsappService, record)
+	if err != nil {
+		return false, err
+	}
+	if sendErr != nil {
+		return false, s.repository.UpdateRecipient(recipient.ID, dtos.CampaignRecipientFailed, sendErr.Error(), &saved.ID, nil)
+	}
+	return true, s.repository.UpdateRecipient(recipient.ID, dtos.CampaignRecipientSent, "", &saved.ID, &now)
+}
+
+// waitWhileRunning espera d mirando el estado de la campaña cada campaignStatusPollInterval (y antes de empezar).
+// Devuelve errCampaignStopped apenas la pausan o cancelan. Cada consulta también renueva la señal del worker.
+func (s *CampaignsService) waitWhileRunning(campaignID int64, d time.Duration) error {
+	deadline := time.Now().Add(d)
+	for {
+		status, err := s.repository.Heartbeat(campaignID, time.Now())
+		if err != nil {
+			return err
+		}
+		if status != dtos.CampaignStatusRunning {
+			return errCampaignStopped
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		if remaining > campaignStatusPollInterval {
+			remaining = campaignStatusPollInterval
+		}
+		time.Sleep(remaining)
+	}
+}
+
+// renderComponents completa las variables de los parámetros con los datos del contacto
+func (s *CampaignsService) renderComponents(state *campaignRun, contact entities.Contact) ([]metaapi.Component, error) {
+	values := map[string]string{
+		"contact.id":    strconv.FormatInt(contact.ID, 10),
+		"contact.phone": contact.NumberPhone,
+	}
+	// Sin nombre ni email la variable queda sin valor y al destinatario no se le envía
+	if name := contact.DisplayName(); name != "" {
+		values["contact.name"] = name
+	}
+	if contact.Email != "" {
+		values["contact.email"] = contact.Email
+	}
+	for name, value := range contact.CustomFieldValues() {
+		values["field."+name] = value
+	}
+	for name, value := range state.variables[strconv.FormatInt(contact.ID, 10)] {
+		values["var."+name] = value
+	}
+	if state.usesEvent {
+		event, err := s.eventsRepository.FindNextByContact(contact.ID, time.Now().Format("2006-01-02T15:04:05"))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			values["event.summary"] = event.Summary
+			values["event.code"] = event.CodeEvent
+			values["event.start"] = event.StartDate
+			if start, err := time.Parse("2006-01-02T15:04:05", event.StartDate); err == nil {
+				values["event.start"] = start.Format("02/01/2006 15:04")
+			}
+		}
+	}
+
+	var missing string
+	replace := func(text string) string {
+		return dtos.CampaignPlaceholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			match := dtos.CampaignPlaceholderPattern.FindStringSubmatch(placeholder)
+			value, ok := values[match[1]+"."+match[2]]
+			if !ok && missing == "" {
+				missing = match[1] + "." + match[2]
+			}
+			return value
+		})
+	}
+
+	// Se copian los componentes para no modificar los de la campaña
+	raw, _ := json.Marshal(state.components)
+	var components []metaapi.Component
+	if err := json.Unmarshal(raw, &components); err != nil {
+		return nil, err
+	}
+	for i := range components {
+		for j := range components[i].Parameters {
+			parameter := &components[i].Parameters[j]
+			parameter.Text = replace(parameter.Text)
+			parameter.Payload = replace(parameter.Payload)
+		}
+	}
+	if missing != "" {
+		return nil, fmt.Errorf("falta el valor de la variable {{%s}}", missing)
+	}
+	return components, nil
+}
+
+// messagingLimit devuelve cuántos contactos distintos puede iniciar el número en 24 horas (0 = sin límite).
+// Los números nuevos arrancan en TIER_250.
+func messagingLimit(tier string) int {
+	if limit, ok := dtos.MessagingLimitTiers[strings.ToUpper(tier)]; ok {
+		return limit
+	}
+	return dtos.MessagingLimitTiers["TIER_250"]
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+)
+
+// Si pausan la campaña mientras Meta limita la velocidad, el worker la suelta sin esperar a que Meta lo deje enviar
+func TestCampaignRunStopsWhenPausedDuringRateLimit(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+
+	campaign := entities.Campaign{ID: 1, NumberPhonesID: 1, Name: "promo", TemplateName: "promo", TemplateLanguage: "es", Components: "[]", Segment: "{}", Status: dtos.CampaignStatusRunning}
+	records := []interface{}{
+		&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101},
+		&entities.Contact{ID: 1, NumberPhonesID: 1, NumberPhone: "+5493510000001"},
+		&campaign,
+		&entities.CampaignRecipient{CampaignsID: 1, ContactsID: 1, Status: dtos.CampaignRecipientPending},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+
+	// Meta limita todos los envíos y el usuario pausa la campaña apenas empieza
+	meta := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		db.Model(&entities.Campaign{}).Where("id = ?", 1).Update("status", dtos.CampaignStatusPaused)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"Rate limit hit","type":"OAuthException","code":130429}}`)
+	}))
+	defer meta.Close()
+
+	service := NewCampaignsService(repos.Campaigns, repos.Contacts, repos.NumberPhones, repos.MessageTemplates, repos.Events,
+		&WhatsappService{whatsappClient: clients.NewWhatsappClient(meta.URL, "v21.0")})
+	service.rateLimitBackoff = time.Hour
+
+	done := make(chan error, 1)
+	go func() { done <- service.run(campaign) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("run kept retrying the paused campaign")
+	}
+
+	var recipient entities.CampaignRecipient
+	db.First(&recipient)
+	if recipient.Status != dtos.CampaignRecipientPending {
+		t.Errorf("recipient status = %q, want it pending for when the campaign is resumed", recipient.Status)
+	}
+}
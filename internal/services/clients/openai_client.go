@@ -0,0 +1,493 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaichat"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaimessages"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openairuns"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaithreads"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaivectorfiles"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaivectorsdtos"
+)
+
+const (
+	defaultOpenAIURL              = "https://api.openai.com/v1"
+	defaultOpenAITimeout          = 2 * time.Minute
+	defaultOpenAIMaxRetries       = 3
+	defaultOpenAIBaseBackoff      = 500 * time.Millisecond
+	defaultOpenAIMaxBackoff       = 8 * time.Second
+	defaultOpenAIBreakerThreshold = 5
+	defaultOpenAIBreakerCooldown  = 30 * time.Second
+
+	// Máximo que se muestra de cada body en los logs de debug
+	openAIDebugBodyLimit = 4 << 10
+)
+
+// OpenAIConfig configura el cliente de OpenAI. Los valores en cero usan los valores por defecto.
+type OpenAIConfig struct {
+	BaseURL string // ej: https://api.openai.com/v1 o un endpoint compatible como http://localhost:11434/v1
+	APIKey  string // Vacía para los endpoints que no la piden (Ollama)
+	Timeout time.Duration
+
+	MaxRetries  int // Reintentos de los errores temporales (429 y 5xx). Negativo para no reintentar.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// El circuit breaker se abre con BreakerThreshold requests seguidos que fallaron y se prueba de nuevo
+	// pasado BreakerCooldown. Con un threshold negativo no se usa.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	Debug bool // Loguea los bodies de los requests y respuestas
+}
+
+// OpenAIConfigFromEnv lee la configuración de OPENAI_API_URL, OPENAI_API_KEY, OPENAI_TIMEOUT_SECONDS,
+// OPENAI_MAX_RETRIES, OPENAI_CIRCUIT_THRESHOLD, OPENAI_CIRCUIT_COOLDOWN_SECONDS y OPENAI_DEBUG
+func OpenAIConfigFromEnv() OpenAIConfig {
+	debug, _ := strconv.ParseBool(os.Getenv("OPENAI_DEBUG"))
+	return OpenAIConfig{
+		BaseURL:          os.Getenv("OPENAI_API_URL"),
+		APIKey:           os.Getenv("OPENAI_API_KEY"),
+		Timeout:          time.Duration(envInt("OPENAI_TIMEOUT_SECONDS", 0)) * time.Second,
+		MaxRetries:       envInt("OPENAI_MAX_RETRIES", defaultOpenAIMaxRetries),
+		BreakerThreshold: envInt("OPENAI_CIRCUIT_THRESHOLD", defaultOpenAIBreakerThreshold),
+		BreakerCooldown:  time.Duration(envInt("OPENAI_CIRCUIT_COOLDOWN_SECONDS", 0)) * time.Second,
+		Debug:            debug,
+	}
+}
+
+// envInt lee un entero de una variable de entorno, con un valor por defecto si no está o no es válido
+func envInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// OpenAIClient es el único cliente HTTP de OpenAI: Assistants API (assistants, threads, runs, archivos y vector
+// stores), transcripciones y Chat Completions, también contra endpoints compatibles. Todos los métodos reciben un
+// context, reintentan los errores temporales respetando el Retry-After y pasan por un circuit breaker por endpoint.
+type OpenAIClient struct {
+	baseURL     string
+	apiKey      string
+	httpClient  *http.Client
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	debug       bool
+
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	breakersMu       sync.Mutex
+	breakers         map[string]*circuitBreaker // Por URL base, así un endpoint caído no corta a los demás
+}
+
+// NewOpenAIClient crea el cliente con la configuración indicada (ver OpenAIConfigFromEnv)
+func NewOpenAIClient(config OpenAIConfig) *OpenAIClient {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultOpenAIURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultOpenAITimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultOpenAIMaxRetries
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = defaultOpenAIBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultOpenAIMaxBackoff
+	}
+	if config.BreakerThreshold == 0 {
+		config.BreakerThreshold = defaultOpenAIBreakerThreshold
+	}
+	if config.BreakerCooldown <= 0 {
+		config.BreakerCooldown = defaultOpenAIBreakerCooldown
+	}
+
+	return &OpenAIClient{
+		baseURL:          strings.TrimRight(config.BaseURL, "/"),
+		apiKey:           config.APIKey,
+		httpClient:       &http.Client{Timeout: config.Timeout},
+		maxRetries:       config.MaxRetries,
+		baseBackoff:      config.BaseBackoff,
+		maxBackoff:       config.MaxBackoff,
+		debug:            config.Debug,
+		breakerThreshold: config.BreakerThreshold,
+		breakerCooldown:  config.BreakerCooldown,
+		breakers:         make(map[string]*circuitBreaker),
+	}
+}
+
+// ===========================================
+// Assistants
+
+// CreateAssistant crea un assistant. request es el body de POST /assistants.
+func (client *OpenAIClient) CreateAssistant(ctx context.Context, request interface{}) (openaiassistantdtos.ResponseCreateAssistant, error) {
+	var assistant openaiassistantdtos.ResponseCreateAssistant
+	err := client.Do(ctx, http.MethodPost, "/assistants", request, &assistant)
+	return assistant, err
+}
+
+// UpdateAssistant modifica un assistant existente
+func (client *OpenAIClient) UpdateAssistant(ctx context.Context, assistantID string, request interface{}) (openaiassistantdtos.ResponseCreateAssistant, error) {
+	var assistant openaiassistantdtos.ResponseCreateAssistant
+	err := client.Do(ctx, http.MethodPost, "/assistants/"+assistantID, request, &assistant)
+	return assistant, err
+}
+
+// DeleteAssistant elimina un assistant
+func (client *OpenAIClient) DeleteAssistant(ctx context.Context, assistantID string) error {
+	return client.Do(ctx, http.MethodDelete, "/assistants/"+assistantID, nil, nil)
+}
+
+// ===========================================
+// Archivos y vector stores
+
+// UploadFile sube un archivo con el purpose indicado (assistants, fine-tune, ...)
+func (client *OpenAIClient) UploadFile(ctx context.Context, content io.Reader, filename, purpose string) (openaiassistantdtos.FileUploadResponse, error) {
+	var file openaiassistantdtos.FileUploadResponse
+	err := client.upload(ctx, "/files", content, filename, map[string]string{"purpose": purpose}, &file)
+	return file, err
+}
+
+// DeleteFile elimina un archivo
+func (client *OpenAIClient) DeleteFile(ctx context.Context, fileID string) error {
+	return client.Do(ctx, http.MethodDelete, "/files/"+fileID, nil, nil)
+}
+
+// CreateVectorStore crea un vector store vacío
+func (client *OpenAIClient) CreateVectorStore(ctx context.Context, name string) (openaivectorsdtos.VectorStore, error) {
+	var vectorStore openaivectorsdtos.VectorStore
+	err := client.Do(ctx, http.MethodPost, "/vector_stores", openaivectorsdtos.CreateVectorStoreRequest{Name: name}, &vectorStore)
+	return vectorStore, err
+}
+
+// AddFileToVectorStore agrega un archivo ya subido a un vector store
+func (client *OpenAIClient) AddFileToVectorStore(ctx context.Context, vectorStoreID, fileID string) (openaivectorfiles.VectorStoreFile, error) {
+	var file openaivectorfiles.VectorStoreFile
+	err := client.Do(ctx, http.MethodPost, "/vector_stores/"+vectorStoreID+"/files", openaivectorfiles.AddFileRequest{FileID: fileID}, &file)
+	return file, err
+}
+
+// DeleteFileFromVectorStore quita un archivo de un vector store (el archivo sigue existiendo)
+func (client *OpenAIClient) DeleteFileFromVectorStore(ctx context.Context, vectorStoreID, fileID string) error {
+	return client.Do(ctx, http.MethodDelete, "/vector_stores/"+vectorStoreID+"/files/"+fileID, nil, nil)
+}
+
+// TranscribeAudio transcribe un audio con el modelo indicado (ej: whisper-1)
+func (client *OpenAIClient) TranscribeAudio(ctx context.Context, content io.Reader, filename, model string) (string, error) {
+	var transcription struct {
+		Text string `json:"text"`
+	}
+	fields := map[string]string{"model": model, "response_format": "json"}
+	if err := client.upload(ctx, "/audio/transcriptions", content, filename, fields, &transcription); err != nil {
+		return "", err
+	}
+	return transcription.Text, nil
+}
+
+// ===========================================
+// Threads, mensajes y runs
+
+// CreateThread crea un thread vacío
+func (client *OpenAIClient) CreateThread(ctx context.Context) (openaithreads.ThreadResponse, error) {
+	var thread openaithreads.ThreadResponse
+	err := client.Do(ctx, http.MethodPost, "/threads", openaithreads.ThreadRequest{}, &thread)
+	return thread, err
+}
+
+// UpdateThread modifica un thread. request es el body de POST /threads/{thread_id} (metadata, tool_resources).
+func (client *OpenAIClient) UpdateThread(ctx context.Context, threadID string, request interface{}) (openaithreads.ThreadUpdateResponse, error) {
+	var thread openaithreads.ThreadUpdateResponse
+	err := client.Do(ctx, http.MethodPost, "/threads/"+threadID, request, &thread)
+	return thread, err
+}
+
+// CreateMessage agrega un mensaje a un thread. request es el body de POST /threads/{thread_id}/messages.
+func (client *OpenAIClient) CreateMessage(ctx context.Context, threadID string, request interface{}) (openaimessages.SendMessageResponse, error) {
+	var message openaimessages.SendMessageResponse
+	err := client.Do(ctx, http.MethodPost, "/threads/"+threadID+"/messages", request, &message)
+	return message, err
+}
+
+// ListMessages devuelve los últimos mensajes de un thread, del más nuevo al más viejo
+func (client *OpenAIClient) ListMessages(ctx context.Context, threadID string) (openaimessages.ListMessagesResponse, error) {
+	var messages openaimessages.ListMessagesResponse
+	err := client.Do(ctx, http.MethodGet, "/threads/"+threadID+"/messages", nil, &messages)
+	return messages, err
+}
+
+// CreateRun corre un assistant sobre un thread
+func (client *OpenAIClient) CreateRun(ctx context.Context, threadID, assistantID string) (openairuns.OpenAIRunResponse, error) {
+	var run openairuns.OpenAIRunResponse
+	err := client.Do(ctx, http.MethodPost, "/threads/"+threadID+"/runs", map[string]string{"assistant_id": assistantID}, &run)
+	return run, err
+}
+
+// GetRun devuelve el estado de un run
+func (client *OpenAIClient) GetRun(ctx context.Context, threadID, runID string) (openairuns.OpenAIRunResponse, error) {
+	var run openairuns.OpenAIRunResponse
+	err := client.Do(ctx, http.MethodGet, "/threads/"+threadID+"/runs/"+runID, nil, &run)
+	return run, err
+}
+
+// ListRuns devuelve los runs de un thread. query acepta limit, order, after y before.
+func (client *OpenAIClient) ListRuns(ctx context.Context, threadID string, query url.Values) ([]openairuns.OpenAIRunResponse, error) {
+	path := "/threads/" + threadID + "/runs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var runs struct {
+		Data []openairuns.OpenAIRunResponse `json:"data"`
+	}
+	err := client.Do(ctx, http.MethodGet, path, nil, &runs)
+	return runs.Data, err
+}
+
+// SubmitToolOutputs envía los resultados de las tools que pidió un run en requires_action
+func (client *OpenAIClient) SubmitToolOutputs(ctx context.Context, threadID, runID string, toolOutputs []openairuns.OpenAIToolOutput) (openairuns.OpenAIRunResponse, error) {
+	var run openairuns.OpenAIRunResponse
+	body := map[string]interface{}{"tool_outputs": toolOutputs}
+	err := client.Do(ctx, http.MethodPost, "/threads/"+threadID+"/runs/"+runID+"/submit_tool_outputs", body, &run)
+	return run, err
+}
+
+// ===========================================
+// Chat Completions
+
+// ChatCompletion hace un pedido a /chat/completions. Si baseURL no está vacía se usa en lugar de la del cliente
+// (assistants que apuntan a su propio endpoint compatible); cada URL tiene su propio circuit breaker.
+func (client *OpenAIClient) ChatCompletion(ctx context.Context, baseURL string, request openaichat.ChatCompletionRequest) (openaichat.ChatCompletionResponse, error) {
+	path := "/chat/completions"
+	if baseURL != "" {
+		path = strings.TrimRight(baseURL, "/") + path
+	}
+	var completion openaichat.ChatCompletionResponse
+	if err := client.Do(ctx, http.MethodPost, path, request, &completion); err != nil {
+		return completion, err
+	}
+	if len(completion.Choices) == 0 {
+		return completion, fmt.Errorf("openai: chat completions response without choices")
+	}
+	return completion, nil
+}
+
+// ===========================================
+
+// Do hace un request JSON a la API y decodifica la respuesta en out (si no es nil). path es relativo a la URL
+// base (ej: /threads) o una URL completa. Los 429 y 5xx se reintentan con backoff; los errores de red solo en
+// los GET y DELETE, porque un POST que no llegó a responder puede haberse procesado igual.
+func (client *OpenAIClient) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	contentType := ""
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	return client.request(ctx, method, path, contentType, payload, out)
+}
+
+// upload hace un POST multipart con el archivo en el campo file y los campos indicados. El body se arma en
+// memoria para poder reenviarlo en los reintentos.
+func (client *OpenAIClient) upload(ctx context.Context, path string, content io.Reader, filename string, fields map[string]string, out interface{}) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.request(ctx, http.MethodPost, path, writer.FormDataContentType(), body.Bytes(), out)
+}
+
+// request pasa el request por el circuit breaker de su endpoint y lo reintenta si falla con un error temporal
+func (client *OpenAIClient) request(ctx context.Context, method, path, contentType string, payload []byte, out interface{}) error {
+	baseURL, endpoint := client.baseURL, client.baseURL+"/"+strings.TrimLeft(path, "/")
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		endpoint = path
+		if parsed, err := url.Parse(path); err == nil {
+			baseURL = parsed.Scheme + "://" + parsed.Host
+		}
+	}
+
+	breaker := client.breakerFor(baseURL)
+	if !breaker.allow() {
+		return ErrOpenAICircuitOpen
+	}
+
+	idempotent := method == http.MethodGet || method == http.MethodDelete
+	err := client.retry(ctx, idempotent, func() error {
+		return client.send(ctx, method, endpoint, contentType, payload, out)
+	})
+
+	switch {
+	case err == nil:
+		breaker.record(false)
+	case ctx.Err() != nil:
+		// Lo canceló quien llamaba: no dice nada del estado de OpenAI
+		breaker.release()
+	default:
+		// Un 4xx (salvo 429) significa que OpenAI respondió bien a un pedido inválido
+		breaker.record(errors.Is(err, ErrOpenAIUnavailable) || errors.Is(err, ErrOpenAIRateLimited))
+	}
+	return err
+}
+
+// send hace un único intento del request
+func (client *OpenAIClient) send(ctx context.Context, method, endpoint, contentType string, payload []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if client.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+client.apiKey)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("OpenAI-Beta", "assistants=v2")
+
+	if client.debug {
+		if strings.HasPrefix(contentType, "multipart/") {
+			log.Printf("openai: %s %s (multipart, %d bytes)", method, endpoint, len(payload))
+		} else {
+			log.Printf("openai: %s %s %s", method, endpoint, debugBody(payload))
+		}
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("openai: %s %s: %w", method, endpoint, ctx.Err())
+		}
+		// Sin respuesta (caído, timeout, conexión cortada)
+		return fmt.Errorf("%w: %s %s: %v", ErrOpenAIUnavailable, method, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		raw := readErrorBody(resp)
+		if client.debug {
+			log.Printf("openai: %s %s -> %s %s", method, endpoint, resp.Status, debugBody(raw))
+		}
+		return parseOpenAIError(resp, raw)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %s %s: %v", ErrOpenAIUnavailable, method, endpoint, err)
+	}
+	if client.debug {
+		log.Printf("openai: %s %s -> %s %s", method, endpoint, resp.Status, debugBody(raw))
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("openai: invalid response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// retry ejecuta request hasta que funcione, devuelva un error definitivo o se agoten los reintentos. Entre intentos
+// espera el Retry-After de la respuesta o un backoff exponencial.
+func (client *OpenAIClient) retry(ctx context.Context, retryNetworkErrors bool, request func() error) error {
+	backoff := client.baseBackoff
+	for attempt := 0; ; attempt++ {
+		err := request()
+		if err == nil {
+			return nil
+		}
+
+		wait := backoff
+		var apiErr *OpenAIAPIError
+		switch {
+		case errors.As(err, &apiErr):
+			if !apiErr.temporary() {
+				return err
+			}
+			if apiErr.retryAfter > 0 {
+				wait = apiErr.retryAfter
+			}
+		case ctx.Err() != nil:
+			return err
+		case !retryNetworkErrors || !errors.Is(err, ErrOpenAIUnavailable):
+			return err
+		}
+		if attempt >= client.maxRetries {
+			return err
+		}
+		// No tiene sentido esperar más de lo que le queda a quien llama
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > client.maxBackoff {
+			backoff = client.maxBackoff
+		}
+	}
+}
+
+// breakerFor devuelve el circuit breaker de una URL base
+func (client *OpenAIClient) breakerFor(baseURL string) *circuitBreaker {
+	client.breakersMu.Lock()
+	defer client.breakersMu.Unlock()
+
+	breaker, ok := client.breakers[baseURL]
+	if !ok {
+		breaker = &circuitBreaker{threshold: client.breakerThreshold, cooldown: client.breakerCooldown}
+		client.breakers[baseURL] = breaker
+	}
+	return breaker
+}
+
+// debugBody recorta un body para los logs de debug
+func debugBody(body []byte) string {
+	if len(body) > openAIDebugBodyLimit {
+		return string(body[:openAIDebugBodyLimit]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,133 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestOpenAIClient es un cliente contra server sin esperas entre reintentos
+func newTestOpenAIClient(server *httptest.Server, breakerThreshold int, breakerCooldown time.Duration) *OpenAIClient {
+	return NewOpenAIClient(OpenAIConfig{
+		BaseURL:          server.URL,
+		APIKey:           "sk-test",
+		BaseBackoff:      time.Millisecond,
+		MaxBackoff:       time.Millisecond,
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  breakerCooldown,
+	})
+}
+
+func TestOpenAIClientRetryAfter(t *testing.T) {
+	var calls int32
+	var firstCall time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			firstCall = time.Now()
+			w.Header().Set("Retry-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`)
+			return
+		}
+		if waited := time.Since(firstCall); waited < 200*time.Millisecond {
+			t.Errorf("retried after %v, want the Retry-After of the response", waited)
+		}
+		if r.URL.Path != "/threads/thread_1/runs/run_1" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("request = %s %s with %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{"id":"run_1","thread_id":"thread_1","status":"completed"}`)
+	}))
+	defer server.Close()
+
+	run, err := newTestOpenAIClient(server, 5, time.Minute).GetRun(context.Background(), "thread_1", "run_1")
+	if err != nil || run.Status != "completed" {
+		t.Fatalf("GetRun = %+v, %v; want the completed run", run, err)
+	}
+	if calls != 2 {
+		t.Errorf("%d requests, want the rate limit retried once", calls)
+	}
+}
+
+func TestOpenAIClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		calls  int32
+	}{
+		{"insufficient quota", http.StatusTooManyRequests, `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`, ErrOpenAIRateLimited, 1},
+		{"invalid api key", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`, ErrOpenAIUnauthorized, 1},
+		{"server error", http.StatusInternalServerError, `{"error":{"message":"The server had an error","type":"server_error","code":null}}`, ErrOpenAIUnavailable, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			_, err := newTestOpenAIClient(server, 5, time.Minute).CreateThread(context.Background())
+			if !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+			var apiErr *OpenAIAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status {
+				t.Errorf("error = %#v, want an *OpenAIAPIError with status %d", err, test.status)
+			}
+			if calls != test.calls {
+				t.Errorf("%d requests, want %d", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestOpenAIClientCircuitBreaker(t *testing.T) {
+	var calls int32
+	var down atomic.Bool
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":"thread_1","object":"thread"}`)
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server, 2, 100*time.Millisecond)
+	ctx := context.Background()
+
+	// Dos requests fallidos (con sus reintentos) abren el circuito
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateThread(ctx); !errors.Is(err, ErrOpenAIUnavailable) {
+			t.Fatalf("error = %v, want ErrOpenAIUnavailable", err)
+		}
+	}
+	before := atomic.LoadInt32(&calls)
+	if _, err := client.CreateThread(ctx); !errors.Is(err, ErrOpenAICircuitOpen) || !errors.Is(err, ErrOpenAIUnavailable) {
+		t.Fatalf("error = %v, want ErrOpenAICircuitOpen", err)
+	}
+	if calls != before {
+		t.Errorf("%d requests with the circuit open, want none", calls-before)
+	}
+
+	// Pasado el cooldown el request de prueba funciona y el circuito se cierra
+	down.Store(false)
+	time.Sleep(100 * time.Millisecond)
+	if thread, err := client.CreateThread(ctx); err != nil || thread.ID != "thread_1" {
+		t.Fatalf("CreateThread after the cooldown = %+v, %v", thread, err)
+	}
+	if _, err := client.CreateThread(ctx); err != nil {
+		t.Errorf("error = %v, want the circuit closed after the trial request", err)
+	}
+}
@@ -0,0 +1,185 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errores de la API de OpenAI (o de un endpoint compatible) agrupados por lo que tiene que hacer quien llama.
+// Se comparan con errors.Is contra lo que devuelve el cliente.
+var (
+	// ErrOpenAIUnavailable: OpenAI no responde o devuelve errores temporales aún después de los reintentos,
+	// o el circuit breaker está abierto. Hay que responder sin el modelo (mensaje de fallback).
+	ErrOpenAIUnavailable = errors.New("openai: service unavailable")
+	// ErrOpenAIRateLimited: se superó el rate limit o la cuota de la cuenta
+	ErrOpenAIRateLimited = errors.New("openai: rate limit or quota exceeded")
+	// ErrOpenAIUnauthorized: la API key es inválida o no tiene permisos
+	ErrOpenAIUnauthorized = errors.New("openai: invalid api key")
+
+	// ErrOpenAICircuitOpen se devuelve sin hacer el request mientras el circuit breaker está abierto
+	ErrOpenAICircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrOpenAIUnavailable)
+)
+
+// OpenAIAPIError es la respuesta con error de la API
+type OpenAIAPIError struct {
+	StatusCode int
+	Status     string
+	Type       string
+	Code       string // ej: rate_limit_exceeded, insufficient_quota
+	Param      string
+	Message    string
+	RequestID  string // x-request-id, el que pide el soporte de OpenAI
+
+	retryAfter time.Duration // Retry-After de la respuesta, si OpenAI lo envió
+}
+
+func (e *OpenAIAPIError) Error() string {
+	message := fmt.Sprintf("openai respondió %s", e.Status)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if e.Code != "" {
+		message += fmt.Sprintf(" (código %s)", e.Code)
+	}
+	return message
+}
+
+// Unwrap devuelve el error genérico que corresponde al status (nil si no es uno de los conocidos)
+func (e *OpenAIAPIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrOpenAIUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrOpenAIRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrOpenAIUnavailable
+	}
+	return nil
+}
+
+// temporary indica si conviene reintentar el request. Sin cuota no tiene sentido reintentar.
+func (e *OpenAIAPIError) temporary() bool {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return e.Code != "insufficient_quota"
+	}
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// parseOpenAIError arma el *OpenAIAPIError con el body de error de la API
+func parseOpenAIError(resp *http.Response, raw []byte) error {
+	apiErr := &OpenAIAPIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	var body struct {
+		Error struct {
+			Message string          `json:"message"`
+			Type    string          `json:"type"`
+			Param   json.RawMessage `json:"param"`
+			Code    json.RawMessage `json:"code"` // Suele ser un string, pero puede venir null o como número
+		} `json:"error"`
+	}
+	if json.Unmarshal(raw, &body) == nil {
+		apiErr.Message = body.Error.Message
+		apiErr.Type = body.Error.Type
+		apiErr.Code = rawString(body.Error.Code)
+		apiErr.Param = rawString(body.Error.Param)
+	}
+	return apiErr
+}
+
+// parseRetryAfter acepta segundos (lo que envía OpenAI) o una fecha HTTP
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// rawString devuelve el valor de un campo JSON que puede ser string, número o null
+func rawString(raw json.RawMessage) string {
+	value := strings.TrimSpace(string(raw))
+	if value == "null" {
+		return ""
+	}
+	return strings.Trim(value, `"`)
+}
+
+// readErrorBody lee el body de una respuesta con error, con un límite
+func readErrorBody(resp *http.Response) []byte {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return raw
+}
+
+// circuitBreaker corta los requests a un endpoint que está caído. Después de threshold requests seguidos que
+// fallaron (ya con sus reintentos) se abre: durante cooldown los requests fallan enseguida con ErrOpenAICircuitOpen.
+// Pasado el cooldown deja pasar un único request de prueba; si funciona se cierra y si falla vuelve a abrirse.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool // Hay un request de prueba en curso
+}
+
+// allow indica si se puede hacer el request
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record registra el resultado de un request que allow dejó pasar
+func (b *circuitBreaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// release libera el request de prueba sin contarlo (ej: lo canceló quien llamaba)
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
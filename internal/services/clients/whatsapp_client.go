@@ -0,0 +1,240 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+)
+
+const (
+	whatsappRequestTimeout = 30 * time.Second
+	whatsappMaxRetries     = 3
+	whatsappBaseBackoff    = 500 * time.Millisecond
+	whatsappMaxBackoff     = 8 * time.Second
+)
+
+// WhatsappCredentials son los datos de un número para usar la Cloud API. Cada número tiene su propio token.
+type WhatsappCredentials struct {
+	PhoneNumberID string
+	Token         string
+}
+
+// WhatsappClient es el cliente de la Graph API de Meta (WhatsApp Cloud API). Es compartido por todos los números:
+// las credenciales se pasan en cada llamada.
+type WhatsappClient struct {
+	baseURL     string
+	httpClient  *http.Client
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+// NewWhatsappClient crea el cliente para la URL de la Graph API (WHATSAPP_URL) y la versión (WHATSAPP_VERSION, ej: v21.0)
+func NewWhatsappClient(apiBaseURL, version string) *WhatsappClient {
+	return &WhatsappClient{
+		baseURL:     strings.TrimRight(apiBaseURL, "/") + "/" + strings.Trim(version, "/"),
+		httpClient:  &http.Client{Timeout: whatsappRequestTimeout},
+		maxRetries:  whatsappMaxRetries,
+		baseBackoff: whatsappBaseBackoff,
+		maxBackoff:  whatsappMaxBackoff,
+	}
+}
+
+// SendText envía un mensaje de texto
+func (client *WhatsappClient) SendText(ctx context.Context, credentials WhatsappCredentials, message metaapi.SendMessageBasic) (whatsapp.SendMessageResponse, error) {
+	return client.SendMessage(ctx, credentials, message)
+}
+
+// SendTemplate envía un template aprobado
+func (client *WhatsappClient) SendTemplate(ctx context.Context, credentials WhatsappCredentials, message metaapi.SendMessageTemplate) (whatsapp.SendMessageResponse, error) {
+	return client.SendMessage(ctx, credentials, message)
+}
+
+// SendMedia envía una imagen, documento, audio, video o sticker
+func (client *WhatsappClient) SendMedia(ctx context.Context, credentials WhatsappCredentials, message metaapi.SendMessageMedia) (whatsapp.SendMessageResponse, error) {
+	return client.SendMessage(ctx, credentials, message)
+}
+
+// SendInteractive envía un mensaje con botones o con un menú de opciones
+func (client *WhatsappClient) SendInteractive(ctx context.Context, credentials WhatsappCredentials, message metaapi.SendMessageInteractive) (whatsapp.SendMessageResponse, error) {
+	return client.SendMessage(ctx, credentials, message)
+}
+
+// SendMessage envía cualquier tipo de mensaje desde el número de las credenciales. El wamid asignado queda en
+// response.MessageID(). Si Meta rechaza el envío devuelve un *WhatsappAPIError.
+func (client *WhatsappClient) SendMessage(ctx context.Context, credentials WhatsappCredentials, message metaapi.OutgoingMessage) (whatsapp.SendMessageResponse, error) {
+	var response whatsapp.SendMessageResponse
+	if credentials.PhoneNumberID == "" || credentials.Token == "" {
+		return response, fmt.Errorf("whatsapp: missing phone number id or token")
+	}
+	err := client.Do(ctx, credentials.Token, http.MethodPost, credentials.PhoneNumberID+"/messages", message, &response)
+	return response, err
+}
+
+// GetMedia devuelve la URL de descarga (válida por unos minutos) y los datos de un archivo recibido
+func (client *WhatsappClient) GetMedia(ctx context.Context, credentials WhatsappCredentials, mediaID string) (whatsapp.MediaURLResponse, error) {
+	var media whatsapp.MediaURLResponse
+	err := client.Do(ctx, credentials.Token, http.MethodGet, mediaID, nil, &media)
+	return media, err
+}
+
+// DownloadMedia descarga el archivo de una URL devuelta por GetMedia. Devuelve el contenido y el Content-Type de la
+// respuesta, o ErrWhatsappMediaTooLarge si el archivo tiene más de maxSize bytes.
+func (client *WhatsappClient) DownloadMedia(ctx context.Context, credentials WhatsappCredentials, mediaURL string, maxSize int64) ([]byte, string, error) {
+	var data []byte
+	var contentType string
+	err := client.retry(ctx, true, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+		if err != nil {
+			return err
+		}
+		// La URL de descarga también requiere el token de acceso
+		req.Header.Set("Authorization", "Bearer "+credentials.Token)
+
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return parseWhatsappError(resp)
+		}
+		// Se lee un byte más del límite para distinguir un archivo del tamaño máximo de uno más grande
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+		contentType = resp.Header.Get("Content-Type")
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("%w (%d bytes)", ErrWhatsappMediaTooLarge, maxSize)
+	}
+	return data, contentType, nil
+}
+
+// Do hace un request a la Graph API con el token indicado y decodifica la respuesta en out (si no es nil). path es
+// relativo a la versión (ej: {waba-id}/message_templates) o una URL completa, como las de paginación que devuelve Meta.
+// Los GET se reintentan con backoff ante rate limits, errores temporales de Meta y errores de red. El resto (los
+// envíos de mensajes) solo ante rate limits: con un error de red o un 5xx Meta puede haber enviado el mensaje igual
+// y reintentarlo se lo mandaría dos veces al contacto.
+func (client *WhatsappClient) Do(ctx context.Context, token, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		endpoint = client.baseURL + "/" + strings.TrimLeft(path, "/")
+	}
+
+	return client.retry(ctx, method == http.MethodGet, func() error {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return parseWhatsappError(resp)
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("whatsapp: invalid response from %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// retry ejecuta request hasta que funcione, devuelva un error definitivo o se agoten los reintentos.
+// Entre intentos espera el Retry-After de Meta o un backoff exponencial. Si el request no es idempotente solo
+// se reintentan los rate limits, que Meta rechaza sin procesar.
+func (client *WhatsappClient) retry(ctx context.Context, idempotent bool, request func() error) error {
+	backoff := client.baseBackoff
+	for attempt := 0; ; attempt++ {
+		err := request()
+		if err == nil {
+			return nil
+		}
+
+		wait := backoff
+		var apiErr *WhatsappAPIError
+		switch {
+		case errors.As(err, &apiErr):
+			if !apiErr.temporary() || (!idempotent && !errors.Is(apiErr, ErrWhatsappRateLimited)) {
+				return err
+			}
+			if apiErr.retryAfter > 0 {
+				wait = apiErr.retryAfter
+			}
+		case ctx.Err() != nil:
+			return err
+		case !idempotent:
+			return err
+		}
+		if attempt >= client.maxRetries {
+			return err
+		}
+
+		if wait > client.maxBackoff {
+			wait = client.maxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > client.maxBackoff {
+			backoff = client.maxBackoff
+		}
+	}
+}
+
+// parseWhatsappError arma el *WhatsappAPIError con el body de error de la Graph API
+func parseWhatsappError(resp *http.Response) error {
+	apiErr := &WhatsappAPIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var graphError metaapi.GraphError
+	if json.Unmarshal(raw, &graphError) == nil {
+		apiErr.Code = graphError.Error.Code
+		apiErr.Subcode = graphError.Error.ErrorSubcode
+		apiErr.Type = graphError.Error.Type
+		apiErr.Message = graphError.Error.Message
+		apiErr.UserMessage = graphError.Error.UserMessage
+		apiErr.Details = graphError.Error.ErrorData.Details
+		apiErr.FBTraceID = graphError.Error.FBTraceID
+	}
+	return apiErr
+}
@@ -0,0 +1,168 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+)
+
+// newTestClient es un cliente contra server sin esperas entre reintentos
+func newTestClient(server *httptest.Server) *WhatsappClient {
+	client := NewWhatsappClient(server.URL, "v21.0")
+	client.baseBackoff = time.Millisecond
+	client.maxBackoff = time.Millisecond
+	return client
+}
+
+var testCredentials = WhatsappCredentials{PhoneNumberID: "1001", Token: "token-1001"}
+
+func TestWhatsappClientSendMessage(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Los dos primeros intentos fallan por rate limit: Meta no los procesó
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Pair rate limit hit","code":131056}}`)
+			return
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"(#130429) Rate limit hit","code":130429}}`)
+			return
+		}
+		if r.URL.Path != "/v21.0/1001/messages" || r.Header.Get("Authorization") != "Bearer token-1001" {
+			t.Errorf("request = %s %s with %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{"messaging_product":"whatsapp","contacts":[{"input":"5493511234567","wa_id":"5493511234567"}],"messages":[{"id":"wamid.1"}]}`)
+	}))
+	defer server.Close()
+
+	response, err := newTestClient(server).SendText(context.Background(), testCredentials, metaapi.NewSendMessageWhatsappBasic("hola", "+5493511234567"))
+	if err != nil || response.MessageID() != "wamid.1" {
+		t.Fatalf("SendText = %+v, %v; want wamid.1", response, err)
+	}
+	if calls != 3 {
+		t.Errorf("%d requests, want the two rate limits retried", calls)
+	}
+}
+
+func TestWhatsappClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		calls  int32
+	}{
+		{"re-engagement", http.StatusBadRequest, `{"error":{"message":"Re-engagement message","code":131047,"error_data":{"details":"More than 24 hours have passed"}}}`, ErrWhatsappReengagement, 1},
+		{"invalid recipient", http.StatusBadRequest, `{"error":{"message":"Recipient phone number not in allowed list","code":131030}}`, ErrWhatsappInvalidRecipient, 1},
+		{"expired token", http.StatusUnauthorized, `{"error":{"message":"Error validating access token","type":"OAuthException","code":190}}`, ErrWhatsappUnauthorized, 1},
+		{"template", http.StatusBadRequest, `{"error":{"message":"Template name does not exist in the translation","code":132001}}`, ErrWhatsappTemplate, 1},
+		{"pair rate limit", http.StatusBadRequest, `{"error":{"message":"Pair rate limit hit","code":131056}}`, ErrWhatsappRateLimited, 4},
+		{"server error", http.StatusInternalServerError, ``, ErrWhatsappUnavailable, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(server).SendTemplate(context.Background(), testCredentials,
+				metaapi.NewSendMessageTemplate("evento_creado", "es_AR", nil, "+5493511234567"))
+			if !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+			var apiErr *WhatsappAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status {
+				t.Errorf("error = %#v, want a *WhatsappAPIError with status %d", err, test.status)
+			}
+			if calls != test.calls {
+				t.Errorf("%d requests, want %d", calls, test.calls)
+			}
+		})
+	}
+}
+
+// Con un 5xx el mensaje pudo haberse enviado: el envío no se reintenta, la consulta de un archivo sí
+func TestWhatsappClientServerErrorRetriesOnlyGets(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":{"message":"Something went wrong","code":131000}}`)
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	_, err := client.SendMessage(context.Background(), testCredentials, metaapi.NewSendMessageWhatsappBasic("hola", "+5493511234567"))
+	if !errors.Is(err, ErrWhatsappUnavailable) || calls != 1 {
+		t.Errorf("SendMessage = %v after %d requests, want ErrWhatsappUnavailable after a single one", err, calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if _, err := client.GetMedia(context.Background(), testCredentials, "media-1"); !errors.Is(err, ErrWhatsappUnavailable) || calls != 4 {
+		t.Errorf("GetMedia = %v after %d requests, want ErrWhatsappUnavailable after 4", err, calls)
+	}
+}
+
+// Un error de red no reintenta el POST (Meta pudo haber enviado el mensaje) y se devuelve sin entrar en pánico
+func TestWhatsappClientNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := newTestClient(server)
+	server.Close()
+
+	_, err := client.SendText(context.Background(), testCredentials, metaapi.NewSendMessageWhatsappBasic("hola", "+5493511234567"))
+	var apiErr *WhatsappAPIError
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want the network error", err)
+	}
+}
+
+// Un archivo más grande que el máximo es un error, no una descarga cortada
+func TestWhatsappClientDownloadMediaLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want error
+	}{
+		{"under the limit", 9, nil},
+		{"at the limit", 10, nil},
+		{"over the limit", 11, ErrWhatsappMediaTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Write(make([]byte, test.size))
+			}))
+			defer server.Close()
+
+			data, contentType, err := newTestClient(server).DownloadMedia(context.Background(), testCredentials, server.URL+"/media", 10)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+			if test.want == nil && (len(data) != test.size || contentType != "image/jpeg") {
+				t.Errorf("DownloadMedia = %d bytes of %q, want %d bytes of image/jpeg", len(data), contentType, test.size)
+			}
+			if test.want != nil && data != nil {
+				t.Errorf("DownloadMedia returned %d bytes with the error", len(data))
+			}
+			if calls != 1 {
+				t.Errorf("%d requests, want the download not retried", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Errores de la Cloud API agrupados por lo que tiene que hacer quien envía. Se comparan con errors.Is contra el
+// *WhatsappAPIError que devuelve el cliente.
+var (
+	// ErrWhatsappRateLimited: se superó algún límite de envíos, hay que esperar y reintentar
+	ErrWhatsappRateLimited = errors.New("whatsapp: rate limit hit")
+	// ErrWhatsappReengagement: pasaron más de 24 horas desde el último mensaje del contacto, solo se pueden enviar templates
+	ErrWhatsappReengagement = errors.New("whatsapp: re-engagement window closed, only templates can be sent")
+	// ErrWhatsappInvalidRecipient: el número no tiene WhatsApp, no está habilitado o no puede recibir el mensaje
+	ErrWhatsappInvalidRecipient = errors.New("whatsapp: invalid recipient")
+	// ErrWhatsappUnauthorized: el token del número es inválido, venció o no tiene permisos
+	ErrWhatsappUnauthorized = errors.New("whatsapp: invalid or expired access token")
+	// ErrWhatsappTemplate: el template no existe, no está aprobado o sus parámetros no coinciden
+	ErrWhatsappTemplate = errors.New("whatsapp: template rejected")
+	// ErrWhatsappUnavailable: error temporal de Meta. Las consultas se pueden reintentar; un envío puede haberse hecho igual
+	ErrWhatsappUnavailable = errors.New("whatsapp: service unavailable")
+
+	// ErrWhatsappMediaTooLarge: el archivo a descargar supera el tamaño máximo pedido
+	ErrWhatsappMediaTooLarge = errors.New("whatsapp: media exceeds the maximum size")
+)
+
+// WhatsappAPIError es la respuesta con error de la Graph API
+type WhatsappAPIError struct {
+	StatusCode  int
+	Status      string
+	Code        int // Código de error de Meta (ej: 131047 si se cerró la ventana de 24 horas)
+	Subcode     int
+	Type        string
+	Message     string
+	UserMessage string
+	Details     string // error_data.details, el detalle que agrega la Cloud API
+	FBTraceID   string
+
+	retryAfter time.Duration // Retry-After de la respuesta, si Meta lo envió
+}
+
+func (e *WhatsappAPIError) Error() string {
+	message := fmt.Sprintf("meta respondió %s", e.Status)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if e.UserMessage != "" {
+		message += ": " + e.UserMessage
+	} else if e.Details != "" {
+		message += ": " + e.Details
+	}
+	if e.Code != 0 {
+		message += fmt.Sprintf(" (código %d)", e.Code)
+	}
+	return message
+}
+
+// Unwrap devuelve el error genérico que corresponde al código de Meta (nil si no es uno de los conocidos)
+func (e *WhatsappAPIError) Unwrap() error {
+	// https://developers.facebook.com/docs/whatsapp/cloud-api/support/error-codes
+	switch e.Code {
+	case 4, 80007, 130429, 131048, 131056:
+		return ErrWhatsappRateLimited
+	case 131047:
+		return ErrWhatsappReengagement
+	case 131021, 131026, 131030:
+		return ErrWhatsappInvalidRecipient
+	case 10, 190:
+		return ErrWhatsappUnauthorized
+	case 1, 2, 131000, 131016:
+		return ErrWhatsappUnavailable
+	}
+	if e.Code >= 132000 && e.Code < 133000 {
+		return ErrWhatsappTemplate
+	}
+	if e.Code >= 200 && e.Code < 300 {
+		return ErrWhatsappUnauthorized
+	}
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrWhatsappUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrWhatsappRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrWhatsappUnavailable
+	}
+	return nil
+}
+
+// temporary indica si conviene reintentar el request
+func (e *WhatsappAPIError) temporary() bool {
+	return errors.Is(e, ErrWhatsappRateLimited) || errors.Is(e, ErrWhatsappUnavailable)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ClosuresService administra los feriados y cierres de cada assistant (días completos o franjas horarias,
+// puntuales o que se repiten todos los años) que la disponibilidad y la reserva de turnos descuentan.
+type ClosuresService struct {
+	repository       repositories.AssistantClosuresRepository
+	assistantService *AssistantService
+}
+
+func NewClosuresService(repository repositories.AssistantClosuresRepository, assistantService *AssistantService) *ClosuresService {
+	return &ClosuresService{repository: repository, assistantService: assistantService}
+}
+
+// WithTenant devuelve una copia del servicio que solo accede a los assistants de los bussiness del scope
+func (s *ClosuresService) WithTenant(tenant dtos.TenantScope) *ClosuresService {
+	return &ClosuresService{repository: s.repository, assistantService: s.assistantService.WithTenant(tenant)}
+}
+
+// GetByAssistant lista los cierres del assistant. Con from ("YYYY-MM-DD") se omiten los que ya pasaron.
+func (s *ClosuresService) GetByAssistant(assistantID int64, from string) ([]dtos.AssistantClosureDto, error) {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return nil, err
+	}
+
+	records, err := s.repository.FindByAssistant(assistantID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	closures := make([]dtos.AssistantClosureDto, 0, len(records))
+	for _, record := range records {
+		closures = append(closures, entities.MapEntityToAssistantClosureDto(record))
+	}
+	return closures, nil
+}
+
+func (s *ClosuresService) GetByID(assistantID, id int64) (dtos.AssistantClosureDto, error) {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	record, err := s.repository.FindByID(assistantID, id)
+	if err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+	return entities.MapEntityToAssistantClosureDto(record), nil
+}
+
+func (s *ClosuresService) Create(assistantID int64, dto dtos.AssistantClosureDto) (dtos.AssistantClosureDto, error) {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	dto.ID = 0
+	dto.AssistantsID = assistantID
+	dto.Source = dtos.ClosureSourceManual
+	dto.ExternalUID = ""
+
+	record := entities.MapDtoToAssistantClosure(dto)
+	if err := s.repository.Create(&record); err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+	return entities.MapEntityToAssistantClosureDto(record), nil
+}
+
+func (s *ClosuresService) Update(assistantID, id int64, dto dtos.AssistantClosureDto) (dtos.AssistantClosureDto, error) {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	existing, err := s.repository.FindByID(assistantID, id)
+	if err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	dto.ID = existing.ID
+	dto.AssistantsID = assistantID
+	dto.Source = existing.Source
+	dto.ExternalUID = existing.ExternalUID
+
+	record := entities.MapDtoToAssistantClosure(dto)
+	record.CreatedAt = existing.CreatedAt
+	if err := s.repository.Update(&record); err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+	return entities.MapEntityToAssistantClosureDto(record), nil
+}
+
+func (s *ClosuresService) Delete(assistantID, id int64) error {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return err
+	}
+	return s.repository.Delete(assistantID, id)
+}
+
+// ImportICal carga los eventos de un archivo iCal (.ics) como cierres del assistant. Los eventos que ya se habían
+// importado (mismo UID) se actualizan, así el mismo calendario de feriados se puede volver a importar cada año.
+func (s *ClosuresService) ImportICal(assistantID int64, reader io.Reader) (dtos.ClosuresImportResultDto, error) {
+	result := dtos.ClosuresImportResultDto{}
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return result, err
+	}
+
+	closures, skipped, err := parseICalClosures(reader, availabilityLocation())
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = skipped
+
+	for _, closure := range closures {
+		closure.AssistantsID = assistantID
+		closure.Source = dtos.ClosureSourceICal
+		if err := closure.ValidateAssistantClosureDto(); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", closure.Reason, err))
+			continue
+		}
+
+		record := entities.MapDtoToAssistantClosure(closure)
+		existing, err := s.repository.FindByExternalUID(assistantID, closure.ExternalUID)
+		switch {
+		case err == nil:
+			record.ID = existing.ID
+			record.CreatedAt = existing.CreatedAt
+			if err := s.repository.Update(&record); err != nil {
+				return result, err
+			}
+			result.Updated++
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := s.repository.Create(&record); err != nil {
+				return result, err
+			}
+			result.Created++
+		default:
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// ClosedRangesOn devuelve los horarios en que el assistant está cerrado ese día y el motivo del primer cierre
+func (s *ClosuresService) ClosedRangesOn(assistantID int64, day time.Time) ([]dtos.TimeRange, string, error) {
+	closures, err := s.repository.FindByAssistantAndDate(assistantID, day)
+	if err != nil {
+		return nil, "", fmt.Errorf("error finding closures: %v", err)
+	}
+
+	var ranges []dtos.TimeRange
+	reason := ""
+	for _, closure := range closures {
+		closed, ok := closure.ClosedRangeOn(day)
+		if !ok {
+			continue
+		}
+		ranges = append(ranges, closed)
+		if reason == "" {
+			reason = closure.Reason
+		}
+	}
+	return ranges, reason, nil
+}
@@ -3,14 +3,14 @@ package services
 import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type ConfigurationsService struct {
-	repository *postgres_client.ConfigurationsRepository
+	repository repositories.ConfigurationsRepository
 }
 
-func NewConfigurationsService(repository *postgres_client.ConfigurationsRepository) *ConfigurationsService {
+func NewConfigurationsService(repository repositories.ConfigurationsRepository) *ConfigurationsService {
 	return &ConfigurationsService{repository: repository}
 }
 
@@ -0,0 +1,475 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidContactImport se devuelve cuando el archivo no se puede interpretar (el controller responde 400)
+var ErrInvalidContactImport = errors.New("archivo de contactos inválido")
+
+// Máximo de filas que se importan de un archivo
+const maxContactImportRows = 10000
+
+// importedContact es una fila del archivo (o una tarjeta del vCard) ya interpretada
+type importedContact struct {
+	Line         int
+	Phone        string
+	Name         string
+	Email        string
+	Notes        string
+	Tags         []string
+	CustomFields map[string]string
+}
+
+// Nombres de columna que se reconocen en el CSV (el resto se importa como campo personalizado)
+var contactImportColumns = map[string]string{
+	"phone": "phone", "telefono": "phone", "teléfono": "phone", "celular": "phone", "whatsapp": "phone", "number": "phone", "numero": "phone", "número": "phone",
+	"name": "name", "nombre": "name",
+	"email": "email", "e-mail": "email", "mail": "email", "correo": "email",
+	"tags": "tags", "etiquetas": "tags",
+	"notes": "notes", "notas": "notes",
+}
+
+// Columnas del export que no se importan (se calculan o las maneja WhatsApp)
+var contactExportOnlyColumns = map[string]bool{
+	"profile_name": true, "opted_out": true, "blocked": true, "last_interaction": true, "events": true, "created_at": true,
+}
+
+// ImportContacts carga los contactos de un archivo CSV o vCard en el número. Los números se normalizan a E.164
+// y los que ya existían (o estaban repetidos en el archivo) no se vuelven a crear; con UpdateExisting se les
+// completa el perfil. Las filas que no se pueden importar se devuelven en Rejected con el motivo.
+func (s *ContactsService) ImportContacts(numberPhoneID int64, reader io.Reader, options dtos.ContactImportOptionsDto) (dtos.ContactImportResultDto, error) {
+	result := dtos.ContactImportResultDto{}
+
+	exists, err := s.repository.DoesNumberPhoneExist(numberPhoneID)
+	if err != nil {
+		return result, err
+	}
+	if !exists {
+		return result, gorm.ErrRecordNotFound
+	}
+
+	var rows []importedContact
+	switch options.Format {
+	case dtos.ContactImportVCard:
+		rows, err = parseVCardContacts(reader)
+	default:
+		rows, err = parseCSVContacts(reader)
+	}
+	if err != nil {
+		return result, err
+	}
+	if len(rows) > maxContactImportRows {
+		return result, fmt.Errorf("%w: el archivo no debe superar los %d contactos", ErrInvalidContactImport, maxContactImportRows)
+	}
+	result.Total = len(rows)
+
+	// Validar cada fila y descartar los números repetidos dentro del archivo
+	valid := make([]importedContact, 0, len(rows))
+	numbers := make([]string, 0, len(rows))
+	byNumber := make(map[string]importedContact, len(rows))
+	for _, row := range rows {
+		number, err := normalizeImportedPhone(row.Phone, options.DefaultCountryCode)
+		if err == nil {
+			err = validateImportedContact(&row)
+		}
+		if err != nil {
+			result.Rejected = append(result.Rejected, dtos.ContactImportRejectionDto{Line: row.Line, Phone: row.Phone, Reason: err.Error()})
+			continue
+		}
+		if _, repeated := byNumber[number]; repeated {
+			result.Duplicates++
+			continue
+		}
+		byNumber[number] = row
+		numbers = append(numbers, number)
+		valid = append(valid, row)
+	}
+
+	existing, err := s.repository.FindByNumbers(numberPhoneID, numbers)
+	if err != nil {
+		return result, err
+	}
+	existingByNumber := make(map[string]entities.Contact, len(existing))
+	for _, contact := range existing {
+		existingByNumber[contact.NumberPhone] = contact
+	}
+
+	var records []entities.Contact
+	for i, number := range numbers {
+		row := valid[i]
+		contact, found := existingByNumber[number]
+		if !found {
+			records = append(records, importedContactToEntity(numberPhoneID, number, row))
+			continue
+		}
+		if !options.UpdateExisting {
+			result.Duplicates++
+			continue
+		}
+		updated, err := s.mergeImportedContact(contact, row)
+		if err != nil {
+			return result, err
+		}
+		if updated {
+			result.Updated++
+		} else {
+			result.Duplicates++
+		}
+	}
+
+	if err := s.repository.CreateMany(numberPhoneID, records); err != nil {
+		return result, err
+	}
+	result.Created = len(records)
+	return result, nil
+}
+
+// mergeImportedContact completa el perfil de un contacto que ya existía sin pisar lo que ya tenía cargado:
+// nombre, email y notas solo si estaban vacíos, las etiquetas se suman y los campos personalizados que faltan se agregan
+func (s *ContactsService) mergeImportedContact(contact entities.Contact, row importedContact) (bool, error) {
+	values := map[string]interface{}{}
+	if contact.Name == "" && row.Name != "" {
+		values["name"] = row.Name
+	}
+	if contact.Email == "" && row.Email != "" {
+		values["email"] = row.Email
+	}
+	if contact.Notes == "" && row.Notes != "" {
+		values["notes"] = row.Notes
+	}
+
+	fields := contact.CustomFieldValues()
+	fieldsChanged := false
+	for key, value := range row.CustomFields {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+			fieldsChanged = true
+		}
+	}
+	if fieldsChanged {
+		values["custom_fields"] = entities.EncodeCustomFields(fields)
+	}
+	if err := s.repository.UpdateProfile(contact.ID, values); err != nil {
+		return false, err
+	}
+
+	tags := contact.TagNames()
+	merged, _ := dtos.NormalizeTags(append(tags, row.Tags...))
+	if len(merged) != len(tags) {
+		if err := s.repository.ReplaceTags(contact.ID, merged); err != nil {
+			return false, err
+		}
+	}
+	return len(values) > 0 || len(merged) != len(tags), nil
+}
+
+// ExportContacts arma un CSV con los contactos del número que cumplen el filtro, sus etiquetas, la última vez
+// que escribieron y cuántos turnos tienen. Los campos personalizados van en una columna cada uno, así el mismo
+// archivo se puede volver a importar.
+func (s *ContactsService) ExportContacts(numberPhoneID int64, filter dtos.ContactFilterDto) ([]byte, error) {
+	exists, err := s.repository.DoesNumberPhoneExist(numberPhoneID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	contacts, err := s.repository.ListByNumberPhone(numberPhoneID, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(contacts))
+	for i, contact := range contacts {
+		ids[i] = contact.ID
+	}
+	lastInteractions, err := s.repository.LastInteractions(ids)
+	if err != nil {
+		return nil, err
+	}
+	eventCounts, err := s.repository.EventCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columnas de los campos personalizados: todos los que usa algún contacto, en orden alfabético
+	fieldSet := map[string]bool{}
+	fieldsByContact := make([]map[string]string, len(contacts))
+	for i, contact := range contacts {
+		fieldsByContact[i] = contact.CustomFieldValues()
+		for key := range fieldsByContact[i] {
+			fieldSet[key] = true
+		}
+	}
+	var fieldColumns []string
+	for key := range fieldSet {
+		if contactImportColumns[key] == "" && !contactExportOnlyColumns[key] {
+			fieldColumns = append(fieldColumns, key)
+		}
+	}
+	sort.Strings(fieldColumns)
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	header := []string{"phone", "name", "profile_name", "email", "tags", "notes", "opted_out", "blocked", "last_interaction", "events", "created_at"}
+	if err := writer.Write(append(header, fieldColumns...)); err != nil {
+		return nil, err
+	}
+	for i, contact := range contacts {
+		lastInteraction := ""
+		if at, ok := lastInteractions[contact.ID]; ok {
+			lastInteraction = at.Format(time.RFC3339)
+		}
+		record := []string{
+			contact.NumberPhone,
+			contact.Name,
+			contact.ProfileName,
+			contact.Email,
+			strings.Join(contact.TagNames(), "|"),
+			contact.Notes,
+			strconv.FormatBool(contact.OptedOutAt != nil),
+			strconv.FormatBool(contact.IsBlocked),
+			lastInteraction,
+			strconv.FormatInt(eventCounts[contact.ID], 10),
+			contact.CreatedAt.Format(time.RFC3339),
+		}
+		for _, key := range fieldColumns {
+			record = append(record, fieldsByContact[i][key])
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// parseCSVContacts lee un CSV con encabezado. Acepta coma o punto y coma como separador (Excel en español usa
+// punto y coma); la única columna obligatoria es la del teléfono. Las etiquetas se separan con | o ;.
+func parseCSVContacts(reader io.Reader) ([]importedContact, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")) // BOM que agrega Excel
+
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	csvReader := csv.NewReader(bytes.NewReader(content))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		csvReader.Comma = ';'
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: el archivo está vacío", ErrInvalidContactImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContactImport, err)
+	}
+
+	columns := make([]string, len(header))
+	hasPhone := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if known, ok := contactImportColumns[name]; ok {
+			columns[i] = known
+			hasPhone = hasPhone || known == "phone"
+		} else if !contactExportOnlyColumns[name] {
+			columns[i] = "field:" + name
+		}
+	}
+	if !hasPhone {
+		return nil, fmt.Errorf("%w: falta la columna del teléfono (phone o telefono)", ErrInvalidContactImport)
+	}
+
+	var rows []importedContact
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: línea %d: %v", ErrInvalidContactImport, line, err)
+		}
+
+		row := importedContact{Line: line, CustomFields: map[string]string{}}
+		empty := true
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			empty = empty && value == ""
+			switch column := columns[i]; {
+			case column == "phone":
+				row.Phone = value
+			case column == "name":
+				row.Name = value
+			case column == "email":
+				row.Email = value
+			case column == "notes":
+				row.Notes = value
+			case column == "tags":
+				row.Tags = strings.FieldsFunc(value, func(r rune) bool { return r == '|' || r == ';' || r == ',' })
+			case strings.HasPrefix(column, "field:") && value != "":
+				row.CustomFields[strings.TrimPrefix(column, "field:")] = value
+			}
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+		if len(rows) > maxContactImportRows {
+			break
+		}
+	}
+	return rows, nil
+}
+
+// parseVCardContacts lee las tarjetas BEGIN:VCARD/END:VCARD de un archivo .vcf (versiones 2.1, 3.0 y 4.0).
+// Se toma el primer teléfono móvil (o el primero que haya), FN (o N), el primer EMAIL, NOTE y CATEGORIES como etiquetas.
+func parseVCardContacts(reader io.Reader) ([]importedContact, error) {
+	// vCard usa el mismo formato de líneas que iCal (plegado, NOMBRE;PARAM=VALOR:valor)
+	lines, err := unfoldICalLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importedContact
+	var card *importedContact
+	var mobile string
+	foundCard := false
+	for i, line := range lines {
+		switch {
+		case strings.EqualFold(line, "BEGIN:VCARD"):
+			foundCard = true
+			card = &importedContact{Line: len(rows) + 1, CustomFields: map[string]string{}}
+			mobile = ""
+		case strings.EqualFold(line, "END:VCARD"):
+			if card == nil {
+				continue
+			}
+			if mobile != "" {
+				card.Phone = mobile
+			}
+			rows = append(rows, *card)
+			card = nil
+			if len(rows) > maxContactImportRows {
+				return rows, nil
+			}
+		case card != nil:
+			name, property, ok := parseICalLine(line)
+			if !ok {
+				continue
+			}
+			// Las propiedades agrupadas vienen como "item1.TEL"
+			if _, after, found := strings.Cut(name, "."); found {
+				name = after
+			}
+			value := unescapeICalText(property.Value)
+			switch name {
+			case "TEL":
+				value = strings.TrimPrefix(value, "tel:")
+				if card.Phone == "" {
+					card.Phone = value
+				}
+				isMobile := strings.Contains(strings.ToUpper(property.Params["TYPE"]), "CELL") || strings.Contains(strings.ToUpper(lines[i]), ";CELL")
+				if mobile == "" && isMobile {
+					mobile = value
+				}
+			case "FN":
+				card.Name = value
+			case "N":
+				if card.Name == "" {
+					// N:Apellido;Nombre;Segundo nombre;Prefijo;Sufijo
+					parts := strings.Split(property.Value, ";")
+					if len(parts) > 1 {
+						card.Name = strings.TrimSpace(unescapeICalText(parts[1]) + " " + unescapeICalText(parts[0]))
+					} else {
+						card.Name = value
+					}
+				}
+			case "EMAIL":
+				if card.Email == "" {
+					card.Email = value
+				}
+			case "NOTE":
+				card.Notes = value
+			case "CATEGORIES":
+				card.Tags = strings.Split(property.Value, ",")
+			case "ORG":
+				card.CustomFields["empresa"] = strings.TrimSpace(strings.ReplaceAll(value, ";", " "))
+			}
+		}
+	}
+	if !foundCard {
+		return nil, fmt.Errorf("%w: el archivo no tiene ninguna tarjeta BEGIN:VCARD", ErrInvalidContactImport)
+	}
+	return rows, nil
+}
+
+// normalizeImportedPhone convierte el número a E.164 para que coincida con los contactos que ya escribieron por
+// WhatsApp. Los números sin código de país se toman del país por defecto y se asume que son celulares (ver
+// phone.ParseMobile).
+func normalizeImportedPhone(raw, countryCode string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", errors.New("falta el teléfono")
+	}
+	number, err := phone.NormalizeMobile(raw, countryCode)
+	if err != nil {
+		return "", fmt.Errorf("teléfono inválido: %q", raw)
+	}
+	return number, nil
+}
+
+// validateImportedContact normaliza y valida los datos del perfil de la fila
+func validateImportedContact(row *importedContact) error {
+	row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+	if row.Email != "" && !dtos.IsValidEmail(row.Email) {
+		return fmt.Errorf("email inválido: %q", row.Email)
+	}
+	if utf8.RuneCountInString(row.Name) > 255 {
+		return errors.New("el nombre excede los 255 caracteres")
+	}
+
+	var err error
+	if row.Tags, err = dtos.NormalizeTags(row.Tags); err != nil {
+		return err
+	}
+	if row.CustomFields, err = dtos.NormalizeCustomFields(row.CustomFields); err != nil {
+		return err
+	}
+	return nil
+}
+
+func importedContactToEntity(numberPhoneID int64, number string, row importedContact) entities.Contact {
+	contact := entities.Contact{
+		NumberPhonesID: numberPhoneID,
+		NumberPhone:    number,
+		Name:           row.Name,
+		Email:          row.Email,
+		Notes:          row.Notes,
+		CustomFields:   entities.EncodeCustomFields(row.CustomFields),
+	}
+	for _, tag := range row.Tags {
+		contact.Tags = append(contact.Tags, entities.ContactTag{Tag: tag})
+	}
+	return contact
+}
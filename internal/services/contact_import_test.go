@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeImportedPhone(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{"+54 9 351 555-1234", "+5493515551234"},
+		{"0054 9 351 5551234", "+5493515551234"},
+		{"5493515551234", "+5493515551234"},
+		{"(0351) 15 555-1234", "+5493515551234"},
+		{"+1 (415) 555-0100", "+14155550100"},
+	}
+	for _, c := range cases {
+		got, err := normalizeImportedPhone(c.raw, "54")
+		if err != nil || got != c.want {
+			t.Errorf("normalizeImportedPhone(%q) = %q, %v; want %q", c.raw, got, err, c.want)
+		}
+	}
+
+	for _, raw := range []string{"", "123", "+54 351 abc", "+1234567890123456"} {
+		if got, err := normalizeImportedPhone(raw, "54"); err == nil {
+			t.Errorf("normalizeImportedPhone(%q) = %q, want error", raw, got)
+		}
+	}
+}
+
+func TestParseCSVContacts(t *testing.T) {
+	content := "\xef\xbb\xbfTeléfono;Nombre;Email;Etiquetas;Plan\n" +
+		"+5493515551234;Juana;juana@mail.com;vip|Clientes;oro\n" +
+		";;;;\n" +
+		"3515550000;Pedro;;;\n"
+
+	rows, err := parseCSVContacts(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseCSVContacts: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2 (the empty one is skipped): %+v", len(rows), rows)
+	}
+	first := rows[0]
+	if first.Line != 2 || first.Phone != "+5493515551234" || first.Name != "Juana" || first.Email != "juana@mail.com" {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"vip", "Clientes"}) || first.CustomFields["plan"] != "oro" {
+		t.Errorf("unexpected tags or custom fields: %+v", first)
+	}
+	if rows[1].Line != 4 || rows[1].Name != "Pedro" {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+
+	if _, err := parseCSVContacts(strings.NewReader("nombre,email\nJuana,juana@mail.com\n")); !errors.Is(err, ErrInvalidContactImport) {
+		t.Errorf("CSV without phone column: err = %v, want ErrInvalidContactImport", err)
+	}
+}
+
+func TestParseVCardContacts(t *testing.T) {
+	content := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Pérez;Juana;;;\r\nTEL;TYPE=HOME:+54 351 4000000\r\n" +
+		"item1.TEL;TYPE=CELL:+54 9 351 555\r\n 1234\r\nEMAIL;TYPE=INTERNET:juana@mail.com\r\n" +
+		"CATEGORIES:vip,clientes\r\nNOTE:Prefiere turnos\\, a la tarde\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:2.1\r\nFN:Pedro\r\nTEL;CELL:3515550000\r\nEND:VCARD\r\n"
+
+	rows, err := parseVCardContacts(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseVCardContacts: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d cards, want 2", len(rows))
+	}
+	juana := rows[0]
+	if juana.Name != "Juana Pérez" || juana.Phone != "+54 9 351 5551234" || juana.Email != "juana@mail.com" {
+		t.Errorf("unexpected first card: %+v", juana)
+	}
+	if juana.Notes != "Prefiere turnos, a la tarde" || !reflect.DeepEqual(juana.Tags, []string{"vip", "clientes"}) {
+		t.Errorf("unexpected notes or tags: %+v", juana)
+	}
+	if rows[1].Name != "Pedro" || rows[1].Phone != "3515550000" {
+		t.Errorf("unexpected second card: %+v", rows[1])
+	}
+
+	if _, err := parseVCardContacts(strings.NewReader("nombre,telefono\n")); !errors.Is(err, ErrInvalidContactImport) {
+		t.Errorf("file without cards: err = %v, want ErrInvalidContactImport", err)
+	}
+}
+
+func TestContactsImportAndExport(t *testing.T) {
+	db, service := newContactsTest(t)
+
+	// El contacto 1 tiene un mensaje y dos turnos, que salen en el export
+	records := []interface{}{&entities.Message{NumberPhonesID: 1, ContactsID: 1, MessageText: "hola", MessageIdWhatsapp: "wamid.1", MessageType: "text"}}
+	for i := 1; i <= 2; i++ {
+		records = append(records, &entities.Events{Summary: "turno", Description: "turno", StartDate: fmt.Sprintf("2030-01-0%dT10:00:00", i), EndDate: fmt.Sprintf("2030-01-0%dT10:30:00", i),
+			CodeEvent: fmt.Sprintf("CODE%d", i), AssistantsID: 1, ContactsID: 1})
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+
+	// El contacto 1 ya existe (5493510000001): se completa su perfil; el número repetido y el inválido no se importan
+	content := "phone,name,email,tags,plan\n" +
+		"+5493510000001,Juana,juana@mail.com,vip,oro\n" +
+		"+54 9 351 555-1234,Pedro,,clientes|vip,\n" +
+		"5493515551234,Pedro otra vez,,,\n" +
+		"abc,Nadie,,,\n" +
+		"3515550000,Ana,no es un email,,\n"
+	result, err := service.ImportContacts(1, strings.NewReader(content), dtos.ContactImportOptionsDto{UpdateExisting: true})
+	if err != nil {
+		t.Fatalf("ImportContacts: %v", err)
+	}
+	if result.Total != 5 || result.Created != 1 || result.Updated != 1 || result.Duplicates != 1 ||
+		len(result.Rejected) != 2 || result.Rejected[0].Line != 5 || result.Rejected[1].Line != 6 {
+		t.Errorf("import result = %+v", result)
+	}
+
+	var pedro entities.Contact
+	if err := db.Preload("Tags").Where("number_phones_id = 1 AND number_phone = ?", "+5493515551234").First(&pedro).Error; err != nil {
+		t.Fatalf("imported contact not found: %v", err)
+	}
+	if pedro.Name != "Pedro" || len(pedro.Tags) != 2 {
+		t.Errorf("unexpected imported contact: %+v", pedro)
+	}
+
+	exported, err := service.ExportContacts(1, dtos.ContactFilterDto{Tag: "vip"})
+	if err != nil {
+		t.Fatalf("ExportContacts: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(exported))).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("unexpected export (%v):\n%s", err, exported)
+	}
+	if got := strings.Join(rows[0], ","); got != "phone,name,profile_name,email,tags,notes,opted_out,blocked,last_interaction,events,created_at,plan" {
+		t.Errorf("unexpected export header: %s", got)
+	}
+	contact1 := rows[1]
+	if contact1[0] != "+5493510000001" || contact1[1] != "Juana" || contact1[4] != "vip" || contact1[8] == "" || contact1[9] != "2" || contact1[11] != "oro" {
+		t.Errorf("unexpected exported contact 1: %v", contact1)
+	}
+
+	// A un número de otro bussiness no se puede importar
+	scoped := service.WithTenant(dtos.NewTenantScope(1, []int64{1}))
+	if _, err := scoped.ImportContacts(2, strings.NewReader(content), dtos.ContactImportOptionsDto{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("import into another bussiness = %v, want ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// Ventana por defecto en la que se agrupan los mensajes seguidos de un mismo contacto
+const defaultMessageDebounce = 3 * time.Second
+
+// mailboxMessage es un mensaje entrante ya procesado que espera su turno en el mailbox del contacto
+type mailboxMessage struct {
+	Contact     *entities.Contact
+	NumberPhone *entities.NumberPhone
+	Content     inboundContent
+	MessageID   string
+}
+
+type pendingMailboxMessage struct {
+	message mailboxMessage
+	done    chan error
+}
+
+type contactMailbox struct {
+	pending []pendingMailboxMessage
+	timer   *time.Timer
+	running bool
+}
+
+// contactMailboxes serializa el procesamiento de los mensajes de cada contacto y agrupa los que llegan
+// dentro de la ventana de debounce en un único lote, para que OpenAI haga un solo run por todos ellos.
+// Contactos distintos se procesan en paralelo.
+type contactMailboxes struct {
+	window time.Duration
+	flush  func(batch []mailboxMessage) error
+
+	mu    sync.Mutex
+	boxes map[int64]*contactMailbox
+}
+
+func newContactMailboxes(window time.Duration, flush func(batch []mailboxMessage) error) *contactMailboxes {
+	return &contactMailboxes{
+		window: window,
+		flush:  flush,
+		boxes:  make(map[int64]*contactMailbox),
+	}
+}
+
+// Submit agrega el mensaje al mailbox del contacto y vuelve enseguida, sin esperar la ventana ni el lote.
+// El canal recibe el error del lote que incluye al mensaje, así quien lo llamó (el job del webhook) puede reintentar.
+func (m *contactMailboxes) Submit(message mailboxMessage) <-chan error {
+	key := message.Contact.ID
+	item := pendingMailboxMessage{message: message, done: make(chan error, 1)}
+
+	m.mu.Lock()
+	box, ok := m.boxes[key]
+	if !ok {
+		box = &contactMailbox{}
+		m.boxes[key] = box
+	}
+	box.pending = append(box.pending, item)
+
+	// Si hay un lote en curso, el mensaje se procesa cuando termine. Si no, cada mensaje nuevo reinicia la ventana.
+	if !box.running {
+		if box.timer != nil {
+			box.timer.Stop()
+		}
+		box.timer = time.AfterFunc(m.window, func() { m.run(key) })
+	}
+	m.mu.Unlock()
+
+	return item.done
+}
+
+// run procesa los lotes pendientes del contacto hasta vaciar su mailbox
+func (m *contactMailboxes) run(key int64) {
+	m.mu.Lock()
+	box, ok := m.boxes[key]
+	if !ok || box.running || len(box.pending) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	box.running = true
+	box.timer = nil
+
+	for {
+		batch := box.pending
+		box.pending = nil
+		m.mu.Unlock()
+
+		// Si Meta reenvió el mismo mensaje mientras esperaba, se procesa una sola vez
+		messages := make([]mailboxMessage, 0, len(batch))
+		seen := make(map[string]bool, len(batch))
+		for _, item := range batch {
+			if item.message.MessageID != "" && seen[item.message.MessageID] {
+				continue
+			}
+			seen[item.message.MessageID] = true
+			messages = append(messages, item.message)
+		}
+		err := m.safeFlush(messages)
+		for _, item := range batch {
+			item.done <- err
+		}
+
+		// Los mensajes que llegaron durante el run se procesan a continuación en un nuevo lote
+		m.mu.Lock()
+		if len(box.pending) == 0 {
+			box.running = false
+			delete(m.boxes, key)
+			m.mu.Unlock()
+			return
+		}
+	}
+}
+
+// safeFlush evita que un panic en el procesamiento deje esperando para siempre a los mensajes del lote
+func (m *contactMailboxes) safeFlush(batch []mailboxMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic processing messages: %v", r)
+		}
+	}()
+	return m.flush(batch)
+}
+
+// joinMailboxText une los textos de un lote en un único mensaje para el assistant
+func joinMailboxText(batch []mailboxMessage) string {
+	texts := make([]string, 0, len(batch))
+	for _, message := range batch {
+		texts = append(texts, message.Content.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// waitMailboxes espera a que terminen los lotes de los mensajes enviados con Submit y devuelve el primer error
+func waitMailboxes(pending []<-chan error) error {
+	var firstErr error
+	for _, done := range pending {
+		if err := <-done; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
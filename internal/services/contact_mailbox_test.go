@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openairuns"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// fakeOpenAIClient simula los runs de OpenAI sobre un thread y detecta si se crean runs superpuestos
+type fakeOpenAIClient struct {
+	runDuration time.Duration
+	failWith    error
+
+	mu       sync.Mutex
+	active   map[string]bool
+	overlaps int
+	runs     int
+	messages map[string][]string
+	maxAlive int
+	alive    int
+}
+
+func newFakeOpenAIClient(runDuration time.Duration) *fakeOpenAIClient {
+	return &fakeOpenAIClient{
+		runDuration: runDuration,
+		active:      make(map[string]bool),
+		messages:    make(map[string][]string),
+	}
+}
+
+func (f *fakeOpenAIClient) ListRunsForThread(ctx context.Context, threadID string, limit int, order, after, before string) ([]openairuns.OpenAIRunResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeOpenAIClient) SendMessageToThread(ctx context.Context, threadID, message string, user bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages[threadID] = append(f.messages[threadID], message)
+	return nil
+}
+
+func (f *fakeOpenAIClient) CreateRunForThreadWithConversation(ctx context.Context, threadID, assistantID string, conversation []map[string]interface{}) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active[threadID] {
+		f.overlaps++
+	}
+	f.active[threadID] = true
+	f.runs++
+	f.alive++
+	if f.alive > f.maxAlive {
+		f.maxAlive = f.alive
+	}
+	return fmt.Sprintf("run_%d", f.runs), nil
+}
+
+func (f *fakeOpenAIClient) WaitForRunCompletion(ctx context.Context, threadID, runID string, maxRetries int, retryInterval time.Duration) (openairuns.OpenAIRunResponse, error) {
+	time.Sleep(f.runDuration)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.active[threadID] = false
+	f.alive--
+	return openairuns.OpenAIRunResponse{ID: runID, ThreadID: threadID, Status: "completed"}, f.failWith
+}
+
+func (f *fakeOpenAIClient) GetMessagesFromThread(ctx context.Context, threadID string) (string, error) {
+	return "respuesta", nil
+}
+
+func (f *fakeOpenAIClient) EnviarToolOutputs(ctx context.Context, threadID, runID string, toolOutputs []openairuns.OpenAIToolOutput) error {
+	return nil
+}
+
+func (f *fakeOpenAIClient) sentMessages(threadID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.messages[threadID]...)
+}
+
+// newTestMailboxes arma los mailboxes con un flush que corre el assistant sobre el thread del contacto usando el fake
+func newTestMailboxes(window time.Duration, client *fakeOpenAIClient) *contactMailboxes {
+	provider := &OpenAIAssistantsProvider{client: client}
+	return newContactMailboxes(window, func(batch []mailboxMessage) error {
+		threadID := fmt.Sprintf("thread_%d", batch[0].Contact.ID)
+		_, err := provider.runThread(context.Background(), threadID, "asst_test", joinMailboxText(batch), nil)
+		return err
+	})
+}
+
+func testMessage(contactID int64, messageID, text string) mailboxMessage {
+	return mailboxMessage{
+		Contact:     &entities.Contact{ID: contactID},
+		NumberPhone: &entities.NumberPhone{ID: 1},
+		Content:     inboundContent{Text: text, MessageType: "text"},
+		MessageID:   messageID,
+	}
+}
+
+// submitAll envía los mensajes con una pausa entre cada uno y devuelve el error del lote de cada uno
+func submitAll(mailboxes *contactMailboxes, gap time.Duration, messages ...mailboxMessage) []error {
+	pending := make([]<-chan error, len(messages))
+	for i, message := range messages {
+		pending[i] = mailboxes.Submit(message)
+		time.Sleep(gap)
+	}
+	errs := make([]error, len(messages))
+	for i, done := range pending {
+		errs[i] = <-done
+	}
+	return errs
+}
+
+// Submit no espera la ventana ni el run: el worker de la cola queda libre para otros jobs
+func TestMailboxSubmitNoBloquea(t *testing.T) {
+	client := newFakeOpenAIClient(50 * time.Millisecond)
+	mailboxes := newTestMailboxes(100*time.Millisecond, client)
+
+	start := time.Now()
+	done := mailboxes.Submit(testMessage(1, "wamid.1", "hola"))
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Submit tardó %v, se esperaba que vuelva enseguida", elapsed)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("el lote terminó antes de la ventana de debounce: %v", err)
+	default:
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("lote: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("el lote terminó a los %v, antes de la ventana más el run", elapsed)
+	}
+}
+
+func TestMailboxAgrupaMensajesSeguidos(t *testing.T) {
+	client := newFakeOpenAIClient(10 * time.Millisecond)
+	mailboxes := newTestMailboxes(80*time.Millisecond, client)
+
+	errs := submitAll(mailboxes, 10*time.Millisecond,
+		testMessage(1, "wamid.1", "hola"),
+		testMessage(1, "wamid.2", "quiero un turno"),
+		testMessage(1, "wamid.3", "para el jueves"),
+	)
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Submit %d: %v", i, err)
+		}
+	}
+
+	sent := client.sentMessages("thread_1")
+	if len(sent) != 1 {
+		t.Fatalf("mensajes enviados a OpenAI = %d, want 1: %q", len(sent), sent)
+	}
+	if want := "hola\nquiero un turno\npara el jueves"; sent[0] != want {
+		t.Errorf("mensaje = %q, want %q", sent[0], want)
+	}
+	if client.runs != 1 {
+		t.Errorf("runs = %d, want 1", client.runs)
+	}
+}
+
+func TestMailboxSerializaRunsDelMismoContacto(t *testing.T) {
+	client := newFakeOpenAIClient(200 * time.Millisecond)
+	mailboxes := newTestMailboxes(20*time.Millisecond, client)
+
+	// El segundo y el tercer mensaje llegan mientras el primer run sigue activo
+	errs := submitAll(mailboxes, 40*time.Millisecond,
+		testMessage(1, "wamid.1", "hola"),
+		testMessage(1, "wamid.2", "sigo acá"),
+		testMessage(1, "wamid.3", "?"),
+	)
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Submit %d: %v", i, err)
+		}
+	}
+
+	if client.overlaps != 0 {
+		t.Errorf("se crearon %d runs superpuestos en el mismo thread", client.overlaps)
+	}
+	sent := client.sentMessages("thread_1")
+	if len(sent) != 2 {
+		t.Fatalf("mensajes enviados a OpenAI = %d, want 2: %q", len(sent), sent)
+	}
+	if want := "sigo acá\n?"; sent[1] != want {
+		t.Errorf("segundo lote = %q, want %q", sent[1], want)
+	}
+}
+
+func TestMailboxContactosDistintosEnParalelo(t *testing.T) {
+	client := newFakeOpenAIClient(100 * time.Millisecond)
+	mailboxes := newTestMailboxes(10*time.Millisecond, client)
+
+	start := time.Now()
+	errs := submitAll(mailboxes, 0,
+		testMessage(1, "wamid.1", "hola"),
+		testMessage(2, "wamid.2", "buenas"),
+		testMessage(3, "wamid.3", "qué tal"),
+	)
+	elapsed := time.Since(start)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Submit %d: %v", i, err)
+		}
+	}
+	if client.maxAlive < 2 {
+		t.Errorf("runs simultáneos = %d, se esperaba que los contactos no se bloqueen entre sí", client.maxAlive)
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Errorf("los tres contactos tardaron %v, parece que se procesaron en serie", elapsed)
+	}
+}
+
+func TestMailboxPropagaErrorATodoElLote(t *testing.T) {
+	client := newFakeOpenAIClient(5 * time.Millisecond)
+	client.failWith = errors.New("openai timeout")
+	mailboxes := newTestMailboxes(50*time.Millisecond, client)
+
+	errs := submitAll(mailboxes, 5*time.Millisecond,
+		testMessage(1, "wamid.1", "hola"),
+		testMessage(1, "wamid.2", "?"),
+	)
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("Submit %d: se esperaba el error del run", i)
+		}
+	}
+}
+
+func TestMailboxIgnoraMensajeDuplicado(t *testing.T) {
+	client := newFakeOpenAIClient(5 * time.Millisecond)
+	mailboxes := newTestMailboxes(50*time.Millisecond, client)
+
+	submitAll(mailboxes, 5*time.Millisecond,
+		testMessage(1, "wamid.1", "hola"),
+		testMessage(1, "wamid.1", "hola"),
+	)
+
+	sent := client.sentMessages("thread_1")
+	if len(sent) != 1 || sent[0] != "hola" {
+		t.Errorf("mensajes enviados a OpenAI = %q, want [\"hola\"]", sent)
+	}
+}
+
+func TestMailboxFlushConPanic(t *testing.T) {
+	mailboxes := newContactMailboxes(time.Millisecond, func(batch []mailboxMessage) error {
+		panic("boom")
+	})
+
+	done := mailboxes.Submit(testMessage(1, "wamid.1", "hola"))
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("se esperaba un error por el panic")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("el lote quedó esperando luego de un panic")
+	}
+}
@@ -2,20 +2,26 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type ContactsService struct {
-	repository *postgres_client.ContactsRepository
+	repository repositories.ContactsRepository
 }
 
-func NewContactsService(repository *postgres_client.ContactsRepository) *ContactsService {
+func NewContactsService(repository repositories.ContactsRepository) *ContactsService {
 	return &ContactsService{repository: repository}
 }
 
+// WithTenant devuelve una copia del servicio que solo accede a los contactos de los números del scope
+func (s *ContactsService) WithTenant(tenant dtos.TenantScope) *ContactsService {
+	return &ContactsService{repository: s.repository.WithTenant(tenant)}
+}
+
 func (s *ContactsService) GetAll() ([]dtos.ContactDto, error) {
 	records, err := s.repository.List()
 	if err != nil {
@@ -38,7 +44,7 @@ func (s *ContactsService) GetById(id int64) (dtos.ContactDto, error) {
 	return entities.MapEntityToContactDto(record), nil
 }
 
-func (s *ContactsService) GetByPhoneNumber(phoneNumber int64) (dtos.ContactDto, error) {
+func (s *ContactsService) GetByPhoneNumber(phoneNumber string) (dtos.ContactDto, error) {
 	record, err := s.repository.FindByPhoneNumber(phoneNumber)
 	if err != nil {
 		return dtos.ContactDto{}, err
@@ -60,8 +66,8 @@ func (s *ContactsService) Delete(id string) error {
 	return s.repository.Delete(id)
 }
 
-func (s *ContactsService) GetContactsByNumberPhone(numberPhoneID int64, page int, limit int) ([]dtos.ContactDto, int, error) {
-	contacts, total, err := s.repository.GetContactsByNumberPhone(numberPhoneID, page, limit)
+func (s *ContactsService) GetContactsByNumberPhone(numberPhoneID int64, filter dtos.ContactFilterDto, page int, limit int) ([]dtos.ContactDto, int, error) {
+	contacts, total, err := s.repository.GetContactsByNumberPhone(numberPhoneID, filter, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -74,6 +80,11 @@ func (s *ContactsService) GetContactsByNumberPhone(numberPhoneID int64, page int
 	return dtos, total, nil
 }
 
+// DoesNumberPhoneExist verifica que el número exista (y sea del scope)
+func (s *ContactsService) DoesNumberPhoneExist(numberPhoneID int64) (bool, error) {
+	return s.repository.DoesNumberPhoneExist(numberPhoneID)
+}
+
 func (s *ContactsService) UpdateIsBlocked(contactID int64, numberPhoneID int64, isBlocked bool) error {
 	contact, err := s.repository.FindByID(contactID)
 	if err != nil {
@@ -85,3 +96,61 @@ func (s *ContactsService) UpdateIsBlocked(contactID int64, numberPhoneID int64,
 	// Llamar al repositorio para actualizar el campo IsBlocked
 	return s.repository.UpdateIsBlocked(contactID, isBlocked)
 }
+
+// SetTags reemplaza las etiquetas del contacto
+func (s *ContactsService) SetTags(contactID int64, request dtos.ContactTagsDto) (dtos.ContactDto, error) {
+	if err := s.repository.ReplaceTags(contactID, request.Tags); err != nil {
+		return dtos.ContactDto{}, err
+	}
+	return s.GetById(contactID)
+}
+
+// SetOptOut da de baja al contacto de las campañas o lo vuelve a suscribir
+func (s *ContactsService) SetOptOut(contactID int64, optedOut bool) (dtos.ContactDto, error) {
+	var optedOutAt *time.Time
+	if optedOut {
+		now := time.Now()
+		optedOutAt = &now
+	}
+	if err := s.repository.UpdateOptOut(contactID, optedOutAt); err != nil {
+		return dtos.ContactDto{}, err
+	}
+	return s.GetById(contactID)
+}
+
+// UpdateProfile edita el perfil del contacto; solo cambia los campos que vienen en el request
+func (s *ContactsService) UpdateProfile(contactID int64, request dtos.ContactProfileDto) (dtos.ContactDto, error) {
+	values := map[string]interface{}{}
+	if request.Name != nil {
+		values["name"] = *request.Name
+	}
+	if request.Email != nil {
+		values["email"] = *request.Email
+	}
+	if request.Notes != nil {
+		values["notes"] = *request.Notes
+	}
+	if request.CustomFields != nil {
+		values["custom_fields"] = entities.EncodeCustomFields(*request.CustomFields)
+	}
+	if err := s.repository.UpdateProfile(contactID, values); err != nil {
+		return dtos.ContactDto{}, err
+	}
+
+	if request.Tags != nil {
+		if err := s.repository.ReplaceTags(contactID, *request.Tags); err != nil {
+			return dtos.ContactDto{}, err
+		}
+	}
+	if request.OptedOut != nil {
+		contact, err := s.repository.FindByID(contactID)
+		if err != nil {
+			return dtos.ContactDto{}, err
+		}
+		// Solo se toca si cambia, para no pisar la fecha de la baja (o del alta) original
+		if *request.OptedOut != (contact.OptedOutAt != nil) {
+			return s.SetOptOut(contactID, *request.OptedOut)
+		}
+	}
+	return s.GetById(contactID)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"gorm.io/gorm"
+)
+
+// newContactsTest carga dos bussiness con un número y un contacto cada uno, todos con el mismo ID que el bussiness
+func newContactsTest(t *testing.T) (*gorm.DB, *ContactsService) {
+	t.Helper()
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	for id := int64(1); id <= 2; id++ {
+		records := []interface{}{
+			&entities.Bussines{ID: id, Name: "Consultorio", Address: "calle 123"},
+			&entities.Assistant{ID: id, BussinessID: id, Name: "Consultorio", EventType: "turno", Active: true},
+			&entities.NumberPhone{ID: id, AssistantsID: id, NumberPhone: fmt.Sprintf("+%d", 5491100000000+id), UUID: fmt.Sprintf("uuid-%d", id),
+				TokenPermanent: fmt.Sprintf("token-%d", id), WhatsappNumberPhoneId: 100 + id},
+			&entities.Contact{ID: id, NumberPhonesID: id, NumberPhone: fmt.Sprintf("+%d", 5493510000000+id)},
+		}
+		for _, record := range records {
+			if err := db.Create(record).Error; err != nil {
+				t.Fatalf("seeding %T: %v", record, err)
+			}
+		}
+	}
+	return db, NewContactsService(sqlite_client.NewRepositories(db).Contacts)
+}
+
+func TestContactsSearchAndProfile(t *testing.T) {
+	_, service := newContactsTest(t)
+
+	name, email := "Juana Pérez", " Juana@Mail.com "
+	tags := []string{"VIP"}
+	fields := map[string]string{"Plan": "oro", "vacio": ""}
+	request := dtos.ContactProfileDto{Name: &name, Email: &email, Tags: &tags, CustomFields: &fields}
+	if err := request.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	contact, err := service.UpdateProfile(1, request)
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if contact.DisplayName != "Juana Pérez" || contact.Email != "juana@mail.com" || !reflect.DeepEqual(contact.Tags, []string{"vip"}) ||
+		!reflect.DeepEqual(contact.CustomFields, map[string]string{"plan": "oro"}) {
+		t.Errorf("contact = %+v, want the profile normalized", contact)
+	}
+
+	invalid := "no es un email"
+	if err := (&dtos.ContactProfileDto{Email: &invalid}).Validate(); err == nil {
+		t.Error("Validate with invalid email = nil, want an error")
+	}
+
+	optedOut := true
+	searches := []struct {
+		filter dtos.ContactFilterDto
+		found  bool
+	}{
+		{dtos.ContactFilterDto{Search: "juana"}, true},
+		{dtos.ContactFilterDto{Search: "mail.com"}, true},
+		{dtos.ContactFilterDto{Search: "5493510000001"}, true},
+		{dtos.ContactFilterDto{Search: "pedro"}, false},
+		{dtos.ContactFilterDto{Tag: "vip"}, true},
+		{dtos.ContactFilterDto{Tag: "otro"}, false},
+		{dtos.ContactFilterDto{OptedOut: &optedOut}, false},
+	}
+	for _, search := range searches {
+		contacts, _, err := service.GetContactsByNumberPhone(1, search.filter, 1, 10)
+		if err != nil {
+			t.Errorf("GetContactsByNumberPhone(%+v): %v", search.filter, err)
+			continue
+		}
+		if found := len(contacts) == 1 && contacts[0].ID == 1; found != search.found {
+			t.Errorf("GetContactsByNumberPhone(%+v) = %+v, want found %v", search.filter, contacts, search.found)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+)
+
+const (
+	// Eventos que se encolan por conexión antes de considerarla trabada y cerrarla (STREAM_BUFFER_SIZE)
+	defaultStreamBuffer = 256
+	// Máximo de mensajes que se reenvían al reconectar (STREAM_BACKFILL_LIMIT); si faltan más se manda un reset
+	defaultStreamBackfill = 500
+)
+
+// ConversationStream reparte a las conexiones del dashboard los mensajes nuevos, los estados y los cambios
+// de handoff. Cada conexión solo recibe los eventos de los números de sus bussiness.
+type ConversationStream struct {
+	messagesRepository     repositories.MessagesRepository
+	numberPhonesRepository repositories.NumberPhonesRepository
+	bufferSize             int
+	backfillLimit          int
+
+	mu          sync.Mutex
+	subscribers map[*StreamSubscription]struct{}
+}
+
+// StreamSubscription es una conexión al stream. Events se cierra si la conexión no consume los eventos a
+// tiempo; el cliente reconecta y recupera los mensajes con el backfill.
+type StreamSubscription struct {
+	Events       chan dtos.StreamEventDto
+	numberPhones map[int64]bool // nil: todos los números
+}
+
+func NewConversationStream(messagesRepository repositories.MessagesRepository, numberPhonesRepository repositories.NumberPhonesRepository) *ConversationStream {
+	return &ConversationStream{
+		messagesRepository:     messagesRepository,
+		numberPhonesRepository: numberPhonesRepository,
+		bufferSize:             envPositiveInt("STREAM_BUFFER_SIZE", defaultStreamBuffer),
+		backfillLimit:          envPositiveInt("STREAM_BACKFILL_LIMIT", defaultStreamBackfill),
+		subscribers:            make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// Subscribe abre una conexión con los números del scope, o solo con numberPhoneID si es mayor a 0.
+// Devuelve gorm.ErrRecordNotFound si el número no existe o es de otro bussiness.
+func (s *ConversationStream) Subscribe(tenant dtos.TenantScope, numberPhoneID int64) (*StreamSubscription, error) {
+	var numberPhones map[int64]bool
+	repository := s.numberPhonesRepository.WithTenant(tenant)
+	switch {
+	case numberPhoneID > 0:
+		if _, err := repository.FindByID(strconv.FormatInt(numberPhoneID, 10)); err != nil {
+			return nil, err
+		}
+		numberPhones = map[int64]bool{numberPhoneID: true}
+	case tenant.Restricted:
+		// Los números que se agreguen después se ven al reconectar
+		records, err := repository.List()
+		if err != nil {
+			return nil, fmt.Errorf("error listing number phones: %v", err)
+		}
+		numberPhones = make(map[int64]bool, len(records))
+		for _, record := range records {
+			numberPhones[record.ID] = true
+		}
+	}
+
+	subscription := &StreamSubscription{
+		Events:       make(chan dtos.StreamEventDto, s.bufferSize),
+		numberPhones: numberPhones,
+	}
+	s.mu.Lock()
+	s.subscribers[subscription] = struct{}{}
+	s.mu.Unlock()
+	return subscription, nil
+}
+
+// Unsubscribe cierra la conexión. Se puede llamar aunque Publish ya la haya cerrado.
+func (s *ConversationStream) Unsubscribe(subscription *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[subscription]; ok {
+		delete(s.subscribers, subscription)
+		close(subscription.Events)
+	}
+}
+
+// Backfill devuelve los mensajes posteriores a afterMessageID que la conexión no recibió. Si son más que el
+// límite devuelve solo un evento reset para que el dashboard recargue las conversaciones por la API.
+func (s *ConversationStream) Backfill(tenant dtos.TenantScope, numberPhoneID, afterMessageID int64) ([]dtos.StreamEventDto, error) {
+	if afterMessageID <= 0 {
+		return nil, nil
+	}
+
+	messages, err := s.messagesRepository.WithTenant(tenant).FindAfterID(afterMessageID, numberPhoneID, s.backfillLimit+1)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching missed messages: %v", err)
+	}
+	if len(messages) > s.backfillLimit {
+		return []dtos.StreamEventDto{{Type: dtos.StreamEventReset, NumberPhoneID: numberPhoneID}}, nil
+	}
+
+	events := make([]dtos.StreamEventDto, len(messages))
+	for i, message := range messages {
+		events[i] = messageEvent(message)
+	}
+	return events, nil
+}
+
+// Publish envía el evento a las conexiones que ven el número. No bloquea: si una conexión tiene la cola
+// llena se la cierra.
+func (s *ConversationStream) Publish(event dtos.StreamEventDto) {
+	// Los servicios armados sin stream (procesos internos, tests) no publican nada
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for subscription := range s.subscribers {
+		if subscription.numberPhones != nil && !subscription.numberPhones[event.NumberPhoneID] {
+			continue
+		}
+		select {
+		case subscription.Events <- event:
+		default:
+			delete(s.subscribers, subscription)
+			close(subscription.Events)
+		}
+	}
+}
+
+// PublishMessage publica un mensaje recién guardado
+func (s *ConversationStream) PublishMessage(message entities.Message) {
+	s.Publish(messageEvent(message))
+}
+
+// PublishHandoff publica el modo de atención actual del contacto
+func (s *ConversationStream) PublishHandoff(contact entities.Contact) {
+	s.Publish(dtos.StreamEventDto{
+		Type:          dtos.StreamEventHandoff,
+		NumberPhoneID: contact.NumberPhonesID,
+		ContactID:     contact.ID,
+		Data:          entities.MapEntityToContactDto(contact).Handoff,
+	})
+}
+
+func messageEvent(message entities.Message) dtos.StreamEventDto {
+	return dtos.StreamEventDto{
+		Type:          dtos.StreamEventMessage,
+		NumberPhoneID: message.NumberPhonesID,
+		ContactID:     message.ContactsID,
+		MessageID:     message.ID,
+		Data:          entities.MapEntityToMessageDto(message),
+	}
+}
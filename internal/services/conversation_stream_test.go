@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// subscribeTo registra una conexión sin pasar por la base (numberPhones nil: todos los números)
+func subscribeTo(stream *ConversationStream, numberPhones map[int64]bool) *StreamSubscription {
+	subscription := &StreamSubscription{Events: make(chan dtos.StreamEventDto, stream.bufferSize), numberPhones: numberPhones}
+	stream.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+func TestConversationStreamFiltersByNumberPhone(t *testing.T) {
+	stream := NewConversationStream(nil, nil)
+	own := subscribeTo(stream, map[int64]bool{1: true})
+	admin := subscribeTo(stream, nil)
+
+	stream.PublishMessage(entities.Message{ID: 10, NumberPhonesID: 1, ContactsID: 5})
+	stream.PublishMessage(entities.Message{ID: 11, NumberPhonesID: 2, ContactsID: 6})
+
+	if len(own.Events) != 1 {
+		t.Fatalf("own subscription got %d events, want 1", len(own.Events))
+	}
+	if event := <-own.Events; event.MessageID != 10 || event.Type != dtos.StreamEventMessage {
+		t.Errorf("own subscription got %+v", event)
+	}
+	if len(admin.Events) != 2 {
+		t.Errorf("unrestricted subscription got %d events, want 2", len(admin.Events))
+	}
+}
+
+func TestConversationStreamClosesSlowSubscriber(t *testing.T) {
+	stream := NewConversationStream(nil, nil)
+	stream.bufferSize = 2
+	slow := subscribeTo(stream, nil)
+
+	for id := int64(1); id <= 3; id++ {
+		stream.PublishMessage(entities.Message{ID: id, NumberPhonesID: 1})
+	}
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("slow subscriber received %d events before closing, want 2", received)
+	}
+	if len(stream.subscribers) != 0 {
+		t.Errorf("slow subscriber is still registered")
+	}
+
+	// Unsubscribe después del cierre no vuelve a cerrar el canal
+	stream.Unsubscribe(slow)
+}
+
+func TestConversationStreamNilIsNoop(t *testing.T) {
+	var stream *ConversationStream
+	stream.PublishMessage(entities.Message{ID: 1, NumberPhonesID: 1})
+	stream.PublishHandoff(entities.Contact{ID: 1, NumberPhonesID: 1})
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// reminderReplyWindow es cuánto tiempo después de enviado un recordatorio se interpreta "confirmo"/"cancelar"
+// como respuesta aunque el contacto no haya respondido citando el mensaje
+const reminderReplyWindow = 48 * time.Hour
+
+const (
+	// reminderMaxAttempts es cuántas veces se intenta enviar cada recordatorio
+	reminderMaxAttempts = 3
+	// reminderStaleAfter es cuánto puede estar un recordatorio en sending antes de considerar que la instancia que
+	// lo enviaba se cayó y volver a reservarlo
+	reminderStaleAfter = 15 * time.Minute
+)
+
+var (
+	reminderConfirmWords = map[string]bool{"confirmo": true, "confirmar": true, "confirmado": true, "confirmada": true}
+	reminderCancelWords  = map[string]bool{"cancelar": true, "cancelo": true, "cancelado": true, "cancelada": true}
+)
+
+// SendDueReminders envía los recordatorios de los eventos próximos según las horas de anticipación configuradas
+// en cada assistant (reminder_offsets). Corre cada pocos minutos desde AutoProcessService: en cada pasada se envía,
+// para cada evento, el recordatorio vencido más cercano al inicio. Los que ya se enviaron no se vuelven a enviar y los
+// que fallaron se reintentan en las pasadas siguientes, hasta reminderMaxAttempts veces.
+func (service *WhatsappService) SendDueReminders() error {
+	now := service.availabilityService.now().In(service.availabilityService.location)
+	from := now.Format("2006-01-02")
+	to := now.Add(dtos.MaxReminderOffsetHours * time.Hour).Format("2006-01-02")
+
+	events, err := service.eventRemindersRepository.FindUpcomingEvents(from, to)
+	if err != nil {
+		return fmt.Errorf("error finding upcoming events: %v", err)
+	}
+
+	for _, event := range events {
+		if err := service.sendEventReminder(event, now); err != nil {
+			log.Printf("Error enviando el recordatorio del evento %s: %v", event.CodeEvent, err)
+		}
+	}
+	return nil
+}
+
+func (service *WhatsappService) sendEventReminder(event entities.Events, now time.Time) error {
+	offsets, err := dtos.ParseReminderOffsets(event.Assistant.ReminderOffsets)
+	if err != nil || len(offsets) == 0 {
+		return err
+	}
+
+	start, ok := service.availabilityService.parseEventDate(event.StartDate)
+	if !ok {
+		return fmt.Errorf("invalid start date %q", event.StartDate)
+	}
+	if !start.After(now) {
+		return nil
+	}
+
+	// Las horas vienen ordenadas de mayor a menor: la última vencida es la más cercana al inicio
+	offset := -1
+	for _, hours := range offsets {
+		if !now.Before(start.Add(-time.Duration(hours) * time.Hour)) {
+			offset = hours
+		}
+	}
+	if offset < 0 {
+		return nil
+	}
+
+	record := entities.EventReminder{
+		EventsID:    event.ID,
+		OffsetHours: offset,
+		ContactsID:  event.ContactsID,
+		Status:      entities.EventReminderStatusSending,
+	}
+
+	// Si el evento se agendó (o se reprogramó) después del momento del recordatorio no tiene sentido mandarlo (ej: turno
+	// para dentro de 3 horas con recordatorio de 24). Se registra igual para no volver a evaluarlo.
+	scheduledAt := event.CreatedAt
+	if event.RescheduledAt != nil {
+		scheduledAt = *event.RescheduledAt
+	}
+	staleBefore := time.Now().Add(-reminderStaleAfter)
+	if scheduledAt.After(start.Add(-time.Duration(offset) * time.Hour)) {
+		record.Status = entities.EventReminderStatusSkipped
+		_, err := service.eventRemindersRepository.Claim(&record, staleBefore, reminderMaxAttempts)
+		return err
+	}
+
+	claimed, err := service.eventRemindersRepository.Claim(&record, staleBefore, reminderMaxAttempts)
+	if err != nil || !claimed {
+		return err
+	}
+
+	numberPhone := event.Contact.NumberPhoneEntity
+	// El template elegido por el assistant para el recordatorio; si no eligió ninguno, reminder_template o el por defecto
+	templateName, languageCode := service.templatesService.LifecycleTemplate(entities.MapAssistantToDto(event.Assistant), dtos.TemplateEventReminder)
+
+	messageTemplate := metaapi.NewBodyWhatsappTemplateRecordatorio(
+		event.Summary,
+		start.Format("02/01/2006 15:04"),
+		event.CodeEvent,
+		event.Contact.NumberPhone,
+		templateName,
+		languageCode,
+	)
+	messageID, sendErr := service.postMessage(numberPhone, messageTemplate)
+	if sendErr != nil {
+		if err := service.eventRemindersRepository.MarkFailed(record.ID, sendErr.Error()); err != nil {
+			log.Printf("Error registrando el recordatorio fallido %d: %v", record.ID, err)
+		}
+		return sendErr
+	}
+
+	// Se guarda en la conversación para que el assistant tenga el contexto si el contacto responde otra cosa
+	_, err = saveBotMessage(service, entities.Message{
+		NumberPhonesID:    numberPhone.ID,
+		ContactsID:        event.ContactsID,
+		MessageIdWhatsapp: messageID,
+		MessageText:       fmt.Sprintf("Recordatorio: %s el %s. Código: %s. Respondé CONFIRMO o CANCELAR.", event.Summary, start.Format("02/01/2006 15:04"), event.CodeEvent),
+		IsFromBot:         true,
+		MessageType:       "template",
+	})
+	if err != nil {
+		log.Printf("Error guardando el recordatorio del evento %s: %v", event.CodeEvent, err)
+	}
+
+	return service.eventRemindersRepository.MarkSent(record.ID, messageID, time.Now())
+}
+
+// handleReminderReply procesa las respuestas "confirmo"/"cancelar" a un recordatorio. Devuelve true si el mensaje
+// era una respuesta a un recordatorio y ya se contestó; en ese caso no pasa por el assistant.
+func (service *WhatsappService) handleReminderReply(contact *entities.Contact, numberPhone *entities.NumberPhone, message whatsapp.Message, content inboundContent) (bool, error) {
+	if content.MessageType != whatsapp.MessageTypeText {
+		return false, nil
+	}
+
+	words := strings.Fields(normalizeReminderReply(content.Text))
+	if len(words) == 0 || len(words) > 4 {
+		return false, nil
+	}
+	confirm, cancel := reminderConfirmWords[words[0]], reminderCancelWords[words[0]]
+	if !confirm && !cancel {
+		return false, nil
+	}
+
+	reminder, err := service.findRepliedReminder(contact, message)
+	if err != nil || reminder == nil {
+		return false, err
+	}
+
+	event := reminder.Event
+	start, ok := service.availabilityService.parseEventDate(event.StartDate)
+	if event.ID <= 0 || !ok || !start.After(time.Now()) {
+		return false, nil
+	}
+
+	assistant, err := service.assistantService.FindAssistantById(numberPhone.AssistantsID)
+	if err != nil {
+		return false, fmt.Errorf("assistant not found: %v", err)
+	}
+
+	var reply string
+	if confirm {
+		if err := service.eventsService.Confirm(event.ID); err != nil {
+			return false, err
+		}
+		reply = fmt.Sprintf("¡Gracias! Confirmamos tu %s del %s. Te esperamos.", assistant.EventType, start.Format("02/01/2006 a las 15:04"))
+	} else {
+		if err := service.cancelEvent(assistant, numberPhone, entities.MapEntityToEventsDto(event)); err != nil {
+			return false, err
+		}
+		reply = fmt.Sprintf("Listo, cancelamos tu %s del %s (código %s). Si querés agendar otro horario escribinos.", assistant.EventType, start.Format("02/01/2006 a las 15:04"), event.CodeEvent)
+	}
+
+	err = service.messagesRepository.Create(entities.Message{
+		NumberPhonesID:    numberPhone.ID,
+		ContactsID:        contact.ID,
+		MessageText:       content.Text,
+		MessageIdWhatsapp: message.ID,
+		IsFromBot:         false,
+		MessageType:       content.MessageType,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error saving contact message: %v", err)
+	}
+
+	if err := service.replyToContact(numberPhone, contact, reply); err != nil {
+		log.Printf("Error respondiendo al recordatorio del evento %s: %v", event.CodeEvent, err)
+	}
+	return true, nil
+}
+
+// findRepliedReminder busca el recordatorio citado en la respuesta o, si el contacto no citó ningún mensaje,
+// el último que se le envió
+func (service *WhatsappService) findRepliedReminder(contact *entities.Contact, message whatsapp.Message) (*entities.EventReminder, error) {
+	if quotedID := message.Context["id"]; quotedID != "" {
+		reminder, err := service.eventRemindersRepository.FindByMessageID(quotedID)
+		if err != nil || reminder != nil {
+			return reminder, err
+		}
+	}
+	return service.eventRemindersRepository.FindLastSentToContact(contact.ID, time.Now().Add(-reminderReplyWindow))
+}
+
+// normalizeReminderReply pasa a minúsculas y saca acentos y signos ("¡Confirmó!" -> "confirmo")
+func normalizeReminderReply(text string) string {
+	replacer := strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u")
+	text = replacer.Replace(strings.ToLower(text))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return ' '
+	}, text)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+	"gorm.io/gorm"
+)
+
+// reminderTest es un WhatsappService con lo que usa el envío de recordatorios, contra una base en memoria y un
+// servidor que hace de Meta
+type reminderTest struct {
+	t       *testing.T
+	db      *gorm.DB
+	repos   *repositories.Repositories
+	service *WhatsappService
+	now     time.Time
+
+	sent    int32       // templates que Meta aceptó
+	failing atomic.Bool // Meta rechaza los envíos
+}
+
+func newReminderTest(t *testing.T, offsets string) *reminderTest {
+	t.Helper()
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	rt := &reminderTest{t: t, db: db, repos: sqlite_client.NewRepositories(db), now: time.Now().UTC()}
+
+	meta := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if rt.failing.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Message undeliverable","type":"OAuthException","code":131026}}`)
+			return
+		}
+		sent := atomic.AddInt32(&rt.sent, 1)
+		fmt.Fprintf(w, `{"messaging_product":"whatsapp","messages":[{"id":"wamid.reminder.%d"}]}`, sent)
+	}))
+	t.Cleanup(meta.Close)
+
+	records := []interface{}{
+		&entities.Bussines{ID: 1, Name: "Consultorio", Address: "calle 123"},
+		&entities.Assistant{ID: 1, BussinessID: 1, Name: "Consultorio", EventType: "turno", ReminderOffsets: offsets, Active: true},
+		&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101},
+		&entities.Contact{ID: 1, NumberPhonesID: 1, NumberPhone: "+5493510000001"},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+
+	whatsappClient := clients.NewWhatsappClient(meta.URL, "v21.0")
+	rt.service = &WhatsappService{
+		eventRemindersRepository: rt.repos.EventReminders,
+		messagesRepository:       rt.repos.Messages,
+		messagesService:          NewMessagesService(rt.repos.Messages, rt.repos.MessageStatuses, nil),
+		templatesService:         NewTemplatesService(rt.repos.MessageTemplates, rt.repos.AssistantTemplates, rt.repos.NumberPhones, nil, whatsappClient),
+		availabilityService:      &AvailabilityService{location: time.UTC, now: func() time.Time { return rt.now }},
+		whatsappClient:           whatsappClient,
+	}
+	return rt
+}
+
+// createEvent agenda un evento que empieza start y se creó createdAt
+func (rt *reminderTest) createEvent(start, createdAt time.Time) entities.Events {
+	rt.t.Helper()
+	event := entities.Events{Summary: "turno", Description: "turno", StartDate: start.Format("2006-01-02T15:04:05"), EndDate: start.Add(30 * time.Minute).Format("2006-01-02T15:04:05"),
+		CodeEvent: "ABC123", AssistantsID: 1, ContactsID: 1, CreatedAt: createdAt}
+	if err := rt.db.Create(&event).Error; err != nil {
+		rt.t.Fatalf("seeding event: %v", err)
+	}
+	return event
+}
+
+// run corre una pasada del scheduler y devuelve los templates enviados en ella
+func (rt *reminderTest) run() int32 {
+	rt.t.Helper()
+	before := atomic.LoadInt32(&rt.sent)
+	if err := rt.service.SendDueReminders(); err != nil {
+		rt.t.Fatalf("SendDueReminders: %v", err)
+	}
+	return atomic.LoadInt32(&rt.sent) - before
+}
+
+func (rt *reminderTest) reminders(eventID int) []entities.EventReminder {
+	rt.t.Helper()
+	var reminders []entities.EventReminder
+	if err := rt.db.Where("events_id = ?", eventID).Order("offset_hours DESC").Find(&reminders).Error; err != nil {
+		rt.t.Fatalf("loading reminders: %v", err)
+	}
+	return reminders
+}
+
+func TestSendDueRemindersSendsOnce(t *testing.T) {
+	rt := newReminderTest(t, "24,2")
+	event := rt.createEvent(rt.now.Add(20*time.Hour), rt.now.Add(-72*time.Hour))
+
+	if sent := rt.run(); sent != 1 {
+		t.Fatalf("first pass sent %d reminders, want 1", sent)
+	}
+	if sent := rt.run(); sent != 0 {
+		t.Errorf("second pass sent %d reminders, want the 24h reminder only once", sent)
+	}
+	reminders := rt.reminders(event.ID)
+	if len(reminders) != 1 || reminders[0].OffsetHours != 24 || reminders[0].Status != entities.EventReminderStatusSent || reminders[0].Attempts != 1 {
+		t.Errorf("reminders = %+v, want the 24h reminder sent in one attempt", reminders)
+	}
+}
+
+// Si el evento se agendó o se reprogramó después del momento del recordatorio, el recordatorio no se manda
+func TestSendDueRemindersSkipsLateBookings(t *testing.T) {
+	rt := newReminderTest(t, "24")
+	booked := rt.createEvent(rt.now.Add(3*time.Hour), rt.now.Add(-time.Hour))
+	rescheduled := rt.createEvent(rt.now.Add(40*time.Hour), rt.now.Add(-72*time.Hour))
+
+	dto := entities.MapEntityToEventsDto(rescheduled)
+	dto.StartDate = rt.now.Add(5 * time.Hour).Format("2006-01-02T15:04:05")
+	if err := NewEventsService(rt.repos.Events, UtilService{}).Update(dto); err != nil {
+		t.Fatalf("rescheduling: %v", err)
+	}
+
+	if sent := rt.run(); sent != 0 {
+		t.Errorf("sent %d reminders for events booked after the reminder time", sent)
+	}
+	for _, event := range []entities.Events{booked, rescheduled} {
+		if reminders := rt.reminders(event.ID); len(reminders) != 1 || reminders[0].Status != entities.EventReminderStatusSkipped {
+			t.Errorf("event %d reminders = %+v, want the 24h reminder skipped", event.ID, reminders)
+		}
+	}
+}
+
+func TestSendDueRemindersRetriesFailures(t *testing.T) {
+	rt := newReminderTest(t, "24")
+	event := rt.createEvent(rt.now.Add(20*time.Hour), rt.now.Add(-72*time.Hour))
+
+	rt.failing.Store(true)
+	rt.run()
+	if reminders := rt.reminders(event.ID); len(reminders) != 1 || reminders[0].Status != entities.EventReminderStatusFailed || reminders[0].Error == "" {
+		t.Fatalf("reminders = %+v, want the reminder failed with the Meta error", reminders)
+	}
+
+	rt.failing.Store(false)
+	if sent := rt.run(); sent != 1 {
+		t.Fatalf("retry sent %d reminders, want 1", sent)
+	}
+	if reminders := rt.reminders(event.ID); reminders[0].Status != entities.EventReminderStatusSent || reminders[0].Attempts != 2 {
+		t.Errorf("reminder = %+v, want sent on the second attempt", reminders[0])
+	}
+}
+
+func TestSendDueRemindersGivesUpAfterMaxAttempts(t *testing.T) {
+	rt := newReminderTest(t, "24")
+	event := rt.createEvent(rt.now.Add(20*time.Hour), rt.now.Add(-72*time.Hour))
+
+	rt.failing.Store(true)
+	for i := 0; i < reminderMaxAttempts+2; i++ {
+		rt.run()
+	}
+	rt.failing.Store(false)
+	if sent := rt.run(); sent != 0 {
+		t.Errorf("sent %d reminders after the attempts ran out", sent)
+	}
+	if reminders := rt.reminders(event.ID); reminders[0].Status != entities.EventReminderStatusFailed || reminders[0].Attempts != reminderMaxAttempts {
+		t.Errorf("reminder = %+v, want failed after %d attempts", reminders[0], reminderMaxAttempts)
+	}
+}
+
+// Un recordatorio que quedó en sending (la instancia se cayó antes de enviarlo) se retoma cuando pasa reminderStaleAfter
+func TestSendDueRemindersReclaimsStaleSending(t *testing.T) {
+	tests := []struct {
+		name      string
+		updatedAt time.Time
+		want      int32
+	}{
+		{"in progress", time.Now(), 0},
+		{"stale", time.Now().Add(-2 * reminderStaleAfter), 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rt := newReminderTest(t, "24")
+			event := rt.createEvent(rt.now.Add(20*time.Hour), rt.now.Add(-72*time.Hour))
+			stuck := entities.EventReminder{EventsID: event.ID, OffsetHours: 24, ContactsID: 1, Status: entities.EventReminderStatusSending, Attempts: 1}
+			if err := rt.db.Create(&stuck).Error; err != nil {
+				t.Fatalf("seeding reminder: %v", err)
+			}
+			rt.db.Model(&stuck).UpdateColumn("updated_at", test.updatedAt)
+
+			if sent := rt.run(); sent != test.want {
+				t.Errorf("sent %d reminders, want %d", sent, test.want)
+			}
+		})
+	}
+}
+
+// Al reprogramar el evento los recordatorios del horario anterior se borran y se envían de nuevo para el nuevo
+func TestSendDueRemindersAfterReschedule(t *testing.T) {
+	rt := newReminderTest(t, "24")
+	event := rt.createEvent(rt.now.Add(20*time.Hour), rt.now.Add(-72*time.Hour))
+	if sent := rt.run(); sent != 1 {
+		t.Fatalf("sent %d reminders before the reschedule, want 1", sent)
+	}
+
+	eventsService := NewEventsService(rt.repos.Events, UtilService{})
+	dto := entities.MapEntityToEventsDto(event)
+	dto.StartDate = rt.now.Add(48 * time.Hour).Format("2006-01-02T15:04:05")
+	if err := eventsService.Update(dto); err != nil {
+		t.Fatalf("rescheduling: %v", err)
+	}
+	if reminders := rt.reminders(event.ID); len(reminders) != 0 {
+		t.Fatalf("reminders = %+v after the reschedule, want them cleared", reminders)
+	}
+
+	if sent := rt.run(); sent != 0 {
+		t.Errorf("sent %d reminders 48h before the new start", sent)
+	}
+	rt.now = rt.now.Add(30 * time.Hour)
+	if sent := rt.run(); sent != 1 {
+		t.Errorf("sent %d reminders 18h before the new start, want 1", sent)
+	}
+}
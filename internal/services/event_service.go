@@ -8,18 +8,24 @@ import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"golang.org/x/exp/rand"
 )
 
 // Interfaz de servicio con DTOs
 type EventsService interface {
 	Create(eventDTO dtos.EventsDto) error
+	// Crea el evento si allow lo permite, verificándolo en la misma transacción (ver AvailabilityService.SlotGuard)
+	CreateChecked(eventDTO dtos.EventsDto, allow func(sameDay []entities.Events) error) error
 	GetByID(id int) (*dtos.EventsDto, error)
 	GetAll(request *filters.EventsFilter, pagination *dtos.Pagination) ([]dtos.EventsDto, dtos.Pagination, error)
 	Update(eventDTO dtos.EventsDto) error
+	// Actualiza el evento si allow permite el nuevo horario, verificándolo en la misma transacción
+	UpdateChecked(eventDTO dtos.EventsDto, allow func(sameDay []entities.Events) error) error
 	Delete(id int) error
 	Cancel(codeEvent string) error
+	// Marca el evento como confirmado por el contacto
+	Confirm(id int) error
 	// método para buscar un evento por contacto, fecha y hora
 	GetEventByContactAndDate(contactID int64, date, currentTime string) ([]entities.Events, error)
 	// Verifica si un codigo existe en un evento.
@@ -28,21 +34,27 @@ type EventsService interface {
 	GenerateUniqueCode() (string, error)
 	GetEventByCodeEvent(contactID int64, codeEvent string) (dtos.EventsDto, error)
 	GetEventsByContactDateAndNumberPhone(contactID int64, date string, assistantID int64) ([]entities.Events, error)
+	// Copia del servicio que solo accede a los eventos de los assistants del scope
+	WithTenant(tenant dtos.TenantScope) EventsService
 }
 
 // Implementación del servicio
 type eventsServiceImpl struct {
-	repo        postgres_client.EventsRepository
+	repo        repositories.EventsRepository
 	utilService UtilService
 }
 
-func NewEventsService(repo postgres_client.EventsRepository, utilService UtilService) EventsService {
+func NewEventsService(repo repositories.EventsRepository, utilService UtilService) EventsService {
 	return &eventsServiceImpl{
 		repo:        repo,
 		utilService: utilService,
 	}
 }
 
+func (s *eventsServiceImpl) WithTenant(tenant dtos.TenantScope) EventsService {
+	return &eventsServiceImpl{repo: s.repo.WithTenant(tenant), utilService: s.utilService}
+}
+
 // GenerateUniqueCode generates a unique code for event, ensuring it does not exist in the database
 func (s *eventsServiceImpl) GenerateUniqueCode() (string, error) {
 	rand.Seed(uint64(time.Now().UnixNano())) // Seed the random number generator
@@ -112,6 +124,15 @@ func (s *eventsServiceImpl) Create(eventDTO dtos.EventsDto) error {
 	return s.repo.Create(&event)
 }
 
+func (s *eventsServiceImpl) CreateChecked(eventDTO dtos.EventsDto, allow func(sameDay []entities.Events) error) error {
+	if eventDTO.Summary == "" || eventDTO.Description == "" {
+		return errors.New("el resumen y la descripción son obligatorios")
+	}
+
+	event := entities.MapDtoToEvents(eventDTO)
+	return s.repo.CreateChecked(&event, allow)
+}
+
 // Obtener un evento por ID y devolver DTO
 func (s *eventsServiceImpl) GetByID(id int) (*dtos.EventsDto, error) {
 	event, err := s.repo.FindByID(id)
@@ -157,11 +178,24 @@ func (s *eventsServiceImpl) Update(eventDTO dtos.EventsDto) error {
 	return s.repo.Update(&event)
 }
 
+func (s *eventsServiceImpl) UpdateChecked(eventDTO dtos.EventsDto, allow func(sameDay []entities.Events) error) error {
+	if eventDTO.ID == 0 {
+		return errors.New("el ID del evento es obligatorio")
+	}
+
+	event := entities.MapDtoToEvents(eventDTO)
+	return s.repo.UpdateChecked(&event, allow)
+}
+
 // Eliminar un evento por ID
 func (s *eventsServiceImpl) Cancel(codeEvent string) error {
 	return s.repo.Cancel(codeEvent)
 }
 
+func (s *eventsServiceImpl) Confirm(id int) error {
+	return s.repo.Confirm(id, time.Now())
+}
+
 // Eliminar un evento por ID
 func (s *eventsServiceImpl) Delete(id int) error {
 	return s.repo.Delete(id)
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+)
+
+// El filtro por mes usa SQL distinto en cada motor (ver dialect.go)
+func TestEventsGetAllByMonth(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	for i, start := range []string{"2030-01-02T10:00:00", "2030-01-31T18:00:00", "2030-02-01T09:00:00"} {
+		event := entities.Events{ID: i + 1, Summary: "turno", StartDate: start, EndDate: start, CodeEvent: start, AssistantsID: 1, ContactsID: 1}
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatalf("seeding event: %v", err)
+		}
+	}
+	service := NewEventsService(sqlite_client.NewRepositories(db).Events, UtilService{})
+
+	events, pagination, err := service.GetAll(&filters.EventsFilter{MonthYear: "2030-01"}, &dtos.Pagination{Number: 1, Size: 10})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(events) != 2 || pagination.Total != 2 {
+		t.Errorf("GetAll(2030-01) = %d events (total %d), want 2", len(events), pagination.Total)
+	}
+}
+
+// Al crear el evento se vuelve a contar la capacidad del horario con el assistant bloqueado
+func TestEventsCreateCheckedRespectsCapacity(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	if err := db.Create(&entities.Assistant{ID: 1, BussinessID: 1, Name: "Consultorio", EventDuration: 30}).Error; err != nil {
+		t.Fatalf("seeding assistant: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	service := NewEventsService(repos.Events, UtilService{})
+	availability := &AvailabilityService{location: time.UTC, now: time.Now}
+	start := time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	book := func(assistant dtos.AssistantDto, code string, start time.Time) error {
+		return service.CreateChecked(dtos.EventsDto{Summary: "turno", Description: "turno", StartDate: start.Format("2006-01-02 15:04:05"),
+			EndDate: start.Add(30 * time.Minute).Format("2006-01-02T15:04:05"), AssistantsID: 1, ContactsID: 1, CodeEvent: code},
+			availability.SlotGuard(assistant, start, 0))
+	}
+
+	assistant := dtos.AssistantDto{ID: 1, EventDuration: 30, SlotCapacity: 2}
+	for i, want := range []error{nil, nil, ErrSlotTaken} {
+		if err := book(assistant, fmt.Sprintf("A%d", i), start); !errors.Is(err, want) {
+			t.Errorf("booking %d at 10:00 = %v, want %v", i+1, err, want)
+		}
+	}
+	if err := book(assistant, "B", start.Add(30*time.Minute)); err != nil {
+		t.Errorf("booking at 10:30 = %v, want the next slot free", err)
+	}
+	if err := book(dtos.AssistantDto{ID: 1, EventDuration: 60, SlotCapacity: 2}, "C", start.Add(-30*time.Minute)); !errors.Is(err, ErrSlotTaken) {
+		t.Errorf("booking a 60 minute event at 9:30 = %v, want ErrSlotTaken (overlaps the full 10:00 slot)", err)
+	}
+
+	var count int64
+	db.Model(&entities.Events{}).Count(&count)
+	if count != 3 {
+		t.Errorf("%d events stored, want 3", count)
+	}
+}
+
+// Al reprogramar también se cuenta la capacidad del nuevo horario con el assistant bloqueado, sin contar el propio evento
+func TestEventsUpdateCheckedRespectsCapacity(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	if err := db.Create(&entities.Assistant{ID: 1, BussinessID: 1, Name: "Consultorio", EventDuration: 30}).Error; err != nil {
+		t.Fatalf("seeding assistant: %v", err)
+	}
+	start := time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i, slot := range []time.Time{start, start, start.Add(time.Hour)} {
+		event := entities.Events{ID: i + 1, Summary: "turno", Description: "turno", StartDate: slot.Format("2006-01-02T15:04:05"),
+			EndDate: slot.Add(30 * time.Minute).Format("2006-01-02T15:04:05"), CodeEvent: fmt.Sprintf("E%d", i+1), AssistantsID: 1, ContactsID: 1}
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatalf("seeding event: %v", err)
+		}
+	}
+	service := NewEventsService(sqlite_client.NewRepositories(db).Events, UtilService{})
+	availability := &AvailabilityService{location: time.UTC, now: time.Now}
+	assistant := dtos.AssistantDto{ID: 1, EventDuration: 30, SlotCapacity: 2}
+
+	reschedule := func(id int, to time.Time) error {
+		return service.UpdateChecked(dtos.EventsDto{ID: id, Summary: "turno", Description: "turno", StartDate: to.Format("2006-01-02T15:04:05"),
+			EndDate: to.Add(30 * time.Minute).Format("2006-01-02T15:04:05"), AssistantsID: 1, ContactsID: 1, CodeEvent: fmt.Sprintf("E%d", id)},
+			availability.SlotGuard(assistant, to, id))
+	}
+
+	if err := reschedule(3, start); !errors.Is(err, ErrSlotTaken) {
+		t.Errorf("moving event 3 to the full 10:00 slot = %v, want ErrSlotTaken", err)
+	}
+	if err := reschedule(1, start.Add(10*time.Minute)); err != nil {
+		t.Errorf("moving event 1 inside its own slot = %v, want it not counted against itself", err)
+	}
+	if err := reschedule(2, start.Add(time.Hour)); err != nil {
+		t.Errorf("moving event 2 to 11:00 = %v, want one place free", err)
+	}
+
+	var event entities.Events
+	db.First(&event, 3)
+	if event.StartDate != "2030-01-02T11:00:00" {
+		t.Errorf("event 3 starts at %s, want it unchanged", event.StartDate)
+	}
+}
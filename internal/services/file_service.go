@@ -4,26 +4,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"os"
 	"time"
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"github.com/minio/minio-go/v7"
 )
 
 type FileService struct {
-	repository  *postgres_client.FileRepository
+	repository  repositories.FileRepository
 	minioClient *minio.Client
 }
 
-func NewFileService(repository *postgres_client.FileRepository, minioClient *minio.Client) *FileService {
+func NewFileService(repository repositories.FileRepository, minioClient *minio.Client) *FileService {
 	return &FileService{repository: repository, minioClient: minioClient}
 }
 
+// WithTenant devuelve una copia del servicio que solo accede a los archivos de los assistants del scope
+func (s *FileService) WithTenant(tenant dtos.TenantScope) *FileService {
+	return &FileService{repository: s.repository.WithTenant(tenant), minioClient: s.minioClient}
+}
+
 func (s *FileService) CreateFile(fileHeader *multipart.FileHeader, assistantsID int64, purpose, fileIDOpenAI, vectorStoreID string) (entities.File, error) {
+	// Se verifica el assistant antes de subir nada a MinIO
+	if err := s.repository.CheckAssistant(assistantsID); err != nil {
+		return entities.File{}, err
+	}
+
 	// Abrir el archivo para obtener su contenido
 	file, err := fileHeader.Open()
 	if err != nil {
@@ -114,7 +125,13 @@ func (s *FileService) DeleteFile(id int64) error {
 
 	return s.repository.Delete(id)
 }
-func uploadToMinIO(client *minio.Client, file multipart.File, fileName string, fileSize int64, contentType string) (string, error) {
+
+// UploadMedia sube a MinIO un archivo recibido por WhatsApp (audio, imagen, documento...) y devuelve el nombre del objeto.
+func (s *FileService) UploadMedia(content io.Reader, fileName string, fileSize int64, contentType string) (string, error) {
+	return uploadToMinIO(s.minioClient, content, fileName, fileSize, contentType)
+}
+
+func uploadToMinIO(client *minio.Client, file io.Reader, fileName string, fileSize int64, contentType string) (string, error) {
 	// Generar un nombre único para el archivo
 	uniqueFileName := fmt.Sprintf("%d-%s", time.Now().Unix(), fileName)
 
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// Tiempo sin respuesta del operador después del cual la conversación vuelve al bot (HANDOFF_TIMEOUT_MINUTES)
+const defaultHandoffTimeout = 60 * time.Minute
+
+// HandoffService maneja quién atiende cada conversación (bot, operador o pausada) y la bandeja de los operadores
+type HandoffService struct {
+	contactsRepository repositories.ContactsRepository
+	messagesRepository repositories.MessagesRepository
+	stream             *ConversationStream
+	timeout            time.Duration
+}
+
+func NewHandoffService(contactsRepository repositories.ContactsRepository, messagesRepository repositories.MessagesRepository, stream *ConversationStream) *HandoffService {
+	return &HandoffService{
+		contactsRepository: contactsRepository,
+		messagesRepository: messagesRepository,
+		stream:             stream,
+		timeout:            time.Duration(envPositiveInt("HANDOFF_TIMEOUT_MINUTES", int(defaultHandoffTimeout/time.Minute))) * time.Minute,
+	}
+}
+
+// WithTenant devuelve una copia del servicio que solo accede a los contactos de los números del scope
+func (s *HandoffService) WithTenant(tenant dtos.TenantScope) *HandoffService {
+	return &HandoffService{
+		contactsRepository: s.contactsRepository.WithTenant(tenant),
+		messagesRepository: s.messagesRepository.WithTenant(tenant),
+		stream:             s.stream,
+		timeout:            s.timeout,
+	}
+}
+
+// GetContact devuelve el contacto (gorm.ErrRecordNotFound si no existe o es de otro bussiness)
+func (s *HandoffService) GetContact(contactID int64) (entities.Contact, error) {
+	return s.contactsRepository.FindByID(contactID)
+}
+
+// SetMode cambia quién atiende la conversación del contacto
+func (s *HandoffService) SetMode(contactID int64, request dtos.HandoffRequestDto) (dtos.ContactDto, error) {
+	if err := request.Validate(); err != nil {
+		return dtos.ContactDto{}, err
+	}
+
+	var since, expiresAt *time.Time
+	reason := request.Reason
+	if request.Mode == dtos.HandoffModeBot {
+		reason = ""
+	} else {
+		now := time.Now()
+		since = &now
+
+		timeout := time.Duration(request.TimeoutMinutes) * time.Minute
+		if timeout == 0 && request.Mode == dtos.HandoffModeHuman {
+			timeout = s.timeout
+		}
+		if timeout > 0 {
+			expires := now.Add(timeout)
+			expiresAt = &expires
+		}
+	}
+
+	if err := s.contactsRepository.UpdateHandoff(contactID, request.Mode, reason, since, expiresAt); err != nil {
+		return dtos.ContactDto{}, err
+	}
+
+	contact, err := s.contactsRepository.FindByID(contactID)
+	if err != nil {
+		return dtos.ContactDto{}, err
+	}
+	s.stream.PublishHandoff(contact)
+	return entities.MapEntityToContactDto(contact), nil
+}
+
+// TakeOver pasa la conversación a un operador. Si ya la tenía un operador se extiende el vencimiento
+// (cada respuesta del operador vuelve a contar el tiempo) y se conserva el motivo original.
+func (s *HandoffService) TakeOver(contactID int64, reason string) error {
+	contact, err := s.contactsRepository.FindByID(contactID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.timeout)
+	since := &now
+	if contact.CurrentHandoffMode(now) == dtos.HandoffModeHuman {
+		since = contact.HandoffAt
+		if reason == "" {
+			reason = contact.HandoffReason
+		}
+	}
+	if err := s.contactsRepository.UpdateHandoff(contactID, dtos.HandoffModeHuman, reason, since, &expiresAt); err != nil {
+		return err
+	}
+
+	contact.HandoffMode = dtos.HandoffModeHuman
+	contact.HandoffReason = reason
+	contact.HandoffAt = since
+	contact.HandoffExpiresAt = &expiresAt
+	s.stream.PublishHandoff(contact)
+	return nil
+}
+
+// IsHandledByBot indica si el assistant tiene que responder los mensajes del contacto
+func (s *HandoffService) IsHandledByBot(contactID int64) (bool, error) {
+	contact, err := s.contactsRepository.FindByID(contactID)
+	if err != nil {
+		return false, err
+	}
+	return contact.CurrentHandoffMode(time.Now()) == dtos.HandoffModeBot, nil
+}
+
+// ReleaseExpired devuelve al bot las conversaciones cuyo modo human o paused venció
+func (s *HandoffService) ReleaseExpired() error {
+	released, err := s.contactsRepository.ReleaseExpiredHandoffs(time.Now())
+	if err != nil {
+		return fmt.Errorf("error releasing expired handoffs: %v", err)
+	}
+	if len(released) > 0 {
+		log.Printf("%d conversaciones volvieron a ser atendidas por el bot", len(released))
+	}
+	for _, contact := range released {
+		s.stream.PublishHandoff(contact)
+	}
+	return nil
+}
+
+// GetInbox devuelve las conversaciones del número que atiende un operador o están pausadas, con su último mensaje
+func (s *HandoffService) GetInbox(numberPhoneID int64) ([]dtos.InboxConversationDto, error) {
+	exists, err := s.contactsRepository.DoesNumberPhoneExist(numberPhoneID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("number phone %d: %w", numberPhoneID, gorm.ErrRecordNotFound)
+	}
+
+	contacts, err := s.contactsRepository.FindHandedOffByNumberPhone(numberPhoneID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := make([]dtos.InboxConversationDto, 0, len(contacts))
+	for _, contact := range contacts {
+		conversation := dtos.InboxConversationDto{Contact: entities.MapEntityToContactDto(contact)}
+		last, err := s.messagesRepository.FindLastByContact(contact.ID)
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			message := entities.MapEntityToMessageDto(*last)
+			conversation.LastMessage = &message
+		}
+		inbox = append(inbox, conversation)
+	}
+	return inbox, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"gorm.io/gorm"
+)
+
+// newHandoffTest carga un número con dos contactos atendidos por el bot
+func newHandoffTest(t *testing.T) (*gorm.DB, *repositories.Repositories, *HandoffService) {
+	t.Helper()
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	records := []interface{}{
+		&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101},
+		&entities.Contact{ID: 1, NumberPhonesID: 1, NumberPhone: "+5493510000001"},
+		&entities.Contact{ID: 2, NumberPhonesID: 1, NumberPhone: "+5493510000002"},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+	service := NewHandoffService(repos.Contacts, repos.Messages, nil)
+	service.timeout = time.Hour
+	return db, repos, service
+}
+
+func TestHandoffTakeOverAndRelease(t *testing.T) {
+	_, repos, service := newHandoffTest(t)
+
+	if err := service.TakeOver(1, "quiere hablar con una persona"); err != nil {
+		t.Fatalf("TakeOver: %v", err)
+	}
+	first, _ := repos.Contacts.FindByID(1)
+	if first.CurrentHandoffMode(time.Now()) != dtos.HandoffModeHuman || first.HandoffReason != "quiere hablar con una persona" ||
+		first.HandoffAt == nil || first.HandoffExpiresAt == nil || first.HandoffExpiresAt.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatalf("contact = %+v, want it handed off for an hour", first)
+	}
+	if byBot, err := service.IsHandledByBot(1); err != nil || byBot {
+		t.Errorf("IsHandledByBot = %v, %v; want false during the handoff", byBot, err)
+	}
+
+	// Cada respuesta del operador extiende el vencimiento y conserva el motivo y el inicio
+	time.Sleep(10 * time.Millisecond)
+	if err := service.TakeOver(1, ""); err != nil {
+		t.Fatalf("TakeOver (again): %v", err)
+	}
+	again, _ := repos.Contacts.FindByID(1)
+	if again.HandoffReason != first.HandoffReason || !again.HandoffAt.Equal(*first.HandoffAt) || !again.HandoffExpiresAt.After(*first.HandoffExpiresAt) {
+		t.Errorf("contact = %+v, want the same handoff with a later expiration than %v", again, first.HandoffExpiresAt)
+	}
+
+	contact, err := service.SetMode(1, dtos.HandoffRequestDto{Mode: dtos.HandoffModeBot})
+	if err != nil {
+		t.Fatalf("SetMode(bot): %v", err)
+	}
+	if contact.Handoff.Mode != dtos.HandoffModeBot || contact.Handoff.Reason != "" {
+		t.Errorf("handoff = %+v, want the conversation back with the bot", contact.Handoff)
+	}
+	if byBot, err := service.IsHandledByBot(1); err != nil || !byBot {
+		t.Errorf("IsHandledByBot = %v, %v; want true after the release", byBot, err)
+	}
+}
+
+func TestHandoffReleaseExpired(t *testing.T) {
+	db, repos, service := newHandoffTest(t)
+
+	// El contacto 1 venció hace un minuto; el 2 todavía tiene una hora
+	now := time.Now()
+	for id, expiresAt := range map[int64]time.Time{1: now.Add(-time.Minute), 2: now.Add(time.Hour)} {
+		since := now.Add(-2 * time.Hour)
+		if err := repos.Contacts.UpdateHandoff(id, dtos.HandoffModeHuman, "operador", &since, &expiresAt); err != nil {
+			t.Fatalf("seeding handoff: %v", err)
+		}
+	}
+
+	if err := service.ReleaseExpired(); err != nil {
+		t.Fatalf("ReleaseExpired: %v", err)
+	}
+	var contacts []entities.Contact
+	db.Order("id").Find(&contacts)
+	if contacts[0].HandoffMode != dtos.HandoffModeBot || contacts[0].HandoffReason != "" || contacts[0].HandoffExpiresAt != nil {
+		t.Errorf("contact 1 = %+v, want it released to the bot", contacts[0])
+	}
+	if contacts[1].HandoffMode != dtos.HandoffModeHuman || contacts[1].HandoffReason != "operador" {
+		t.Errorf("contact 2 = %+v, want it still with the operator", contacts[1])
+	}
+}
+
+// Mientras atiende un operador el assistant no responde: los mensajes solo se guardan para la bandeja
+func TestBotSilencedDuringHandoff(t *testing.T) {
+	db, repos, handoffService := newHandoffTest(t)
+	if err := handoffService.TakeOver(1, "operador"); err != nil {
+		t.Fatalf("TakeOver: %v", err)
+	}
+
+	// Sin assistant ni proveedor: si el servicio intentara responder fallaría
+	service := &WhatsappService{
+		handoffService:     handoffService,
+		messagesRepository: repos.Messages,
+		messagesService:    NewMessagesService(repos.Messages, repos.MessageStatuses, nil),
+	}
+	contact, _ := repos.Contacts.FindByID(1)
+	numberPhone, _ := repos.NumberPhones.FindByID("1")
+	err := service.handleMessageWithOpenAI([]mailboxMessage{
+		{Contact: &contact, NumberPhone: &numberPhone, Content: inboundContent{Text: "hola", MessageType: "text"}, MessageID: "wamid.1"},
+		{Contact: &contact, NumberPhone: &numberPhone, Content: inboundContent{Text: "¿hay alguien?", MessageType: "text"}, MessageID: "wamid.2"},
+	})
+	if err != nil {
+		t.Fatalf("handleMessageWithOpenAI: %v", err)
+	}
+
+	var messages []entities.Message
+	db.Order("id").Find(&messages)
+	if len(messages) != 2 || messages[0].IsFromBot || messages[1].IsFromBot {
+		t.Errorf("messages = %+v, want the two messages of the contact and no reply", messages)
+	}
+}
+
+// El motivo se corta en 255 caracteres sin partir las letras con tilde
+func TestEscalateToHumanTruncatesReason(t *testing.T) {
+	_, repos, handoffService := newHandoffTest(t)
+	service := &WhatsappService{handoffService: handoffService}
+
+	contact, _ := repos.Contacts.FindByID(1)
+	numberPhone, _ := repos.NumberPhones.FindByID("1")
+	arguments, _ := json.Marshal(escalateToHumanArgs{Reason: "a" + strings.Repeat("ñ", 300)})
+	if _, err := service.toolEscalateToHuman(ToolContext{Contact: &contact, NumberPhone: &numberPhone}, arguments); err != nil {
+		t.Fatalf("toolEscalateToHuman: %v", err)
+	}
+
+	stored, _ := repos.Contacts.FindByID(1)
+	if !utf8.ValidString(stored.HandoffReason) || utf8.RuneCountInString(stored.HandoffReason) != 255 {
+		t.Errorf("reason has %d runes (valid UTF-8: %v), want 255", utf8.RuneCountInString(stored.HandoffReason), utf8.ValidString(stored.HandoffReason))
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// icalProperty es una línea "NOMBRE;PARAM=VALOR:valor" de un archivo iCal
+type icalProperty struct {
+	Params map[string]string
+	Value  string
+}
+
+// parseICalClosures convierte los VEVENT de un archivo iCal en cierres. Soporta lo que exportan Google Calendar
+// y los calendarios de feriados: eventos de día completo (VALUE=DATE), eventos con horario (se toman como cierre
+// parcial si empiezan y terminan el mismo día) y RRULE:FREQ=YEARLY. Los eventos que no se pueden interpretar,
+// como los que se repiten de otra forma, se devuelven en skipped.
+func parseICalClosures(reader io.Reader, location *time.Location) ([]dtos.AssistantClosureDto, []string, error) {
+	lines, err := unfoldICalLines(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var closures []dtos.AssistantClosureDto
+	var skipped []string
+	var event map[string]icalProperty
+	foundCalendar := false
+
+	for _, line := range lines {
+		switch {
+		case strings.EqualFold(line, "BEGIN:VCALENDAR"):
+			foundCalendar = true
+		case strings.EqualFold(line, "BEGIN:VEVENT"):
+			event = make(map[string]icalProperty)
+		case strings.EqualFold(line, "END:VEVENT"):
+			if event == nil {
+				continue
+			}
+			closure, err := icalEventToClosure(event, location)
+			if err != nil {
+				label := event["SUMMARY"].Value
+				if label == "" {
+					label = event["UID"].Value
+				}
+				skipped = append(skipped, fmt.Sprintf("%s: %v", label, err))
+			} else {
+				closures = append(closures, closure)
+			}
+			event = nil
+		case event != nil:
+			name, property, ok := parseICalLine(line)
+			if ok {
+				event[name] = property
+			}
+		}
+	}
+
+	if !foundCalendar {
+		return nil, nil, fmt.Errorf("the file is not a valid iCal calendar")
+	}
+	return closures, skipped, nil
+}
+
+// unfoldICalLines une las líneas partidas (RFC 5545: las que empiezan con espacio o tab continúan la anterior)
+func unfoldICalLines(reader io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func parseICalLine(line string) (string, icalProperty, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", icalProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	property := icalProperty{Params: make(map[string]string), Value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			property.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), property, true
+}
+
+func icalEventToClosure(event map[string]icalProperty, location *time.Location) (dtos.AssistantClosureDto, error) {
+	dtStart, ok := event["DTSTART"]
+	if !ok {
+		return dtos.AssistantClosureDto{}, fmt.Errorf("event without DTSTART")
+	}
+	uid := event["UID"].Value
+	if uid == "" {
+		return dtos.AssistantClosureDto{}, fmt.Errorf("event without UID")
+	}
+
+	recurringYearly, err := parseICalRecurrence(event["RRULE"].Value)
+	if err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	start, allDay, err := parseICalDate(dtStart, location)
+	if err != nil {
+		return dtos.AssistantClosureDto{}, err
+	}
+
+	// DTEND es exclusivo: un feriado de día completo termina a las 00:00 del día siguiente
+	end := start
+	if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if dtEnd, ok := event["DTEND"]; ok {
+		if end, _, err = parseICalDate(dtEnd, location); err != nil {
+			return dtos.AssistantClosureDto{}, err
+		}
+	}
+	if !end.After(start) {
+		return dtos.AssistantClosureDto{}, fmt.Errorf("event without duration")
+	}
+
+	closure := dtos.AssistantClosureDto{
+		StartDate:       start.Format("2006-01-02"),
+		Reason:          unescapeICalText(event["SUMMARY"].Value),
+		ExternalUID:     uid,
+		RecurringYearly: recurringYearly,
+	}
+
+	switch {
+	case allDay:
+		closure.EndDate = end.AddDate(0, 0, -1).Format("2006-01-02")
+	case start.Format("2006-01-02") == end.Format("2006-01-02"):
+		// Cierre de unas horas dentro del día
+		closure.EndDate = closure.StartDate
+		closure.StartTime = start.Format("15:04")
+		closure.EndTime = end.Format("15:04")
+	default:
+		// Un evento con horario que abarca varios días cierra esos días completos
+		closure.EndDate = end.Format("2006-01-02")
+	}
+	return closure, nil
+}
+
+// parseICalRecurrence indica si la RRULE es la de un evento que se repite todos los años. Un cierre solo puede ser
+// anual o de una vez: cualquier otra repetición (semanal, cada dos años, con COUNT o UNTIL...) es un error, para no
+// importar solo su primera fecha.
+func parseICalRecurrence(rule string) (bool, error) {
+	if rule == "" {
+		return false, nil
+	}
+	yearly := false
+	for _, part := range strings.Split(strings.ToUpper(rule), ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch {
+		case key == "FREQ" && value == "YEARLY":
+			yearly = true
+		case key == "INTERVAL" && value == "1", key == "WKST":
+		default:
+			return false, fmt.Errorf("unsupported recurrence %q", rule)
+		}
+	}
+	if !yearly {
+		return false, fmt.Errorf("unsupported recurrence %q", rule)
+	}
+	return true, nil
+}
+
+// parseICalDate interpreta DATE (20250709), DATE-TIME local (20250709T090000), en UTC (…Z) o con TZID
+func parseICalDate(property icalProperty, location *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(property.Value)
+	if property.Params["VALUE"] == "DATE" || len(value) == 8 {
+		parsed, err := time.ParseInLocation("20060102", value, location)
+		return parsed, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		parsed, err := time.Parse("20060102T150405Z", value)
+		return parsed.In(location), false, err
+	}
+
+	eventLocation := location
+	if tzid := property.Params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			eventLocation = loaded
+		}
+	}
+	parsed, err := time.ParseInLocation("20060102T150405", value, eventLocation)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+	}
+	return parsed.In(location), false, nil
+}
+
+func unescapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
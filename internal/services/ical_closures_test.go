@@ -0,0 +1,153 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// icalCalendar arma un calendario con un VEVENT por cada grupo de líneas, con los fines de línea CRLF del RFC 5545
+func icalCalendar(events ...string) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Google Inc//Google Calendar 70.9054//EN"}
+	for _, event := range events {
+		lines = append(lines, "BEGIN:VEVENT", event, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestParseICalClosures(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		want    *dtos.AssistantClosureDto // nil si el evento se saltea
+		skipped string
+	}{
+		{
+			name:  "all day",
+			event: "UID:a\r\nSUMMARY:Carnaval\r\nDTSTART;VALUE=DATE:20260302\r\nDTEND;VALUE=DATE:20260303",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", Reason: "Carnaval", ExternalUID: "a"},
+		},
+		{
+			// DTEND es exclusivo: del 24 al 27 cierra el 24, 25 y 26
+			name:  "several days",
+			event: "UID:a\r\nSUMMARY:Vacaciones\r\nDTSTART;VALUE=DATE:20261224\r\nDTEND;VALUE=DATE:20261227",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-12-24", EndDate: "2026-12-26", Reason: "Vacaciones", ExternalUID: "a"},
+		},
+		{
+			name:  "date without VALUE nor DTEND",
+			event: "UID:a\r\nSUMMARY:Feriado\r\nDTSTART:20260302",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", Reason: "Feriado", ExternalUID: "a"},
+		},
+		{
+			name:  "local date-time",
+			event: "UID:a\r\nSUMMARY:Reunión\r\nDTSTART:20260302T090000\r\nDTEND:20260302T130000",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", StartTime: "09:00", EndTime: "13:00", Reason: "Reunión", ExternalUID: "a"},
+		},
+		{
+			// 12:00 UTC son las 09:00 en UTC-3
+			name:  "utc date-time",
+			event: "UID:a\r\nSUMMARY:Reunión\r\nDTSTART:20260302T120000Z\r\nDTEND:20260302T150000Z",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", StartTime: "09:00", EndTime: "12:00", Reason: "Reunión", ExternalUID: "a"},
+		},
+		{
+			name:  "tzid",
+			event: "UID:a\r\nSUMMARY:Reunión\r\nDTSTART;TZID=UTC:20260302T120000\r\nDTEND;TZID=\"UTC\":20260302T150000",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", StartTime: "09:00", EndTime: "12:00", Reason: "Reunión", ExternalUID: "a"},
+		},
+		{
+			name:  "unknown tzid uses the assistant timezone",
+			event: "UID:a\r\nSUMMARY:Reunión\r\nDTSTART;TZID=Mars/Olympus:20260302T090000\r\nDTEND;TZID=Mars/Olympus:20260302T100000",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", StartTime: "09:00", EndTime: "10:00", Reason: "Reunión", ExternalUID: "a"},
+		},
+		{
+			// 02:00 UTC del 3 son las 23:00 del 2 en UTC-3: termina el mismo día
+			name:  "utc date-time that is the same local day",
+			event: "UID:a\r\nSUMMARY:Inventario\r\nDTSTART:20260302T220000Z\r\nDTEND:20260303T020000Z",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-02", StartTime: "19:00", EndTime: "23:00", Reason: "Inventario", ExternalUID: "a"},
+		},
+		{
+			name:  "date-time across days",
+			event: "UID:a\r\nSUMMARY:Mudanza\r\nDTSTART:20260302T180000\r\nDTEND:20260304T100000",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-03-02", EndDate: "2026-03-04", Reason: "Mudanza", ExternalUID: "a"},
+		},
+		{
+			name:  "folded and escaped lines",
+			event: "UID:a\r\nSUMMARY:Día de la Soberanía Nacional\\, feriado tras\r\n ladable\r\nDTSTART;VALUE=DATE:\r\n\t20261123",
+			want:  &dtos.AssistantClosureDto{StartDate: "2026-11-23", EndDate: "2026-11-23", Reason: "Día de la Soberanía Nacional, feriado trasladable", ExternalUID: "a"},
+		},
+		{
+			name:  "yearly",
+			event: "UID:a\r\nSUMMARY:Navidad\r\nDTSTART;VALUE=DATE:20251225\r\nRRULE:FREQ=YEARLY;WKST=SU",
+			want:  &dtos.AssistantClosureDto{StartDate: "2025-12-25", EndDate: "2025-12-25", Reason: "Navidad", ExternalUID: "a", RecurringYearly: true},
+		},
+		{
+			name:    "weekly recurrence",
+			event:   "UID:a\r\nSUMMARY:Cierre de los lunes\r\nDTSTART;VALUE=DATE:20260302\r\nRRULE:FREQ=WEEKLY;BYDAY=MO",
+			skipped: `Cierre de los lunes: unsupported recurrence "FREQ=WEEKLY;BYDAY=MO"`,
+		},
+		{
+			name:    "yearly with count",
+			event:   "UID:a\r\nSUMMARY:Congreso\r\nDTSTART;VALUE=DATE:20260302\r\nRRULE:FREQ=YEARLY;COUNT=3",
+			skipped: `Congreso: unsupported recurrence "FREQ=YEARLY;COUNT=3"`,
+		},
+		{
+			name:    "without duration",
+			event:   "UID:a\r\nSUMMARY:Recordatorio\r\nDTSTART:20260302T090000",
+			skipped: "Recordatorio: event without duration",
+		},
+		{
+			name:    "without uid",
+			event:   "SUMMARY:Feriado\r\nDTSTART;VALUE=DATE:20260302",
+			skipped: "Feriado: event without UID",
+		},
+		{
+			name:    "without dtstart",
+			event:   "UID:b\r\nDTEND;VALUE=DATE:20260302",
+			skipped: "b: event without DTSTART",
+		},
+		{
+			name:    "invalid date",
+			event:   "UID:a\r\nSUMMARY:Feriado\r\nDTSTART:2026-03-02T09:00\r\nDTEND:20260302T100000",
+			skipped: `Feriado: invalid date "2026-03-02T09:00"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			closures, skipped, err := parseICalClosures(strings.NewReader(icalCalendar(test.event)), testLocation)
+			if err != nil {
+				t.Fatalf("parseICalClosures: %v", err)
+			}
+			if test.want == nil {
+				if len(closures) != 0 || len(skipped) != 1 || skipped[0] != test.skipped {
+					t.Errorf("closures = %+v, skipped = %q; want the event skipped with %q", closures, skipped, test.skipped)
+				}
+				return
+			}
+			if len(skipped) != 0 || len(closures) != 1 || !reflect.DeepEqual(closures[0], *test.want) {
+				t.Errorf("closures = %+v, skipped = %q; want %+v", closures, skipped, *test.want)
+			}
+		})
+	}
+}
+
+func TestParseICalClosuresSeveralEvents(t *testing.T) {
+	calendar := icalCalendar(
+		"UID:a\r\nSUMMARY:Carnaval\r\nDTSTART;VALUE=DATE:20260216\r\nDTEND;VALUE=DATE:20260218",
+		"UID:b\r\nSUMMARY:Cierre semanal\r\nDTSTART;VALUE=DATE:20260302\r\nRRULE:FREQ=WEEKLY",
+		"UID:c\r\nSUMMARY:Navidad\r\nDTSTART;VALUE=DATE:20251225\r\nRRULE:FREQ=YEARLY",
+	)
+	closures, skipped, err := parseICalClosures(strings.NewReader(calendar), testLocation)
+	if err != nil {
+		t.Fatalf("parseICalClosures: %v", err)
+	}
+	if len(closures) != 2 || closures[0].ExternalUID != "a" || closures[1].ExternalUID != "c" || len(skipped) != 1 {
+		t.Errorf("closures = %+v, skipped = %q; want a and c imported and b skipped", closures, skipped)
+	}
+
+	if _, _, err := parseICalClosures(strings.NewReader("UID:a\r\nDTSTART:20260302\r\n"), testLocation); err == nil {
+		t.Error("parsing a file without VCALENDAR succeeded, want an error")
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+)
+
+const (
+	defaultInboundJobWorkers     = 4
+	defaultInboundJobMaxAttempts = 5
+	inboundJobPollInterval       = 2 * time.Second
+	inboundJobBaseBackoff        = 30 * time.Second
+	inboundJobMaxBackoff         = time.Hour
+	// Un job que lleva más de este tiempo en processing se considera abandonado (ej: reinicio del proceso)
+	inboundJobStaleAfter = 10 * time.Minute
+)
+
+// ErrInboundJobNotReplayable se devuelve al intentar reencolar un job que no está en dead
+var ErrInboundJobNotReplayable = errors.New("only dead jobs can be replayed")
+
+// InboundJobsService persiste las notificaciones del webhook y las procesa con un pool de workers con reintentos
+type InboundJobsService struct {
+	repository      repositories.InboundJobsRepository
+	whatsappService *WhatsappService
+	workers         int
+	maxAttempts     int
+	wake            chan struct{}
+}
+
+// NewInboundJobsService inicializa la cola. La concurrencia y los reintentos se configuran con
+// INBOUND_JOBS_WORKERS e INBOUND_JOBS_MAX_ATTEMPTS.
+func NewInboundJobsService(repository repositories.InboundJobsRepository, whatsappService *WhatsappService) *InboundJobsService {
+	return &InboundJobsService{
+		repository:      repository,
+		whatsappService: whatsappService,
+		workers:         envPositiveInt("INBOUND_JOBS_WORKERS", defaultInboundJobWorkers),
+		maxAttempts:     envPositiveInt("INBOUND_JOBS_MAX_ATTEMPTS", defaultInboundJobMaxAttempts),
+		wake:            make(chan struct{}, 1),
+	}
+}
+
+// Enqueue guarda la notificación para procesarla en segundo plano. Si falla, el webhook debe responder con error para que Meta reintente.
+func (s *InboundJobsService) Enqueue(payload []byte) error {
+	job := entities.InboundJob{
+		Payload:     string(payload),
+		Status:      entities.InboundJobStatusPending,
+		MaxAttempts: s.maxAttempts,
+		NextRunAt:   time.Now(),
+	}
+	if err := s.repository.Create(&job); err != nil {
+		return fmt.Errorf("error enqueuing webhook payload: %v", err)
+	}
+
+	// Despertar a un worker sin esperar al próximo poll
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start levanta los workers y el proceso que recupera los jobs abandonados
+func (s *InboundJobsService) Start() {
+	if n, err := s.repository.RequeueStale(time.Now()); err != nil {
+		log.Printf("Error requeuing stale inbound jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("%d inbound jobs que quedaron en processing se volvieron a encolar", n)
+	}
+
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+	go s.requeueStaleLoop()
+
+	log.Printf("InboundJobsService iniciado con %d workers.", s.workers)
+}
+
+func (s *InboundJobsService) worker() {
+	ticker := time.NewTicker(inboundJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Procesar todo lo que haya disponible antes de volver a esperar
+		for s.runNext() {
+		}
+
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *InboundJobsService) requeueStaleLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := s.repository.RequeueStale(time.Now().Add(-inboundJobStaleAfter)); err != nil {
+			log.Printf("Error requeuing stale inbound jobs: %v", err)
+		}
+	}
+}
+
+// runNext toma y procesa un job. Devuelve false si no había jobs disponibles.
+func (s *InboundJobsService) runNext() bool {
+	job, err := s.repository.ClaimNext(time.Now())
+	if err != nil {
+		log.Printf("Error claiming inbound job: %v", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	pending, err := s.process(*job)
+	if err != nil || len(pending) == 0 {
+		s.finish(*job, err)
+		return true
+	}
+
+	// Los mensajes para el assistant esperan la ventana de debounce en el mailbox de su contacto. El worker sigue con
+	// otros jobs y este queda en processing hasta que terminen sus lotes (si el proceso se reinicia, lo recupera RequeueStale).
+	go func() {
+		if err := waitMailboxes(pending); err != nil {
+			s.finish(*job, fmt.Errorf("error processing message: %v", err))
+			return
+		}
+		s.finish(*job, nil)
+	}()
+	return true
+}
+
+// finish marca el job como terminado o lo reprograma si falló
+func (s *InboundJobsService) finish(job entities.InboundJob, err error) {
+	if err != nil {
+		s.handleFailure(job, err)
+		return
+	}
+	if err := s.repository.MarkDone(job.ID, time.Now()); err != nil {
+		log.Printf("Error marking inbound job %d as done: %v", job.ID, err)
+	}
+}
+
+// process procesa la notificación y devuelve los resultados pendientes de los mensajes que quedaron en los mailboxes
+func (s *InboundJobsService) process(job entities.InboundJob) (pending []<-chan error, err error) {
+	// Un payload que hace panic no debe tirar abajo al worker
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic processing job: %v", r)
+		}
+	}()
+
+	var response whatsapp.ResponseComplet
+	if err := json.Unmarshal([]byte(job.Payload), &response); err != nil {
+		return nil, fmt.Errorf("invalid payload: %v", err)
+	}
+
+	// Callbacks de estado de los mensajes enviados (sent, delivered, read, failed)
+	if err := s.whatsappService.HandleMessageStatuses(response); err != nil {
+		return nil, fmt.Errorf("error processing message statuses: %v", err)
+	}
+
+	// Lógica principal delegada al servicio de WhatsApp
+	pending, err = s.whatsappService.HandleIncomingMessageWithAssistant(response)
+	if err != nil {
+		return nil, fmt.Errorf("error processing message: %v", err)
+	}
+	return pending, nil
+}
+
+// handleFailure reprograma el job con backoff exponencial o lo pasa a dead si agotó los intentos
+func (s *InboundJobsService) handleFailure(job entities.InboundJob, jobErr error) {
+	attempts := job.Attempts + 1
+	status := entities.InboundJobStatusPending
+	nextRunAt := time.Now().Add(inboundJobBackoff(attempts))
+
+	if attempts >= job.MaxAttempts {
+		status = entities.InboundJobStatusDead
+		log.Printf("Inbound job %d pasó a dead luego de %d intentos: %v", job.ID, attempts, jobErr)
+	} else {
+		log.Printf("Inbound job %d falló (intento %d/%d), se reintenta a las %s: %v", job.ID, attempts, job.MaxAttempts, nextRunAt.Format("15:04:05"), jobErr)
+	}
+
+	if err := s.repository.MarkFailed(job.ID, status, attempts, jobErr.Error(), nextRunAt); err != nil {
+		log.Printf("Error updating inbound job %d: %v", job.ID, err)
+	}
+}
+
+// inboundJobBackoff devuelve la espera antes del próximo intento: 30s, 1m, 2m, 4m... con un máximo de 1h
+func inboundJobBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := inboundJobBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= inboundJobMaxBackoff {
+			return inboundJobMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// GetJobs devuelve los jobs filtrados por estado con paginación
+func (s *InboundJobsService) GetJobs(status string, page, limit int) ([]dtos.InboundJobDto, int, error) {
+	records, total, err := s.repository.GetByStatus(status, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]dtos.InboundJobDto, len(records))
+	for i, record := range records {
+		jobs[i] = entities.MapEntityToInboundJobDto(record)
+	}
+	return jobs, total, nil
+}
+
+// Replay vuelve a encolar un job en dead para que los workers lo procesen de nuevo
+func (s *InboundJobsService) Replay(id int64) (dtos.InboundJobDto, error) {
+	rows, err := s.repository.Replay(id, time.Now())
+	if err != nil {
+		return dtos.InboundJobDto{}, err
+	}
+	if rows == 0 {
+		if _, err := s.repository.FindByID(id); err != nil {
+			return dtos.InboundJobDto{}, err
+		}
+		return dtos.InboundJobDto{}, ErrInboundJobNotReplayable
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	record, err := s.repository.FindByID(id)
+	if err != nil {
+		return dtos.InboundJobDto{}, err
+	}
+	return entities.MapEntityToInboundJobDto(record), nil
+}
+
+// envPositiveInt lee un entero positivo de una variable de entorno, con un valor por defecto
+func envPositiveInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
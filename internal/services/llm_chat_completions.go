@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaichat"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+)
+
+// ChatCompletionsProvider responde con POST /chat/completions. Sirve para OpenAI y para cualquier endpoint
+// compatible (Ollama, vLLM, LM Studio). La conversación se manda completa en cada pedido.
+type ChatCompletionsProvider struct {
+	client       *clients.OpenAIClient
+	hasBaseURL   bool // false si no hay endpoint por defecto (Ollama sin OLLAMA_URL): cada assistant tiene que traer el suyo
+	defaultModel string
+	// Endpoints que un assistant puede elegir con llm_base_url. Nil para OpenAI: ahí se ignora, porque el cliente
+	// manda la API key de la plataforma a la URL que le pasen
+	allowedBaseURLs map[string]bool
+}
+
+// NewOpenAIChatProvider usa la API de OpenAI con el modelo configurado en cada assistant. Comparte el cliente (y su
+// circuit breaker) con la Assistants API.
+func NewOpenAIChatProvider(client *clients.OpenAIClient) *ChatCompletionsProvider {
+	return &ChatCompletionsProvider{client: client, hasBaseURL: true}
+}
+
+// NewOllamaProvider usa un endpoint local compatible con OpenAI (ej: http://localhost:11434/v1), sin API key.
+// Un assistant puede apuntar a otro endpoint con llm_base_url solo si está en allowedBaseURLs (OLLAMA_ALLOWED_URLS).
+func NewOllamaProvider(baseURL, defaultModel string, allowedBaseURLs []string) *ChatCompletionsProvider {
+	config := clients.OpenAIConfigFromEnv()
+	config.BaseURL = baseURL
+	config.APIKey = ""
+	// Los modelos locales suelen ser más lentos, sobre todo en la primera carga
+	config.Timeout = 5 * time.Minute
+
+	allowed := make(map[string]bool)
+	for _, allowedURL := range append(allowedBaseURLs, baseURL) {
+		if allowedURL = normalizeBaseURL(allowedURL); allowedURL != "" {
+			allowed[allowedURL] = true
+		}
+	}
+	return &ChatCompletionsProvider{
+		client:          clients.NewOpenAIClient(config),
+		hasBaseURL:      baseURL != "",
+		defaultModel:    defaultModel,
+		allowedBaseURLs: allowed,
+	}
+}
+
+func (p *ChatCompletionsProvider) Reply(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	// Vacía usa la URL del cliente
+	var baseURL string
+	if p.allowedBaseURLs != nil && request.Assistant.LLMBaseURL != "" {
+		baseURL = normalizeBaseURL(request.Assistant.LLMBaseURL)
+		if !p.allowedBaseURLs[baseURL] {
+			return LLMResponse{}, fmt.Errorf("llm base url %q not allowed for assistant %d", request.Assistant.LLMBaseURL, request.Assistant.ID)
+		}
+	}
+	if baseURL == "" && !p.hasBaseURL {
+		return LLMResponse{}, fmt.Errorf("llm base url not configured for assistant %d", request.Assistant.ID)
+	}
+
+	model := request.Assistant.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	chatRequest := buildChatCompletionRequest(model, request)
+	var usage LLMUsage
+	for round := 0; ; round++ {
+		completion, err := p.client.ChatCompletion(ctx, baseURL, chatRequest)
+		if err != nil {
+			return LLMResponse{}, fmt.Errorf("error calling chat completions: %w", err)
+		}
+		message := completion.Choices[0].Message
+		usage.add(completion.Model, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+		if usage.Model == "" {
+			usage.Model = model
+		}
+
+		response := LLMResponse{Text: message.Content, Usage: usage}
+		for _, toolCall := range message.ToolCalls {
+			response.ToolCalls = append(response.ToolCalls, LLMToolCall{
+				ID:        toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+		if len(response.ToolCalls) == 0 || request.ExecuteTools == nil {
+			return response, nil
+		}
+		if round >= maxLLMToolRounds {
+			return LLMResponse{}, fmt.Errorf("model still requires tools after %d rounds", maxLLMToolRounds)
+		}
+
+		// Se agrega el pedido de tools y sus resultados a la conversación y se vuelve a llamar al modelo
+		chatRequest.Messages = append(chatRequest.Messages, message)
+		for _, output := range request.ExecuteTools(response.ToolCalls) {
+			chatRequest.Messages = append(chatRequest.Messages, openaichat.ChatMessage{
+				Role:       "tool",
+				Content:    output.Output,
+				ToolCallID: output.ToolCallID,
+			})
+		}
+	}
+}
+
+// buildChatCompletionRequest arma los mensajes: instrucciones del assistant, historial y el mensaje nuevo
+func buildChatCompletionRequest(model string, request LLMRequest) openaichat.ChatCompletionRequest {
+	messages := make([]openaichat.ChatMessage, 0, len(request.History)+2)
+	if request.Assistant.Instructions != "" {
+		messages = append(messages, openaichat.ChatMessage{Role: "system", Content: request.Assistant.Instructions})
+	}
+	for _, message := range request.History {
+		messages = append(messages, openaichat.ChatMessage{Role: message.Role, Content: message.Content})
+	}
+	messages = append(messages, openaichat.ChatMessage{Role: "user", Content: request.Message})
+
+	var tools []openaichat.ChatTool
+	for _, tool := range request.Tools {
+		tools = append(tools, openaichat.ChatTool{
+			Type: "function",
+			Function: openaichat.ChatFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	return openaichat.ChatCompletionRequest{
+		Model:    model,
+		Messages: messages,
+		Tools:    tools,
+	}
+}
+
+// normalizeBaseURL compara las URLs sin espacios ni la barra final
+func normalizeBaseURL(baseURL string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/")
+}
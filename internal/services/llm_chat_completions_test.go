@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openaichat"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+)
+
+// newChatCompletionsServer responde con replies en orden y guarda los pedidos y el header Authorization de cada uno
+func newChatCompletionsServer(t *testing.T, replies ...string) (*httptest.Server, *[]openaichat.ChatCompletionRequest, *[]string) {
+	t.Helper()
+	var requests []openaichat.ChatCompletionRequest
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat/completions" {
+			t.Errorf("request = %s %s, want POST /chat/completions", r.Method, r.URL.Path)
+		}
+		var request openaichat.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, request)
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(requests) > len(replies) {
+			t.Errorf("%d requests, want %d", len(requests), len(replies))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, replies[len(requests)-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests, &authorizations
+}
+
+const chatReplyText = `{"model":"llama3.1","choices":[{"message":{"role":"assistant","content":"¡Hola! ¿En qué te ayudo?"}}],"usage":{"prompt_tokens":20,"completion_tokens":8}}`
+
+func TestChatCompletionsProviderReply(t *testing.T) {
+	server, requests, authorizations := newChatCompletionsServer(t, chatReplyText)
+	provider := NewOllamaProvider(server.URL, "llama3.1", nil)
+
+	response, err := provider.Reply(context.Background(), LLMRequest{
+		Assistant: dtos.AssistantDto{ID: 1, LLMProvider: dtos.LLMProviderOllama, Instructions: "Sos la recepción del consultorio"},
+		Message:   "quiero un turno",
+		History:   []LLMMessage{{Role: "user", Content: "hola"}, {Role: "assistant", Content: "¡Hola!"}},
+	})
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	want := LLMResponse{Text: "¡Hola! ¿En qué te ayudo?", Usage: LLMUsage{Model: "llama3.1", PromptTokens: 20, CompletionTokens: 8}}
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("response = %+v, want %+v", response, want)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("%d requests, want 1", len(*requests))
+	}
+	request := (*requests)[0]
+	var roles []string
+	for _, message := range request.Messages {
+		roles = append(roles, message.Role+": "+message.Content)
+	}
+	wantRoles := []string{"system: Sos la recepción del consultorio", "user: hola", "assistant: ¡Hola!", "user: quiero un turno"}
+	if request.Model != "llama3.1" || !reflect.DeepEqual(roles, wantRoles) {
+		t.Errorf("request = %s %v, want the default model and %v", request.Model, roles, wantRoles)
+	}
+	if (*authorizations)[0] != "" {
+		t.Errorf("Authorization = %q, want no API key sent to Ollama", (*authorizations)[0])
+	}
+}
+
+func TestChatCompletionsProviderToolRoundTrip(t *testing.T) {
+	toolCall := `{"model":"llama3.1","choices":[{"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"check_availability","arguments":"{\"date\":\"2026-03-16\"}"}}]}}],"usage":{"prompt_tokens":30,"completion_tokens":10}}`
+	server, requests, _ := newChatCompletionsServer(t, toolCall, chatReplyText)
+	provider := NewOllamaProvider(server.URL, "llama3.1", nil)
+
+	var executed []LLMToolCall
+	response, err := provider.Reply(context.Background(), LLMRequest{
+		Assistant: dtos.AssistantDto{ID: 1, LLMProvider: dtos.LLMProviderOllama, Model: "qwen2.5"},
+		Message:   "¿tenés turno el lunes?",
+		Tools:     []LLMTool{{Name: "check_availability", Parameters: json.RawMessage(`{"type":"object"}`)}},
+		ExecuteTools: func(calls []LLMToolCall) []LLMToolOutput {
+			executed = append(executed, calls...)
+			return []LLMToolOutput{{ToolCallID: calls[0].ID, Output: `{"available":true}`}}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if want := []LLMToolCall{{ID: "call_1", Name: "check_availability", Arguments: `{"date":"2026-03-16"}`}}; !reflect.DeepEqual(executed, want) {
+		t.Errorf("executed = %+v, want %+v", executed, want)
+	}
+	if response.Text != "¡Hola! ¿En qué te ayudo?" || response.Usage.PromptTokens != 50 || response.Usage.CompletionTokens != 18 {
+		t.Errorf("response = %+v, want the final text with the tokens of both rounds", response)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("%d requests, want 2", len(*requests))
+	}
+	first, second := (*requests)[0], (*requests)[1]
+	if first.Model != "qwen2.5" || len(first.Tools) != 1 || first.Tools[0].Function.Name != "check_availability" {
+		t.Errorf("first request = %+v, want the assistant model and its tools", first)
+	}
+	messages := second.Messages
+	if len(messages) != 3 || len(messages[1].ToolCalls) != 1 || messages[2].Role != "tool" ||
+		messages[2].ToolCallID != "call_1" || messages[2].Content != `{"available":true}` {
+		t.Errorf("second request messages = %+v, want the tool call and its output", messages)
+	}
+}
+
+func TestChatCompletionsProviderBaseURL(t *testing.T) {
+	// Sin OLLAMA_URL y sin endpoint propio no hay a quién preguntarle
+	_, err := NewOllamaProvider("", "llama3.1", nil).Reply(context.Background(), LLMRequest{
+		Assistant: dtos.AssistantDto{ID: 7, LLMProvider: dtos.LLMProviderOllama},
+		Message:   "hola",
+	})
+	if err == nil {
+		t.Error("Reply without base url = nil, want an error")
+	}
+
+	// Un endpoint propio del assistant solo se usa si está permitido, y nunca recibe una API key
+	custom, requests, authorizations := newChatCompletionsServer(t, chatReplyText)
+	provider := NewOllamaProvider("", "llama3.1", []string{" " + custom.URL + "/ "})
+	assistant := dtos.AssistantDto{ID: 7, LLMProvider: dtos.LLMProviderOllama, LLMBaseURL: custom.URL}
+	if _, err := provider.Reply(context.Background(), LLMRequest{Assistant: assistant, Message: "hola"}); err != nil {
+		t.Fatalf("Reply with allowed base url: %v", err)
+	}
+	if len(*requests) != 1 || (*authorizations)[0] != "" {
+		t.Errorf("%d requests with Authorization %q, want one without API key", len(*requests), *authorizations)
+	}
+
+	other, otherRequests, _ := newChatCompletionsServer(t)
+	assistant.LLMBaseURL = other.URL
+	if _, err := provider.Reply(context.Background(), LLMRequest{Assistant: assistant, Message: "hola"}); err == nil || len(*otherRequests) != 0 {
+		t.Errorf("Reply with base url out of the allow-list = %v after %d requests, want an error without calling it", err, len(*otherRequests))
+	}
+}
+
+// OpenAI ignora llm_base_url: la API key de la plataforma solo va a la URL configurada
+func TestOpenAIChatProviderIgnoresAssistantBaseURL(t *testing.T) {
+	openAI, requests, authorizations := newChatCompletionsServer(t, chatReplyText)
+	var customCalls int32
+	custom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&customCalls, 1)
+	}))
+	defer custom.Close()
+
+	client := clients.NewOpenAIClient(clients.OpenAIConfig{BaseURL: openAI.URL, APIKey: "sk-test", MaxRetries: -1, BreakerThreshold: -1})
+	_, err := NewOpenAIChatProvider(client).Reply(context.Background(), LLMRequest{
+		Assistant: dtos.AssistantDto{ID: 1, LLMProvider: dtos.LLMProviderOpenAIChat, Model: "gpt-4o-mini", LLMBaseURL: custom.URL},
+		Message:   "hola",
+	})
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if customCalls != 0 || len(*requests) != 1 || (*authorizations)[0] != "Bearer sk-test" {
+		t.Errorf("custom endpoint got %d requests and OpenAI %d with %q, want only OpenAI with the key", customCalls, len(*requests), *authorizations)
+	}
+}
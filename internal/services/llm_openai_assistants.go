@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openairuns"
+)
+
+// openAIThreadClient son las operaciones de OpenAI que se usan para correr el assistant sobre el thread de un contacto.
+// Lo implementa OpenAIAssistantService; en los tests se reemplaza por un fake.
+type openAIThreadClient interface {
+	ListRunsForThread(ctx context.Context, threadID string, limit int, order, after, before string) ([]openairuns.OpenAIRunResponse, error)
+	SendMessageToThread(ctx context.Context, threadID, message string, user bool) error
+	CreateRunForThreadWithConversation(ctx context.Context, threadID, assistantID string, conversation []map[string]interface{}) (string, error)
+	WaitForRunCompletion(ctx context.Context, threadID, runID string, maxRetries int, retryInterval time.Duration) (openairuns.OpenAIRunResponse, error)
+	GetMessagesFromThread(ctx context.Context, threadID string) (string, error)
+	EnviarToolOutputs(ctx context.Context, threadID, runID string, toolOutputs []openairuns.OpenAIToolOutput) error
+}
+
+// OpenAIAssistantsProvider responde con la Assistants API de OpenAI: la conversación vive en un thread por contacto
+// y el conocimiento del assistant en su vector store.
+type OpenAIAssistantsProvider struct {
+	client                 openAIThreadClient
+	openAIAssistantService *OpenAIAssistantService
+	threadService          *ThreadService
+	fileService            *FileService
+}
+
+func NewOpenAIAssistantsProvider(openAIAssistantService *OpenAIAssistantService, threadService *ThreadService, fileService *FileService) *OpenAIAssistantsProvider {
+	return &OpenAIAssistantsProvider{
+		client:                 openAIAssistantService,
+		openAIAssistantService: openAIAssistantService,
+		threadService:          threadService,
+		fileService:            fileService,
+	}
+}
+
+func (p *OpenAIAssistantsProvider) Reply(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	// Crear o usar el Thread existente
+	thread, err := p.threadService.GetOrCreateThread(ctx, request.Contact, request.Assistant)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error obteniendo o creando thread: %w", err)
+	}
+
+	// Obtengo el vector_store que usa el assistant
+	files, err := p.fileService.GetFileByAssistantID(request.Assistant.ID)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error GetFileByAssistantID: %v", err)
+	}
+
+	if len(files) > 0 {
+		fileAssistant := files[len(files)-1]
+		// Asigno el archivo al hilo.
+		err = p.openAIAssistantService.EjecutarThread(ctx, thread.OpenaiThreadsId, []string{fileAssistant.OpenaiVectorStoreIDs})
+		if err != nil {
+			return LLMResponse{}, fmt.Errorf("error EjecutarThread: %w", err)
+		}
+	} else {
+		fmt.Println("Assistant sin file")
+	}
+
+	return p.runThread(ctx, thread.OpenaiThreadsId, request.Assistant.OpenaiAssistantsID, request.Message, request.ExecuteTools)
+}
+
+// runThread agrega el mensaje al thread, crea un run y espera la respuesta del assistant.
+// Mientras el run pida tools, las ejecuta con executeTools y le envía los resultados.
+func (p *OpenAIAssistantsProvider) runThread(ctx context.Context, threadID, assistantID, message string, executeTools LLMToolExecutor) (LLMResponse, error) {
+
+	// Verificar si es seguro proceder (sin runs activos)
+	safeToProceed, err := p.checkForActiveRuns(ctx, threadID)
+	if err != nil {
+		return LLMResponse{}, err // Devuelve el error si no es seguro proceder
+	}
+	if !safeToProceed {
+		return LLMResponse{}, fmt.Errorf("an active run is still processing")
+	}
+
+	// Crear un run para el thread con la conversación completa
+	err = p.client.SendMessageToThread(ctx, threadID, message, true)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error creating message with conversation: %w", err)
+	}
+
+	runID, err := p.client.CreateRunForThreadWithConversation(ctx, threadID, assistantID, nil)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error creating run with conversation: %w", err)
+	}
+	fmt.Printf("Run created: %s\n", runID)
+
+	// Esperar a que el run esté completado
+	run, err := p.client.WaitForRunCompletion(ctx, threadID, runID, 30, 2*time.Second)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error waiting for run completion: %w", err)
+	}
+
+	// El assistant pide ejecutar funciones dejando el run en required action
+	for round := 0; run.RequiredAction.Type == "submit_tool_outputs" && len(run.RequiredAction.SubmitToolOutputs.ToolCalls) > 0; round++ {
+		var toolCalls []LLMToolCall
+		for _, toolCall := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+			toolCalls = append(toolCalls, LLMToolCall{
+				ID:        toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+		if executeTools == nil {
+			return LLMResponse{ToolCalls: toolCalls}, nil
+		}
+		if round >= maxLLMToolRounds {
+			return LLMResponse{}, fmt.Errorf("run %s still requires tools after %d rounds", runID, maxLLMToolRounds)
+		}
+
+		var toolOutputs []openairuns.OpenAIToolOutput
+		for _, output := range executeTools(toolCalls) {
+			toolOutputs = append(toolOutputs, openairuns.OpenAIToolOutput{ToolCallID: output.ToolCallID, Output: output.Output})
+		}
+		if err := p.client.EnviarToolOutputs(ctx, threadID, runID, toolOutputs); err != nil {
+			return LLMResponse{}, fmt.Errorf("error submitting tool outputs: %w", err)
+		}
+
+		run, err = p.client.WaitForRunCompletion(ctx, threadID, runID, 30, 2*time.Second)
+		if err != nil {
+			return LLMResponse{}, fmt.Errorf("error waiting for run completion: %w", err)
+		}
+	}
+
+	// Obtener los mensajes del thread y encontrar la respuesta del asistente
+	text, err := p.client.GetMessagesFromThread(ctx, threadID)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("error getting messages from thread: %w", err)
+	}
+
+	// El run completado informa los tokens de todos sus pasos, incluidas las rondas de tools
+	var usage LLMUsage
+	if run.Usage != nil {
+		usage.add(run.Model, run.Usage.PromptTokens, run.Usage.CompletionTokens)
+	}
+	return LLMResponse{Text: text, Usage: usage}, nil
+}
+
+// checkForActiveRuns checks if there are any active runs for a given thread and retries a few times if there are.
+// Returns true if it is safe to proceed (no active runs), false and an error otherwise.
+func (p *OpenAIAssistantsProvider) checkForActiveRuns(ctx context.Context, threadID string) (bool, error) {
+	maxRetries := 5
+	retryInterval := time.Second * 10 // 10 segundos de intervalo entre reintento
+
+	for i := 0; i < maxRetries; i++ {
+		runs, err := p.client.ListRunsForThread(ctx, threadID, 20, "desc", "", "")
+		if err != nil {
+			return false, fmt.Errorf("error listing runs for thread: %w", err)
+		}
+
+		activeRunFound := false
+		for _, run := range runs {
+			if run.Status == "active" {
+				activeRunFound = true
+				fmt.Printf("Waiting, found active run: %s\n", run.ID)
+				break
+			}
+			if run.Status == "requires_action" {
+				activeRunFound = true
+				fmt.Printf("Waiting, found requires_action run: %s\n", run.ID)
+				break
+			}
+		}
+
+		if !activeRunFound {
+			return true, nil // No active runs, safe to proceed
+		}
+
+		// If active runs are found and it's not the last retry attempt, wait before retrying
+		if i < maxRetries-1 {
+			fmt.Printf("Retrying in %v seconds...\n", retryInterval.Seconds())
+			if err := sleepContext(ctx, retryInterval); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return false, fmt.Errorf("cannot create a new run, active run did not complete after %d retries", maxRetries)
+}
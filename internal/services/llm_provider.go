@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+)
+
+// LLMMessage es un mensaje previo de la conversación con el contacto
+type LLMMessage struct {
+	Role    string // user o assistant
+	Content string
+}
+
+// LLMTool es una función que el modelo puede pedir ejecutar
+type LLMTool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON Schema de los argumentos
+}
+
+// LLMToolCall es el pedido del modelo de ejecutar una función
+type LLMToolCall struct {
+	ID        string
+	Name      string
+	Arguments string // JSON con los argumentos
+}
+
+// LLMToolOutput es el resultado de ejecutar una tool, que se le devuelve al modelo
+type LLMToolOutput struct {
+	ToolCallID string
+	Output     string // JSON con el resultado
+}
+
+// LLMToolExecutor ejecuta las tools que pidió el modelo y devuelve sus resultados
+type LLMToolExecutor func(calls []LLMToolCall) []LLMToolOutput
+
+// LLMRequest es todo lo que necesita un proveedor para responderle a un contacto
+type LLMRequest struct {
+	Assistant dtos.AssistantDto
+	Contact   dtos.ContactDto
+	Message   string       // Mensaje nuevo del contacto (ya con la fecha y hora actual)
+	History   []LLMMessage // Conversación previa, del más viejo al más nuevo. La Assistants API la ignora porque usa el thread
+	Tools     []LLMTool
+
+	// Si está definido, el proveedor ejecuta las tools que pida el modelo, le devuelve los resultados y sigue
+	// hasta obtener la respuesta final en texto. Si no, devuelve las tool calls en LLMResponse.
+	ExecuteTools LLMToolExecutor
+}
+
+// Máxima cantidad de rondas de tools por mensaje, para no quedar en un loop si el modelo sigue pidiendo funciones
+const maxLLMToolRounds = 5
+
+// LLMResponse es la respuesta del modelo: un texto para el contacto o las funciones que pidió ejecutar
+type LLMResponse struct {
+	Text      string
+	ToolCalls []LLMToolCall
+	Usage     LLMUsage
+}
+
+// LLMUsage son los tokens que consumió el modelo para responder, sumando todas las rondas de tools
+type LLMUsage struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// add suma los tokens de una llamada al modelo
+func (u *LLMUsage) add(model string, promptTokens, completionTokens int) {
+	if model != "" {
+		u.Model = model
+	}
+	u.PromptTokens += promptTokens
+	u.CompletionTokens += completionTokens
+}
+
+// LLMProvider abstrae el modelo que atiende a los contactos de un assistant.
+// El context corta la respuesta completa, incluidas las rondas de tools.
+type LLMProvider interface {
+	Reply(ctx context.Context, request LLMRequest) (LLMResponse, error)
+}
+
+// LLMProviders resuelve el proveedor configurado en cada assistant
+type LLMProviders struct {
+	providers map[string]LLMProvider
+}
+
+// NewLLMProviders registra los proveedores disponibles por nombre (dtos.LLMProviderOpenAIAssistants, etc.)
+func NewLLMProviders(providers map[string]LLMProvider) *LLMProviders {
+	return &LLMProviders{providers: providers}
+}
+
+// For devuelve el proveedor del assistant. Si no tiene uno configurado se usa la Assistants API de OpenAI.
+func (p *LLMProviders) For(assistant dtos.AssistantDto) (LLMProvider, error) {
+	name := assistant.LLMProvider
+	if name == "" {
+		name = dtos.LLMProviderOpenAIAssistants
+	}
+
+	provider, ok := p.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("llm provider %q not available", name)
+	}
+	return provider, nil
+}
@@ -3,14 +3,14 @@ package services
 import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type LogsService struct {
-	repository *postgres_client.LogsRepository
+	repository repositories.LogsRepository
 }
 
-func NewLogsService(repository *postgres_client.LogsRepository) *LogsService {
+func NewLogsService(repository repositories.LogsRepository) *LogsService {
 	return &LogsService{repository: repository}
 }
 
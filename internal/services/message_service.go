@@ -1,17 +1,38 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
 )
 
+// Orden de los estados de un mensaje saliente. Los callbacks pueden llegar desordenados (ej: read antes que delivered).
+var messageStatusRank = map[string]int{
+	whatsapp.StatusSent:      1,
+	whatsapp.StatusDelivered: 2,
+	whatsapp.StatusRead:      3,
+}
+
 type MessagesService struct {
-	repository *postgres_client.MessagesRepository
+	repository       repositories.MessagesRepository
+	statusRepository repositories.MessageStatusesRepository
+	stream           *ConversationStream
+}
+
+func NewMessagesService(repository repositories.MessagesRepository, statusRepository repositories.MessageStatusesRepository, stream *ConversationStream) *MessagesService {
+	return &MessagesService{repository: repository, statusRepository: statusRepository, stream: stream}
 }
 
-func NewMessagesService(repository *postgres_client.MessagesRepository) *MessagesService {
-	return &MessagesService{repository: repository}
+// WithTenant - Devuelve una copia del servicio que solo accede a los mensajes de los números del scope
+func (s *MessagesService) WithTenant(tenant dtos.TenantScope) *MessagesService {
+	return &MessagesService{repository: s.repository.WithTenant(tenant), statusRepository: s.statusRepository, stream: s.stream}
 }
 
 // GetMessagesByNumberPhone - Obtiene los mensajes asociados a un número de teléfono específico con paginación
@@ -44,7 +65,155 @@ func (s *MessagesService) GetMessagesByNumberPhoneAndContact(numberPhoneID int64
 	return dtos, total, nil
 }
 
+// GetFailedMessagesByNumberPhone - Obtiene los mensajes que WhatsApp informó como fallidos para un número de teléfono
+func (s *MessagesService) GetFailedMessagesByNumberPhone(numberPhoneID int64, page int, limit int) ([]dtos.MessageDto, int, error) {
+	messages, total, err := s.repository.GetMessagesByNumberPhoneAndStatus(numberPhoneID, whatsapp.StatusFailed, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dtos := make([]dtos.MessageDto, len(messages))
+	for i, message := range messages {
+		dtos[i] = entities.MapEntityToMessageDto(message)
+	}
+
+	return dtos, total, nil
+}
+
+// GetStatusHistory - Devuelve todos los estados informados por WhatsApp para un mensaje
+func (s *MessagesService) GetStatusHistory(messageID int64) ([]dtos.MessageStatusDto, error) {
+	message, err := s.repository.FindByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.statusRepository.FindByMessageIdWhatsapp(message.MessageIdWhatsapp)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]dtos.MessageStatusDto, len(records))
+	for i, record := range records {
+		history[i] = entities.MapEntityToMessageStatusDto(record)
+	}
+
+	return history, nil
+}
+
 // DoesNumberPhoneExist - Verifica si un number_phones_id existe en la base de datos
 func (s *MessagesService) DoesNumberPhoneExist(numberPhoneID int64) (bool, error) {
 	return s.repository.DoesNumberPhoneExist(numberPhoneID)
 }
+
+// RegisterStatus guarda un callback de estado de WhatsApp en el historial y actualiza el estado del mensaje al que pertenece
+func (s *MessagesService) RegisterStatus(status whatsapp.Status) error {
+	if status.ID == "" || status.Status == "" {
+		return fmt.Errorf("status callback without message id or status")
+	}
+
+	statusAt := time.Now()
+	if seconds, err := strconv.ParseInt(status.Timestamp, 10, 64); err == nil {
+		statusAt = time.Unix(seconds, 0)
+	}
+
+	record := entities.MessageStatus{
+		MessageIdWhatsapp:  status.ID,
+		Status:             status.Status,
+		StatusTimestamp:    statusAt,
+		RecipientID:        status.RecipientID,
+		ConversationID:     status.Conversation.ID,
+		ConversationOrigin: status.Conversation.Origin.Type,
+		PricingCategory:    status.Pricing.Category,
+		Billable:           status.Pricing.Billable,
+	}
+	if len(status.Errors) > 0 {
+		record.ErrorCode = status.Errors[0].Code
+		record.ErrorTitle = status.Errors[0].Title
+		record.ErrorDetails = status.Errors[0].ErrorData.Details
+	}
+
+	message, err := s.repository.FindByMessageIdWhatsapp(status.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("error finding message %s: %w", status.ID, err)
+	}
+	found := err == nil
+	if found {
+		record.MessagesID = &message.ID
+	}
+
+	if err := s.statusRepository.Create(&record); err != nil {
+		return fmt.Errorf("error saving status %s for message %s: %w", status.Status, status.ID, err)
+	}
+
+	// Si el mensaje todavía no se guardó, el estado se aplica cuando se registre (ver ApplyPendingStatuses)
+	if !found || !shouldReplaceStatus(message.Status, status.Status) {
+		return nil
+	}
+
+	if err := s.repository.UpdateStatus(message.ID, record.Status, record.StatusTimestamp, record.ErrorCode, record.ErrorTitle); err != nil {
+		return err
+	}
+	s.publishStatus(message, record.Status, record.ErrorCode, record.ErrorTitle)
+	return nil
+}
+
+// PublishMessage avisa por el stream del dashboard que se guardó un mensaje nuevo
+func (s *MessagesService) PublishMessage(message entities.Message) {
+	s.stream.PublishMessage(message)
+}
+
+func (s *MessagesService) publishStatus(message entities.Message, status string, errorCode int, errorTitle string) {
+	s.stream.Publish(dtos.StreamEventDto{
+		Type:          dtos.StreamEventStatus,
+		NumberPhoneID: message.NumberPhonesID,
+		ContactID:     message.ContactsID,
+		Data: dtos.MessageStatusEventDto{
+			MessageID:         message.ID,
+			MessageIdWhatsapp: message.MessageIdWhatsapp,
+			Status:            status,
+			ErrorCode:         errorCode,
+			ErrorTitle:        errorTitle,
+		},
+	})
+}
+
+// ApplyPendingStatuses aplica a un mensaje recién guardado los estados que WhatsApp informó antes de que existiera en la base
+func (s *MessagesService) ApplyPendingStatuses(message entities.Message) error {
+	if err := s.statusRepository.LinkToMessage(message.MessageIdWhatsapp, message.ID); err != nil {
+		return err
+	}
+
+	records, err := s.statusRepository.FindByMessageIdWhatsapp(message.MessageIdWhatsapp)
+	if err != nil {
+		return err
+	}
+
+	current := message.Status
+	var latest *entities.MessageStatus
+	for i := range records {
+		if shouldReplaceStatus(current, records[i].Status) {
+			current = records[i].Status
+			latest = &records[i]
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	if err := s.repository.UpdateStatus(message.ID, latest.Status, latest.StatusTimestamp, latest.ErrorCode, latest.ErrorTitle); err != nil {
+		return err
+	}
+	s.publishStatus(message, latest.Status, latest.ErrorCode, latest.ErrorTitle)
+	return nil
+}
+
+// shouldReplaceStatus indica si el nuevo estado avanza al actual. Un failed siempre se registra.
+func shouldReplaceStatus(current, incoming string) bool {
+	if incoming == whatsapp.StatusFailed {
+		return true
+	}
+	if current == whatsapp.StatusFailed {
+		return false
+	}
+	return messageStatusRank[incoming] > messageStatusRank[current]
+}
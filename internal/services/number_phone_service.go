@@ -1,20 +1,34 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
 )
 
+// ErrInvalidNumberPhone se devuelve cuando el número de la plataforma o el de notificaciones no es válido
+var ErrInvalidNumberPhone = errors.New("número de teléfono inválido")
+
 type NumberPhonesService struct {
-	repository *postgres_client.NumberPhonesRepository
+	repository repositories.NumberPhonesRepository
 }
 
-func NewNumberPhonesService(repository *postgres_client.NumberPhonesRepository) *NumberPhonesService {
+func NewNumberPhonesService(repository repositories.NumberPhonesRepository) *NumberPhonesService {
 	return &NumberPhonesService{repository: repository}
 }
 
+// WithTenant devuelve una copia del servicio que solo accede a los números de los assistants del scope
+func (s *NumberPhonesService) WithTenant(tenant dtos.TenantScope) *NumberPhonesService {
+	return &NumberPhonesService{repository: s.repository.WithTenant(tenant)}
+}
+
 func (s *NumberPhonesService) GetAll() ([]dtos.NumberPhoneDto, error) {
 	records, err := s.repository.List()
 	if err != nil {
@@ -81,16 +95,65 @@ func (s *NumberPhonesService) GetById(id string) (dtos.NumberPhoneDto, error) {
 	return entities.MapEntityToNumberPhoneDto(record), nil
 }
 
+// FindByWhatsappNumberPhoneID busca el número por el phone_number_id de Meta (el que llega en los webhooks)
+func (s *NumberPhonesService) FindByWhatsappNumberPhoneID(whatsappNumberPhoneID string) (entities.NumberPhone, error) {
+	return s.repository.FindByWhatsappNumberPhoneID(whatsappNumberPhoneID)
+}
+
+// GetAppSecretByWhatsappNumberPhoneID devuelve el app secret configurado para el phone_number_id de Meta.
+// Si el número no tiene uno propio se usa WHATSAPP_APP_SECRET.
+func (s *NumberPhonesService) GetAppSecretByWhatsappNumberPhoneID(whatsappNumberPhoneID string) (string, error) {
+	if whatsappNumberPhoneID != "" {
+		record, err := s.repository.FindByWhatsappNumberPhoneID(whatsappNumberPhoneID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		if record.AppSecret != "" {
+			return record.AppSecret, nil
+		}
+	}
+	return os.Getenv("WHATSAPP_APP_SECRET"), nil
+}
+
 func (s *NumberPhonesService) Create(dto dtos.NumberPhoneDto) error {
+	if dto.NumberPhone == "" {
+		return fmt.Errorf("%w: number_phone es obligatorio", ErrInvalidNumberPhone)
+	}
+	if err := normalizeNumberPhoneDto(&dto); err != nil {
+		return err
+	}
 	record := entities.MapDtoToNumberPhone(dto)
 	return s.repository.Create(record)
 }
 
 func (s *NumberPhonesService) Update(id string, dto dtos.NumberPhoneDto) error {
+	if err := normalizeNumberPhoneDto(&dto); err != nil {
+		return err
+	}
 	record := entities.MapDtoToNumberPhone(dto)
 	return s.repository.Update(id, record)
 }
 
+// normalizeNumberPhoneDto pasa a E.164 el número de la plataforma y el de notificaciones. El de la plataforma puede
+// ser un fijo; al de notificaciones se le escribe por WhatsApp, así que es un celular.
+func normalizeNumberPhoneDto(dto *dtos.NumberPhoneDto) error {
+	if dto.NumberPhone != "" {
+		number, err := phone.Normalize(string(dto.NumberPhone), "")
+		if err != nil {
+			return fmt.Errorf("%w: number_phone: %v", ErrInvalidNumberPhone, err)
+		}
+		dto.NumberPhone = dtos.PhoneNumber(number)
+	}
+	if dto.NumberPhoneToNotify != "" {
+		number, err := phone.NormalizeMobile(string(dto.NumberPhoneToNotify), "")
+		if err != nil {
+			return fmt.Errorf("%w: number_phone_to_notify: %v", ErrInvalidNumberPhone, err)
+		}
+		dto.NumberPhoneToNotify = dtos.PhoneNumber(number)
+	}
+	return nil
+}
+
 func (s *NumberPhonesService) Delete(id string) error {
 	return s.repository.Delete(id)
 }
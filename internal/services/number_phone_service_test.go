@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+)
+
+// El app secret se recibe al crear o editar el número pero nunca se devuelve
+func TestNumberPhoneAppSecretIsWriteOnly(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	service := NewNumberPhonesService(sqlite_client.NewRepositories(db).NumberPhones)
+
+	var request dtos.NumberPhoneDto
+	if err := json.Unmarshal([]byte(`{"assistants_id":1,"number_phone":"+5491100000001","whatsapp_number_phone_id":101,"app_secret":"shh"}`), &request); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if err := service.Create(request); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	list, err := service.GetAll()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("GetAll = %+v, %v", list, err)
+	}
+	encoded, _ := json.Marshal(list)
+	if strings.Contains(string(encoded), "shh") || strings.Contains(string(encoded), "app_secret") {
+		t.Errorf("GET /number-phones body %s contains the app secret", encoded)
+	}
+
+	// Editar el número sin mandar el secret no lo borra
+	if err := service.Update("1", dtos.NumberPhoneDto{MessagingLimitTier: "TIER_1K"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if secret, err := service.GetAppSecretByWhatsappNumberPhoneID("101"); err != nil || secret != "shh" {
+		t.Errorf("app secret = %q, %v; want the stored one", secret, err)
+	}
+
+}
+
+// Los números se guardan en E.164; el de notificaciones es un celular
+func TestNumberPhoneNormalizesNumbers(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	if err := db.Create(&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101}).Error; err != nil {
+		t.Fatalf("seeding number phone: %v", err)
+	}
+	service := NewNumberPhonesService(repos.NumberPhones)
+
+	var request dtos.NumberPhoneDto
+	if err := json.Unmarshal([]byte(`{"number_phone":"+54 9 11 4444-5555","number_phone_to_notify":"0351 15 555-1234"}`), &request); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if err := service.Update("1", request); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	var numberPhone entities.NumberPhone
+	db.First(&numberPhone, 1)
+	if numberPhone.NumberPhone != "+5491144445555" || numberPhone.NumberPhoneToNotify != "+5493515551234" {
+		t.Errorf("numbers were not stored in E.164: %q, %q", numberPhone.NumberPhone, numberPhone.NumberPhoneToNotify)
+	}
+
+	if err := service.Update("1", dtos.NumberPhoneDto{NumberPhoneToNotify: "123"}); !errors.Is(err, ErrInvalidNumberPhone) {
+		t.Errorf("Update with invalid number = %v, want ErrInvalidNumberPhone", err)
+	}
+}
@@ -3,14 +3,14 @@ package services
 import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type Password_resetsService struct {
-	repository *postgres_client.PasswordResetsRepository
+	repository repositories.PasswordResetsRepository
 }
 
-func NewPassword_resetsService(repository *postgres_client.PasswordResetsRepository) *Password_resetsService {
+func NewPassword_resetsService(repository repositories.PasswordResetsRepository) *Password_resetsService {
 	return &Password_resetsService{repository: repository}
 }
 
@@ -3,14 +3,14 @@ package services
 import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type PermissionsService struct {
-	repository *postgres_client.PermissionsRepository
+	repository repositories.PermissionsRepository
 }
 
-func NewPermissionsService(repository *postgres_client.PermissionsRepository) *PermissionsService {
+func NewPermissionsService(repository repositories.PermissionsRepository) *PermissionsService {
 	return &PermissionsService{repository: repository}
 }
 
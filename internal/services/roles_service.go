@@ -3,14 +3,14 @@ package services
 import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 )
 
 type RolesService struct {
-	repository *postgres_client.RolesRepository
+	repository repositories.RolesRepository
 }
 
-func NewRolesService(repository *postgres_client.RolesRepository) *RolesService {
+func NewRolesService(repository repositories.RolesRepository) *RolesService {
 	return &RolesService{repository: repository}
 }
 
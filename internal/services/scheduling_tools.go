@@ -0,0 +1,544 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	googlecalendar "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/googleCalendar"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"google.golang.org/api/calendar/v3"
+)
+
+// Formato de fecha y hora que usa el modelo en meeting_date y new_date
+const toolDateTimeLayout = "2006-01-02T15:04:05"
+
+// schedulingToolArgs son los argumentos que puede mandar el modelo en las tools de turnos
+type schedulingToolArgs struct {
+	DateToSearch string `json:"date_to_search"`
+	EventCode    string `json:"event_code"`
+	UserName     string `json:"user_name"`
+	UserEmail    string `json:"user_email"`
+	MeetingDate  string `json:"meeting_date"`
+	NewDate      string `json:"new_date"`
+}
+
+// toolEvent es un evento tal como se le devuelve al modelo
+type toolEvent struct {
+	Code    string `json:"code"`
+	Summary string `json:"summary"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// registerSchedulingTools registra las tools para consultar, crear, modificar y cancelar turnos
+func (service *WhatsappService) registerSchedulingTools(registry *ToolRegistry) {
+	registry.Register(AssistantTool{
+		Name:        "getMeetings",
+		Description: "Lista los eventos agendados del contacto para una fecha.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"date_to_search": {"type": "string", "description": "Fecha a consultar en formato YYYY-MM-DD"}
+			},
+			"required": ["date_to_search"]
+		}`),
+		Handler:  service.toolGetMeetings,
+		ReadOnly: true,
+	})
+	registry.Register(AssistantTool{
+		Name:        "getMeetingDetails",
+		Description: "Devuelve el detalle de un evento del contacto a partir de su código.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"event_code": {"type": "string", "description": "Código del evento"}
+			},
+			"required": ["event_code"]
+		}`),
+		Handler:  service.toolGetMeetingDetails,
+		ReadOnly: true,
+	})
+	registry.Register(AssistantTool{
+		Name:        "createMeeting",
+		Description: "Agenda un nuevo evento para el contacto.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"user_name": {"type": "string", "description": "Nombre del contacto"},
+				"user_email": {"type": "string", "description": "Email del contacto"},
+				"meeting_date": {"type": "string", "description": "Fecha y hora de inicio en formato YYYY-MM-DDTHH:MM:SS (hora de Argentina)"}
+			},
+			"required": ["user_name", "user_email", "meeting_date"]
+		}`),
+		Handler: service.toolCreateMeeting,
+	})
+	registry.Register(AssistantTool{
+		Name:        "updateEvents",
+		Description: "Cambia la fecha y hora de un evento del contacto.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"event_code": {"type": "string", "description": "Código del evento a modificar"},
+				"new_date": {"type": "string", "description": "Nueva fecha y hora de inicio en formato YYYY-MM-DDTHH:MM:SS (hora de Argentina)"},
+				"user_name": {"type": "string", "description": "Nombre del contacto"},
+				"user_email": {"type": "string", "description": "Email del contacto"}
+			},
+			"required": ["event_code", "new_date"]
+		}`),
+		Handler: service.toolUpdateEvent,
+	})
+	registry.Register(AssistantTool{
+		Name:        "deleteEvent",
+		Description: "Cancela un evento del contacto.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"event_code": {"type": "string", "description": "Código del evento a cancelar"}
+			},
+			"required": ["event_code"]
+		}`),
+		Handler: service.toolDeleteEvent,
+	})
+}
+
+func decodeSchedulingArgs(arguments json.RawMessage) (schedulingToolArgs, error) {
+	var args schedulingToolArgs
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return args, fmt.Errorf("invalid arguments: %v", err)
+	}
+	return args, nil
+}
+
+func mapEventsToToolEvents(events []entities.Events) []toolEvent {
+	result := make([]toolEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, toolEvent{Code: event.CodeEvent, Summary: event.Summary, Start: event.StartDate, End: event.EndDate})
+	}
+	return result
+}
+
+func (service *WhatsappService) toolGetMeetings(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	args, err := decodeSchedulingArgs(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventsDB []entities.Events
+	// Si el contacto es el dueño configurado para recibir notificaciones, se buscan todos los eventos del assistant. Si no, solo los del contacto.
+	if ctx.NumberPhone.NumberPhoneToNotify == ctx.Contact.NumberPhone {
+		eventsDB, err = service.eventsService.GetEventsByContactDateAndNumberPhone(ctx.Contact.ID, args.DateToSearch, ctx.Assistant.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving events by contact, date, and numberPhoneID: %v", err)
+		}
+	} else {
+		eventsDB, err = service.eventsService.GetEventByContactAndDate(ctx.Contact.ID, args.DateToSearch, ctx.Now.Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving events by contact, date, and time: %v", err)
+		}
+	}
+
+	return map[string]interface{}{
+		"date":   args.DateToSearch,
+		"events": mapEventsToToolEvents(eventsDB),
+	}, nil
+}
+
+func (service *WhatsappService) toolGetMeetingDetails(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	args, err := decodeSchedulingArgs(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := service.eventsService.GetEventByCodeEvent(ctx.Contact.ID, args.EventCode)
+	if err != nil || event.ID <= 0 {
+		return map[string]interface{}{"found": false}, nil
+	}
+
+	return map[string]interface{}{
+		"found": true,
+		"event": toolEvent{Code: event.CodeEvent, Summary: event.Summary, Start: event.StartDate, End: event.EndDate},
+	}, nil
+}
+
+func (service *WhatsappService) toolCreateMeeting(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	args, err := decodeSchedulingArgs(arguments)
+	if err != nil {
+		return nil, err
+	}
+	assistant, contact, numberPhone := ctx.Assistant, ctx.Contact, ctx.NumberPhone
+
+	startDate, err := time.Parse(toolDateTimeLayout, args.MeetingDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid meeting_date %q, expected format YYYY-MM-DDTHH:MM:SS", args.MeetingDate)
+	}
+
+	// Verificar que el horario esté dentro del horario de trabajo y que no esté ocupado por otro evento
+	isAvailable, reason, err := service.availabilityService.CheckSlot(assistant, startDate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error checking availability: %v", err)
+	}
+	if !isAvailable {
+		return map[string]interface{}{
+			"created":       false,
+			"reason":        reason,
+			"working_hours": assistant.WorkingHours,
+			"hint":          "Usá getAvailableSlots para ofrecerle al contacto los horarios libres",
+		}, nil
+	}
+
+	dateToSearch := startDate.Format("2006-01-02")
+
+	// Verificar si el contacto ya tiene la cantidad maxima de eventos posibles en un dia
+	eventsInDate, err := service.eventsService.GetEventsByContactDateAndNumberPhone(contact.ID, dateToSearch, assistant.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving events by contact, date, and numberPhoneID: %v", err)
+	}
+	if len(eventsInDate) >= int(assistant.EventCountPerDay) {
+		return map[string]interface{}{
+			"created":            false,
+			"reason":             "max_events_per_day",
+			"max_events_per_day": assistant.EventCountPerDay,
+			"event_type":         assistant.EventType,
+		}, nil
+	}
+
+	eventsExists, err := service.eventsService.GetEventByContactAndDate(contact.ID, dateToSearch, ctx.Now.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving event by contact and date: %v", err)
+	}
+	if len(eventsExists) > 0 && assistant.EventCountPerDay == 1 {
+		// Solo puede haber un evento activo por día
+		return map[string]interface{}{
+			"created":        false,
+			"reason":         "already_has_event",
+			"existing_event": mapEventsToToolEvents(eventsExists[:1])[0],
+		}, nil
+	}
+
+	code, err := service.eventsService.GenerateUniqueCode()
+	if err != nil {
+		return nil, fmt.Errorf("error generating unique event code: %v", err)
+	}
+
+	startDateToStr := startDate.Format("2006-01-02 15:04:05")
+	endDate := startDate.Add(time.Duration(assistant.EventDuration) * time.Minute)
+	endDateStr := endDate.Format(toolDateTimeLayout)
+
+	eventDTO := dtos.EventsDto{
+		Summary:      args.UserName,
+		Description:  "Contacto: " + args.UserEmail + "\n Tel: " + contact.NumberPhone,
+		StartDate:    startDateToStr,
+		EndDate:      endDateStr,
+		AssistantsID: assistant.ID,
+		ContactsID:   contact.ID,
+		CodeEvent:    code,
+	}
+
+	if assistant.AccountGoogle {
+		context := context.Background()
+		token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
+		if err != nil {
+			return nil, err
+		}
+
+		event := &calendar.Event{
+			Summary:     assistant.EventType + " - " + eventDTO.Summary,
+			Description: args.UserName + ", " + args.UserEmail,
+			Start: &calendar.EventDateTime{
+				DateTime: args.MeetingDate,
+				TimeZone: "UTC-3",
+			},
+			End: &calendar.EventDateTime{
+				DateTime: endDateStr,
+				TimeZone: "UTC-3",
+			},
+			Attendees: []*calendar.EventAttendee{
+				{Email: args.UserEmail},
+			},
+			ConferenceData: &calendar.ConferenceData{
+				CreateRequest: &calendar.CreateConferenceRequest{
+					RequestId: fmt.Sprintf("meet-%d", time.Now().Unix()), // ID único
+					ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
+						Type: "hangoutsMeet",
+					},
+				},
+			},
+		}
+		eventGoogleCalendar, err := service.googleCalendarService.CreateGoogleCalendarEvent(token, context, event)
+		if err != nil {
+			log.Println("Error al crear evento en google calendar. " + err.Error())
+		}
+
+		if eventGoogleCalendar != nil {
+			eventDTO.EventGoogleCalendarID = eventGoogleCalendar.Id
+		} else {
+			eventDTO.EventGoogleCalendarID = "eventGoogleCalendar_default"
+		}
+	}
+
+	// La capacidad del horario se vuelve a verificar al crear el evento: otra conversación pudo haberlo ocupado
+	// después de CheckSlot
+	err = service.eventsService.CreateChecked(eventDTO, service.availabilityService.SlotGuard(assistant, startDate, 0))
+	if errors.Is(err, ErrSlotTaken) {
+		service.deleteGoogleCalendarEvent(assistant, eventDTO.EventGoogleCalendarID)
+		return map[string]interface{}{
+			"created": false,
+			"reason":  SlotUnavailableFull,
+			"hint":    "Usá getAvailableSlots para ofrecerle al contacto los horarios libres",
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating event: %v", err)
+	}
+
+	// El nombre y el email que dio el contacto quedan en su perfil (sin pisar lo que cargó un operador)
+	if err := service.fillContactProfile(contact, args.UserName, args.UserEmail); err != nil {
+		log.Printf("Error guardando el perfil del contacto %d: %v", contact.ID, err)
+	}
+
+	// Notificar al dueño del número que un contacto registró un turno
+	templateName, languageCode := service.templatesService.LifecycleTemplate(assistant, dtos.TemplateEventCreated)
+	messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(
+		eventDTO.Summary,
+		startDateToStr,
+		endDate.Format("02/01/2006 15:04"),
+		contact.NumberPhone,
+		eventDTO.CodeEvent,
+		numberPhone.NumberPhoneToNotify,
+		templateName,
+		languageCode,
+	)
+	if err := service.SendMessageTemplate(messageTemplate, *numberPhone); err != nil {
+		fmt.Printf("ERROR AL NOTIFICAR EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s\n", err, eventDTO.CodeEvent)
+	}
+
+	return map[string]interface{}{
+		"created": true,
+		"event": toolEvent{
+			Code:    eventDTO.CodeEvent,
+			Summary: eventDTO.Summary,
+			Start:   startDate.Format("02/01/2006 15:04"),
+			End:     endDate.Format("02/01/2006 15:04"),
+		},
+	}, nil
+}
+
+// deleteGoogleCalendarEvent borra del Google Calendar del assistant un evento que no se llegó a guardar
+func (service *WhatsappService) deleteGoogleCalendarEvent(assistant dtos.AssistantDto, eventID string) {
+	if !assistant.AccountGoogle || eventID == "" || eventID == "eventGoogleCalendar_default" {
+		return
+	}
+	context := context.Background()
+	token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
+	if err == nil {
+		err = service.googleCalendarService.DeleteGoogleCalendarEvent(token, context, eventID)
+	}
+	if err != nil {
+		log.Println("no se pudo eliminar el evento de google: " + err.Error())
+	}
+}
+
+// fillContactProfile completa el nombre y el email del contacto si todavía no los tiene
+func (service *WhatsappService) fillContactProfile(contact *entities.Contact, name, email string) error {
+	name = strings.TrimSpace(name)
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	values := map[string]interface{}{}
+	if contact.Name == "" && name != "" && utf8.RuneCountInString(name) <= 255 {
+		values["name"] = name
+	}
+	if contact.Email == "" && dtos.IsValidEmail(email) {
+		values["email"] = email
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if err := service.contactsRepository.UpdateProfile(contact.ID, values); err != nil {
+		return err
+	}
+	if value, ok := values["name"]; ok {
+		contact.Name = value.(string)
+	}
+	if value, ok := values["email"]; ok {
+		contact.Email = value.(string)
+	}
+	return nil
+}
+
+func (service *WhatsappService) toolUpdateEvent(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	args, err := decodeSchedulingArgs(arguments)
+	if err != nil {
+		return nil, err
+	}
+	assistant, contact, numberPhone := ctx.Assistant, ctx.Contact, ctx.NumberPhone
+
+	eventFound, err := service.eventsService.GetEventByCodeEvent(contact.ID, args.EventCode)
+	if err != nil || eventFound.ID <= 0 {
+		return map[string]interface{}{"updated": false, "reason": "event_not_found"}, nil
+	}
+
+	newDate, err := time.Parse(toolDateTimeLayout, args.NewDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid new_date %q, expected format YYYY-MM-DDTHH:MM:SS", args.NewDate)
+	}
+
+	// Verificar disponibilidad en la nueva fecha y hora, sin contar el propio evento
+	isAvailable, reason, err := service.availabilityService.CheckSlot(assistant, newDate, eventFound.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking availability: %v", err)
+	}
+	if !isAvailable {
+		return map[string]interface{}{
+			"updated":       false,
+			"reason":        reason,
+			"working_hours": assistant.WorkingHours,
+			"hint":          "Usá getAvailableSlots para ofrecerle al contacto los horarios libres",
+		}, nil
+	}
+
+	endDate := newDate.Add(time.Duration(assistant.EventDuration) * time.Minute)
+
+	eventDTO := dtos.EventsDto{
+		ID:                    eventFound.ID,
+		Summary:               eventFound.Summary,
+		Description:           eventFound.Description,
+		StartDate:             args.NewDate,
+		EndDate:               endDate.Format(toolDateTimeLayout),
+		AssistantsID:          assistant.ID,
+		ContactsID:            contact.ID,
+		CodeEvent:             eventFound.CodeEvent,
+		EventGoogleCalendarID: eventFound.EventGoogleCalendarID,
+		CreatedAt:             eventFound.CreatedAt,
+	}
+	// Igual que al crear, la capacidad del nuevo horario se vuelve a verificar al guardar el cambio
+	err = service.eventsService.UpdateChecked(eventDTO, service.availabilityService.SlotGuard(assistant, newDate, eventFound.ID))
+	if errors.Is(err, ErrSlotTaken) {
+		return map[string]interface{}{
+			"updated": false,
+			"reason":  SlotUnavailableFull,
+			"hint":    "Usá getAvailableSlots para ofrecerle al contacto los horarios libres",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if assistant.AccountGoogle {
+		context := context.Background()
+		token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
+		if err != nil {
+			return nil, err
+		}
+
+		event := &googlecalendar.EventRequest{
+			Summary:     eventDTO.Summary,
+			Description: args.UserName + ", " + args.UserEmail,
+			Start:       eventDTO.StartDate,
+			End:         eventDTO.EndDate,
+			ContactsID:  uint(contact.ID),
+		}
+		if _, err := service.googleCalendarService.UpdateGoogleCalendarEvent(token, context, eventDTO.EventGoogleCalendarID, event); err != nil {
+			log.Println("Error al modificar evento en google calendar. " + err.Error())
+		}
+	}
+
+	// Notificar al dueño del número que se modificó el turno
+	contactToString := numberPhone.NumberPhoneToNotify
+	templateName, languageCode := service.templatesService.LifecycleTemplate(assistant, dtos.TemplateEventUpdated)
+	messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(
+		eventDTO.Summary,
+		args.NewDate,
+		endDate.Format("2006-01-02 15:04:05"),
+		contactToString,
+		eventDTO.CodeEvent,
+		contactToString,
+		templateName,
+		languageCode,
+	)
+	if err := service.SendMessageTemplate(messageTemplate, *numberPhone); err != nil {
+		fmt.Printf("ERROR AL NOTIFICAR EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s\n", err, eventDTO.CodeEvent)
+	}
+
+	return map[string]interface{}{
+		"updated": true,
+		"event": toolEvent{
+			Code:    eventDTO.CodeEvent,
+			Summary: eventDTO.Summary,
+			Start:   newDate.Format("02/01/2006 15:04"),
+			End:     endDate.Format("02/01/2006 15:04"),
+		},
+	}, nil
+}
+
+func (service *WhatsappService) toolDeleteEvent(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	args, err := decodeSchedulingArgs(arguments)
+	if err != nil {
+		return nil, err
+	}
+	assistant, contact, numberPhone := ctx.Assistant, ctx.Contact, ctx.NumberPhone
+
+	event, err := service.eventsService.GetEventByCodeEvent(contact.ID, args.EventCode)
+	if err != nil || event.ID <= 0 {
+		return map[string]interface{}{"cancelled": false, "reason": "event_not_found"}, nil
+	}
+
+	if err := service.cancelEvent(assistant, numberPhone, event); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"cancelled": true, "event_code": event.CodeEvent}, nil
+}
+
+// cancelEvent elimina el evento, lo borra del Google Calendar del assistant y le avisa al dueño del número.
+// Lo usan la tool deleteEvent y la respuesta "cancelar" a un recordatorio.
+func (service *WhatsappService) cancelEvent(assistant dtos.AssistantDto, numberPhone *entities.NumberPhone, event dtos.EventsDto) error {
+	if err := service.eventsService.Cancel(event.CodeEvent); err != nil {
+		return err
+	}
+
+	if assistant.AccountGoogle {
+		context := context.Background()
+		token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
+		if err != nil {
+			return err
+		}
+
+		if err := service.googleCalendarService.DeleteGoogleCalendarEvent(token, context, event.EventGoogleCalendarID); err != nil {
+			log.Println("no se pudo eliminar el evento de google: " + err.Error())
+		}
+	}
+
+	// Notificar al dueño del número que se canceló el turno. Si el assistant eligió un template se usa ese
+	// (llega aunque no haya una conversación abierta); si no, el mensaje de texto de siempre.
+	notifyTo := numberPhone.NumberPhoneToNotify
+	if service.templatesService.HasLifecycleTemplate(assistant.ID, dtos.TemplateEventCancelled) {
+		templateName, languageCode := service.templatesService.LifecycleTemplate(assistant, dtos.TemplateEventCancelled)
+		messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(event.Summary, event.StartDate, event.EndDate, notifyTo, event.CodeEvent, notifyTo, templateName, languageCode)
+		if err := service.SendMessageTemplate(messageTemplate, *numberPhone); err != nil {
+			fmt.Printf("ERROR AL NOTIFICAR CANCELACIÓN DE EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s", err, event.CodeEvent)
+		}
+		return nil
+	}
+
+	textNotifyClient := fmt.Sprintf("  \n\n 🔴 *Cancelación de Turno* \n\n🔶 %s : *%s*\n⏰ *Hora de Inicio:* %sHs.\n⏳ *Hora de Fin:* %sHs.\n🔏 *Código:* %s.\n\n⚠️ Su turno ha sido cancelado. Para más información, por favor, contáctenos.",
+		service.utilService.CapitalizeFirstLetter(assistant.EventType),
+		event.Summary,
+		event.StartDate,
+		event.EndDate,
+		event.CodeEvent,
+	)
+	message := metaapi.NewSendMessageWhatsappBasic(textNotifyClient, notifyTo)
+	if _, err := service.postMessage(*numberPhone, message); err != nil {
+		fmt.Printf("ERROR AL NOTIFICAR CANCELACIÓN DE EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s", err, event.CodeEvent)
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+	"gorm.io/gorm"
+)
+
+// ErrMissingBusinessAccount es el error de administrar templates de un número sin whatsapp_business_id
+var ErrMissingBusinessAccount = errors.New("el número no tiene configurado el whatsapp_business_id")
+
+// ErrInvalidTemplate es el error de un template que no se puede crear o asignar al assistant
+var ErrInvalidTemplate = errors.New("template inválido")
+
+// MetaAPIError es el error de un request a la Graph API que falló o que Meta rechazó
+type MetaAPIError struct {
+	Message string
+	Err     error // Error del cliente, un *clients.WhatsappAPIError si Meta respondió con error
+}
+
+func (e *MetaAPIError) Error() string {
+	return e.Message
+}
+
+func (e *MetaAPIError) Unwrap() error {
+	return e.Err
+}
+
+// Templates por defecto de cada momento del ciclo de vida cuando el assistant no eligió uno
+var defaultLifecycleTemplates = map[string]string{
+	dtos.TemplateEventCreated:   metaapi.TemplateEventoCreado,
+	dtos.TemplateEventUpdated:   metaapi.TemplateEventoModificado,
+	dtos.TemplateEventCancelled: metaapi.TemplateEventoCancelado,
+	dtos.TemplateEventReminder:  metaapi.TemplateRecordatorioEvento,
+}
+
+// TemplatesService administra los templates del WhatsApp Business Account de cada número (Graph API) y
+// qué template usa cada assistant para avisar la creación, modificación, cancelación y recordatorio de eventos
+type TemplatesService struct {
+	repository             repositories.MessageTemplatesRepository
+	assistantTemplates     repositories.AssistantTemplatesRepository
+	numberPhonesRepository repositories.NumberPhonesRepository
+	assistantService       *AssistantService
+	whatsappClient         *clients.WhatsappClient
+}
+
+func NewTemplatesService(repository repositories.MessageTemplatesRepository, assistantTemplates repositories.AssistantTemplatesRepository, numberPhonesRepository repositories.NumberPhonesRepository, assistantService *AssistantService, whatsappClient *clients.WhatsappClient) *TemplatesService {
+	return &TemplatesService{
+		repository:             repository,
+		assistantTemplates:     assistantTemplates,
+		numberPhonesRepository: numberPhonesRepository,
+		assistantService:       assistantService,
+		whatsappClient:         whatsappClient,
+	}
+}
+
+// WithTenant devuelve una copia del servicio que solo accede a los números y assistants del scope
+func (s *TemplatesService) WithTenant(tenant dtos.TenantScope) *TemplatesService {
+	scoped := *s
+	scoped.numberPhonesRepository = s.numberPhonesRepository.WithTenant(tenant)
+	scoped.assistantService = s.assistantService.WithTenant(tenant)
+	return &scoped
+}
+
+// GetByNumberPhone lista los templates guardados del número. Con refresh primero los sincroniza con Meta.
+func (s *TemplatesService) GetByNumberPhone(numberPhoneID int64, refresh bool) ([]dtos.MessageTemplateDto, error) {
+	if refresh {
+		if err := s.Sync(numberPhoneID); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.findNumberPhone(numberPhoneID); err != nil {
+		return nil, err
+	}
+
+	records, err := s.repository.FindByNumberPhone(numberPhoneID)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]dtos.MessageTemplateDto, 0, len(records))
+	for _, record := range records {
+		templates = append(templates, entities.MapEntityToMessageTemplateDto(record))
+	}
+	return templates, nil
+}
+
+// Sync trae todos los templates del WhatsApp Business Account del número y reemplaza la copia local
+func (s *TemplatesService) Sync(numberPhoneID int64) error {
+	numberPhone, err := s.findNumberPhone(numberPhoneID)
+	if err != nil {
+		return err
+	}
+	if numberPhone.WhatsappBusinessID == 0 {
+		return ErrMissingBusinessAccount
+	}
+
+	query := url.Values{}
+	query.Set("fields", "id,name,language,status,category,rejected_reason,components")
+	query.Set("limit", "100")
+	next := strconv.FormatInt(numberPhone.WhatsappBusinessID, 10) + "/message_templates?" + query.Encode()
+
+	now := time.Now()
+	var records []entities.MessageTemplate
+	for next != "" {
+		var page metaapi.MessageTemplatesPage
+		if err := s.graphRequest(http.MethodGet, next, numberPhone.TokenPermanent, nil, &page); err != nil {
+			return fmt.Errorf("error listing templates: %w", err)
+		}
+		for _, template := range page.Data {
+			records = append(records, templateRecord(numberPhone.ID, template, now))
+		}
+		next = page.Paging.Next
+	}
+
+	return s.repository.ReplaceForNumberPhone(numberPhone.ID, records)
+}
+
+// Create envía un template nuevo a aprobación y lo guarda con el estado que devuelve Meta
+func (s *TemplatesService) Create(numberPhoneID int64, request dtos.CreateMessageTemplateDto) (dtos.MessageTemplateDto, error) {
+	if err := request.Validate(); err != nil {
+		return dtos.MessageTemplateDto{}, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	numberPhone, err := s.findNumberPhone(numberPhoneID)
+	if err != nil {
+		return dtos.MessageTemplateDto{}, err
+	}
+	if numberPhone.WhatsappBusinessID == 0 {
+		return dtos.MessageTemplateDto{}, ErrMissingBusinessAccount
+	}
+
+	var response metaapi.CreateMessageTemplateResponse
+	endpoint := strconv.FormatInt(numberPhone.WhatsappBusinessID, 10) + "/message_templates"
+	if err := s.graphRequest(http.MethodPost, endpoint, numberPhone.TokenPermanent, request, &response); err != nil {
+		return dtos.MessageTemplateDto{}, fmt.Errorf("error creating template: %w", err)
+	}
+
+	// Los componentes se vuelven a leer con el formato de Meta para calcular los parámetros igual que al sincronizar
+	var components []metaapi.TemplateDefinedPart
+	raw, _ := json.Marshal(request.Components)
+	_ = json.Unmarshal(raw, &components)
+
+	status := response.Status
+	if status == "" {
+		status = dtos.TemplateStatusPending
+	}
+	category := response.Category
+	if category == "" {
+		category = request.Category
+	}
+	record := templateRecord(numberPhone.ID, metaapi.MessageTemplate{
+		ID:         response.ID,
+		Name:       request.Name,
+		Language:   request.Language,
+		Status:     status,
+		Category:   category,
+		Components: components,
+	}, time.Now())
+	if err := s.repository.Upsert(&record); err != nil {
+		return dtos.MessageTemplateDto{}, err
+	}
+	return entities.MapEntityToMessageTemplateDto(record), nil
+}
+
+// Delete borra el template (en todos sus idiomas) del WhatsApp Business Account y de la copia local
+func (s *TemplatesService) Delete(numberPhoneID int64, name string) error {
+	numberPhone, err := s.findNumberPhone(numberPhoneID)
+	if err != nil {
+		return err
+	}
+	if numberPhone.WhatsappBusinessID == 0 {
+		return ErrMissingBusinessAccount
+	}
+
+	query := url.Values{}
+	query.Set("name", name)
+	endpoint := strconv.FormatInt(numberPhone.WhatsappBusinessID, 10) + "/message_templates?" + query.Encode()
+	if err := s.graphRequest(http.MethodDelete, endpoint, numberPhone.TokenPermanent, nil, nil); err != nil {
+		return fmt.Errorf("error deleting template: %w", err)
+	}
+	return s.repository.DeleteByName(numberPhone.ID, name)
+}
+
+// SyncPending vuelve a sincronizar los números que tienen templates esperando aprobación
+func (s *TemplatesService) SyncPending() error {
+	ids, err := s.repository.FindNumberPhoneIDsWithStatus(dtos.TemplateStatusPending)
+	if err != nil {
+		return fmt.Errorf("error finding pending templates: %v", err)
+	}
+	for _, id := range ids {
+		if err := s.Sync(id); err != nil {
+			log.Printf("Error sincronizando los templates del número %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// GetAssistantTemplates devuelve el template de cada momento del ciclo de vida (los no configurados con el valor por defecto)
+func (s *TemplatesService) GetAssistantTemplates(assistantID int64) ([]dtos.AssistantTemplateDto, error) {
+	assistant, err := s.assistantService.FindAssistantById(assistantID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.assistantTemplates.FindByAssistant(assistantID)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := make(map[string]dtos.AssistantTemplateDto, len(records))
+	for _, record := range records {
+		configured[record.Event] = entities.MapEntityToAssistantTemplateDto(record)
+	}
+
+	templates := make([]dtos.AssistantTemplateDto, 0, len(defaultLifecycleTemplates))
+	for _, event := range []string{dtos.TemplateEventCreated, dtos.TemplateEventUpdated, dtos.TemplateEventCancelled, dtos.TemplateEventReminder} {
+		if template, ok := configured[event]; ok {
+			templates = append(templates, template)
+			continue
+		}
+		templates = append(templates, defaultLifecycleTemplate(assistant, event))
+	}
+	return templates, nil
+}
+
+// SetAssistantTemplates reemplaza los templates elegidos por el assistant. Cada template tiene que estar
+// sincronizado en alguno de sus números y esperar la cantidad de parámetros que se envían en ese momento.
+func (s *TemplatesService) SetAssistantTemplates(assistantID int64, requests []dtos.AssistantTemplateDto) ([]dtos.AssistantTemplateDto, error) {
+	if _, err := s.assistantService.FindAssistantById(assistantID); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	records := make([]entities.AssistantTemplate, 0, len(requests))
+	for i := range requests {
+		request := &requests[i]
+		if err := request.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		if seen[request.Event] {
+			return nil, fmt.Errorf("%w: el evento %s está repetido", ErrInvalidTemplate, request.Event)
+		}
+		seen[request.Event] = true
+
+		template, err := s.repository.FindForAssistant(assistantID, request.TemplateName, request.Language)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: el template %s (%s) no está sincronizado en ningún número del assistant", ErrInvalidTemplate, request.TemplateName, request.Language)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if expected := dtos.TemplateEventBodyParameters[request.Event]; template.Schema().BodyParameters != expected {
+			return nil, fmt.Errorf("%w: el template %s tiene %d parámetros en el body y para %s se envían %d", ErrInvalidTemplate,
+				template.Name, template.Schema().BodyParameters, request.Event, expected)
+		}
+
+		records = append(records, entities.AssistantTemplate{
+			Event:        request.Event,
+			TemplateName: request.TemplateName,
+			Language:     request.Language,
+		})
+	}
+
+	if err := s.assistantTemplates.Replace(assistantID, records); err != nil {
+		return nil, err
+	}
+	return s.GetAssistantTemplates(assistantID)
+}
+
+// LifecycleTemplate devuelve el template y el idioma que usa el assistant en el momento indicado
+func (s *TemplatesService) LifecycleTemplate(assistant dtos.AssistantDto, event string) (name, language string) {
+	record, err := s.assistantTemplates.FindByEvent(assistant.ID, event)
+	if err == nil {
+		return record.TemplateName, record.Language
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Error buscando el template %s del assistant %d: %v", event, assistant.ID, err)
+	}
+	template := defaultLifecycleTemplate(assistant, event)
+	return template.TemplateName, template.Language
+}
+
+// HasLifecycleTemplate indica si el assistant eligió un template para el momento
+func (s *TemplatesService) HasLifecycleTemplate(assistantID int64, event string) bool {
+	_, err := s.assistantTemplates.FindByEvent(assistantID, event)
+	return err == nil
+}
+
+func defaultLifecycleTemplate(assistant dtos.AssistantDto, event string) dtos.AssistantTemplateDto {
+	name := defaultLifecycleTemplates[event]
+	if event == dtos.TemplateEventReminder && assistant.ReminderTemplate != "" {
+		name = assistant.ReminderTemplate
+	}
+	return dtos.AssistantTemplateDto{Event: event, TemplateName: name, Language: "es"}
+}
+
+func (s *TemplatesService) findNumberPhone(numberPhoneID int64) (entities.NumberPhone, error) {
+	return s.numberPhonesRepository.FindByID(strconv.FormatInt(numberPhoneID, 10))
+}
+
+// templateRecord arma el registro local del template con el esquema de parámetros de sus componentes
+func templateRecord(numberPhoneID int64, template metaapi.MessageTemplate, syncedAt time.Time) entities.MessageTemplate {
+	components, _ := json.Marshal(template.Components)
+	schema, _ := json.Marshal(templateParameterSchema(template.Components))
+
+	// Meta devuelve NONE cuando el template no fue rechazado
+	rejectedReason := template.RejectedReason
+	if rejectedReason == "NONE" {
+		rejectedReason = ""
+	}
+
+	return entities.MessageTemplate{
+		NumberPhonesID:  numberPhoneID,
+		MetaTemplateID:  template.ID,
+		Name:            template.Name,
+		Language:        template.Language,
+		Category:        template.Category,
+		Status:          template.Status,
+		RejectedReason:  rejectedReason,
+		Components:      string(components),
+		ParameterSchema: string(schema),
+		SyncedAt:        &syncedAt,
+	}
+}
+
+func templateParameterSchema(components []metaapi.TemplateDefinedPart) dtos.TemplateParameterSchemaDto {
+	var schema dtos.TemplateParameterSchemaDto
+	for _, component := range components {
+		switch strings.ToUpper(component.Type) {
+		case "HEADER":
+			schema.HeaderFormat = strings.ToUpper(component.Format)
+			if schema.HeaderFormat == "TEXT" {
+				schema.HeaderParameters = dtos.CountTemplatePlaceholders(component.Text)
+			} else if schema.HeaderFormat != "" {
+				// Los headers con archivo o ubicación reciben un parámetro con el media
+				schema.HeaderParameters = 1
+			}
+		case "BODY":
+			schema.BodyParameters = dtos.CountTemplatePlaceholders(component.Text)
+		case "BUTTONS":
+			for i, button := range component.Buttons {
+				schema.Buttons = append(schema.Buttons, dtos.TemplateButtonSchemaDto{
+					Index:      i,
+					Type:       strings.ToUpper(button.Type),
+					Parameters: dtos.CountTemplatePlaceholders(button.URL),
+				})
+			}
+		}
+	}
+	return schema
+}
+
+// graphRequest hace un request a la Graph API (path relativo a la versión o URL de paginación) y decodifica la
+// respuesta en out (si no es nil). Si el request falla o Meta responde con error se devuelve un *MetaAPIError con su mensaje.
+func (s *TemplatesService) graphRequest(method, path, token string, body, out interface{}) error {
+	if err := s.whatsappClient.Do(context.Background(), token, method, path, body, out); err != nil {
+		return &MetaAPIError{Message: err.Error(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"os"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+)
+
+// TenantService arma el TenantScope de cada request a partir de los bussiness del usuario (bussiness_has_users)
+type TenantService struct {
+	repository repositories.TenantRepository
+}
+
+func NewTenantService(repository repositories.TenantRepository) *TenantService {
+	return &TenantService{repository: repository}
+}
+
+// ResolveScope devuelve los bussiness a los que puede acceder el usuario. El rol configurado en ROL_ADMIN
+// no tiene restricciones.
+func (s *TenantService) ResolveScope(userID int64, role string) (dtos.TenantScope, error) {
+	if adminRole := os.Getenv("ROL_ADMIN"); adminRole != "" && role == adminRole {
+		return dtos.TenantScope{UserID: userID}, nil
+	}
+
+	bussinessIDs, err := s.repository.FindBussinessIDsByUser(userID)
+	if err != nil {
+		return dtos.TenantScope{}, err
+	}
+	return dtos.NewTenantScope(userID, bussinessIDs), nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,23 +9,23 @@ import (
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/mappers"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"gorm.io/gorm"
 )
 
 // ThreadService define la lógica de negocio para hilos
 type ThreadService struct {
-	threadRepo             *postgres_client.ThreadRepository
+	threadRepo             repositories.ThreadRepository
 	openAIAssistantService *OpenAIAssistantService
 }
 
 // NewThreadService crea una nueva instancia del servicio
-func NewThreadService(threadRepo *postgres_client.ThreadRepository, openAIAssistantService *OpenAIAssistantService) *ThreadService {
+func NewThreadService(threadRepo repositories.ThreadRepository, openAIAssistantService *OpenAIAssistantService) *ThreadService {
 	return &ThreadService{threadRepo: threadRepo, openAIAssistantService: openAIAssistantService}
 }
 
 // Obtener el último Thread de un contacto o crear uno nuevo si tiene más de 12 horas
-func (s *ThreadService) GetOrCreateThread(contact dtos.ContactDto, assistant dtos.AssistantDto) (*dtos.ThreadResponse, error) {
+func (s *ThreadService) GetOrCreateThread(ctx context.Context, contact dtos.ContactDto, assistant dtos.AssistantDto) (*dtos.ThreadResponse, error) {
 	// Buscar el último Thread del contacto que no esté eliminado
 	lastThread, err := s.threadRepo.FindLastActiveByContactID(contact.ID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -38,9 +39,9 @@ func (s *ThreadService) GetOrCreateThread(contact dtos.ContactDto, assistant dto
 	}
 
 	// Si no hay un thread reciente, crear uno nuevo en OpenAI
-	newThreadID, err := s.openAIAssistantService.CreateThread(assistant.Model, assistant.Instructions)
+	newThreadID, err := s.openAIAssistantService.CreateThread(ctx, assistant.Model, assistant.Instructions)
 	if err != nil {
-		return nil, fmt.Errorf("error creando thread en OpenAI: %v", err)
+		return nil, fmt.Errorf("error creando thread en OpenAI: %w", err)
 	}
 
 	// Crear el nuevo Thread en la base de datos
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// ToolContext es la conversación en la que el modelo pidió ejecutar la tool
+type ToolContext struct {
+	Assistant   dtos.AssistantDto
+	Contact     *entities.Contact
+	NumberPhone *entities.NumberPhone
+	Now         time.Time // Fecha y hora actual en Argentina
+}
+
+// ToolHandler ejecuta la tool con los argumentos que mandó el modelo. Lo que devuelve se serializa a JSON
+// y se le envía al modelo como resultado; si devuelve error, el modelo recibe {"error": "..."}.
+type ToolHandler func(ctx ToolContext, arguments json.RawMessage) (interface{}, error)
+
+// AssistantTool es una función que el assistant puede pedir ejecutar, con el JSON Schema de sus argumentos
+type AssistantTool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	Handler     ToolHandler
+	// ReadOnly indica que la tool solo consulta datos: se puede ejecutar en paralelo con otras. Las que no lo son
+	// (agendar, cancelar, derivar) se ejecutan de a una y pueden modificar el contacto del ToolContext.
+	ReadOnly bool
+}
+
+// ToolRegistry guarda las tools disponibles para los assistants. Sus definiciones se registran en OpenAI al crear
+// o editar un assistant y se mandan en cada pedido a los proveedores de Chat Completions.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]AssistantTool
+	names []string // Orden de registro, para mandar siempre las definiciones en el mismo orden
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]AssistantTool)}
+}
+
+// Register agrega una tool. Si ya existía una con el mismo nombre la reemplaza.
+func (r *ToolRegistry) Register(tool AssistantTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[tool.Name]; !exists {
+		r.names = append(r.names, tool.Name)
+	}
+	r.tools[tool.Name] = tool
+}
+
+// Definitions devuelve las definiciones de todas las tools registradas
+func (r *ToolRegistry) Definitions() []LLMTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]LLMTool, 0, len(r.names))
+	for _, name := range r.names {
+		tool := r.tools[name]
+		definitions = append(definitions, LLMTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		})
+	}
+	return definitions
+}
+
+// Execute ejecuta todas las tools que pidió el modelo y devuelve los resultados en el mismo orden. Las de solo
+// lectura corren en paralelo, cada una con su copia del contacto; las demás corren de a una y en el orden en que las
+// pidió el modelo, así dos createMeeting del mismo run no verifican el mismo horario a la vez.
+func (r *ToolRegistry) Execute(ctx ToolContext, calls []LLMToolCall) []LLMToolOutput {
+	outputs := make([]LLMToolOutput, len(calls))
+
+	var wg sync.WaitGroup
+	var sequential []int
+	for i, call := range calls {
+		if !r.isReadOnly(call.Name) {
+			sequential = append(sequential, i)
+			continue
+		}
+		readCtx := ctx
+		if ctx.Contact != nil {
+			contact := *ctx.Contact
+			readCtx.Contact = &contact
+		}
+		wg.Add(1)
+		go func(i int, call LLMToolCall) {
+			defer wg.Done()
+			outputs[i] = LLMToolOutput{ToolCallID: call.ID, Output: r.executeOne(readCtx, call)}
+		}(i, call)
+	}
+	for _, i := range sequential {
+		outputs[i] = LLMToolOutput{ToolCallID: calls[i].ID, Output: r.executeOne(ctx, calls[i])}
+	}
+	wg.Wait()
+
+	return outputs
+}
+
+func (r *ToolRegistry) isReadOnly(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tools[name].ReadOnly
+}
+
+func (r *ToolRegistry) executeOne(ctx ToolContext, call LLMToolCall) (output string) {
+	r.mu.RLock()
+	tool, ok := r.tools[call.Name]
+	r.mu.RUnlock()
+	if !ok {
+		return toolErrorOutput(fmt.Errorf("unknown tool %q", call.Name))
+	}
+
+	// Una tool que hace panic no debe cortar la conversación: el modelo recibe el error y le responde al contacto
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("Panic ejecutando la tool %s: %v", call.Name, rec)
+			output = toolErrorOutput(fmt.Errorf("internal error executing %s", call.Name))
+		}
+	}()
+
+	arguments := json.RawMessage(call.Arguments)
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	result, err := tool.Handler(ctx, arguments)
+	if err != nil {
+		log.Printf("Error ejecutando la tool %s (contacto %d): %v", call.Name, ctx.Contact.ID, err)
+		return toolErrorOutput(err)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return toolErrorOutput(fmt.Errorf("error encoding result: %v", err))
+	}
+	return string(encoded)
+}
+
+func toolErrorOutput(err error) string {
+	encoded, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(encoded)
+}
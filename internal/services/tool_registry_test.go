@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// Las tools con efectos corren de a una y en orden; las de solo lectura en paralelo con su copia del contacto
+func TestToolRegistryExecute(t *testing.T) {
+	registry := NewToolRegistry()
+	var running, maxRunning int32
+	var mu sync.Mutex
+	var order []string
+
+	write := func(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(10 * time.Millisecond)
+
+		var args struct{ Name string }
+		json.Unmarshal(arguments, &args)
+		ctx.Contact.Name = args.Name
+		mu.Lock()
+		order = append(order, args.Name)
+		mu.Unlock()
+		return args.Name, nil
+	}
+	read := func(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return ctx.Contact.Name, nil
+	}
+	registry.Register(AssistantTool{Name: "write", Handler: write})
+	registry.Register(AssistantTool{Name: "read", Handler: read, ReadOnly: true})
+
+	contact := &entities.Contact{ID: 1, Name: "inicial"}
+	outputs := registry.Execute(ToolContext{Contact: contact}, []LLMToolCall{
+		{ID: "1", Name: "write", Arguments: `{"name":"a"}`},
+		{ID: "2", Name: "read"},
+		{ID: "3", Name: "write", Arguments: `{"name":"b"}`},
+		{ID: "4", Name: "read"},
+		{ID: "5", Name: "missing"},
+	})
+
+	want := []string{`"a"`, `"inicial"`, `"b"`, `"inicial"`, `{"error":"unknown tool \"missing\""}`}
+	for i, output := range outputs {
+		if output.ToolCallID != []string{"1", "2", "3", "4", "5"}[i] || output.Output != want[i] {
+			t.Errorf("output %d = %+v, want %s", i, output, want[i])
+		}
+	}
+	if maxRunning != 1 {
+		t.Errorf("%d tools with side effects ran at once, want 1", maxRunning)
+	}
+	if len(order) != 2 || order[0] != "a" || order[1] != "b" {
+		t.Errorf("tools with side effects ran in order %v, want [a b]", order)
+	}
+	if contact.Name != "b" {
+		t.Errorf("contact name = %q, want the change of the last tool", contact.Name)
+	}
+}
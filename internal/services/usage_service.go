@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidUsageFilter se devuelve cuando los parámetros del reporte o de la cuota no son válidos
+var ErrInvalidUsageFilter = errors.New("parámetros inválidos")
+
+// LLMPrice es el precio de un modelo en USD por millón de tokens
+type LLMPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Precios de lista de OpenAI. Se pueden reemplazar o completar con LLM_PRICES, un JSON con el mismo formato
+// ({"gpt-4o-mini": {"prompt": 0.15, "completion": 0.6}}). Los modelos sin precio (los locales) cuestan 0.
+var defaultLLMPrices = map[string]LLMPrice{
+	"gpt-3.5-turbo": {Prompt: 0.50, Completion: 1.50},
+	"gpt-4-turbo":   {Prompt: 10, Completion: 30},
+	"gpt-4o":        {Prompt: 2.50, Completion: 10},
+	"gpt-4o-mini":   {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":       {Prompt: 2, Completion: 8},
+	"gpt-4.1-mini":  {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1-nano":  {Prompt: 0.10, Completion: 0.40},
+}
+
+// LLMPrices es la tabla de precios por modelo
+type LLMPrices map[string]LLMPrice
+
+// LoadLLMPrices devuelve los precios por defecto con los de LLM_PRICES encima
+func LoadLLMPrices() LLMPrices {
+	prices := LLMPrices{}
+	for model, price := range defaultLLMPrices {
+		prices[model] = price
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("LLM_PRICES")); raw != "" {
+		var custom map[string]LLMPrice
+		if err := json.Unmarshal([]byte(raw), &custom); err != nil {
+			log.Printf("LLM_PRICES inválido, se usan los precios por defecto: %v", err)
+			return prices
+		}
+		for model, price := range custom {
+			prices[strings.ToLower(model)] = price
+		}
+	}
+	return prices
+}
+
+// Cost devuelve el costo en USD. OpenAI informa el modelo con la versión ("gpt-4o-mini-2024-07-18"), así que si no
+// hay un precio exacto se usa el del nombre más largo que sea prefijo.
+func (p LLMPrices) Cost(model string, promptTokens, completionTokens int64) float64 {
+	model = strings.ToLower(model)
+	price, ok := p[model]
+	if !ok {
+		matched := ""
+		for name, candidate := range p {
+			if strings.HasPrefix(model, name+"-") && len(name) > len(matched) {
+				matched, price, ok = name, candidate, true
+			}
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1_000_000
+}
+
+// UsageService registra el consumo de tokens del modelo, arma los reportes y controla la cuota de cada bussiness
+type UsageService struct {
+	repository          repositories.TokenUsagesRepository
+	bussinessRepository repositories.BussinessRepository
+	contactsRepository  repositories.ContactsRepository
+	prices              LLMPrices
+	tenant              dtos.TenantScope
+	now                 func() time.Time
+}
+
+func NewUsageService(repository repositories.TokenUsagesRepository, bussinessRepository repositories.BussinessRepository, contactsRepository repositories.ContactsRepository, prices LLMPrices) *UsageService {
+	return &UsageService{
+		repository:          repository,
+		bussinessRepository: bussinessRepository,
+		contactsRepository:  contactsRepository,
+		prices:              prices,
+		now:                 time.Now,
+	}
+}
+
+// WithTenant devuelve una copia del servicio que solo accede al consumo de los bussiness del scope
+func (s *UsageService) WithTenant(tenant dtos.TenantScope) *UsageService {
+	scoped := *s
+	scoped.repository = s.repository.WithTenant(tenant)
+	scoped.bussinessRepository = s.bussinessRepository.WithTenant(tenant)
+	scoped.tenant = tenant
+	return &scoped
+}
+
+// today es la fecha actual en la zona horaria de Argentina, la misma que usa el assistant
+func (s *UsageService) today() time.Time {
+	loc, err := time.LoadLocation("America/Argentina/Buenos_Aires")
+	if err != nil {
+		return s.now()
+	}
+	return s.now().In(loc)
+}
+
+// Record guarda los tokens que consumió una respuesta al contacto y actualiza su total
+func (s *UsageService) Record(assistant dtos.AssistantDto, numberPhoneID, contactID int64, usage LLMUsage) error {
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return nil
+	}
+	provider := assistant.LLMProvider
+	if provider == "" {
+		provider = dtos.LLMProviderOpenAIAssistants
+	}
+
+	record := entities.TokenUsage{
+		BussinessID:      assistant.BussinessID,
+		AssistantsID:     assistant.ID,
+		NumberPhonesID:   numberPhoneID,
+		ContactsID:       contactID,
+		Day:              s.today().Format(dtos.UsageDayLayout),
+		Provider:         provider,
+		Model:            usage.Model,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		Cost:             s.prices.Cost(usage.Model, int64(usage.PromptTokens), int64(usage.CompletionTokens)),
+	}
+	if err := s.repository.Create(&record); err != nil {
+		return fmt.Errorf("error saving token usage: %v", err)
+	}
+
+	total, err := s.repository.ContactTotal(contactID)
+	if err != nil {
+		return fmt.Errorf("error summing contact tokens: %v", err)
+	}
+	return s.contactsRepository.UpdateCountTokens(contactID, total)
+}
+
+// Report devuelve el consumo del período agrupado según el filtro
+func (s *UsageService) Report(filter dtos.UsageFilterDto) (dtos.UsageReportDto, error) {
+	if err := filter.Validate(s.today()); err != nil {
+		return dtos.UsageReportDto{}, fmt.Errorf("%w: %v", ErrInvalidUsageFilter, err)
+	}
+	if filter.BussinessID > 0 && !s.tenant.Allows(filter.BussinessID) {
+		return dtos.UsageReportDto{}, gorm.ErrRecordNotFound
+	}
+
+	rows, err := s.repository.Report(filter)
+	if err != nil {
+		return dtos.UsageReportDto{}, err
+	}
+
+	report := dtos.UsageReportDto{From: filter.From, To: filter.To, GroupBy: filter.GroupBy, Rows: make([]dtos.UsageReportRowDto, 0, len(rows))}
+	for _, row := range rows {
+		row.TotalTokens = row.PromptTokens + row.CompletionTokens
+		report.Rows = append(report.Rows, row)
+
+		report.Totals.PromptTokens += row.PromptTokens
+		report.Totals.CompletionTokens += row.CompletionTokens
+		report.Totals.TotalTokens += row.TotalTokens
+		report.Totals.Runs += row.Runs
+		report.Totals.Cost += row.Cost
+	}
+	return report, nil
+}
+
+// GetQuota devuelve la cuota del bussiness y lo que consumió en el mes en curso
+func (s *UsageService) GetQuota(bussinessID int64) (dtos.BussinessQuotaDto, error) {
+	bussiness, err := s.bussinessRepository.FindByID(bussinessID)
+	if err != nil {
+		return dtos.BussinessQuotaDto{}, err
+	}
+
+	today := s.today()
+	firstDay := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	tokens, cost, err := s.repository.BussinessTotals(bussinessID, firstDay.Format(dtos.UsageDayLayout), today.Format(dtos.UsageDayLayout))
+	if err != nil {
+		return dtos.BussinessQuotaDto{}, err
+	}
+
+	fallback := bussiness.QuotaFallbackMessage
+	if fallback == "" {
+		fallback = dtos.DefaultQuotaFallbackMessage
+	}
+	return dtos.BussinessQuotaDto{
+		BussinessID:       bussiness.ID,
+		MonthlyTokenQuota: bussiness.MonthlyTokenQuota,
+		MonthlyCostQuota:  bussiness.MonthlyCostQuota,
+		FallbackMessage:   fallback,
+		Month:             today.Format("2006-01"),
+		UsedTokens:        tokens,
+		UsedCost:          cost,
+		Exceeded: (bussiness.MonthlyTokenQuota > 0 && tokens >= bussiness.MonthlyTokenQuota) ||
+			(bussiness.MonthlyCostQuota > 0 && cost >= bussiness.MonthlyCostQuota),
+	}, nil
+}
+
+// UpdateQuota cambia la cuota mensual del bussiness
+func (s *UsageService) UpdateQuota(bussinessID int64, request dtos.BussinessQuotaRequestDto) (dtos.BussinessQuotaDto, error) {
+	if err := request.Validate(); err != nil {
+		return dtos.BussinessQuotaDto{}, fmt.Errorf("%w: %v", ErrInvalidUsageFilter, err)
+	}
+	if _, err := s.bussinessRepository.FindByID(bussinessID); err != nil {
+		return dtos.BussinessQuotaDto{}, err
+	}
+
+	values := map[string]interface{}{}
+	if request.MonthlyTokenQuota != nil {
+		values["monthly_token_quota"] = *request.MonthlyTokenQuota
+	}
+	if request.MonthlyCostQuota != nil {
+		values["monthly_cost_quota"] = *request.MonthlyCostQuota
+	}
+	if request.FallbackMessage != nil {
+		values["quota_fallback_message"] = *request.FallbackMessage
+	}
+	if err := s.bussinessRepository.UpdateQuota(bussinessID, values); err != nil {
+		return dtos.BussinessQuotaDto{}, err
+	}
+	return s.GetQuota(bussinessID)
+}
+
+// QuotaExceeded indica si el bussiness ya consumió su cuota del mes y, en ese caso, el mensaje con el que responde
+// el bot mientras tanto
+func (s *UsageService) QuotaExceeded(bussinessID int64) (bool, string, error) {
+	// Se consulta en cada mensaje: sin cuota configurada no hace falta sumar el consumo
+	bussiness, err := s.bussinessRepository.FindByID(bussinessID)
+	if err != nil {
+		return false, "", err
+	}
+	if bussiness.MonthlyTokenQuota == 0 && bussiness.MonthlyCostQuota == 0 {
+		return false, "", nil
+	}
+
+	quota, err := s.GetQuota(bussinessID)
+	if err != nil {
+		return false, "", err
+	}
+	return quota.Exceeded, quota.FallbackMessage, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+)
+
+func TestLLMPricesCost(t *testing.T) {
+	prices := LLMPrices{
+		"gpt-4o":      {Prompt: 2.50, Completion: 10},
+		"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60},
+	}
+
+	cases := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-4o", 2.50 + 10},
+		{"GPT-4o-mini", 0.15 + 0.60},
+		// OpenAI informa el modelo con la versión: gana el prefijo más largo
+		{"gpt-4o-mini-2024-07-18", 0.15 + 0.60},
+		{"gpt-4o-2024-08-06", 2.50 + 10},
+		{"gpt-4o2", 0},
+		{"llama3", 0},
+	}
+	for _, tc := range cases {
+		if got := prices.Cost(tc.model, 1_000_000, 1_000_000); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Cost(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestUsageReportAndQuota(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+
+	// 15:00 UTC del 15 de marzo son las 12:00 en Buenos Aires
+	today := "2026-03-15"
+	records := []interface{}{
+		&entities.Bussines{ID: 1, Name: "Consultorio", Address: "calle 123"},
+		&entities.Bussines{ID: 2, Name: "Peluquería", Address: "calle 456"},
+		&entities.TokenUsage{BussinessID: 1, AssistantsID: 1, NumberPhonesID: 1, ContactsID: 1, Day: today, Model: "gpt-4o-mini", PromptTokens: 300, CompletionTokens: 100, Cost: 0.0001},
+		&entities.TokenUsage{BussinessID: 1, AssistantsID: 1, NumberPhonesID: 1, ContactsID: 1, Day: today, Model: "gpt-4o-mini", PromptTokens: 500, CompletionTokens: 100, Cost: 0.0002},
+		&entities.TokenUsage{BussinessID: 2, AssistantsID: 2, NumberPhonesID: 2, ContactsID: 2, Day: today, Model: "gpt-4o-mini", PromptTokens: 9000, CompletionTokens: 1000, Cost: 0.002},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+	service := NewUsageService(repos.TokenUsages, repos.Bussiness, repos.Contacts, LLMPrices{})
+	service.now = func() time.Time { return time.Date(2026, 3, 15, 15, 0, 0, 0, time.UTC) }
+	scoped := service.WithTenant(dtos.NewTenantScope(1, []int64{1}))
+
+	// Sin filtro de bussiness el reporte solo suma los del scope
+	report, err := scoped.Report(dtos.UsageFilterDto{GroupBy: []string{"assistant", "model"}})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	want := dtos.UsageReportRowDto{AssistantsID: 1, Model: "gpt-4o-mini", PromptTokens: 800, CompletionTokens: 200, TotalTokens: 1000, Runs: 2}
+	if len(report.Rows) != 1 || math.Abs(report.Rows[0].Cost-0.0003) > 1e-9 {
+		t.Fatalf("report rows = %+v, want only %+v", report.Rows, want)
+	}
+	row := report.Rows[0]
+	row.Cost = 0
+	if row != want {
+		t.Errorf("report row = %+v, want %+v", row, want)
+	}
+	if _, err := scoped.Report(dtos.UsageFilterDto{GroupBy: []string{"semana"}}); !errors.Is(err, ErrInvalidUsageFilter) {
+		t.Errorf("Report with invalid group_by = %v, want ErrInvalidUsageFilter", err)
+	}
+
+	quota, fallback := int64(1000), "Volvemos pronto"
+	updated, err := scoped.UpdateQuota(1, dtos.BussinessQuotaRequestDto{MonthlyTokenQuota: &quota, FallbackMessage: &fallback})
+	if err != nil || updated.UsedTokens != 1000 || !updated.Exceeded {
+		t.Errorf("UpdateQuota = %+v, %v; want 1000 tokens used and the quota exceeded", updated, err)
+	}
+	negative := int64(-1)
+	if _, err := scoped.UpdateQuota(1, dtos.BussinessQuotaRequestDto{MonthlyTokenQuota: &negative}); !errors.Is(err, ErrInvalidUsageFilter) {
+		t.Errorf("UpdateQuota with negative quota = %v, want ErrInvalidUsageFilter", err)
+	}
+
+	exceeded, message, err := service.QuotaExceeded(1)
+	if err != nil || !exceeded || message != "Volvemos pronto" {
+		t.Errorf("QuotaExceeded(1) = %v, %q, %v; want true, \"Volvemos pronto\"", exceeded, message, err)
+	}
+	if exceeded, _, err := service.QuotaExceeded(2); err != nil || exceeded {
+		t.Errorf("QuotaExceeded(2) = %v, %v; want false without quota", exceeded, err)
+	}
+}
@@ -6,16 +6,16 @@ import (
 
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UsersService struct {
-	repository   *postgres_client.UsersRepository
+	repository   repositories.UsersRepository
 	rolesService *RolesService
 }
 
-func NewUsersService(repository *postgres_client.UsersRepository, rolesService *RolesService) *UsersService {
+func NewUsersService(repository repositories.UsersRepository, rolesService *RolesService) *UsersService {
 	return &UsersService{repository: repository, rolesService: rolesService}
 }
 
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// escalateToHumanArgs son los argumentos de la tool escalateToHuman
+type escalateToHumanArgs struct {
+	Reason string `json:"reason"`
+}
+
+// registerHandoffTools registra la tool con la que el assistant deriva la conversación a un operador
+func (service *WhatsappService) registerHandoffTools(registry *ToolRegistry) {
+	registry.Register(AssistantTool{
+		Name: "escalateToHuman",
+		Description: "Deriva la conversación a una persona del negocio. Usala cuando el contacto pida hablar con una persona " +
+			"o cuando no puedas resolver lo que necesita. Después de llamarla avisale al contacto que lo va a atender una persona.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"reason": {"type": "string", "description": "Motivo por el que se deriva, en pocas palabras"}
+			},
+			"required": ["reason"]
+		}`),
+		Handler: service.toolEscalateToHuman,
+	})
+}
+
+func (service *WhatsappService) toolEscalateToHuman(ctx ToolContext, arguments json.RawMessage) (interface{}, error) {
+	var args escalateToHumanArgs
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %v", err)
+	}
+	// handoff_reason es de 255 caracteres: se corta por runas para no partir una letra con tilde o un emoji
+	reason := strings.TrimSpace(args.Reason)
+	if runes := []rune(reason); len(runes) > 255 {
+		reason = string(runes[:255])
+	}
+
+	if err := service.handoffService.TakeOver(ctx.Contact.ID, reason); err != nil {
+		return nil, fmt.Errorf("error escalating conversation: %v", err)
+	}
+
+	// Se avisa al número de notificaciones del negocio para que un operador tome la conversación
+	if ctx.NumberPhone.NumberPhoneToNotify != "" {
+		message := fmt.Sprintf("🙋 El contacto %s pidió hablar con una persona.\n*Motivo:* %s\n\nRespondele desde la bandeja de %s.",
+			ctx.Contact.NumberPhone, reason, ctx.Assistant.Name)
+		if err := service.SendWhatsappNotification(*ctx.NumberPhone, message); err != nil {
+			log.Printf("Error avisando la derivación del contacto %d: %v", ctx.Contact.ID, err)
+		}
+	}
+
+	return map[string]string{
+		"status":  "escalated",
+		"message": "La conversación quedó derivada a una persona del negocio, que va a responder por este mismo chat.",
+	}, nil
+}
+
+// SendOperatorMessage envía al contacto un mensaje escrito por un operador desde la bandeja. La conversación
+// pasa a ser atendida por el operador (o se extiende su vencimiento) para que el assistant no responda encima.
+// El contacto ya tiene que estar validado contra el tenant del operador. Fuera de la ventana de 24 horas
+// devuelve dtos.ErrOutsideServiceWindow.
+func (service *WhatsappService) SendOperatorMessage(contactID, usersID int64, text string) (dtos.MessageDto, error) {
+	contact, err := service.handoffService.GetContact(contactID)
+	if err != nil {
+		return dtos.MessageDto{}, err
+	}
+
+	numberPhone, err := service.numberPhone.repository.FindByID(strconv.FormatInt(contact.NumberPhonesID, 10))
+	if err != nil {
+		return dtos.MessageDto{}, fmt.Errorf("number phone not found: %v", err)
+	}
+
+	if err := service.checkServiceWindow(contact.ID); err != nil {
+		return dtos.MessageDto{}, err
+	}
+	if err := service.handoffService.TakeOver(contact.ID, ""); err != nil {
+		return dtos.MessageDto{}, err
+	}
+
+	var operator *int64
+	if usersID > 0 {
+		operator = &usersID
+	}
+	saved, err := service.sendTextToContact(&numberPhone, &contact, text, operator)
+	return entities.MapEntityToMessageDto(saved), err
+}
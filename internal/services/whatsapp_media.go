@@ -0,0 +1,222 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"strings"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// Tamaño máximo que se descarga de un archivo recibido por WhatsApp (límite de documentos en la Cloud API)
+const maxWhatsappMediaSize = 100 << 20
+
+// inboundContent es lo que se obtiene de un mensaje entrante luego de procesarlo según su tipo:
+// el texto que se le envía al assistant y, si corresponde, el archivo guardado en MinIO.
+type inboundContent struct {
+	Text          string
+	MessageType   string
+	MediaPath     string
+	MediaMimeType string
+}
+
+// buildInboundContent convierte cualquier tipo de mensaje de la Cloud API en algo que el assistant pueda usar.
+// Si el mensaje no tiene contenido útil (reacciones, stickers, tipos no soportados) devuelve Text vacío.
+func (service *WhatsappService) buildInboundContent(message whatsapp.Message, numberPhone *entities.NumberPhone) (inboundContent, error) {
+	content := inboundContent{MessageType: message.Type}
+
+	switch message.Type {
+	case whatsapp.MessageTypeText:
+		content.Text = message.Text.Body
+	case whatsapp.MessageTypeAudio:
+		return service.handleAudioMessage(message, numberPhone)
+	case whatsapp.MessageTypeImage, whatsapp.MessageTypeVideo, whatsapp.MessageTypeDocument:
+		return service.handleFileMessage(message, numberPhone)
+	case whatsapp.MessageTypeLocation:
+		content.Text = formatLocationMessage(message.Location)
+	case whatsapp.MessageTypeContacts:
+		content.Text = formatSharedContactsMessage(message.Contacts)
+	case whatsapp.MessageTypeInteractive:
+		content.Text = formatInteractiveMessage(message.Interactive)
+	case whatsapp.MessageTypeButton:
+		if message.Button != nil {
+			content.Text = message.Button.Text
+		}
+	case whatsapp.MessageTypeReaction, whatsapp.MessageTypeSticker:
+		// No se le responde al contacto por una reacción o un sticker
+		log.Printf("Mensaje %s de tipo %s ignorado", message.ID, message.Type)
+	default:
+		log.Printf("Tipo de mensaje no soportado: %s (mensaje %s)", message.Type, message.ID)
+	}
+
+	return content, nil
+}
+
+// handleAudioMessage descarga la nota de voz, la guarda en MinIO y la transcribe con OpenAI
+func (service *WhatsappService) handleAudioMessage(message whatsapp.Message, numberPhone *entities.NumberPhone) (inboundContent, error) {
+	content := inboundContent{MessageType: message.Type}
+	if message.Audio == nil {
+		return content, fmt.Errorf("audio message %s without media", message.ID)
+	}
+
+	data, mimeType, err := service.downloadMedia(*numberPhone, message.Audio.ID)
+	if err != nil {
+		return content, fmt.Errorf("error downloading audio: %v", err)
+	}
+
+	filename := mediaFilename(message.Audio.ID, "", mimeType)
+	content.MediaMimeType = mimeType
+	content.MediaPath, err = service.fileService.UploadMedia(bytes.NewReader(data), filename, int64(len(data)), mimeType)
+	if err != nil {
+		return content, err
+	}
+
+	transcript, err := service.openAIAssistantService.TranscribeAudio(context.Background(), bytes.NewReader(data), filename)
+	if err != nil {
+		return content, fmt.Errorf("error transcribing audio: %v", err)
+	}
+	if transcript == "" {
+		return content, nil
+	}
+
+	content.Text = fmt.Sprintf("[Nota de voz transcripta]\n%s", transcript)
+	return content, nil
+}
+
+// handleFileMessage descarga imágenes, videos y documentos, los guarda en MinIO y arma un texto con el caption y el archivo
+func (service *WhatsappService) handleFileMessage(message whatsapp.Message, numberPhone *entities.NumberPhone) (inboundContent, error) {
+	content := inboundContent{MessageType: message.Type}
+
+	var media *whatsapp.Media
+	var label string
+	switch message.Type {
+	case whatsapp.MessageTypeImage:
+		media, label = message.Image, "Imagen"
+	case whatsapp.MessageTypeVideo:
+		media, label = message.Video, "Video"
+	case whatsapp.MessageTypeDocument:
+		media, label = message.Document, "Documento"
+	}
+	if media == nil {
+		return content, fmt.Errorf("%s message %s without media", message.Type, message.ID)
+	}
+
+	data, mimeType, err := service.downloadMedia(*numberPhone, media.ID)
+	if err != nil {
+		return content, fmt.Errorf("error downloading %s: %v", message.Type, err)
+	}
+
+	filename := mediaFilename(media.ID, media.Filename, mimeType)
+	content.MediaMimeType = mimeType
+	content.MediaPath, err = service.fileService.UploadMedia(bytes.NewReader(data), filename, int64(len(data)), mimeType)
+	if err != nil {
+		return content, err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "[%s recibido: %s]", label, filename)
+	if media.Caption != "" {
+		fmt.Fprintf(&text, "\n%s", media.Caption)
+	}
+	content.Text = text.String()
+
+	return content, nil
+}
+
+func formatLocationMessage(location *whatsapp.Location) string {
+	if location == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "[Ubicación compartida]\nLatitud: %f\nLongitud: %f", location.Latitude, location.Longitude)
+	if location.Name != "" {
+		fmt.Fprintf(&text, "\nNombre: %s", location.Name)
+	}
+	if location.Address != "" {
+		fmt.Fprintf(&text, "\nDirección: %s", location.Address)
+	}
+	return text.String()
+}
+
+func formatSharedContactsMessage(contacts []whatsapp.SharedContact) string {
+	if len(contacts) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+	text.WriteString("[Contactos compartidos]")
+	for _, contact := range contacts {
+		fmt.Fprintf(&text, "\n%s", contact.Name.FormattedName)
+		for _, phone := range contact.Phones {
+			fmt.Fprintf(&text, " - Tel: %s", phone.Phone)
+		}
+		for _, email := range contact.Emails {
+			fmt.Fprintf(&text, " - Email: %s", email.Email)
+		}
+	}
+	return text.String()
+}
+
+func formatInteractiveMessage(interactive whatsapp.InteractiveMessage) string {
+	switch interactive.Type {
+	case "button_reply":
+		return interactive.ButtonReply.Title
+	case "list_reply":
+		if interactive.ListReply.Description != "" {
+			return interactive.ListReply.Title + " - " + interactive.ListReply.Description
+		}
+		return interactive.ListReply.Title
+	}
+	return ""
+}
+
+// mediaFilename arma un nombre de archivo con la extensión correspondiente al mime type
+func mediaFilename(mediaID, originalName, mimeType string) string {
+	if originalName != "" {
+		return originalName
+	}
+
+	extension := ""
+	baseMime := strings.TrimSpace(strings.Split(mimeType, ";")[0])
+	switch baseMime {
+	case "audio/ogg":
+		extension = ".ogg"
+	case "audio/mpeg":
+		extension = ".mp3"
+	case "image/jpeg":
+		extension = ".jpg"
+	default:
+		if extensions, err := mime.ExtensionsByType(baseMime); err == nil && len(extensions) > 0 {
+			extension = extensions[0]
+		}
+	}
+	return mediaID + extension
+}
+
+// downloadMedia obtiene la URL de un archivo por su ID en la Graph API y lo descarga
+func (service *WhatsappService) downloadMedia(numberPhone entities.NumberPhone, mediaID string) ([]byte, string, error) {
+	ctx := context.Background()
+	credentials := whatsappCredentials(numberPhone)
+
+	media, err := service.whatsappClient.GetMedia(ctx, credentials, mediaID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error retrieving media url: %w", err)
+	}
+
+	data, contentType, err := service.whatsappClient.DownloadMedia(ctx, credentials, media.URL, maxWhatsappMediaSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("error downloading media: %w", err)
+	}
+
+	mimeType := media.MimeType
+	if mimeType == "" {
+		mimeType = contentType
+	}
+
+	return data, mimeType, nil
+}
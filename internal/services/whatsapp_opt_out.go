@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+)
+
+// Mensajes con los que el contacto se da de baja o vuelve a suscribirse a las campañas (ya normalizados)
+var (
+	optOutMessages = map[string]bool{"baja": true, "stop": true, "unsubscribe": true, "desuscribirme": true, "darme de baja": true}
+	optInMessages  = map[string]bool{"alta": true, "start": true, "suscribirme": true}
+)
+
+// handleOptOutReply procesa los pedidos de baja ("BAJA", "STOP") y de alta ("ALTA") de las campañas, escritos o
+// con el botón del template. Devuelve true si el mensaje era uno de esos y ya se contestó; en ese caso no pasa por el assistant.
+func (service *WhatsappService) handleOptOutReply(contact *entities.Contact, numberPhone *entities.NumberPhone, message whatsapp.Message, content inboundContent) (bool, error) {
+	if content.MessageType != whatsapp.MessageTypeText && content.MessageType != whatsapp.MessageTypeButton {
+		return false, nil
+	}
+
+	text := strings.Join(strings.Fields(normalizeReminderReply(content.Text)), " ")
+	optOut, optIn := optOutMessages[text], optInMessages[text]
+	// ALTA solo se interpreta como suscripción si el contacto se había dado de baja
+	if !optOut && !(optIn && contact.OptedOutAt != nil) {
+		return false, nil
+	}
+
+	var optedOutAt *time.Time
+	reply := "Listo, te suscribiste de nuevo. Si no querés recibir más promociones escribí BAJA."
+	if optOut {
+		now := time.Now()
+		optedOutAt = &now
+		reply = "Listo, no vas a recibir más promociones de este número. Si querés volver a recibirlas escribí ALTA."
+	}
+	if err := service.contactsRepository.UpdateOptOut(contact.ID, optedOutAt); err != nil {
+		return false, fmt.Errorf("error updating contact opt-out: %v", err)
+	}
+	contact.OptedOutAt = optedOutAt
+
+	err := service.messagesRepository.Create(entities.Message{
+		NumberPhonesID:    numberPhone.ID,
+		ContactsID:        contact.ID,
+		MessageText:       content.Text,
+		MessageIdWhatsapp: message.ID,
+		IsFromBot:         false,
+		MessageType:       content.MessageType,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error saving contact message: %v", err)
+	}
+
+	if err := service.replyToContact(numberPhone, contact, reply); err != nil {
+		log.Printf("Error respondiendo la baja del contacto %d: %v", contact.ID, err)
+	}
+	return true, nil
+}
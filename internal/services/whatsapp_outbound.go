@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"gorm.io/gorm"
+)
+
+// Ventana de atención de WhatsApp: fuera de ella solo se pueden enviar templates
+const serviceWindow = 24 * time.Hour
+
+// SendOutboundMessage envía un mensaje (texto, template, media o interactivo) desde un número del tenant a un
+// contacto y lo guarda en messages. Devuelve gorm.ErrRecordNotFound si el número o el contacto no están en el
+// scope y dtos.ErrOutsideServiceWindow si no es un template y el contacto no escribió en las últimas 24 horas.
+// Si WhatsApp rechaza el envío el mensaje se guarda igual con estado failed y se devuelve junto con el error.
+func (service *WhatsappService) SendOutboundMessage(tenant dtos.TenantScope, request dtos.OutboundMessageDto) (dtos.MessageDto, error) {
+	if err := request.Validate(); err != nil {
+		return dtos.MessageDto{}, err
+	}
+
+	numberPhone, err := service.numberPhone.repository.WithTenant(tenant).FindByID(strconv.FormatInt(request.NumberPhoneID, 10))
+	if err != nil {
+		return dtos.MessageDto{}, err
+	}
+
+	var contact *entities.Contact
+	if request.ContactID > 0 {
+		found, err := service.handoffService.WithTenant(tenant).GetContact(request.ContactID)
+		if err != nil {
+			return dtos.MessageDto{}, err
+		}
+		if found.NumberPhonesID != numberPhone.ID {
+			return dtos.MessageDto{}, fmt.Errorf("contact %d of number phone %d: %w", found.ID, numberPhone.ID, gorm.ErrRecordNotFound)
+		}
+		contact = &found
+	} else {
+		contact, err = service.findOrCreateContact(&numberPhone, request.To, "")
+		if err != nil {
+			return dtos.MessageDto{}, err
+		}
+	}
+
+	if request.Type != dtos.OutboundTypeTemplate {
+		if err := service.checkServiceWindow(contact.ID); err != nil {
+			return dtos.MessageDto{}, err
+		}
+	}
+
+	recipient := contact.NumberPhone
+	var payload metaapi.OutgoingMessage
+	switch request.Type {
+	case dtos.OutboundTypeText:
+		payload = metaapi.NewSendMessageWhatsappBasic(request.Text, recipient)
+	case dtos.OutboundTypeTemplate:
+		payload = metaapi.NewSendMessageTemplate(request.Template.Name, request.Template.Language, request.Template.Components, recipient)
+	case dtos.OutboundTypeInteractive:
+		payload = metaapi.NewSendMessageInteractive(*request.Interactive, recipient)
+	default:
+		payload = metaapi.NewSendMessageMedia(request.Type, *request.Media, recipient)
+	}
+	messageID, sendErr := service.postMessage(numberPhone, payload)
+
+	record := entities.Message{
+		NumberPhonesID:    numberPhone.ID,
+		ContactsID:        contact.ID,
+		MessageIdWhatsapp: messageID,
+		MessageText:       request.StoredText(),
+		IsFromBot:         true,
+		MessageType:       request.Type,
+	}
+	if request.Media != nil {
+		record.MediaPath = request.Media.Link
+		if record.MediaPath == "" {
+			record.MediaPath = request.Media.ID
+		}
+	}
+	if tenant.UserID > 0 {
+		record.UsersID = &tenant.UserID
+	}
+	if sendErr != nil {
+		markSendFailed(&record, sendErr)
+	}
+
+	saved, err := saveBotMessage(service, record)
+	if err != nil {
+		return dtos.MessageDto{}, err
+	}
+	return entities.MapEntityToMessageDto(saved), sendErr
+}
+
+// checkServiceWindow devuelve dtos.ErrOutsideServiceWindow si el contacto no escribió en las últimas 24 horas
+func (service *WhatsappService) checkServiceWindow(contactID int64) error {
+	lastInbound, err := service.messagesRepository.LastInboundAt(contactID)
+	if err != nil {
+		return fmt.Errorf("error checking service window: %v", err)
+	}
+	if lastInbound == nil || time.Since(*lastInbound) > serviceWindow {
+		return dtos.ErrOutsideServiceWindow
+	}
+	return nil
+}
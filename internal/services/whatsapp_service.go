@@ -1,233 +1,317 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/config"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
-	googlecalendar "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/googleCalendar"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/openaiassistantdtos/openairuns"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
 	metaapi "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp/metaApi"
 	whatsappservicedto "github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp_service_DTO"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
 	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities/filters"
-	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/postgres_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/phone"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
 	"golang.org/x/exp/rand"
 	"golang.org/x/oauth2"
-	"google.golang.org/api/calendar/v3"
+	"gorm.io/gorm"
 )
 
 type WhatsappService struct {
-	usersService           *UsersService
-	logsService            *LogsService
-	openAIAssistantService *OpenAIAssistantService
-	utilService            *UtilService
-	userSessions           map[string]*whatsapp.UserSession // Mapa para almacenar sesiones por PhoneNumberID
-	numberPhone            *NumberPhonesService
-	messagesRepository     *postgres_client.MessagesRepository
-	assistantService       *AssistantService
-	configurationService   *ConfigurationsService
-	googleCalendarService  *GoogleCalendarService
-	oauthConfig            *oauth2.Config
-	eventsService          EventsService
-	threadService          *ThreadService
+	usersService             *UsersService
+	logsService              *LogsService
+	openAIAssistantService   *OpenAIAssistantService
+	utilService              *UtilService
+	userSessions             map[string]*whatsapp.UserSession // Mapa para almacenar sesiones por PhoneNumberID
+	numberPhone              *NumberPhonesService
+	messagesRepository       repositories.MessagesRepository
+	assistantService         *AssistantService
+	configurationService     *ConfigurationsService
+	googleCalendarService    *GoogleCalendarService
+	oauthConfig              *oauth2.Config
+	eventsService            EventsService
+	threadService            *ThreadService
+	fileService              *FileService
+	messagesService          *MessagesService
+	llmProviders             *LLMProviders
+	tools                    *ToolRegistry
+	availabilityService      *AvailabilityService
+	eventRemindersRepository repositories.EventRemindersRepository
+	contactsRepository       repositories.ContactsRepository
+	handoffService           *HandoffService
+	templatesService         *TemplatesService
+	usageService             *UsageService
+	whatsappClient           *clients.WhatsappClient
+	mailboxes                *contactMailboxes
 }
 
-func NewWhatsappService(usersService *UsersService, logsService *LogsService, openAIAssistantService *OpenAIAssistantService, utilService *UtilService, numberPhone *NumberPhonesService, messagesRepository *postgres_client.MessagesRepository, assistantService *AssistantService, configurationService *ConfigurationsService, googleCalendarService *GoogleCalendarService, oauthConfig *oauth2.Config, eventsService EventsService, threadService *ThreadService) *WhatsappService {
-	return &WhatsappService{
-		usersService:           usersService,
-		logsService:            logsService,
-		openAIAssistantService: openAIAssistantService,
-		utilService:            utilService,
-		userSessions:           make(map[string]*whatsapp.UserSession),
-		numberPhone:            numberPhone,
-		messagesRepository:     messagesRepository,
-		assistantService:       assistantService,
-		configurationService:   configurationService,
-		googleCalendarService:  googleCalendarService,
-		oauthConfig:            oauthConfig,
-		eventsService:          eventsService,
-		threadService:          threadService,
-	}
+func NewWhatsappService(usersService *UsersService, logsService *LogsService, openAIAssistantService *OpenAIAssistantService, utilService *UtilService, numberPhone *NumberPhonesService, messagesRepository repositories.MessagesRepository, assistantService *AssistantService, configurationService *ConfigurationsService, googleCalendarService *GoogleCalendarService, oauthConfig *oauth2.Config, eventsService EventsService, threadService *ThreadService, fileService *FileService, messagesService *MessagesService, llmProviders *LLMProviders, tools *ToolRegistry, availabilityService *AvailabilityService, eventRemindersRepository repositories.EventRemindersRepository, handoffService *HandoffService, templatesService *TemplatesService, usageService *UsageService, contactsRepository repositories.ContactsRepository, whatsappClient *clients.WhatsappClient) *WhatsappService {
+	service := &WhatsappService{
+		usersService:             usersService,
+		logsService:              logsService,
+		openAIAssistantService:   openAIAssistantService,
+		utilService:              utilService,
+		userSessions:             make(map[string]*whatsapp.UserSession),
+		numberPhone:              numberPhone,
+		messagesRepository:       messagesRepository,
+		assistantService:         assistantService,
+		configurationService:     configurationService,
+		googleCalendarService:    googleCalendarService,
+		oauthConfig:              oauthConfig,
+		eventsService:            eventsService,
+		threadService:            threadService,
+		fileService:              fileService,
+		messagesService:          messagesService,
+		llmProviders:             llmProviders,
+		tools:                    tools,
+		availabilityService:      availabilityService,
+		eventRemindersRepository: eventRemindersRepository,
+		handoffService:           handoffService,
+		templatesService:         templatesService,
+		usageService:             usageService,
+		contactsRepository:       contactsRepository,
+		whatsappClient:           whatsappClient,
+	}
+
+	// Tools de turnos que el assistant puede ejecutar (consultar, crear, modificar y cancelar eventos)
+	service.registerSchedulingTools(tools)
+	// Tool para que el assistant derive la conversación a un operador
+	service.registerHandoffTools(tools)
+
+	// Los mensajes de un mismo contacto se procesan de a uno y los que llegan seguidos se agrupan (MESSAGE_DEBOUNCE_MS)
+	window := time.Duration(envPositiveInt("MESSAGE_DEBOUNCE_MS", int(defaultMessageDebounce/time.Millisecond))) * time.Millisecond
+	service.mailboxes = newContactMailboxes(window, service.handleMessageWithOpenAI)
+
+	return service
 }
 
-// Funcion para pruebas rapidas de funciones
-
-func (service *WhatsappService) DemoFunctionWhatsappService(templateName, numberPhoneIDWhatsapp, tokenWhatsapp string) error {
-	// Crear el mensaje template
-	messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(
-		"summary",
-		"startDate",
-		"formattedEnd",
-		"contactToString",
-		"CodeEvent",
-		"5493794869394",
-		templateName,
-	)
-
-	// Enviar mensaje template
-	err := service.SendMessageTemplate(
-		messageTemplate,
-		numberPhoneIDWhatsapp,
-		tokenWhatsapp,
-	)
-
-	if err != nil {
-		fmt.Printf("ERROR AL NOTIFICAR EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s\n", err, "adsfasd")
-	}
-	return nil
-}
-
-func (service *WhatsappService) HandleIncomingMessageWithAssistant(response whatsapp.ResponseComplet) error {
+// HandleIncomingMessageWithAssistant procesa los mensajes de la notificación. Los que tiene que responder el assistant
+// quedan en el mailbox de su contacto y no se los espera: se devuelven los canales con el resultado de cada uno.
+func (service *WhatsappService) HandleIncomingMessageWithAssistant(response whatsapp.ResponseComplet) ([]<-chan error, error) {
+	var pending []<-chan error
 	for _, entry := range response.Entry {
-		fmt.Println("Len Entry: ", len(response.Entry))
-		fmt.Println("ID Entry: ", entry.ID)
 		for _, change := range entry.Changes {
 			for _, message := range change.Value.Messages {
-
-				fmt.Println("ID Message: ", message.ID)
-				fmt.Print(message.Text.Body)
-
 				// Verificar si el mensaje ya existe por su message_id_whatsapp
 				exists, err := service.messagesRepository.ExistsByMessageID(message.ID)
 				if err != nil {
-					fmt.Println("MENSAJE REPETIDO")
-					return fmt.Errorf("failed to check message existence: %w", err)
+					return pending, fmt.Errorf("failed to check message existence: %w", err)
 				}
 
-				// Si ya se proceso el mensaje se retorna nil
+				// Si ya se proceso el mensaje se sigue con el próximo (puede ser un reintento del job)
 				if exists {
-					fmt.Printf("Message with ID %s already processed\n", message.ID)
-					return nil
+					log.Printf("Message with ID %s already processed", message.ID)
+					continue
 				}
 
 				// Extraer información básica
-				sender, text, _, _, phoneNumberID, err := extractMessageInfo(change.Value)
+				sender, _, phoneNumberID, err := extractMessageInfo(change.Value, message)
 				if err != nil {
 					log.Printf("Error extracting message info: %v", err)
-					return err
+					return pending, err
 				}
 
 				// Buscar el número de teléfono asociado
 				numberPhone, err := service.findNumberPhoneByPhoneID(phoneNumberID)
 				if err != nil {
 					log.Printf("Error finding NumberPhone: %v", err)
-					return err
+					return pending, err
+				}
+
+				// El wa_id viene sin + y, en México, con el 1 de celular: se guarda en E.164
+				senderNumber, err := phone.FromWhatsApp(sender)
+				if err != nil {
+					log.Printf("Error parsing sender %q: %v", sender, err)
+					continue
 				}
 
-				// Buscar el contacto asociado
-				contact, err := service.findOrCreateContact(numberPhone, sender)
+				// Buscar el contacto asociado (y actualizar el nombre que tiene en WhatsApp)
+				contact, err := service.findOrCreateContact(numberPhone, senderNumber.E164(), contactProfileName(change.Value, sender))
 				if err != nil {
 					log.Printf("Error finding or creating contact: %v", err)
-					return err
+					return pending, err
 				}
 
+				// Los mensajes que siguen en el lote pueden ser de otros contactos
 				if contact.IsBlocked {
 					log.Printf("contacto bloqueado")
-					return nil
+					continue
 				}
 
-				// Manejar el mensaje con OpenAI
-				err = service.handleMessageWithOpenAI(contact, text, message.ID, numberPhone)
+				// Convertir el mensaje según su tipo (texto, audio, imagen, ubicación...)
+				content, err := service.buildInboundContent(message, numberPhone)
 				if err != nil {
-					log.Printf("Error handling message with OpenAI: %v", err)
-					return err
+					log.Printf("Error processing %s message: %v", message.Type, err)
+					return pending, err
+				}
+				if strings.TrimSpace(content.Text) == "" {
+					continue
+				}
+
+				// Las bajas de las campañas (BAJA/STOP) se resuelven sin pasar por el assistant
+				handled, err := service.handleOptOutReply(contact, numberPhone, message, content)
+				if err != nil {
+					log.Printf("Error handling opt-out reply: %v", err)
+					return pending, err
+				}
+				if handled {
+					continue
 				}
+
+				// Las respuestas "confirmo"/"cancelar" a un recordatorio se resuelven sin pasar por el assistant
+				handled, err = service.handleReminderReply(contact, numberPhone, message, content)
+				if err != nil {
+					log.Printf("Error handling reminder reply: %v", err)
+					return pending, err
+				}
+				if handled {
+					continue
+				}
+
+				// Manejar el mensaje con OpenAI. Espera en el mailbox del contacto junto con los mensajes que llegaron seguidos.
+				pending = append(pending, service.mailboxes.Submit(mailboxMessage{
+					Contact:     contact,
+					NumberPhone: numberPhone,
+					Content:     content,
+					MessageID:   message.ID,
+				}))
 			}
 		}
 	}
-	return nil
+	return pending, nil
 }
 
 func (service *WhatsappService) findNumberPhoneByPhoneID(WhatsappNumberPhoneID string) (*entities.NumberPhone, error) {
 	// Busca en la base de datos el número de teléfono asociado al ID recibido
-	var numberPhone entities.NumberPhone
-	err := config.DB.Where("whatsapp_number_phone_id = ?", WhatsappNumberPhoneID).First(&numberPhone).Error
+	numberPhone, err := service.numberPhone.FindByWhatsappNumberPhoneID(WhatsappNumberPhoneID)
 	if err != nil {
 		return nil, fmt.Errorf("number phone not found: %v", err)
 	}
 	return &numberPhone, nil
 }
 
-func (service *WhatsappService) findOrCreateContact(numberPhone *entities.NumberPhone, sender string) (*entities.Contact, error) {
+// findOrCreateContact busca el contacto por su número en E.164 (ver el paquete phone) o lo crea si no existe
+func (service *WhatsappService) findOrCreateContact(numberPhone *entities.NumberPhone, number, profileName string) (*entities.Contact, error) {
 	// Busca el contacto en la base de datos o crea uno nuevo
-	var contact entities.Contact
-	err := config.DB.Where("number_phones_id = ? AND number_phone = ?", numberPhone.ID, sender).First(&contact).Error
+	contact, err := service.contactsRepository.FindByNumber(numberPhone.ID, number)
 	if err == nil {
+		// El contacto puede cambiar su nombre de WhatsApp en cualquier momento
+		if profileName != "" && profileName != contact.ProfileName {
+			if err := service.contactsRepository.UpdateProfile(contact.ID, map[string]interface{}{"profile_name": profileName}); err != nil {
+				return nil, fmt.Errorf("error updating contact profile name: %v", err)
+			}
+			contact.ProfileName = profileName
+		}
 		return &contact, nil
 	}
-
-	senderInt64, err := strconv.Atoi(sender)
-	if err == nil && contact.ID > 0 {
-		return &contact, nil
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error finding contact: %v", err)
 	}
 
 	// Crear un nuevo contacto si no existe
-	contact = entities.Contact{
+	contact, err = service.contactsRepository.CreateAndReturn(entities.Contact{
 		NumberPhonesID: numberPhone.ID,
-		NumberPhone:    int64(senderInt64),
-	}
-	err = config.DB.Create(&contact).Error
+		NumberPhone:    number,
+		ProfileName:    profileName,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error creating contact: %v", err)
 	}
 	return &contact, nil
 }
 
-func (service *WhatsappService) handleMessageWithOpenAI(contact *entities.Contact, text, messageID string, numberPhone *entities.NumberPhone) error {
-	// Configurar el asistente
-	assistant, err := service.assistantService.FindAssistantById(numberPhone.AssistantsID)
-	if err != nil {
-		return fmt.Errorf("assistant not found: %v", err)
+// contactProfileContext resume el nombre y el email conocidos del contacto ("" si no hay ninguno)
+func contactProfileContext(contact *entities.Contact) string {
+	var known []string
+	if name := contact.DisplayName(); name != "" {
+		known = append(known, "nombre: "+name)
+	}
+	if contact.Email != "" {
+		known = append(known, "email: "+contact.Email)
+	}
+	if len(known) == 0 {
+		return ""
 	}
+	return "Datos del contacto: " + strings.Join(known, ", ")
+}
 
-	// Crear o usar el Thread existente
-	thread, err := service.threadService.GetOrCreateThread(entities.MapEntityToContactDto(*contact), assistant)
+// contactProfileName devuelve el nombre de WhatsApp del remitente (value.contacts[].profile.name)
+func contactProfileName(value whatsapp.Value, sender string) string {
+	for _, contact := range value.Contacts {
+		if contact.WAID == sender {
+			return strings.TrimSpace(contact.Profile.Name)
+		}
+	}
+	return ""
+}
+
+// Tiempo máximo para que el modelo responda un lote, incluidas las rondas de tools y las esperas de los runs
+const llmReplyTimeout = 5 * time.Minute
+
+// handleMessageWithOpenAI procesa un lote de mensajes seguidos de un mismo contacto con un único run del assistant
+func (service *WhatsappService) handleMessageWithOpenAI(batch []mailboxMessage) error {
+	// Si Meta reenvió un mensaje mientras se procesaba el original, el reenvío llega en el lote siguiente con el
+	// original ya guardado: se descarta para no responder dos veces
+	pending := make([]mailboxMessage, 0, len(batch))
+	for _, message := range batch {
+		exists, err := service.messagesRepository.ExistsByMessageID(message.MessageID)
+		if err != nil {
+			return fmt.Errorf("failed to check message existence: %w", err)
+		}
+		if !exists {
+			pending = append(pending, message)
+		}
+	}
+	batch = pending
+	if len(batch) == 0 {
+		return nil
+	}
+	contact, numberPhone := batch[0].Contact, batch[0].NumberPhone
+	text := joinMailboxText(batch)
+
+	// Si la conversación la atiende un operador (o está pausada) el assistant no responde; los mensajes se guardan
+	// para que aparezcan en la bandeja. Se consulta en cada lote porque el modo puede cambiar mientras se espera.
+	handledByBot, err := service.handoffService.IsHandledByBot(contact.ID)
 	if err != nil {
-		return fmt.Errorf("error obteniendo o creando thread: %v", err)
+		return fmt.Errorf("error checking handoff mode: %v", err)
+	}
+	if !handledByBot {
+		return service.saveContactMessages(numberPhone, contact, batch)
 	}
 
-	// Obtengo el vector_store que usa el assistant
-	files, err := service.assistantService.serviceFile.GetFileByAssistantID(assistant.ID)
+	// Configurar el asistente
+	assistant, err := service.assistantService.FindAssistantById(numberPhone.AssistantsID)
 	if err != nil {
-		return fmt.Errorf("error GetFileByAssistantID: %v", err)
+		return fmt.Errorf("assistant not found: %v", err)
 	}
 
-	var fileAssistant dtos.FileDto
-	if len(files) > 0 {
-		fileAssistant = files[len(files)-1]
-		// Asigno el archivo al hilo.
-		err = service.openAIAssistantService.EjecutarThread(thread.OpenaiThreadsId, []string{fileAssistant.OpenaiVectorStoreIDs})
+	// Si el bussiness superó su cuota mensual el bot queda pausado: no se consulta al modelo y se responde el
+	// mensaje de reemplazo
+	if service.usageService != nil {
+		exceeded, fallback, err := service.usageService.QuotaExceeded(assistant.BussinessID)
 		if err != nil {
-			return fmt.Errorf("error EjecutarThread: %v", err)
+			log.Printf("Error checking usage quota of bussiness %d: %v", assistant.BussinessID, err)
+		} else if exceeded {
+			if err := service.saveContactMessages(numberPhone, contact, batch); err != nil {
+				return err
+			}
+			return service.replyToContact(numberPhone, contact, fallback)
 		}
-	} else {
-		fmt.Println("Assistant sin file")
 	}
 
-	// Guardar el mensaje del contacto en la base de datos
-	err = service.messagesRepository.Create(entities.Message{
-		NumberPhonesID:    numberPhone.ID,
-		ContactsID:        contact.ID,
-		MessageText:       text,
-		MessageIdWhatsapp: messageID,
-		IsFromBot:         false,
-	})
+	// Proveedor del modelo configurado en el assistant (Assistants API, Chat Completions, Ollama)
+	provider, err := service.llmProviders.For(assistant)
 	if err != nil {
-		return fmt.Errorf("error saving contact message: %v", err)
+		return err
 	}
 
 	loc, err := time.LoadLocation("America/Argentina/Buenos_Aires")
@@ -267,468 +351,135 @@ func (service *WhatsappService) handleMessageWithOpenAI(contact *entities.Contac
 
 	// Crear el texto final
 	text += fmt.Sprintf("\n\nFecha y hora actual en Argentina: %s\n%s\n%s", formattedTime, availableDaysText, workingHoursText)
+	// Lo que ya se sabe del contacto, para que el assistant no lo vuelva a preguntar
+	if profile := contactProfileContext(contact); profile != "" {
+		text += "\n" + profile
+	}
 
 	// Enviar el mensaje a OpenAI
-	response, err := service.InteractWithAssistant(thread.OpenaiThreadsId, assistant.OpenaiAssistantsID, text)
+	history, err := service.conversationHistory(contact.ID)
 	if err != nil {
-		return fmt.Errorf("error sending message to OpenAI: %v", err)
+		return fmt.Errorf("error loading conversation history: %v", err)
+	}
+
+	toolContext := ToolContext{Assistant: assistant, Contact: contact, NumberPhone: numberPhone, Now: currentTime}
+	ctx, cancel := context.WithTimeout(context.Background(), llmReplyTimeout)
+	defer cancel()
+	llmResponse, err := provider.Reply(ctx, LLMRequest{
+		Assistant: assistant,
+		Contact:   entities.MapEntityToContactDto(*contact),
+		Message:   text,
+		History:   history,
+		Tools:     service.tools.Definitions(),
+		ExecuteTools: func(calls []LLMToolCall) []LLMToolOutput {
+			return service.tools.Execute(toolContext, calls)
+		},
+	})
+	if errors.Is(err, clients.ErrOpenAIUnavailable) {
+		// El modelo está caído: en lugar de reintentar el job (el contacto esperaría sin respuesta) se guardan los
+		// mensajes y se le avisa que vuelva a escribir
+		log.Printf("LLM unavailable for contact %d: %v", contact.ID, err)
+		if err := service.saveContactMessages(numberPhone, contact, batch); err != nil {
+			return err
+		}
+		return service.replyToContact(numberPhone, contact, llmUnavailableMessage())
 	}
-
-	// Este valor lo usaremos como respuesta "por defecto" en caso de error o fallback
-	responseUser := "Podrías ser más específico, por favor?"
-
-	// 2. Parsear la respuesta en la estructura AssistantResponse
-	assistantResp, err := parseAssistantResponse(response)
 	if err != nil {
-		// Si hay un error en el parseo, lo mostramos y devolvemos nil (o podrías manejarlo distinto)
-		fmt.Println("Error parseando la respuesta del Assistant:", err.Error())
-		// Guardamos en la base de datos la respuestaUser (fallback) y retornamos
-		if saveErr := saveMessageWithUniqueID(service, int(numberPhone.NumberPhone), int(contact.ID), response); saveErr != nil {
-			return fmt.Errorf("error saving fallback contact message: %v", saveErr)
-		}
-
-		// Enviar el fallback al usuario
-		contactToString := strconv.Itoa(int(contact.NumberPhone))
-		message := metaapi.NewSendMessageWhatsappBasic(response, contactToString)
-		sendErr := service.sendMessageBasic(message, strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10), numberPhone.TokenPermanent)
-		if sendErr != nil {
-			return fmt.Errorf("error sending fallback response to user: %v", sendErr)
-		}
-
-		return nil
+		return fmt.Errorf("error sending message to OpenAI: %w", err)
 	}
-
-	// 3. Lógica según assistantResp.Function
-	switch assistantResp.Function {
-
-	case "getMeetingDetails":
-		fmt.Print("getMeetingDetails")
-	case "getMeetings":
-
-		var eventsDB []entities.Events
-		startDateStr := assistantResp.UserData.DateToSearch
-		// Si el contacto es el dueño configurado para recibir notificaciones, se buscan todos los eventos que estan relacionados a ese NumberPhone. Si no, se obtienen los eventos de un contactos.
-		if numberPhone.NumberPhoneToNotify == contact.NumberPhone {
-			eventsDB, err = service.eventsService.GetEventsByContactDateAndNumberPhone(contact.ID, startDateStr, assistant.ID)
-			if err != nil {
-				return fmt.Errorf("error retrieving events by contact, date, and numberPhoneID: %v", err)
-			}
-		} else {
-
-			startDateStr := assistantResp.UserData.DateToSearch
-			formattedTime := currentTime.Format("2006-01-02 15:04:05")
-			eventsDB, err = service.eventsService.GetEventByContactAndDate(contact.ID, startDateStr, formattedTime)
-			if err != nil {
-				return fmt.Errorf("error retrieving events by contact, date, and time: %v", err)
-			}
-		}
-
-		parsedStart, err := service.utilService.ParseDateString(startDateStr, FormatTypeDateForPersona)
-		if err != nil {
-			parsedStart = time.Now() // fallback en caso de error
-		}
-
-		formattedStartStr := parsedStart.Format("02-01-2006")
-		if len(eventsDB) == 0 {
-			responseUser = fmt.Sprintf("⚠️ *No hay eventos programados para el %s.*\n\nParece que no tienes eventos agendados para esta fecha. Si necesitas crear alguno o tienes alguna consulta, no dudes en contactarnos. 😊", formattedStartStr)
-			break
-		}
-
-		// Formatear la cabecera con un mensaje claro y visualmente atractivo
-		responseUser = fmt.Sprintf("🌟 *Eventos programados para el %s*:\n\n", formattedStartStr)
-
-		for i, event := range eventsDB {
-			// Intentar parsear las fechas (se asume formato RFC3339)
-			startTime, err := time.Parse(time.RFC3339, event.StartDate)
-			if err != nil {
-				startTime = time.Now() // fallback en caso de error
-			}
-			endTime, err := time.Parse(time.RFC3339, event.EndDate)
-			if err != nil {
-				endTime = time.Now() // fallback en caso de error
-			}
-			// Formatear las fechas en un formato más amigable (solo hora)
-			formattedStart := startTime.Format("15:04")
-			formattedEnd := endTime.Format("15:04")
-
-			// Agregar los detalles del evento con un formato más visual y emojis para claridad
-			responseUser += fmt.Sprintf(
-				"🔶 *Evento #%d:* %s\n⏰ *Hora de Inicio:* %s\n⏳ *Hora de Fin:* %s\n\n🔏 *Código:* %s\n────────────────\n",
-				i+1,
-				event.Summary,
-				formattedStart,
-				formattedEnd,
-				event.CodeEvent,
-			)
-		}
-
-	case "createMeeting":
-		fmt.Println("Creando evento...")
-		fmt.Print("========= RESPONSE ASSISTANT ==========")
-		fmt.Print(response)
-		fmt.Print("========== RESPONSE PARSED ============")
-		fmt.Print(assistantResp)
-		fmt.Print("===================")
-
-		formattedTime := currentTime.Format("2006-01-02 15:04:05")
-
-		endDateStrToDate, err := time.Parse("2006-01-02T15:04:05", assistantResp.UserData.MeetingDate)
-		if err != nil {
-			fmt.Println("Error al parsear la fecha:", err)
-			return err
-		}
-
-		// Verificar si la fecha y hora están dentro del rango de trabajo del asistente
-		isAvailable, err := service.assistantService.IsWithinWorkingHours(assistant.ID, endDateStrToDate)
-		if err != nil {
-			fmt.Println("Error al verificar las horas de trabajo:", err)
-			return err
-		}
-		fmt.Print("\nIsAvailable?: ", isAvailable)
-		if !isAvailable {
-			responseUser = fmt.Sprintf(" Lo siento 🙁, no estamos disponible en el horario seleccionado. Recuerda que el horario de atencion es de: %s Por favor, elige otro horario 💪", assistant.WorkingHours)
-			break
-		}
-
-		dateToSearch := endDateStrToDate.Format("2006-01-02")
-
-		// Verificar si el contacto ya tiene la cantidad maxima de eventos posibles en un dia.
-		eventsInDate, err := service.eventsService.GetEventsByContactDateAndNumberPhone(contact.ID, dateToSearch, assistant.ID)
-		if err != nil {
-			return fmt.Errorf("error retrieving events by contact, date, and numberPhoneID: %v", err)
-		}
-		if len(eventsInDate) >= int(assistant.EventCountPerDay) {
-			responseUser = fmt.Sprintf("Lo siento 🙁. La cantidad máxima de %s por día es de %d. Si deseas cancelar o modificar alguno, solo hazmelo saber.💪", assistant.EventType, assistant.EventCountPerDay)
-			break
-		}
-
-		eventsExists, err := service.eventsService.GetEventByContactAndDate(contact.ID, dateToSearch, formattedTime)
-		if err != nil {
-			log.Printf("Error retrieving event by contact and date: %v", err)
-			return fmt.Errorf("failed to create event: %v", err)
-		}
-		if len(eventsExists) > 0 && assistant.EventCountPerDay == 1 {
-			event := eventsExists[0] // Tomamos el primer evento porque solo debe haber uno activo
-
-			// Intentamos parsear las fechas del evento
-			startTime, err := time.Parse(time.RFC3339, event.StartDate)
-			if err != nil {
-				startTime = time.Now() // fallback en caso de error
-			}
-			endTime, err := time.Parse(time.RFC3339, event.EndDate)
-			if err != nil {
-				endTime = time.Now() // fallback en caso de error
-			}
-
-			// Formateamos las fechas en un formato amigable
-			formattedStart := startTime.Format("15:04")
-			formattedEnd := endTime.Format("15:04")
-
-			// Mensaje para informar al usuario que ya tiene un turno en esa fecha
-			responseUser = fmt.Sprintf(
-				"⚠️ *Ya tienes un turno programado para esta fecha.*\n\n📅 *Fecha:* %s\n⏰ *Hora de Inicio:* %s\n⏳ *Hora de Fin:* %s\n🔏 *Código:* %s\n\nSi deseas cambiar la fecha y hora de tu turno o cancelarlo, por favor háznoslo saber.",
-				endDateStrToDate.Format("02-01-2006"), formattedStart, formattedEnd, event.CodeEvent,
-			)
-
-			break
-		}
-
-		// Generamos un código único para el evento
-		code, err := service.eventsService.GenerateUniqueCode()
-		if err != nil {
-			log.Printf("Error generating unique event code: %v", err)
-			return fmt.Errorf("error creating event: %v", err)
-		}
-
-		startDateStrToDate, err := time.Parse("2006-01-02T15:04:05", assistantResp.UserData.MeetingDate)
-		if err != nil {
-			fmt.Println("Error al parsear la fecha:", err)
-			return err
-		}
-
-		startDateToStr := startDateStrToDate.Format("2006-01-02 15:04:05")
-
-		// Sumar 30 minutos
-		endDate := endDateStrToDate.Add(time.Duration(assistant.EventDuration) * time.Minute)
-		endDateStr := endDate.Format("2006-01-02T15:04:05")
-
-		eventDTO := dtos.EventsDto{
-			Summary:      assistantResp.UserData.UserName,
-			Description:  "Contacto: " + assistantResp.UserData.UserEmail + "\n Tel: " + strconv.Itoa(int(contact.NumberPhone)),
-			StartDate:    startDateToStr,
-			EndDate:      endDateStr,
-			AssistantsID: assistant.ID,
-			ContactsID:   contact.ID,
-			CodeEvent:    code, // Genero un codigo único para el evento
-		}
-
-		// Creo el evento en la base de datos
-
-		if assistant.AccountGoogle {
-			context := context.Background()
-			token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
-			if err != nil {
-				return err
-			}
-
-			// Crear el evento
-			event := &calendar.Event{
-				Summary:     assistant.EventType + " - " + eventDTO.Summary,
-				Description: assistantResp.UserData.UserName + ", " + assistantResp.UserData.UserEmail,
-				Start: &calendar.EventDateTime{
-					DateTime: assistantResp.UserData.MeetingDate,
-					TimeZone: "UTC-3",
-				},
-				End: &calendar.EventDateTime{
-					DateTime: endDateStr,
-					TimeZone: "UTC-3",
-				},
-				Attendees: []*calendar.EventAttendee{
-					{Email: assistantResp.UserData.UserEmail},
-				},
-				ConferenceData: &calendar.ConferenceData{
-					CreateRequest: &calendar.CreateConferenceRequest{
-						RequestId: fmt.Sprintf("meet-%d", time.Now().Unix()), // ID único
-						ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
-							Type: "hangoutsMeet",
-						},
-					},
-				},
-			}
-			eventGoogleCalendar, err := service.googleCalendarService.CreateGoogleCalendarEvent(token, context, event)
-			if err != nil {
-				log.Println("Error al crear evento en google calendar. " + err.Error())
-			}
-
-			if eventGoogleCalendar != nil {
-				eventDTO.EventGoogleCalendarID = eventGoogleCalendar.Id
-			} else {
-				eventDTO.EventGoogleCalendarID = "eventGoogleCalendar_default"
-			}
-
-		}
-
-		err = service.eventsService.Create(eventDTO)
-		if err != nil {
-			log.Printf("\nCreate(eventDTO): %s", err.Error())
-			return fmt.Errorf("error creating event: %v", err)
-		}
-
-		// Parsear las fechas en el formato esperado
-		startDateTime, err := time.Parse("2006-01-02T15:04:05", assistantResp.UserData.MeetingDate)
-		if err != nil {
-			fmt.Printf("Error al procesar la fecha de inicio: %v\n", err)
-			return err
-		}
-
-		endDateTime, err := time.Parse("2006-01-02T15:04:05", endDateStr)
-		if err != nil {
-			fmt.Printf("Error al procesar la fecha de finalización: %v\n", err)
-			return err
-		}
-
-		// Extraer componentes de la fecha
-		formattedStart := startDateTime.Format("02/01/2006 15:04")
-		formattedEnd := endDateTime.Format("02/01/2006 15:04")
-
-		// Mensaje de respuesta
-		responseUser = fmt.Sprintf(
-			"✅ ¡Tu nuevo evento se agendó con éxito! 📅\n\n🕒 Inicio: %s \n🕒 Fin: %s.\n🔏 Código: %s\n\nTe esperamos... ¡Que tengas un excelente día! 😊",
-			formattedStart, formattedEnd, eventDTO.CodeEvent)
-
-		// Notificar al cliente
-		//  Enviar la notificacion al cliente de que un usuario registró un turno o reunion
-		contactToString := strconv.Itoa(int(contact.NumberPhone))
-		contactForNotifyToString := strconv.Itoa(int(numberPhone.NumberPhoneToNotify))
-
-		// Crear el mensaje template
-		messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(
-			eventDTO.Summary,
-			startDateToStr,
-			formattedEnd,
-			contactToString,
-			eventDTO.CodeEvent,
-			contactForNotifyToString,
-			metaapi.TemplateEventoCreado,
-		)
-
-		// Enviar mensaje template
-		err = service.SendMessageTemplate(
-			messageTemplate,
-			strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10),
-			numberPhone.TokenPermanent,
-		)
-
-		if err != nil {
-			fmt.Printf("ERROR AL NOTIFICAR EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s\n", err, eventDTO.CodeEvent)
-		}
-
-	case "updateEvents":
-		// Convertir la fecha actual al formato adecuado (se asume RFC3339)
-		currentTimeStr := time.Now().Format(time.RFC3339)
-
-		// Se obtiene el evento del contacto para la fecha indicada y con hora >= a la actual
-		eventFound, err := service.eventsService.GetEventByCodeEvent(contact.ID, assistantResp.UserData.EventCode)
-		if err != nil {
-			responseUser = "Lo siento, pero no pudimos encontrar el turno que mencionas. Te puedes ayudar viendo los turnos que tenes en la fecha que quieres consultar. 😊"
-			break
-		}
-		if eventFound.ID <= 0 {
-			return fmt.Errorf("no se encontró un evento para el contacto %d en la fecha %s con hora mayor o igual a %s", contact.ID, assistantResp.UserData.MeetingDate, currentTimeStr)
-		}
-
-		newDateStrToDate, err := time.Parse("2006-01-02T15:04:05", assistantResp.UserData.NewDate)
-		if err != nil {
-			fmt.Println("Error al parsear la fecha:", err)
-			return err
-		}
-
-		// Verificar si el asistente tiene disponibilidad en la nueva fecha y hora
-		isAvailable, err := service.assistantService.IsWithinWorkingHours(assistant.ID, newDateStrToDate)
-		if err != nil {
-			fmt.Println("Error al verificar las horas de trabajo:", err)
-			return err
-		}
-
-		if !isAvailable {
-			responseUser = "⚠️ Lo siento, el asistente no está disponible en el horario seleccionado para actualizar el turno. Por favor, elige otro horario."
-			break
-		}
-
-		// Sumar 30 minutos
-		endDate := newDateStrToDate.Add(time.Duration(assistant.EventDuration) * time.Minute)
-		endDateStr := endDate.Format("2006-01-02T15:04:05")
-
-		// Actualizar el evento en la base de datos con la información nueva
-		eventDTO := dtos.EventsDto{
-			ID:           eventFound.ID,
-			Summary:      eventFound.Summary,
-			Description:  eventFound.Description,
-			StartDate:    assistantResp.UserData.NewDate,
-			EndDate:      endDateStr,
-			AssistantsID: assistant.ID,
-			ContactsID:   contact.ID,
-			CodeEvent:    eventFound.CodeEvent,
-			CreatedAt:    eventFound.CreatedAt,
-		}
-
-		err = service.eventsService.Update(eventDTO)
-		if err != nil {
-			return err
+	if service.usageService != nil {
+		if err := service.usageService.Record(assistant, numberPhone.ID, contact.ID, llmResponse.Usage); err != nil {
+			log.Printf("Error recording token usage of contact %d: %v", contact.ID, err)
 		}
+	}
 
-		if assistant.AccountGoogle {
-			context := context.Background()
-			token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
-			if err != nil {
-				return err
-			}
+	// Guardar los mensajes del contacto en la base de datos. Se guardan recién cuando OpenAI respondió:
+	// si falla antes, el job del webhook se reintenta y los mensajes no deben figurar como ya procesados.
+	if err := service.saveContactMessages(numberPhone, contact, batch); err != nil {
+		return err
+	}
 
-			// Crear el evento
-			event := &googlecalendar.EventRequest{
-				Summary:     eventDTO.Summary,
-				Description: assistantResp.UserData.UserName + ", " + assistantResp.UserData.UserEmail,
-				Start:       eventDTO.StartDate,
-				End:         eventDTO.EndDate,
-				ContactsID:  uint(contact.ID),
-			}
+	// El modelo ya recibió el resultado de las tools y escribió la respuesta final.
+	// Los assistants con instrucciones viejas todavía responden {"message": "..."}, en ese caso se usa solo el mensaje.
+	responseUser := llmResponse.Text
+	if assistantResp, err := parseAssistantResponse(responseUser); err == nil && assistantResp.Message != "" {
+		responseUser = assistantResp.Message
+	}
+	if strings.TrimSpace(responseUser) == "" {
+		responseUser = "Podrías ser más específico, por favor?"
+	}
 
-			if _, err := service.googleCalendarService.UpdateGoogleCalendarEvent(token, context, eventDTO.EventGoogleCalendarID, event); err != nil {
-				log.Println("Error al crear evento en google calendar. " + err.Error())
-			}
-		}
+	// 4. Enviar la respuesta al usuario y guardarla con el wamid devuelto por WhatsApp
+	err = service.replyToContact(numberPhone, contact, responseUser)
+	if err != nil {
+		return fmt.Errorf("error sending response to user: %v", err)
+	}
 
-		responseUser = "✅ Tu evento ha sido modificada con éxito. Si nesesitas cualquier otra cosa, estoy acá para ayudarte 😊"
-
-		// Notificar al cliente
-		//  Enviar la notificacion al cliente de que un usuario registró un turno o reunion
-		contactToString := strconv.Itoa(int(numberPhone.NumberPhoneToNotify))
-		endDateStr = endDate.Format("2006-01-02 15:04:05")
-
-		// Crear el mensaje template
-		messageTemplate := metaapi.NewBodyWhatsappTemplateCRUD(
-			eventDTO.Summary,
-			assistantResp.UserData.NewDate,
-			endDateStr,
-			contactToString,
-			eventDTO.CodeEvent,
-			contactToString,
-			metaapi.TemplateEventoModificado,
-		)
-
-		// Enviar mensaje template
-		err = service.SendMessageTemplate(
-			messageTemplate,
-			strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10),
-			numberPhone.TokenPermanent,
-		)
+	return nil
+}
 
-		if err != nil {
-			fmt.Printf("ERROR AL NOTIFICAR EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s\n", err, eventDTO.CodeEvent)
-		}
+// llmUnavailableMessage es la respuesta cuando el modelo no está disponible (LLM_UNAVAILABLE_MESSAGE)
+func llmUnavailableMessage() string {
+	if message := strings.TrimSpace(os.Getenv("LLM_UNAVAILABLE_MESSAGE")); message != "" {
+		return message
+	}
+	return dtos.DefaultLLMUnavailableMessage
+}
 
-	case "deleteEvent":
-		event, err := service.eventsService.GetEventByCodeEvent(contact.ID, assistantResp.UserData.EventCode)
+// saveContactMessages guarda los mensajes que escribió el contacto
+func (service *WhatsappService) saveContactMessages(numberPhone *entities.NumberPhone, contact *entities.Contact, batch []mailboxMessage) error {
+	for _, message := range batch {
+		saved, err := service.messagesRepository.CreateAndReturn(entities.Message{
+			NumberPhonesID:    numberPhone.ID,
+			ContactsID:        contact.ID,
+			MessageText:       message.Content.Text,
+			MessageIdWhatsapp: message.MessageID,
+			IsFromBot:         false,
+			MessageType:       message.Content.MessageType,
+			MediaPath:         message.Content.MediaPath,
+			MediaMimeType:     message.Content.MediaMimeType,
+		})
 		if err != nil {
-			fmt.Println(err.Error())
-			responseUser = fmt.Sprint("Lo siento, ocurrió un error al eliminar reunion.\n Podrías intentar mas tarde o simplemente modificar tu reunión. \nSi nesesitas cualquier otra cosa, estoy acá para ayudarte 😊")
+			return fmt.Errorf("error saving contact message: %v", err)
 		}
+		service.messagesService.PublishMessage(saved)
+	}
+	return nil
+}
 
-		err = service.eventsService.Cancel(assistantResp.UserData.EventCode)
-		if err != nil {
-			return err
-		}
-		responseUser = fmt.Sprintf("✅ Su turno con el código '%s' ha sido cancelado con éxito. Si nesesitas cualquier otra cosa, estoy acá para ayudarte 😊", assistantResp.UserData.EventCode)
-
-		if assistant.AccountGoogle {
-			context := context.Background()
-			token, err := service.googleCalendarService.GetOrRefreshToken(int(assistant.ID), service.oauthConfig, context)
-			if err != nil {
-				return err
-			}
-
-			if err := service.googleCalendarService.DeleteGoogleCalendarEvent(token, context, event.EventGoogleCalendarID); err != nil {
-				log.Println("no se pudo eliminar el evento de google: " + err.Error())
-			}
-		}
+// replyToContact envía un mensaje de texto al contacto y lo guarda en messages con el wamid que devuelve WhatsApp,
+// para poder asociarle luego los callbacks de estado. Si el envío falla el mensaje se guarda igual con estado failed.
+func (service *WhatsappService) replyToContact(numberPhone *entities.NumberPhone, contact *entities.Contact, text string) error {
+	_, err := service.sendTextToContact(numberPhone, contact, text, nil)
+	return err
+}
 
-		// // Notificar al cliente
-		// //  Enviar la notificacion al cliente de que un usuario registró un turno o reunion
-		contactToString := strconv.Itoa(int(numberPhone.NumberPhoneToNotify))
-		textNotifyClient := fmt.Sprintf("  \n\n 🔴 *Cancelación de Turno* \n\n🔶 %s : *%s*\n⏰ *Hora de Inicio:* %sHs.\n⏳ *Hora de Fin:* %sHs.\n🔏 *Código:* %s.\n\n⚠️ Su turno ha sido cancelado. Para más información, por favor, contáctenos.",
-			service.utilService.CapitalizeFirstLetter(assistant.EventType),
-			event.Summary,
-			event.StartDate,
-			event.EndDate,
-			event.CodeEvent,
-		)
-
-		message := metaapi.NewSendMessageWhatsappBasic(textNotifyClient, contactToString)
-		err = service.sendMessageBasic(message, strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10), numberPhone.TokenPermanent)
-		if err != nil {
-			fmt.Printf("ERROR AL NOTIFICAR CANCELACIÓN DE EVENTO AL CLIENTE,\nERROR: %s \nCódigo de evento: %s", err, event.CodeEvent)
-		}
+// sendTextToContact es replyToContact indicando el operador que escribió el mensaje (nil si es del bot).
+// Devuelve el mensaje guardado.
+func (service *WhatsappService) sendTextToContact(numberPhone *entities.NumberPhone, contact *entities.Contact, text string, usersID *int64) (entities.Message, error) {
+	contactToString := contact.NumberPhone
+	message := metaapi.NewSendMessageWhatsappBasic(text, contactToString)
+	messageID, sendErr := service.postMessage(*numberPhone, message)
 
-	default:
-		// El texto a mostrar al usuario viene en assistantResp.Message
-		responseUser = assistantResp.Message
+	record := entities.Message{
+		NumberPhonesID:    numberPhone.ID,
+		ContactsID:        contact.ID,
+		MessageIdWhatsapp: messageID,
+		MessageText:       text,
+		IsFromBot:         true,
+		MessageType:       whatsapp.MessageTypeText,
+		UsersID:           usersID,
 	}
-
-	// 4. Guardar la respuesta que le daremos al usuario
-	err = saveMessageWithUniqueID(service, int(numberPhone.ID), int(contact.ID), responseUser)
-	if err != nil {
-		return fmt.Errorf("error saving contact message: %v", err)
+	if sendErr != nil {
+		markSendFailed(&record, sendErr)
 	}
 
-	// 5. Enviar la respuesta al usuario
-	contactToString := strconv.Itoa(int(contact.NumberPhone))
-	message := metaapi.NewSendMessageWhatsappBasic(responseUser, contactToString)
-	err = service.sendMessageBasic(message, strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10), numberPhone.TokenPermanent)
+	saved, err := saveBotMessage(service, record)
 	if err != nil {
-		return fmt.Errorf("error sending response to user: %v", err)
+		return saved, fmt.Errorf("error saving contact message: %v", err)
 	}
 
-	return nil
+	return saved, sendErr
 }
 
 func parseAssistantResponse(response string) (assistantResp *openaiassistantdtos.AssistantJSONResponse, err error) {
@@ -768,173 +519,39 @@ func generateUniqueID() string {
 	return string(id)
 }
 
-// Lógica para guardar un mensaje con un ID único
-func saveMessageWithUniqueID(service *WhatsappService, numberPhoneID, contactID int, response string) error {
-	var messageID string
-	var isUnique bool
-
-	// Intentar generar un ID único
-	for !isUnique {
-		messageID = generateUniqueID()
+// saveBotMessage guarda un mensaje enviado por el bot (o por un operador). Si WhatsApp no devolvió un wamid
+// (envío fallido) se genera un ID único para respetar la restricción de message_id_whatsapp.
+func saveBotMessage(service *WhatsappService, record entities.Message) (entities.Message, error) {
+	for record.MessageIdWhatsapp == "" {
+		messageID := generateUniqueID()
 
 		// Comprobar si el ID ya existe en la base de datos
 		exists, err := service.messagesRepository.ExistsByMessageID(messageID)
 		if err != nil {
-			return fmt.Errorf("error checking message ID uniqueness: %v", err)
+			return record, fmt.Errorf("error checking message ID uniqueness: %v", err)
 		}
 
 		if !exists {
-			isUnique = true
+			record.MessageIdWhatsapp = messageID
 		}
 	}
 
 	// Guardar el mensaje en la base de datos
-	err := service.messagesRepository.Create(entities.Message{
-		NumberPhonesID:    int64(numberPhoneID),
-		ContactsID:        int64(contactID),
-		MessageIdWhatsapp: messageID,
-		MessageText:       response,
-		IsFromBot:         true,
-	})
+	saved, err := service.messagesRepository.CreateAndReturn(record)
 	if err != nil {
-		return fmt.Errorf("error saving assistant response: %v", err)
+		return record, fmt.Errorf("error saving assistant response: %v", err)
 	}
 
-	return nil
-}
+	service.messagesService.PublishMessage(saved)
 
-func (s *WhatsappService) InteractWithAssistant(threadID, assistantID, message string) (response string, err error) {
-
-	// Verificar si es seguro proceder (sin runs activos)
-	safeToProceed, err := s.CheckForActiveRuns(threadID)
-	if err != nil {
-		return "", err // Devuelve el error si no es seguro proceder
-	}
-	if !safeToProceed {
-		return "", fmt.Errorf("an active run is still processing")
-	}
-
-	// Crear un run para el thread con la conversación completa
-	err = s.openAIAssistantService.SendMessageToThread(threadID, message, true)
-	if err != nil {
-		return "", fmt.Errorf("error creating message with conversation: %v", err)
-	}
-
-	runID, err := s.openAIAssistantService.CreateRunForThreadWithConversation(threadID, assistantID, nil)
-	if err != nil {
-		return "", fmt.Errorf("error creating run with conversation: %v", err)
-	}
-	fmt.Printf("Run created: %s\n", runID)
-
-	// Esperar a que el run esté completado
-	requiredAction, err := s.openAIAssistantService.WaitForRunCompletion(threadID, runID, 30, 2*time.Second)
-	if err != nil {
-		return "", fmt.Errorf("error waiting for run completion: %v", err)
-	}
-
-	// el assistant manda la funcion pero en un estado de required action, se pasa a JSON el objeto y se devuelve como string para ser decodificado y usado luego
-	if requiredAction.RequiredAction.Type == "submit_tool_outputs" && len(requiredAction.RequiredAction.SubmitToolOutputs.ToolCalls) > 0 {
-		response, err = convertToAssistantJSON(requiredAction)
-		if err != nil {
-			return "", fmt.Errorf("error getting messages from thread: %v", err)
-		}
-	} else {
-		// Obtener los mensajes del thread y encontrar la respuesta del asistente
-		response, err = s.openAIAssistantService.GetMessagesFromThread(threadID)
-		if err != nil {
-			return "", fmt.Errorf("error getting messages from thread: %v", err)
-		}
-	}
-
-	return response, nil
-}
-
-// ConvertToAssistantJSON convierte OpenAIRunResponse en AssistantJSONResponse
-func convertToAssistantJSON(response openairuns.OpenAIRunResponse) (string, error) {
-	// Verifica que haya una acción requerida
-
-	// Obtiene la primera toolCall (ajusta si pueden haber varias)
-	toolCall := response.RequiredAction.SubmitToolOutputs.ToolCalls[0]
-
-	// Decodifica los argumentos JSON
-	var args map[string]string
-	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
-		fmt.Printf("error al decodificar los argumentos (convertToAssistantJSON): %v", err)
-		return "", fmt.Errorf("error al decodificar los argumentos: %v", err)
-	}
-
-	// Crea la respuesta
-	assistantResponse := openaiassistantdtos.AssistantJSONResponse{
-		Function: toolCall.Function.Name,
-	}
-
-	// Mapea los argumentos decodificados a la estructura AssistantJSONResponse
-	if val, exists := args["user_name"]; exists {
-		assistantResponse.UserData.UserName = val
-	}
-	if val, exists := args["user_email"]; exists {
-		assistantResponse.UserData.UserEmail = val
-	}
-	if val, exists := args["meeting_date"]; exists {
-		assistantResponse.UserData.MeetingDate = val
-	}
-	if val, exists := args["event_code"]; exists {
-		assistantResponse.UserData.EventCode = val
-	}
-	if val, exists := args["new_date"]; exists {
-		assistantResponse.UserData.NewDate = val
-	}
-	if val, exists := args["date_to_search"]; exists {
-		assistantResponse.UserData.DateToSearch = val
-	}
-
-	// Convierte la estructura a JSON
-	jsonResponse, err := json.Marshal(assistantResponse)
-	if err != nil {
-		return "", fmt.Errorf("error al serializar AssistantJSONResponse: %v", err)
-	}
-
-	return string(jsonResponse), nil
-}
-
-// CheckForActiveRuns checks if there are any active runs for a given thread and retries a few times if there are.
-// Returns true if it is safe to proceed (no active runs), false and an error otherwise.
-func (s *WhatsappService) CheckForActiveRuns(threadID string) (bool, error) {
-	maxRetries := 5
-	retryInterval := time.Second * 10 // 10 segundos de intervalo entre reintento
-
-	for i := 0; i < maxRetries; i++ {
-		runs, err := s.openAIAssistantService.ListRunsForThread(threadID, 20, "desc", "", "")
-		if err != nil {
-			return false, fmt.Errorf("error listing runs for thread: %v", err)
-		}
-
-		activeRunFound := false
-		for _, run := range runs {
-			if run.Status == "active" {
-				activeRunFound = true
-				fmt.Printf("Waiting, found active run: %s\n", run.ID)
-				break
-			}
-			if run.Status == "requires_action" {
-				activeRunFound = true
-				fmt.Printf("Waiting, found requires_action run: %s\n", run.ID)
-				break
-			}
-		}
-
-		if !activeRunFound {
-			return true, nil // No active runs, safe to proceed
-		}
-
-		// If active runs are found and it's not the last retry attempt, wait before retrying
-		if i < maxRetries-1 {
-			fmt.Printf("Retrying in %v seconds...\n", retryInterval.Seconds())
-			time.Sleep(retryInterval)
+	// Los callbacks de estado pueden llegar antes de que se guarde el mensaje
+	if saved.Status != whatsapp.StatusFailed {
+		if err := service.messagesService.ApplyPendingStatuses(saved); err != nil {
+			log.Printf("Error applying pending statuses to message %s: %v", saved.MessageIdWhatsapp, err)
 		}
 	}
 
-	return false, fmt.Errorf("cannot create a new run, active run did not complete after %d retries", maxRetries)
+	return saved, nil
 }
 
 func (s *WhatsappService) NotifyInteractions(horasAtras uint) error {
@@ -1054,122 +671,106 @@ func (s *WhatsappService) NotifyInteractions(horasAtras uint) error {
 func (s *WhatsappService) SendWhatsappNotification(numberPhone entities.NumberPhone, message string) error {
 	// Lógica para enviar notificaciones por WhatsApp
 	// Puedes usar Baileys o la API que tengas configurada para este propósito
-	fmt.Printf("Enviando mensaje a %d: %s\n", numberPhone.NumberPhoneToNotify, message)
+	fmt.Printf("Enviando mensaje a %s: %s\n", numberPhone.NumberPhoneToNotify, message)
 
 	// Enviar la respuesta al usuario
-	contactToString := strconv.Itoa(int(numberPhone.NumberPhoneToNotify))
+	contactToString := numberPhone.NumberPhoneToNotify
 	messageForBody := metaapi.NewSendMessageWhatsappBasic(message, contactToString)
-	err := s.sendMessageBasic(messageForBody, strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10), numberPhone.TokenPermanent)
+	_, err := s.postMessage(numberPhone, messageForBody)
 	if err != nil {
 		return fmt.Errorf("error sending response to user: %v", err)
 	}
 	return nil
 }
 
-func (service *WhatsappService) sendMessageBasic(message metaapi.SendMessageBasic, phoneNumberId string, tokenApiWhatsapp string) error {
-
-	reqJSON, err := json.Marshal(message)
-	if err != nil {
-		fmt.Println("Error al convertir el cuerpo de la solicitud a JSON:", err)
-		return err
-	}
-
-	client := &http.Client{}
-
-	base, err := url.Parse(os.Getenv("WHATSAPP_URL") + "/" + os.Getenv("WHATSAPP_VERSION") + "/" + phoneNumberId + "/messages")
-	fmt.Println("Url: ", base.String())
+// postMessage envía cualquier mensaje (texto, template, media, interactivo) desde el número y devuelve el ID (wamid)
+// que asigna WhatsApp. Si Meta rechaza el envío devuelve un *clients.WhatsappAPIError.
+func (service *WhatsappService) postMessage(numberPhone entities.NumberPhone, message metaapi.OutgoingMessage) (string, error) {
+	response, err := service.whatsappClient.SendMessage(context.Background(), whatsappCredentials(numberPhone), message)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return err
+		log.Printf("Error enviando mensaje %s a %s desde el número %d: %v", message.MessageType(), message.Recipient(), numberPhone.ID, err)
+		return "", err
 	}
+	return response.MessageID(), nil
+}
 
-	req, err := http.NewRequest("POST", base.String(), bytes.NewBuffer(reqJSON))
-	if err != nil {
-		fmt.Println("Error al construir solicitud POST:", err)
-		return err
+// whatsappCredentials son las credenciales de la Cloud API del número
+func whatsappCredentials(numberPhone entities.NumberPhone) clients.WhatsappCredentials {
+	return clients.WhatsappCredentials{
+		PhoneNumberID: strconv.FormatInt(numberPhone.WhatsappNumberPhoneId, 10),
+		Token:         numberPhone.TokenPermanent,
 	}
+}
 
-	req.Header.Set("Authorization", "Bearer "+tokenApiWhatsapp)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-
-	if resp.StatusCode != 200 {
-		respuesta, erro := VerCuerpoRespuesta(resp)
-		if erro != nil {
-			fmt.Println("Error al mostrar respuesta erronea")
-		}
-		fmt.Println(respuesta)
-		return fmt.Errorf("Codigo error: " + resp.Status)
+// markSendFailed deja el mensaje a guardar con estado failed y el error que devolvió WhatsApp
+func markSendFailed(record *entities.Message, sendErr error) {
+	now := time.Now()
+	record.Status = whatsapp.StatusFailed
+	record.StatusUpdatedAt = &now
+	record.ErrorTitle = sendErr.Error()
+	var apiErr *clients.WhatsappAPIError
+	if errors.As(sendErr, &apiErr) {
+		record.ErrorCode = apiErr.Code
 	}
-
-	defer resp.Body.Close()
-
-	return err
 }
 
-// Función que muestra la respuesta que devuelve una API.
-func VerCuerpoRespuesta(resp *http.Response) (body []byte, err error) {
-	body, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalln(err)
+// HandleMessageStatuses registra los callbacks de estado (sent, delivered, read, failed) que llegan al webhook.
+// Si falla la base devuelve el error para que el job de la cola se reintente; registrar un estado dos veces no
+// duplica el historial.
+func (service *WhatsappService) HandleMessageStatuses(response whatsapp.ResponseComplet) error {
+	for _, entry := range response.Entry {
+		for _, change := range entry.Changes {
+			for _, status := range change.Value.Statuses {
+				if status.ID == "" || status.Status == "" {
+					log.Printf("Callback de estado sin id de mensaje o sin estado, se ignora: %+v", status)
+					continue
+				}
+				if err := service.messagesService.RegisterStatus(status); err != nil {
+					return fmt.Errorf("error registering status %s for message %s: %w", status.Status, status.ID, err)
+				}
+				if status.Status == whatsapp.StatusFailed {
+					log.Printf("WhatsApp informó el envío fallido del mensaje %s a %s: %+v", status.ID, status.RecipientID, status.Errors)
+				}
+			}
+		}
 	}
-	//log.Println(string(body))
-	//fmt.Print()
-	return
+	return nil
 }
 
 // Extrae la información del mensaje
-func extractMessageInfo(value whatsapp.Value) (sender, text, fechaMessage, messageType, phoneNumberID string, err error) {
+func extractMessageInfo(value whatsapp.Value, message whatsapp.Message) (sender, fechaMessage, phoneNumberID string, err error) {
+	if message.From == "" {
+		return "", "", "", fmt.Errorf("message %s without sender", message.ID)
+	}
 
-	fmt.Println("Fecha: " + value.Messages[0].Timestamp)
-	sender = value.Messages[0].From
-	text = value.Messages[0].Text.Body
-	messageType = value.Messages[0].Type
+	sender = message.From
+	fechaMessage = message.Timestamp
 	phoneNumberID = value.Metadata.PhoneNumberID
 
-	return sender, text, fechaMessage, messageType, phoneNumberID, nil
+	return sender, fechaMessage, phoneNumberID, nil
 }
 
-func (service *WhatsappService) SendMessageTemplate(message metaapi.SendMessageTemplate, phoneNumberId, tokenApiWhatsapp string) error {
-	reqJSON, err := json.Marshal(message)
-	if err != nil {
-		fmt.Println("Error al convertir la plantilla a JSON:", err)
-		return err
-	}
-
-	client := &http.Client{}
-
-	base, err := url.Parse(os.Getenv("WHATSAPP_URL") + "/" + os.Getenv("WHATSAPP_VERSION") + "/" + phoneNumberId + "/messages")
-	if err != nil {
-		fmt.Println("Error:", err)
-		return err
-	}
-
-	req, err := http.NewRequest("POST", base.String(), bytes.NewBuffer(reqJSON))
-	if err != nil {
-		fmt.Println("Error al construir solicitud POST:", err)
-		return err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+tokenApiWhatsapp)
-	req.Header.Set("Content-Type", "application/json")
+// SendMessageTemplate envía un template desde el número
+func (service *WhatsappService) SendMessageTemplate(message metaapi.SendMessageTemplate, numberPhone entities.NumberPhone) error {
+	_, err := service.postMessage(numberPhone, message)
+	return err
+}
 
-	resp, err := client.Do(req)
+// conversationHistory arma la conversación reciente del contacto para los proveedores que no guardan el contexto (Chat Completions, Ollama).
+// Usa la misma ventana de 12 horas con la que se renueva el thread de la Assistants API.
+func (service *WhatsappService) conversationHistory(contactID int64) ([]LLMMessage, error) {
+	messages, err := service.messagesRepository.GetRecentByContact(contactID, time.Now().Add(-12*time.Hour), 20)
 	if err != nil {
-		fmt.Println("Error al realizar la petición:", err)
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		respuesta, erro := VerCuerpoRespuesta(resp)
-		if erro != nil {
-			fmt.Println("Error al mostrar respuesta erronea")
+	history := make([]LLMMessage, 0, len(messages))
+	for _, message := range messages {
+		role := "user"
+		if message.IsFromBot {
+			role = "assistant"
 		}
-		fmt.Println(respuesta)
-		return fmt.Errorf("Codigo error: %s", resp.Status)
+		history = append(history, LLMMessage{Role: role, Content: message.MessageText})
 	}
-
-	return nil
+	return history, nil
 }
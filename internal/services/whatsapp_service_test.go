@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/dtos/whatsapp"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/entities"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/repositories/sqlite_client"
+	"github.com/OvniCore-SA/api_go_whatsapp_chatbot/internal/services/clients"
+)
+
+// El webhook busca el número y el contacto con los repositorios, sin depender de una base global
+func TestFindOrCreateContact(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	if err := db.Create(&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", WhatsappNumberPhoneId: 101}).Error; err != nil {
+		t.Fatalf("seeding number phone: %v", err)
+	}
+	service := &WhatsappService{numberPhone: NewNumberPhonesService(repos.NumberPhones), contactsRepository: repos.Contacts}
+
+	numberPhone, err := service.findNumberPhoneByPhoneID("101")
+	if err != nil || numberPhone.ID != 1 {
+		t.Fatalf("findNumberPhoneByPhoneID(101) = %+v, %v", numberPhone, err)
+	}
+	if _, err := service.findNumberPhoneByPhoneID("999"); err == nil {
+		t.Error("findNumberPhoneByPhoneID(999) found a number phone")
+	}
+
+	created, err := service.findOrCreateContact(numberPhone, "+5493510000001", "Juan")
+	if err != nil || created.ID == 0 {
+		t.Fatalf("findOrCreateContact (new) = %+v, %v", created, err)
+	}
+	found, err := service.findOrCreateContact(numberPhone, "+5493510000001", "Juan Pérez")
+	if err != nil || found.ID != created.ID || found.ProfileName != "Juan Pérez" {
+		t.Fatalf("findOrCreateContact (existing) = %+v, %v", found, err)
+	}
+
+	stored, err := repos.Contacts.FindByID(created.ID)
+	if err != nil || stored.ProfileName != "Juan Pérez" {
+		t.Errorf("stored contact = %+v, %v; want the new profile name", stored, err)
+	}
+	var count int64
+	db.Model(&entities.Contact{}).Count(&count)
+	if count != 1 {
+		t.Errorf("%d contacts, want 1", count)
+	}
+}
+
+// Meta puede reenviar el mismo callback y el job se puede reintentar: el historial no se duplica
+func TestHandleMessageStatusesIsIdempotent(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	service := &WhatsappService{messagesService: NewMessagesService(repos.Messages, repos.MessageStatuses, nil)}
+	response := whatsapp.ResponseComplet{Entry: []whatsapp.Entry{{Changes: []whatsapp.Change{{Value: whatsapp.Value{Statuses: []whatsapp.Status{
+		{ID: "wamid.1", Status: whatsapp.StatusSent, Timestamp: "1700000000"},
+		{ID: "wamid.1", Status: whatsapp.StatusDelivered, Timestamp: "1700000005"},
+	}}}}}}}
+
+	for i := 0; i < 2; i++ {
+		if err := service.HandleMessageStatuses(response); err != nil {
+			t.Fatalf("HandleMessageStatuses (delivery %d): %v", i+1, err)
+		}
+	}
+	history, err := repos.MessageStatuses.FindByMessageIdWhatsapp("wamid.1")
+	if err != nil || len(history) != 2 {
+		t.Errorf("history = %d statuses, %v; want sent and delivered once", len(history), err)
+	}
+}
+
+// Si no se puede guardar el estado, el error vuelve al job de la cola para que se reintente
+func TestHandleMessageStatusesReturnsDBErrors(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+	service := &WhatsappService{messagesService: NewMessagesService(repos.Messages, repos.MessageStatuses, nil)}
+	response := func(statuses ...whatsapp.Status) whatsapp.ResponseComplet {
+		return whatsapp.ResponseComplet{Entry: []whatsapp.Entry{{Changes: []whatsapp.Change{{Value: whatsapp.Value{Statuses: statuses}}}}}}
+	}
+
+	// Los callbacks incompletos se ignoran: reintentarlos no los arregla
+	if err := service.HandleMessageStatuses(response(whatsapp.Status{Status: whatsapp.StatusDelivered})); err != nil {
+		t.Errorf("status without message id = %v, want it ignored", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	if err := service.HandleMessageStatuses(response(whatsapp.Status{ID: "wamid.1", Status: whatsapp.StatusDelivered, Timestamp: "1700000000"})); err == nil {
+		t.Error("HandleMessageStatuses with the database down = nil, want the error")
+	}
+}
+
+// Fuera de la ventana de 24 horas solo se le pueden enviar templates al contacto
+func TestSendMessageOutsideServiceWindow(t *testing.T) {
+	db, err := sqlite_client.OpenInMemory()
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	repos := sqlite_client.NewRepositories(db)
+
+	// El último mensaje del contacto es de hace dos días
+	records := []interface{}{
+		&entities.NumberPhone{ID: 1, AssistantsID: 1, NumberPhone: "+5491100000001", UUID: "uuid-1", TokenPermanent: "token", WhatsappNumberPhoneId: 101},
+		&entities.Contact{ID: 1, NumberPhonesID: 1, NumberPhone: "+5493510000001"},
+		&entities.Message{NumberPhonesID: 1, ContactsID: 1, MessageText: "hola", MessageIdWhatsapp: "wamid.1", MessageType: "text", CreatedAt: time.Now().Add(-48 * time.Hour)},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seeding %T: %v", record, err)
+		}
+	}
+	// Sin URL de la Cloud API: si llegara a enviar, el envío falla con otro error
+	service := &WhatsappService{
+		numberPhone:        NewNumberPhonesService(repos.NumberPhones),
+		handoffService:     NewHandoffService(repos.Contacts, repos.Messages, nil),
+		messagesRepository: repos.Messages,
+		contactsRepository: repos.Contacts,
+		whatsappClient:     clients.NewWhatsappClient("", ""),
+	}
+
+	_, err = service.SendOutboundMessage(dtos.TenantScope{}, dtos.OutboundMessageDto{NumberPhoneID: 1, ContactID: 1, Type: dtos.OutboundTypeText, Text: "hola"})
+	if !errors.Is(err, dtos.ErrOutsideServiceWindow) {
+		t.Errorf("SendOutboundMessage = %v, want ErrOutsideServiceWindow", err)
+	}
+	if _, err := service.SendOperatorMessage(1, 0, "hola"); !errors.Is(err, dtos.ErrOutsideServiceWindow) {
+		t.Errorf("SendOperatorMessage = %v, want ErrOutsideServiceWindow", err)
+	}
+
+	var count int64
+	db.Model(&entities.Message{}).Where("contacts_id = 1").Count(&count)
+	if count != 1 {
+		t.Errorf("contact has %d messages, want only the seeded one", count)
+	}
+}